@@ -4,7 +4,9 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -13,9 +15,13 @@ import (
 
 	"rubyone-voice/config"
 	"rubyone-voice/database"
+	"rubyone-voice/esl"
+	"rubyone-voice/mailer"
 	"rubyone-voice/controllers"
 	"rubyone-voice/services"
 	"rubyone-voice/routes"
+	"rubyone-voice/storage"
+	"rubyone-voice/transcription"
 )
 
 func main() {
@@ -51,6 +57,177 @@ func main() {
 	permissionController := controllers.NewPermissionController(permissionService)
 	routes.SetupPermissionRoutes(app, permissionController, permissionService)
 
+	// Inicializar Tenants
+	tenantService := services.NewTenantService(database.DB)
+	tenantController := controllers.NewTenantController(tenantService)
+	routes.SetupTenantRoutes(app, tenantController)
+
+	// Inicializar Planos e Assinaturas
+	subscriptionService := services.NewSubscriptionService(database.DB)
+	subscriptionController := controllers.NewSubscriptionController(subscriptionService)
+	routes.SetupSubscriptionRoutes(app, subscriptionController)
+
+	// Inicializar chamadas ativas (registrado antes de /calls/:id)
+	activeCallService := services.NewActiveCallService(database.DB, func() (esl.Client, error) {
+		return esl.Dial(config.AppConfig.ESLAddress, config.AppConfig.ESLPassword, 5*time.Second, services.ActiveCallEvents...)
+	}, func() (esl.Client, error) {
+		return esl.Dial(config.AppConfig.ESLAddress, config.AppConfig.ESLPassword, 5*time.Second)
+	})
+	activeCallController := controllers.NewActiveCallController(activeCallService)
+	routes.SetupActiveCallRoutes(app, activeCallController)
+
+	// Inicializar Calls
+	callService := services.NewCallService(database.DB)
+	callController := controllers.NewCallController(callService)
+	routes.SetupCallRoutes(app, callController, database.DB)
+
+	// Inicializar credenciais de switch e ingestão de CDR
+	switchCredentialService := services.NewSwitchCredentialService(database.DB)
+	switchCredentialController := controllers.NewSwitchCredentialController(switchCredentialService)
+	routes.SetupSwitchCredentialRoutes(app, switchCredentialController)
+
+	cdrService := services.NewCdrService(database.DB)
+	cdrController := controllers.NewCdrController(cdrService)
+	routes.SetupCdrRoutes(app, cdrController, database.DB)
+
+	// Inicializar detecção de fraude
+	fraudService := services.NewFraudService(database.DB)
+	fraudController := controllers.NewFraudController(fraudService)
+	routes.SetupFraudRoutes(app, fraudController)
+
+	// Inicializar tarifação
+	ratingService := services.NewRatingService(database.DB)
+	ratingController := controllers.NewRatingController(ratingService)
+	routes.SetupRatingRoutes(app, ratingController)
+
+	// Inicializar importação de chamadas
+	callImportService := services.NewCallImportService(database.DB)
+	callImportController := controllers.NewCallImportController(callImportService)
+	routes.SetupCallImportRoutes(app, callImportController)
+
+	// Inicializar analytics de chamadas
+	analyticsService := services.NewAnalyticsService(database.DB)
+	analyticsController := controllers.NewAnalyticsController(analyticsService)
+	routes.SetupAnalyticsRoutes(app, analyticsController)
+
+	// Inicializar armazenamento de gravações
+	cfg := config.AppConfig
+	recordingStorage, err := storage.New(storage.Config{
+		Driver:    cfg.StorageDriver,
+		LocalPath: cfg.StorageLocalPath,
+		Endpoint:  cfg.S3Endpoint,
+		Region:    cfg.S3Region,
+		Bucket:    cfg.S3Bucket,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+		UseSSL:    cfg.S3UseSSL,
+	})
+	if err != nil {
+		log.Fatal("Falha ao inicializar armazenamento de gravações:", err)
+	}
+
+	recordingService := services.NewRecordingService(database.DB, recordingStorage)
+	recordingController := controllers.NewRecordingController(recordingService)
+	routes.SetupRecordingRoutes(app, recordingController)
+
+	// Inicializar transcrição de gravações
+	transcriptionProvider, err := transcription.New(transcription.Config{
+		Driver:  cfg.TranscriptionProvider,
+		Command: cfg.TranscriptionCommand,
+	})
+	if err != nil {
+		log.Fatal("Falha ao inicializar provedor de transcrição:", err)
+	}
+
+	transcriptionService := services.NewTranscriptionService(database.DB, recordingStorage, transcriptionProvider, cfg.TranscriptionMaxAttempts)
+	transcriptController := controllers.NewTranscriptController(transcriptionService)
+	routes.SetupTranscriptRoutes(app, transcriptController)
+
+	stopWorkers := make(chan struct{})
+	if transcriptionProvider != nil {
+		go transcriptionService.Run(time.Duration(cfg.TranscriptionPollInterval)*time.Second, stopWorkers)
+	}
+
+	// Inicializar retenção de chamadas
+	retentionService := services.NewRetentionService(database.DB, recordingStorage, cfg.RetentionGraceDays)
+	retentionController := controllers.NewRetentionController(retentionService)
+	routes.SetupRetentionRoutes(app, retentionController)
+	go retentionService.Run(time.Duration(cfg.RetentionPurgeInterval)*time.Second, stopWorkers)
+
+	// Inicializar números (DIDs)
+	phoneNumberService := services.NewPhoneNumberService(database.DB)
+	phoneNumberController := controllers.NewPhoneNumberController(phoneNumberService)
+	routes.SetupPhoneNumberRoutes(app, phoneNumberController)
+
+	// Inicializar endpoints SIP
+	sipEndpointService := services.NewSipEndpointService(database.DB)
+	sipEndpointController := controllers.NewSipEndpointController(sipEndpointService)
+	routes.SetupSipEndpointRoutes(app, sipEndpointController)
+
+	// Inicializar regras de roteamento de chamadas de entrada
+	routingService := services.NewRoutingService(database.DB)
+	routingScheduleService := services.NewRoutingScheduleService(database.DB)
+	routingController := controllers.NewRoutingController(routingService)
+	routingScheduleController := controllers.NewRoutingScheduleController(routingScheduleService)
+	routes.SetupRoutingRoutes(app, routingController, routingScheduleController)
+
+	// Inicializar fluxos de URA (IVR)
+	ivrFlowService := services.NewIvrFlowService(database.DB)
+	ivrFlowController := controllers.NewIvrFlowController(ivrFlowService)
+	routes.SetupIvrFlowRoutes(app, ivrFlowController)
+
+	// Inicializar filas de atendimento (ACD)
+	queueService := services.NewQueueService(database.DB)
+	queueController := controllers.NewQueueController(queueService)
+	routes.SetupQueueRoutes(app, queueController, database.DB)
+
+	// Inicializar correio de voz e notificações por e-mail
+	voicemailMailer, err := mailer.New(mailer.Config{
+		Driver:   cfg.MailerDriver,
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	})
+	if err != nil {
+		log.Fatal("Falha ao inicializar envio de e-mails:", err)
+	}
+
+	voicemailService := services.NewVoicemailService(database.DB, recordingStorage, voicemailMailer)
+	voicemailController := controllers.NewVoicemailController(voicemailService)
+	routes.SetupVoicemailRoutes(app, voicemailController, database.DB)
+
+	// Inicializar salas de conferência
+	conferenceService := services.NewConferenceService(database.DB)
+	conferenceController := controllers.NewConferenceController(conferenceService)
+	routes.SetupConferenceRoutes(app, conferenceController)
+
+	// Inicializar listas de bloqueio e permissão de chamadores
+	callerListService := services.NewCallerListService(database.DB)
+	callerListController := controllers.NewCallerListController(callerListService)
+	routes.SetupCallerListRoutes(app, callerListController)
+
+	// Inicializar provedor mod_xml_curl do FreeSWITCH
+	xmlCurlService := services.NewXMLCurlService(database.DB, cfg.FreeSwitchGateway)
+	xmlCurlController := controllers.NewXMLCurlController(xmlCurlService)
+	routes.SetupXMLCurlRoutes(app, xmlCurlController, cfg.XMLCurlSecret, strings.Split(cfg.XMLCurlAllowedIPs, ","))
+
+	// Inicializar originação de chamadas (click-to-call) via ESL
+	originateService := services.NewOriginateService(database.DB, func() (esl.Client, error) {
+		return esl.Dial(cfg.ESLAddress, cfg.ESLPassword, 5*time.Second, "BACKGROUND_JOB")
+	}, cfg.OriginateRingTimeout)
+	originateController := controllers.NewOriginateController(originateService)
+	routes.SetupOriginateRoutes(app, originateController)
+	if cfg.ESLAddress != "" {
+		go originateService.Run(time.Duration(cfg.ESLReconnectInterval)*time.Second, stopWorkers)
+		go activeCallService.Run(time.Duration(cfg.ESLReconnectInterval)*time.Second, stopWorkers)
+	}
+
+	// Inicializar controle de admissão de chamadas (limites de canais e CPS)
+	admissionService := services.NewAdmissionService(database.DB)
+	admissionController := controllers.NewAdmissionController(admissionService)
+	routes.SetupAdmissionRoutes(app, admissionController, database.DB)
 
 	// Middlewares
 	app.Use(recover.New())
@@ -78,6 +255,7 @@ func main() {
 	go func() {
 		<-c
 		log.Println("Gracefully shutting down...")
+		close(stopWorkers)
 		app.Shutdown()
 	}()
 
@@ -16,10 +16,56 @@ type Config struct {
 	Env        string `mapstructure:"ENV"`
 	JWTSecret  string `mapstructure:"JWT_SECRET"`
 	Port       string `mapstructure:"PORT"`
+
+	// Recording storage
+	StorageDriver    string `mapstructure:"STORAGE_DRIVER"`
+	StorageLocalPath string `mapstructure:"STORAGE_LOCAL_PATH"`
+	S3Endpoint       string `mapstructure:"S3_ENDPOINT"`
+	S3Region         string `mapstructure:"S3_REGION"`
+	S3Bucket         string `mapstructure:"S3_BUCKET"`
+	S3AccessKey      string `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey      string `mapstructure:"S3_SECRET_KEY"`
+	S3UseSSL         bool   `mapstructure:"S3_USE_SSL"`
+
+	// Signed recording download links
+	RecordingURLSecret string `mapstructure:"RECORDING_URL_SECRET"`
+	RecordingURLTTL    int    `mapstructure:"RECORDING_URL_TTL"`
+
+	// Call transcription
+	TranscriptionProvider     string `mapstructure:"TRANSCRIPTION_PROVIDER"`
+	TranscriptionCommand      string `mapstructure:"TRANSCRIPTION_COMMAND"`
+	TranscriptionPollInterval int    `mapstructure:"TRANSCRIPTION_POLL_INTERVAL"`
+	TranscriptionMaxAttempts  int    `mapstructure:"TRANSCRIPTION_MAX_ATTEMPTS"`
+
+	// Call retention purge
+	RetentionPurgeInterval int  `mapstructure:"RETENTION_PURGE_INTERVAL"`
+	RetentionGraceDays     uint `mapstructure:"RETENTION_GRACE_DAYS"`
+
+	// FreeSWITCH mod_xml_curl provider
+	XMLCurlSecret     string `mapstructure:"XMLCURL_SECRET"`
+	XMLCurlAllowedIPs string `mapstructure:"XMLCURL_ALLOWED_IPS"`
+	FreeSwitchGateway string `mapstructure:"FREESWITCH_GATEWAY"`
+
+	// FreeSWITCH event socket
+	ESLAddress           string `mapstructure:"ESL_ADDRESS"`
+	ESLPassword          string `mapstructure:"ESL_PASSWORD"`
+	ESLReconnectInterval int    `mapstructure:"ESL_RECONNECT_INTERVAL"`
+	OriginateRingTimeout int    `mapstructure:"ORIGINATE_RING_TIMEOUT"`
+
+	// Email notifications
+	MailerDriver string `mapstructure:"MAILER_DRIVER"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom     string `mapstructure:"SMTP_FROM"`
 }
 
 var AppConfig *Config
 
+// defaultJWTSecret is the placeholder JWT_SECRET shipped for development.
+const defaultJWTSecret = "your-secret-key"
+
 func LoadConfig() {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
@@ -36,14 +82,53 @@ func LoadConfig() {
 	viper.SetDefault("DB_NAME", "rubyone_voice_db")
 	viper.SetDefault("DB_SSL_MODE", "disable")
 	viper.SetDefault("ENV", "development")
-	viper.SetDefault("JWT_SECRET", "your-secret-key")
+	viper.SetDefault("JWT_SECRET", defaultJWTSecret)
 	viper.SetDefault("PORT", "8080")
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_PATH", "./data/recordings")
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY", "")
+	viper.SetDefault("S3_SECRET_KEY", "")
+	viper.SetDefault("S3_USE_SSL", false)
+	viper.SetDefault("RECORDING_URL_SECRET", "")
+	viper.SetDefault("RECORDING_URL_TTL", 300)
+	viper.SetDefault("TRANSCRIPTION_PROVIDER", "")
+	viper.SetDefault("TRANSCRIPTION_COMMAND", "")
+	viper.SetDefault("TRANSCRIPTION_POLL_INTERVAL", 30)
+	viper.SetDefault("TRANSCRIPTION_MAX_ATTEMPTS", 3)
+	viper.SetDefault("RETENTION_PURGE_INTERVAL", 3600)
+	viper.SetDefault("RETENTION_GRACE_DAYS", 30)
+	viper.SetDefault("XMLCURL_SECRET", "")
+	viper.SetDefault("XMLCURL_ALLOWED_IPS", "")
+	viper.SetDefault("FREESWITCH_GATEWAY", "")
+	viper.SetDefault("ESL_ADDRESS", "")
+	viper.SetDefault("ESL_PASSWORD", "ClueCon")
+	viper.SetDefault("ESL_RECONNECT_INTERVAL", 5)
+	viper.SetDefault("ORIGINATE_RING_TIMEOUT", 30)
+	viper.SetDefault("MAILER_DRIVER", "")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("SMTP_FROM", "")
 	
 	config := &Config{}
 	
 	if err := viper.Unmarshal(config); err != nil {
 		log.Fatal("Failed to unmarshal config:", err)
 	}
+
+	// Recording links are signed with RECORDING_URL_SECRET, or JWT_SECRET
+	// when it is unset. Signing with the public placeholder would let anyone
+	// forge download links.
+	if config.RecordingURLSecret == "" && (config.JWTSecret == "" || config.JWTSecret == defaultJWTSecret) {
+		if config.Env != "development" {
+			log.Fatal("RECORDING_URL_SECRET or JWT_SECRET must be set outside development")
+		}
+		log.Println("WARNING: neither RECORDING_URL_SECRET nor JWT_SECRET is set; recording links are signed with the development placeholder secret")
+	}
 	
 	AppConfig = config
 }
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type ActiveCallController struct {
+	ActiveCallService *services.ActiveCallService
+}
+
+func NewActiveCallController(service *services.ActiveCallService) *ActiveCallController {
+	return &ActiveCallController{ActiveCallService: service}
+}
+
+func (acc *ActiveCallController) GetActiveCalls(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	channels, err := acc.ActiveCallService.GetActiveCalls(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "active calls retrieved successfully",
+		"data":    channels,
+	})
+}
+
+func (acc *ActiveCallController) Hangup(c *fiber.Ctx) error {
+	if err := acc.ActiveCallService.Hangup(c.Params("uuid")); err != nil {
+		switch err.Error() {
+		case "invalid channel uuid":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "active call not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "switch not connected":
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "hangup requested",
+	})
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type AdmissionController struct {
+	AdmissionService *services.AdmissionService
+}
+
+func NewAdmissionController(service *services.AdmissionService) *AdmissionController {
+	return &AdmissionController{AdmissionService: service}
+}
+
+type admissionRequest struct {
+	CallUUID string `json:"call_uuid"`
+	Caller   string `json:"caller"`
+	Callee   string `json:"callee"`
+}
+
+// Authorize answers whether the switch may start a new call for the
+// credential's tenant. When the caller is given it is also screened against
+// the tenant's caller lists. A denial is a normal answer, not an error, so it is
+// returned with 200 and the reason.
+func (ac *AdmissionController) Authorize(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req admissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	decision, err := ac.AdmissionService.AuthorizeCall(tenantID, services.AdmissionCall{
+		UUID:   req.CallUUID,
+		Caller: req.Caller,
+		Callee: req.Callee,
+	})
+	if err != nil {
+		switch err.Error() {
+		case "call uuid is required":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "tenant not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "call uuid belongs to another tenant":
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	message := "call denied"
+	if decision.Allow {
+		message = "call allowed"
+	}
+
+	return c.JSON(fiber.Map{
+		"message": message,
+		"data":    decision,
+	})
+}
+
+// Release tells the API a call admitted earlier has ended.
+func (ac *AdmissionController) Release(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req admissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+	if req.CallUUID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "call uuid is required",
+		})
+	}
+
+	if err := ac.AdmissionService.Release(req.CallUUID, &tenantID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "call released successfully",
+	})
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type AnalyticsController struct {
+	AnalyticsService *services.AnalyticsService
+}
+
+func NewAnalyticsController(service *services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{AnalyticsService: service}
+}
+
+// GetCallStats takes from and to (RFC 3339, required), interval
+// (hour|day|month), group_by (prefix|country|caller|callee|direction|
+// disposition|hangup_cause|sip_code|hangup_side), prefix_length, direction,
+// disposition and tz.
+func (ac *AnalyticsController) GetCallStats(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid from timestamp",
+		})
+	}
+
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid to timestamp",
+		})
+	}
+
+	loc, err := time.LoadLocation(c.Query("tz", "UTC"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid tz",
+		})
+	}
+
+	report, err := ac.AnalyticsService.GetCallStats(tenantID, services.CallStatsQuery{
+		From:         from,
+		To:           to,
+		Interval:     c.Query("interval"),
+		GroupBy:      c.Query("group_by"),
+		PrefixLength: c.QueryInt("prefix_length", 2),
+		Direction:    c.Query("direction"),
+		Disposition:  c.Query("disposition"),
+		Location:     loc,
+	})
+	if err != nil {
+		switch err.Error() {
+		case "a valid from/to range is required", "invalid interval", "invalid group_by",
+			"invalid direction", "invalid disposition":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "call analytics retrieved successfully",
+		"data":    report,
+	})
+}
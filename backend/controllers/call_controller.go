@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
+	"strings"
+	"time"
 	"github.com/gofiber/fiber/v2"
 	"github.com/your-module/backend/services"
 )
@@ -22,6 +25,7 @@ func (cc *CallController) CreateCall(c *fiber.Ctx) error {
 		Callee       string `json:"callee"`
 		Duration     uint   `json:"duration"`
 		RecordingURL string `json:"recording_url"`
+		services.CallOutcome
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -42,8 +46,13 @@ func (cc *CallController) CreateCall(c *fiber.Ctx) error {
 		})
 	}
 
-	call, err := cc.CallService.CreateCall(tenantID, req.Caller, req.Callee, req.Duration, req.RecordingURL)
+	call, err := cc.CallService.CreateCall(tenantID, req.Caller, req.Callee, req.Duration, req.RecordingURL, req.CallOutcome)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -55,11 +64,28 @@ func (cc *CallController) CreateCall(c *fiber.Ctx) error {
 	})
 }
 
+// GetAllCalls searches the tenant's calls. Supported query parameters are
+// caller, callee, prefix, from, to (RFC 3339, on start_time), min_billsec,
+// max_billsec, answered, has_recording, direction, disposition,
+// hangup_cause, sip_code, sort, order, cursor and limit.
 func (cc *CallController) GetAllCalls(c *fiber.Ctx) error {
 	tenantID := c.Locals("tenant_id").(uint)
 
-	calls, err := cc.CallService.GetAllCalls(tenantID)
+	filter, err := parseCallFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	page, err := cc.CallService.SearchCalls(tenantID, *filter)
 	if err != nil {
+		switch err.Error() {
+		case "invalid sort field", "invalid sort order", "invalid cursor":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -67,10 +93,64 @@ func (cc *CallController) GetAllCalls(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"message": "calls retrieved successfully",
-		"data":    calls,
+		"data":    page.Calls,
+		"pagination": fiber.Map{
+			"total":       page.Total,
+			"limit":       page.Limit,
+			"has_more":    page.HasMore,
+			"next_cursor": page.NextCursor,
+		},
 	})
 }
 
+func parseCallFilter(c *fiber.Ctx) (*services.CallFilter, error) {
+	filter := &services.CallFilter{
+		Caller:      c.Query("caller"),
+		Callee:      c.Query("callee"),
+		Prefix:      c.Query("prefix"),
+		Country:     c.Query("country"),
+		Direction:   strings.ToLower(c.Query("direction")),
+		Disposition: strings.ToLower(c.Query("disposition")),
+		HangupCause: strings.ToUpper(c.Query("hangup_cause")),
+		Sort:        c.Query("sort"),
+		Order:       strings.ToLower(c.Query("order")),
+		Cursor:      c.Query("cursor"),
+		Limit:       c.QueryInt("limit", services.DefaultCallPageSize),
+	}
+
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, errors.New("invalid " + name + " timestamp")
+			}
+			*target = &t
+		}
+	}
+
+	for name, target := range map[string]**int{"min_billsec": &filter.MinBillsec, "max_billsec": &filter.MaxBillsec, "sip_code": &filter.SIPCode} {
+		if value := c.Query(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errors.New("invalid " + name)
+			}
+			*target = &n
+		}
+	}
+
+	for name, target := range map[string]**bool{"answered": &filter.Answered, "has_recording": &filter.HasRecording} {
+		if value := c.Query(name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("invalid " + name)
+			}
+			*target = &b
+		}
+	}
+
+	return filter, nil
+}
+
 func (cc *CallController) GetCallByID(c *fiber.Ctx) error {
 	tenantID := c.Locals("tenant_id").(uint)
 	
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type CallImportController struct {
+	CallImportService *services.CallImportService
+}
+
+func NewCallImportController(service *services.CallImportService) *CallImportController {
+	return &CallImportController{CallImportService: service}
+}
+
+// callImportMaxBytes caps an import file. The file is held in memory while
+// it is read, so larger exports have to be split.
+const callImportMaxBytes = 4 * 1024 * 1024
+
+// ImportCalls reads a CSV from a multipart "file" field or the raw request
+// body. Query parameters:
+//
+//	columns     field:source pairs, e.g. "caller:src,callee:dst,start_time:3"
+//	has_header  "false" when the first row is data (default "true")
+//	time_format "rfc3339" (default), "epoch" or a Go time layout
+//	tz          zone for time layouts without an offset (default UTC)
+func (cic *CallImportController) ImportCalls(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	opts := services.CallImportOptions{
+		Columns:    map[string]string{},
+		HasHeader:  c.Query("has_header", "true") != "false",
+		TimeFormat: c.Query("time_format"),
+		Location:   time.UTC,
+	}
+
+	if columns := c.Query("columns"); columns != "" {
+		for _, pair := range strings.Split(columns, ",") {
+			field, source, ok := strings.Cut(pair, ":")
+			if !ok || strings.TrimSpace(source) == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "invalid column mapping " + pair,
+				})
+			}
+			opts.Columns[strings.TrimSpace(field)] = strings.TrimSpace(source)
+		}
+	}
+
+	if tz := c.Query("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid tz",
+			})
+		}
+		opts.Location = loc
+	}
+
+	var body io.Reader
+	if file, err := c.FormFile("file"); err == nil {
+		if file.Size > callImportMaxBytes {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": "import file is too large",
+			})
+		}
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid import file",
+			})
+		}
+		defer f.Close()
+		body = f
+	} else {
+		if len(c.Body()) > callImportMaxBytes {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": "import file is too large",
+			})
+		}
+		body = bytes.NewReader(c.Body())
+	}
+
+	report, err := cic.CallImportService.ImportCSV(tenantID, body, opts)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "import file") ||
+			strings.HasPrefix(err.Error(), "invalid") ||
+			strings.HasPrefix(err.Error(), "mapped column") ||
+			strings.HasPrefix(err.Error(), "a caller") ||
+			strings.HasPrefix(err.Error(), "a callee") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "calls imported",
+		"data":    report,
+	})
+}
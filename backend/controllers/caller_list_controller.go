@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type CallerListController struct {
+	CallerListService *services.CallerListService
+}
+
+func NewCallerListController(service *services.CallerListService) *CallerListController {
+	return &CallerListController{CallerListService: service}
+}
+
+type callerListEntryRequest struct {
+	ListType  string     `json:"list_type"`
+	MatchType string     `json:"match_type"`
+	Pattern   string     `json:"pattern"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *callerListEntryRequest) toModel() *models.CallerListEntry {
+	return &models.CallerListEntry{
+		ListType:  r.ListType,
+		MatchType: r.MatchType,
+		Pattern:   r.Pattern,
+		Reason:    r.Reason,
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+func callerListErrorStatus(err error) int {
+	switch err.Error() {
+	case "caller list entry not found", "tenant not found":
+		return fiber.StatusNotFound
+	case "entry already exists":
+		return fiber.StatusConflict
+	case "invalid list type", "invalid match type", "invalid pattern", "pattern is required",
+		"wildcard pattern needs a * or ?", "wildcard pattern needs at least one digit",
+		"reason is too long", "expiry must be in the future":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (clc *CallerListController) CreateEntry(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	userID := c.Locals("user_id").(uint)
+
+	var req callerListEntryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	entry, err := clc.CallerListService.CreateEntry(tenantID, &userID, req.toModel())
+	if err != nil {
+		return c.Status(callerListErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "caller list entry created successfully",
+		"data":    entry,
+	})
+}
+
+// GetEntries lists entries. Query parameters:
+//   list             "block" or "allow" (default both)
+//   include_expired  "true" to include expired entries
+func (clc *CallerListController) GetEntries(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	listType := c.Query("list")
+	if listType != "" && listType != models.CallerListBlock && listType != models.CallerListAllow {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid list type",
+		})
+	}
+
+	entries, err := clc.CallerListService.GetEntries(tenantID, listType, c.Query("include_expired") == "true")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "caller list entries retrieved successfully",
+		"data":    entries,
+	})
+}
+
+func (clc *CallerListController) GetEntry(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	entryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid caller list entry ID",
+		})
+	}
+
+	entry, err := clc.CallerListService.GetEntryByID(tenantID, uint(entryID))
+	if err != nil {
+		return c.Status(callerListErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "caller list entry retrieved successfully",
+		"data":    entry,
+	})
+}
+
+func (clc *CallerListController) UpdateEntry(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	entryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid caller list entry ID",
+		})
+	}
+
+	var req callerListEntryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	entry, err := clc.CallerListService.UpdateEntry(tenantID, uint(entryID), req.toModel())
+	if err != nil {
+		return c.Status(callerListErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "caller list entry updated successfully",
+		"data":    entry,
+	})
+}
+
+func (clc *CallerListController) DeleteEntry(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	entryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid caller list entry ID",
+		})
+	}
+
+	if err := clc.CallerListService.DeleteEntry(tenantID, uint(entryID)); err != nil {
+		return c.Status(callerListErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "caller list entry deleted successfully",
+	})
+}
+
+// ImportEntries reads a CSV from a multipart "file" field or the raw
+// request body. The header names the columns: list and pattern, and
+// optionally match_type, reason and expires_at.
+func (clc *CallerListController) ImportEntries(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	userID := c.Locals("user_id").(uint)
+
+	var body io.Reader
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid import file",
+			})
+		}
+		defer f.Close()
+		body = f
+	} else {
+		body = bytes.NewReader(c.Body())
+	}
+
+	report, err := clc.CallerListService.ImportCSV(tenantID, &userID, body)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "import file") ||
+			strings.HasPrefix(err.Error(), "invalid") ||
+			strings.HasPrefix(err.Error(), "a list") ||
+			strings.HasPrefix(err.Error(), "a pattern") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(callerListErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "caller list entries imported",
+		"data":    report,
+	})
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type CdrController struct {
+	CdrService *services.CdrService
+}
+
+func NewCdrController(service *services.CdrService) *CdrController {
+	return &CdrController{CdrService: service}
+}
+
+func (cc *CdrController) IngestFreeSwitchCDR(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	payload, err := services.DecodeFreeSwitchPayload(c.Body(), c.FormValue("cdr"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	call, created, err := cc.CdrService.IngestFreeSwitchCDR(tenantID, payload)
+	if err != nil {
+		switch err.Error() {
+		case "call uuid belongs to another tenant":
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "invalid cdr payload",
+			"cdr is missing the uuid variable",
+			"cdr is missing the caller number",
+			"cdr is missing the destination number":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if !created {
+		return c.JSON(fiber.Map{
+			"message": "cdr already ingested, call updated",
+			"data":    call,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "cdr ingested successfully",
+		"data":    call,
+	})
+}
+
+func (cc *CdrController) IngestAsteriskCDR(c *fiber.Ctx) error {
+	return cc.ingestAsterisk(c, false)
+}
+
+func (cc *CdrController) IngestAsteriskCEL(c *fiber.Ctx) error {
+	return cc.ingestAsterisk(c, true)
+}
+
+// ingestAsterisk handles both Asterisk endpoints. Timestamps in the records
+// are read in the "tz" query parameter's zone, defaulting to UTC.
+func (cc *CdrController) ingestAsterisk(c *fiber.Ctx, cel bool) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		parsed, err := time.LoadLocation(tz)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid tz",
+			})
+		}
+		loc = parsed
+	}
+
+	rows, err := services.ParseAsteriskRows(c.Body(), cel)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var result *services.AsteriskIngestResult
+	if cel {
+		result, err = cc.CdrService.IngestAsteriskCEL(tenantID, rows, loc)
+	} else {
+		result, err = cc.CdrService.IngestAsteriskCDR(tenantID, rows, loc)
+	}
+	if err != nil {
+		switch err.Error() {
+		case "call uuid belongs to another tenant":
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "cdr row is missing uniqueid",
+			"cdr row is missing src or dst",
+			"cel event is missing linkedid":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "asterisk records ingested successfully",
+		"data":    result,
+	})
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type ConferenceController struct {
+	ConferenceService *services.ConferenceService
+}
+
+func NewConferenceController(service *services.ConferenceService) *ConferenceController {
+	return &ConferenceController{ConferenceService: service}
+}
+
+type conferenceRoomRequest struct {
+	Name             string     `json:"name"`
+	Extension        string     `json:"extension"`
+	PhoneNumberID    *uint      `json:"phone_number_id"`
+	ParticipantPIN   string     `json:"participant_pin"`
+	ModeratorPIN     string     `json:"moderator_pin"`
+	MaxParticipants  int        `json:"max_participants"`
+	RecordingEnabled bool       `json:"recording_enabled"`
+	ValidFrom        *time.Time `json:"valid_from"`
+	ValidUntil       *time.Time `json:"valid_until"`
+	IsActive         *bool      `json:"is_active"`
+}
+
+func (r *conferenceRoomRequest) toModel() *models.ConferenceRoom {
+	room := &models.ConferenceRoom{
+		Name:             r.Name,
+		Extension:        r.Extension,
+		PhoneNumberID:    r.PhoneNumberID,
+		ParticipantPIN:   r.ParticipantPIN,
+		ModeratorPIN:     r.ModeratorPIN,
+		MaxParticipants:  r.MaxParticipants,
+		RecordingEnabled: r.RecordingEnabled,
+		ValidFrom:        r.ValidFrom,
+		ValidUntil:       r.ValidUntil,
+		IsActive:         true,
+	}
+	if r.IsActive != nil {
+		room.IsActive = *r.IsActive
+	}
+	return room
+}
+
+func conferenceErrorStatus(err error) int {
+	switch err.Error() {
+	case "conference room not found", "phone number not found", "tenant not found":
+		return fiber.StatusNotFound
+	case "extension already has a conference room", "extension is used by a sip endpoint",
+		"phone number already has a conference room":
+		return fiber.StatusConflict
+	case "quota exceeded: max conference rooms reached", "max participants exceeds the plan limit",
+		"no active subscription found":
+		return fiber.StatusForbidden
+	case "name is required", "extension must be 2 to 10 digits", "pin must be 4 to 10 digits",
+		"moderator and participant pins must differ", "max participants cannot be negative",
+		"valid until must be after valid from":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (cc *ConferenceController) CreateRoom(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req conferenceRoomRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	room, err := cc.ConferenceService.CreateRoom(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(conferenceErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "conference room created successfully",
+		"data":    room,
+	})
+}
+
+func (cc *ConferenceController) GetRooms(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	rooms, err := cc.ConferenceService.GetRooms(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "conference rooms retrieved successfully",
+		"data":    rooms,
+	})
+}
+
+func (cc *ConferenceController) GetRoom(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid conference room ID",
+		})
+	}
+
+	room, err := cc.ConferenceService.GetRoomByID(tenantID, uint(roomID))
+	if err != nil {
+		return c.Status(conferenceErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "conference room retrieved successfully",
+		"data":    room,
+	})
+}
+
+func (cc *ConferenceController) UpdateRoom(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid conference room ID",
+		})
+	}
+
+	var req conferenceRoomRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	room, err := cc.ConferenceService.UpdateRoom(tenantID, uint(roomID), req.toModel())
+	if err != nil {
+		return c.Status(conferenceErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "conference room updated successfully",
+		"data":    room,
+	})
+}
+
+func (cc *ConferenceController) DeleteRoom(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid conference room ID",
+		})
+	}
+
+	if err := cc.ConferenceService.DeleteRoom(tenantID, uint(roomID)); err != nil {
+		return c.Status(conferenceErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "conference room deleted successfully",
+	})
+}
+
+// GetParticipants lists who is in the room now, as reported by the switch.
+func (cc *ConferenceController) GetParticipants(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid conference room ID",
+		})
+	}
+
+	participants, err := cc.ConferenceService.GetParticipants(tenantID, uint(roomID))
+	if err != nil {
+		return c.Status(conferenceErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "conference participants retrieved successfully",
+		"data":    participants,
+	})
+}
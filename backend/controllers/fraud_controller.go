@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type FraudController struct {
+	FraudService *services.FraudService
+}
+
+func NewFraudController(service *services.FraudService) *FraudController {
+	return &FraudController{FraudService: service}
+}
+
+// fraudRuleRequest uses pointers so an update only changes the fields that
+// were sent.
+type fraudRuleRequest struct {
+	Name            *string  `json:"name"`
+	Type            *string  `json:"type"`
+	Prefixes        []string `json:"prefixes"`
+	Threshold       *float64 `json:"threshold"`
+	BaselineHours   *int     `json:"baseline_hours"`
+	MinCalls        *int     `json:"min_calls"`
+	CooldownMinutes *int     `json:"cooldown_minutes"`
+	BlockTenant     *bool    `json:"block_tenant"`
+	IsActive        *bool    `json:"is_active"`
+}
+
+func (r *fraudRuleRequest) toModel() *models.FraudRule {
+	rule := &models.FraudRule{
+		BaselineHours:   168,
+		MinCalls:        10,
+		CooldownMinutes: 60,
+		IsActive:        true,
+	}
+	r.applyTo(rule)
+	return rule
+}
+
+func (r *fraudRuleRequest) applyTo(rule *models.FraudRule) {
+	if r.Name != nil {
+		rule.Name = strings.TrimSpace(*r.Name)
+	}
+	if r.Type != nil {
+		rule.Type = *r.Type
+	}
+	if r.Prefixes != nil {
+		rule.Prefixes = strings.Join(r.Prefixes, ",")
+	}
+	if r.Threshold != nil {
+		rule.Threshold = *r.Threshold
+	}
+	if r.BaselineHours != nil {
+		rule.BaselineHours = *r.BaselineHours
+	}
+	if r.MinCalls != nil {
+		rule.MinCalls = *r.MinCalls
+	}
+	if r.CooldownMinutes != nil {
+		rule.CooldownMinutes = *r.CooldownMinutes
+	}
+	if r.BlockTenant != nil {
+		rule.BlockTenant = *r.BlockTenant
+	}
+	if r.IsActive != nil {
+		rule.IsActive = *r.IsActive
+	}
+}
+
+func fraudErrorStatus(err error) int {
+	switch err.Error() {
+	case "fraud rule not found", "fraud alert not found", "tenant not found":
+		return fiber.StatusNotFound
+	case "fraud alert already resolved", "tenant is not blocked":
+		return fiber.StatusConflict
+	case "rule name is required", "invalid rule type", "at least one prefix is required",
+		"threshold must be greater than 0", "spike threshold must be greater than 1",
+		"baseline hours cannot be negative", "min calls must be at least 1", "cooldown cannot be negative":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (fc *FraudController) CreateRule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req fraudRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	rule, err := fc.FraudService.CreateRule(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(fraudErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "fraud rule created successfully",
+		"data":    rule,
+	})
+}
+
+func (fc *FraudController) GetRules(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	rules, err := fc.FraudService.GetRules(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "fraud rules retrieved successfully",
+		"data":    rules,
+	})
+}
+
+func (fc *FraudController) UpdateRule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	ruleIDStr := c.Params("id")
+	ruleID, err := strconv.ParseUint(ruleIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rule ID",
+		})
+	}
+
+	var req fraudRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	rule, err := fc.FraudService.GetRuleByID(tenantID, uint(ruleID))
+	if err != nil {
+		return c.Status(fraudErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	req.applyTo(rule)
+
+	rule, err = fc.FraudService.UpdateRule(tenantID, uint(ruleID), rule)
+	if err != nil {
+		return c.Status(fraudErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "fraud rule updated successfully",
+		"data":    rule,
+	})
+}
+
+func (fc *FraudController) DeleteRule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	ruleIDStr := c.Params("id")
+	ruleID, err := strconv.ParseUint(ruleIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rule ID",
+		})
+	}
+
+	if err := fc.FraudService.DeleteRule(tenantID, uint(ruleID)); err != nil {
+		return c.Status(fraudErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "fraud rule deleted successfully",
+	})
+}
+
+// GetAlerts lists the tenant's most recent alerts, optionally filtered by
+// ?status=open|resolved.
+func (fc *FraudController) GetAlerts(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	alerts, err := fc.FraudService.GetAlerts(tenantID, c.Query("status"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "fraud alerts retrieved successfully",
+		"data":    alerts,
+	})
+}
+
+func (fc *FraudController) ResolveAlert(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	userID := c.Locals("user_id").(uint)
+
+	alertIDStr := c.Params("id")
+	alertID, err := strconv.ParseUint(alertIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid alert ID",
+		})
+	}
+
+	alert, err := fc.FraudService.ResolveAlert(tenantID, uint(alertID), userID)
+	if err != nil {
+		return c.Status(fraudErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "fraud alert resolved successfully",
+		"data":    alert,
+	})
+}
+
+// UnblockTenant lets an admin restore call creation for a tenant blocked by
+// a fraud rule.
+func (fc *FraudController) UnblockTenant(c *fiber.Ctx) error {
+	tenantIDStr := c.Params("id")
+	tenantID, err := strconv.ParseUint(tenantIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid tenant ID",
+		})
+	}
+
+	if err := fc.FraudService.UnblockTenant(uint(tenantID)); err != nil {
+		return c.Status(fraudErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "tenant fraud block cleared successfully",
+	})
+}
@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"strconv"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/ivr"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type IvrFlowController struct {
+	IvrFlowService *services.IvrFlowService
+}
+
+func NewIvrFlowController(service *services.IvrFlowService) *IvrFlowController {
+	return &IvrFlowController{IvrFlowService: service}
+}
+
+type ivrFlowRequest struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Graph       ivr.Graph `json:"graph"`
+}
+
+func (r *ivrFlowRequest) toModel() *models.IvrFlow {
+	return &models.IvrFlow{
+		Name:        r.Name,
+		Description: r.Description,
+		Graph:       r.Graph,
+	}
+}
+
+func ivrFlowErrorStatus(err error) int {
+	switch err.Error() {
+	case "ivr flow not found", "ivr flow version not found":
+		return fiber.StatusNotFound
+	case "ivr flow is used by a routing rule":
+		return fiber.StatusConflict
+	case "ivr flow has validation errors":
+		return fiber.StatusUnprocessableEntity
+	case "name is required", ivr.ErrInvalidInput.Error():
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func parseIvrFlowID(c *fiber.Ctx) (uint, bool) {
+	flowID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(flowID), true
+}
+
+func (ic *IvrFlowController) CreateFlow(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req ivrFlowRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	flow, err := ic.IvrFlowService.CreateFlow(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ivr flow created successfully",
+		"data":    flow,
+	})
+}
+
+func (ic *IvrFlowController) GetFlows(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flows, err := ic.IvrFlowService.GetFlows(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flows retrieved successfully",
+		"data":    flows,
+	})
+}
+
+func (ic *IvrFlowController) GetFlow(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	flow, err := ic.IvrFlowService.GetFlowByID(tenantID, flowID)
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flow retrieved successfully",
+		"data":    flow,
+	})
+}
+
+func (ic *IvrFlowController) UpdateFlow(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	var req ivrFlowRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	flow, err := ic.IvrFlowService.UpdateFlow(tenantID, flowID, req.toModel())
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flow updated successfully",
+		"data":    flow,
+	})
+}
+
+func (ic *IvrFlowController) DeleteFlow(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	if err := ic.IvrFlowService.DeleteFlow(tenantID, flowID); err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flow deleted successfully",
+	})
+}
+
+// ValidateFlow reports the draft's problems; an empty list means it can be
+// published.
+func (ic *IvrFlowController) ValidateFlow(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	problems, err := ic.IvrFlowService.ValidateFlow(tenantID, flowID)
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if problems == nil {
+		problems = []ivr.Problem{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flow validated",
+		"data": fiber.Map{
+			"valid":    len(problems) == 0,
+			"problems": problems,
+		},
+	})
+}
+
+func (ic *IvrFlowController) PublishFlow(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	userID := c.Locals("user_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	version, problems, err := ic.IvrFlowService.PublishFlow(tenantID, flowID, &userID)
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error":    err.Error(),
+			"problems": problems,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "ivr flow published successfully",
+		"data":    version,
+	})
+}
+
+func (ic *IvrFlowController) GetVersions(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	versions, err := ic.IvrFlowService.GetVersions(tenantID, flowID)
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flow versions retrieved successfully",
+		"data":    versions,
+	})
+}
+
+func (ic *IvrFlowController) GetVersion(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil || version < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid version",
+		})
+	}
+
+	flowVersion, err := ic.IvrFlowService.GetVersion(tenantID, flowID, version)
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flow version retrieved successfully",
+		"data":    flowVersion,
+	})
+}
+
+// Simulate walks the flow with the given DTMF inputs, one per collect
+// node. Version 0 or omitted runs the draft; lookups supplies responses for
+// http_lookup nodes by node ID.
+func (ic *IvrFlowController) Simulate(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	flowID, ok := parseIvrFlowID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid ivr flow ID",
+		})
+	}
+
+	var req struct {
+		Version int               `json:"version"`
+		Inputs  []string          `json:"inputs"`
+		Lookups map[string]string `json:"lookups"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+	if req.Version < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid version",
+		})
+	}
+
+	simulation, problems, err := ic.IvrFlowService.Simulate(tenantID, flowID, services.IvrSimulation{
+		Version: req.Version,
+		Inputs:  req.Inputs,
+		Lookups: req.Lookups,
+	})
+	if err != nil {
+		return c.Status(ivrFlowErrorStatus(err)).JSON(fiber.Map{
+			"error":    err.Error(),
+			"problems": problems,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "ivr flow simulated successfully",
+		"data":    simulation,
+	})
+}
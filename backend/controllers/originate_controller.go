@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"strings"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type OriginateController struct {
+	OriginateService *services.OriginateService
+}
+
+func NewOriginateController(service *services.OriginateService) *OriginateController {
+	return &OriginateController{OriginateService: service}
+}
+
+// Originate rings the caller's extension (or endpoint_id when given) and
+// bridges it to the destination once answered. The call is tracked by the
+// returned job; its call_uuid is the UUID the CDR will carry.
+func (oc *OriginateController) Originate(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	userID := c.Locals("user_id").(uint)
+
+	var req struct {
+		EndpointID  *uint  `json:"endpoint_id"`
+		Destination string `json:"destination"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	job, err := oc.OriginateService.Originate(tenantID, userID, services.OriginateRequest{
+		EndpointID:  req.EndpointID,
+		Destination: req.Destination,
+	})
+	if err != nil {
+		switch {
+		case err.Error() == "invalid destination":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case err.Error() == "sip endpoint not found", err.Error() == "tenant not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case strings.HasPrefix(err.Error(), "call not admitted"):
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case err.Error() == "switch not connected", strings.HasPrefix(err.Error(), "switch rejected originate"):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "call originate queued",
+		"data":    job,
+	})
+}
+
+func (oc *OriginateController) GetJob(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	job, err := oc.OriginateService.GetJob(tenantID, c.Params("job_uuid"))
+	if err != nil {
+		if err.Error() == "originate job not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "originate job retrieved successfully",
+		"data":    job,
+	})
+}
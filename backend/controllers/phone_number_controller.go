@@ -0,0 +1,385 @@
+package controllers
+
+import (
+	"strconv"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type PhoneNumberController struct {
+	PhoneNumberService *services.PhoneNumberService
+}
+
+func NewPhoneNumberController(service *services.PhoneNumberService) *PhoneNumberController {
+	return &PhoneNumberController{PhoneNumberService: service}
+}
+
+// phoneNumberRequest carries the inventory attributes shared by create,
+// import and update.
+type phoneNumberRequest struct {
+	Status       string  `json:"status"`
+	VoiceEnabled *bool   `json:"voice_enabled"`
+	SMSEnabled   bool    `json:"sms_enabled"`
+	FaxEnabled   bool    `json:"fax_enabled"`
+	MonthlyCost  float64 `json:"monthly_cost"`
+	Carrier      string  `json:"carrier"`
+}
+
+func (r *phoneNumberRequest) toModel() models.PhoneNumber {
+	number := models.PhoneNumber{
+		Status:       r.Status,
+		VoiceEnabled: true,
+		SMSEnabled:   r.SMSEnabled,
+		FaxEnabled:   r.FaxEnabled,
+		MonthlyCost:  r.MonthlyCost,
+		Carrier:      r.Carrier,
+	}
+	if r.VoiceEnabled != nil {
+		number.VoiceEnabled = *r.VoiceEnabled
+	}
+	return number
+}
+
+func phoneNumberErrorStatus(err error) int {
+	switch err.Error() {
+	case "phone number not found", "tenant not found":
+		return fiber.StatusNotFound
+	case "phone number already exists", "phone number is not available", "phone number is not assigned",
+		"cannot delete an assigned phone number":
+		return fiber.StatusConflict
+	case "quota exceeded: max phone numbers reached", "no active subscription found":
+		return fiber.StatusForbidden
+	case "invalid phone number", "monthly cost cannot be negative", "no phone numbers to import",
+		"too many phone numbers to import", "invalid block size", "invalid block start",
+		"number block overflows its range", "invalid phone number status", "invalid routing type",
+		"routing destination is required", "invalid routing destination":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func parseNumberID(c *fiber.Ctx) (uint, bool) {
+	numberID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(numberID), true
+}
+
+func (pc *PhoneNumberController) CreateNumber(c *fiber.Ctx) error {
+	var req struct {
+		phoneNumberRequest
+		Number string `json:"number"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.CreateNumber(req.Number, req.toModel())
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "phone number created successfully",
+		"data":    number,
+	})
+}
+
+// ImportNumbers accepts either {"numbers": [...]} or a block given by
+// {"block_start": "+551130000000", "block_size": 100}.
+func (pc *PhoneNumberController) ImportNumbers(c *fiber.Ctx) error {
+	var req struct {
+		phoneNumberRequest
+		Numbers    []string `json:"numbers"`
+		BlockStart string   `json:"block_start"`
+		BlockSize  int      `json:"block_size"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	result, err := pc.PhoneNumberService.ImportNumbers(services.PhoneNumberImport{
+		Numbers:    req.Numbers,
+		BlockStart: req.BlockStart,
+		BlockSize:  req.BlockSize,
+		Template:   req.toModel(),
+	})
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone numbers imported",
+		"data":    result,
+	})
+}
+
+func (pc *PhoneNumberController) GetNumbers(c *fiber.Ctx) error {
+	filter := services.PhoneNumberFilter{
+		Status:  c.Query("status"),
+		Country: c.Query("country"),
+		Prefix:  c.Query("prefix"),
+	}
+
+	if tenantIDStr := c.Query("tenant_id"); tenantIDStr != "" {
+		tenantID, err := strconv.ParseUint(tenantIDStr, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid tenant ID",
+			})
+		}
+		id := uint(tenantID)
+		filter.TenantID = &id
+	}
+
+	numbers, err := pc.PhoneNumberService.GetNumbers(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone numbers retrieved successfully",
+		"data":    numbers,
+	})
+}
+
+func (pc *PhoneNumberController) GetNumber(c *fiber.Ctx) error {
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.GetNumberByID(numberID, nil)
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number retrieved successfully",
+		"data":    number,
+	})
+}
+
+func (pc *PhoneNumberController) UpdateNumber(c *fiber.Ctx) error {
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	var req phoneNumberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.UpdateNumber(numberID, req.toModel())
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number updated successfully",
+		"data":    number,
+	})
+}
+
+func (pc *PhoneNumberController) DeleteNumber(c *fiber.Ctx) error {
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	if err := pc.PhoneNumberService.DeleteNumber(numberID); err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number deleted successfully",
+	})
+}
+
+func (pc *PhoneNumberController) AssignNumber(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	var req struct {
+		TenantID uint `json:"tenant_id"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.TenantID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "tenant_id is required",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.AssignNumber(numberID, req.TenantID, &userID)
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number assigned successfully",
+		"data":    number,
+	})
+}
+
+func (pc *PhoneNumberController) ReleaseNumber(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.ReleaseNumber(numberID, nil, &userID)
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number released successfully",
+		"data":    number,
+	})
+}
+
+func (pc *PhoneNumberController) GetTenantNumbers(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	numbers, err := pc.PhoneNumberService.GetNumbers(services.PhoneNumberFilter{
+		TenantID: &tenantID,
+		Status:   c.Query("status"),
+		Prefix:   c.Query("prefix"),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone numbers retrieved successfully",
+		"data":    numbers,
+	})
+}
+
+func (pc *PhoneNumberController) GetTenantNumber(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.GetNumberByID(numberID, &tenantID)
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number retrieved successfully",
+		"data":    number,
+	})
+}
+
+// SetRouting accepts {"routing_type": "", "routing_destination": ""} to
+// clear the routing.
+func (pc *PhoneNumberController) SetRouting(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	var req struct {
+		RoutingType        string `json:"routing_type"`
+		RoutingDestination string `json:"routing_destination"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.SetRouting(tenantID, numberID, req.RoutingType, req.RoutingDestination)
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number routing updated successfully",
+		"data":    number,
+	})
+}
+
+func (pc *PhoneNumberController) ReleaseTenantNumber(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	userID := c.Locals("user_id").(uint)
+
+	numberID, ok := parseNumberID(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number ID",
+		})
+	}
+
+	number, err := pc.PhoneNumberService.ReleaseNumber(numberID, &tenantID, &userID)
+	if err != nil {
+		return c.Status(phoneNumberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "phone number released successfully",
+		"data":    number,
+	})
+}
@@ -0,0 +1,406 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type QueueController struct {
+	QueueService *services.QueueService
+}
+
+func NewQueueController(service *services.QueueService) *QueueController {
+	return &QueueController{QueueService: service}
+}
+
+type queueRequest struct {
+	Name                string `json:"name"`
+	Strategy            string `json:"strategy"`
+	ServiceLevelSeconds int    `json:"service_level_seconds"`
+	WrapUpSeconds       int    `json:"wrap_up_seconds"`
+	AgentTimeout        int    `json:"agent_timeout"`
+}
+
+func (r *queueRequest) toModel() *models.Queue {
+	return &models.Queue{
+		Name:                r.Name,
+		Strategy:            r.Strategy,
+		ServiceLevelSeconds: r.ServiceLevelSeconds,
+		WrapUpSeconds:       r.WrapUpSeconds,
+		AgentTimeout:        r.AgentTimeout,
+	}
+}
+
+type agentStateRequest struct {
+	State       string `json:"state"`
+	PauseReason string `json:"pause_reason"`
+}
+
+func queueErrorStatus(err error) int {
+	switch err.Error() {
+	case "queue not found", "queue agent not found", "user not found":
+		return fiber.StatusNotFound
+	case "user is already an agent of this queue", "queue is used by a routing rule",
+		"agent is on a call":
+		return fiber.StatusConflict
+	case "name is required", "invalid queue strategy", "wrap-up seconds cannot be negative",
+		"invalid agent state", "pause reason is required", "a valid from/to range is required",
+		"call uuid is required", "invalid call event type", "queue is required", "agent is required":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+// parseQueueParams reads the queue ID and, when the route has one, the
+// agent ID.
+func parseQueueParams(c *fiber.Ctx) (uint, uint, bool) {
+	queueID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	if c.Params("agent_id") == "" {
+		return uint(queueID), 0, true
+	}
+	agentID, err := strconv.ParseUint(c.Params("agent_id"), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(queueID), uint(agentID), true
+}
+
+func (qc *QueueController) CreateQueue(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req queueRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	queue, err := qc.QueueService.CreateQueue(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "queue created successfully",
+		"data":    queue,
+	})
+}
+
+func (qc *QueueController) GetQueues(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queues, err := qc.QueueService.GetQueues(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "queues retrieved successfully",
+		"data":    queues,
+	})
+}
+
+func (qc *QueueController) GetQueue(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, _, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue ID",
+		})
+	}
+
+	queue, err := qc.QueueService.GetQueueByID(tenantID, queueID)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "queue retrieved successfully",
+		"data":    queue,
+	})
+}
+
+func (qc *QueueController) UpdateQueue(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, _, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue ID",
+		})
+	}
+
+	var req queueRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	queue, err := qc.QueueService.UpdateQueue(tenantID, queueID, req.toModel())
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "queue updated successfully",
+		"data":    queue,
+	})
+}
+
+func (qc *QueueController) DeleteQueue(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, _, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue ID",
+		})
+	}
+
+	if err := qc.QueueService.DeleteQueue(tenantID, queueID); err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "queue deleted successfully",
+	})
+}
+
+func (qc *QueueController) GetAgents(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, _, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue ID",
+		})
+	}
+
+	agents, err := qc.QueueService.GetAgents(tenantID, queueID)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "queue agents retrieved successfully",
+		"data":    agents,
+	})
+}
+
+func (qc *QueueController) AddAgent(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, _, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue ID",
+		})
+	}
+
+	var req struct {
+		UserID uint `json:"user_id"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.UserID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "user_id is required",
+		})
+	}
+
+	agent, err := qc.QueueService.AddAgent(tenantID, queueID, req.UserID)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "queue agent added successfully",
+		"data":    agent,
+	})
+}
+
+func (qc *QueueController) RemoveAgent(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, agentID, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue or agent ID",
+		})
+	}
+
+	if err := qc.QueueService.RemoveAgent(tenantID, queueID, agentID); err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "queue agent removed successfully",
+	})
+}
+
+// SetAgentState lets a supervisor make an agent available or pause them.
+func (qc *QueueController) SetAgentState(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, agentID, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue or agent ID",
+		})
+	}
+
+	var req agentStateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	agent, err := qc.QueueService.SetAgentState(tenantID, queueID, agentID, req.State, req.PauseReason)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "agent state updated successfully",
+		"data":    agent,
+	})
+}
+
+// SetMyState changes the calling user's state in every queue they belong
+// to.
+func (qc *QueueController) SetMyState(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	userID := c.Locals("user_id").(uint)
+
+	var req agentStateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	agents, err := qc.QueueService.SetUserState(tenantID, userID, req.State, req.PauseReason)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "agent state updated successfully",
+		"data":    agents,
+	})
+}
+
+// GetQueueStats reports the queue metrics for calls that entered it within
+// from/to (RFC 3339), defaulting to the last 24 hours.
+func (qc *QueueController) GetQueueStats(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	queueID, _, ok := parseQueueParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid queue ID",
+		})
+	}
+
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid from, expected RFC 3339",
+			})
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid to, expected RFC 3339",
+			})
+		}
+		to = parsed
+	}
+
+	stats, err := qc.QueueService.GetQueueStats(tenantID, queueID, from, to)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "queue stats retrieved successfully",
+		"data":    stats,
+	})
+}
+
+// IngestCallEvent records a queue event reported by a switch.
+func (qc *QueueController) IngestCallEvent(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req struct {
+		CallUUID   string     `json:"call_uuid"`
+		Type       string     `json:"type"`
+		QueueID    *uint      `json:"queue_id"`
+		AgentID    *uint      `json:"agent_id"`
+		OccurredAt *time.Time `json:"occurred_at"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	event := &models.CallEvent{
+		CallUUID: req.CallUUID,
+		Type:     req.Type,
+		QueueID:  req.QueueID,
+		AgentID:  req.AgentID,
+	}
+	if req.OccurredAt != nil {
+		event.OccurredAt = *req.OccurredAt
+	}
+
+	event, err := qc.QueueService.RecordCallEvent(tenantID, event)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "call event recorded successfully",
+		"data":    event,
+	})
+}
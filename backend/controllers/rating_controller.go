@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type RatingController struct {
+	RatingService *services.RatingService
+}
+
+func NewRatingController(service *services.RatingService) *RatingController {
+	return &RatingController{RatingService: service}
+}
+
+func (rc *RatingController) UploadRateDeck(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+	return rc.uploadRateDeck(c, &tenantID)
+}
+
+func (rc *RatingController) UploadGlobalRateDeck(c *fiber.Ctx) error {
+	return rc.uploadRateDeck(c, nil)
+}
+
+// uploadRateDeck accepts either a JSON body with a rates array or a
+// multipart form with a "name" field and a CSV "file".
+func (rc *RatingController) uploadRateDeck(c *fiber.Ctx, tenantID *uint) error {
+	var name string
+	var rates []models.Rate
+
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		name = c.FormValue("name")
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "rate deck file is required",
+			})
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid rate deck file",
+			})
+		}
+		defer file.Close()
+
+		rates, err = services.ParseRateDeckCSV(file)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	} else {
+		var req struct {
+			Name  string        `json:"name"`
+			Rates []models.Rate `json:"rates"`
+		}
+
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		name = req.Name
+		rates = req.Rates
+	}
+
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "rate deck name is required",
+		})
+	}
+
+	deck, err := rc.RatingService.UploadRateDeck(tenantID, name, rates)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "rate") ||
+			strings.HasPrefix(err.Error(), "duplicate prefix") ||
+			strings.HasPrefix(err.Error(), "min duration") ||
+			strings.HasPrefix(err.Error(), "increment") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "rate deck uploaded successfully",
+		"data":    deck,
+	})
+}
+
+func (rc *RatingController) GetRateDecks(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	decks, err := rc.RatingService.GetRateDecks(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "rate decks retrieved successfully",
+		"data":    decks,
+	})
+}
+
+func (rc *RatingController) GetRateDeckByID(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	deckIDStr := c.Params("id")
+	deckID, err := strconv.ParseUint(deckIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rate deck ID",
+		})
+	}
+
+	deck, err := rc.RatingService.GetRateDeckByID(tenantID, uint(deckID))
+	if err != nil {
+		if err.Error() == "rate deck not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "rate deck not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "rate deck retrieved successfully",
+		"data":    deck,
+	})
+}
+
+func (rc *RatingController) DeleteRateDeck(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	deckIDStr := c.Params("id")
+	deckID, err := strconv.ParseUint(deckIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rate deck ID",
+		})
+	}
+
+	return rc.respondDeleted(c, rc.RatingService.DeleteRateDeck(tenantID, uint(deckID)))
+}
+
+func (rc *RatingController) DeleteGlobalRateDeck(c *fiber.Ctx) error {
+	deckIDStr := c.Params("id")
+	deckID, err := strconv.ParseUint(deckIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rate deck ID",
+		})
+	}
+
+	return rc.respondDeleted(c, rc.RatingService.DeleteGlobalRateDeck(uint(deckID)))
+}
+
+func (rc *RatingController) respondDeleted(c *fiber.Ctx, err error) error {
+	if err != nil {
+		if err.Error() == "rate deck not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "rate deck not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "rate deck deleted successfully",
+	})
+}
+
+func (rc *RatingController) RerateCalls(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	if req.From.IsZero() || req.To.IsZero() || req.To.Before(req.From) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "a valid from/to range is required",
+		})
+	}
+
+	changed, err := rc.RatingService.RerateCalls(tenantID, req.From, req.To)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "calls re-rated successfully",
+		"data": fiber.Map{
+			"updated": changed,
+		},
+	})
+}
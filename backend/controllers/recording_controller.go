@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+	"github.com/your-module/backend/utils"
+)
+
+type RecordingController struct {
+	RecordingService *services.RecordingService
+}
+
+func NewRecordingController(service *services.RecordingService) *RecordingController {
+	return &RecordingController{RecordingService: service}
+}
+
+// UploadRecording expects a multipart form with the audio in a "file" field.
+func (rc *RecordingController) UploadRecording(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	callIDStr := c.Params("id")
+	callID, err := strconv.ParseUint(callIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid call ID",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "recording file is required",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid recording file",
+		})
+	}
+	defer file.Close()
+
+	call, err := rc.RecordingService.AttachRecording(tenantID, uint(callID), fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		switch err.Error() {
+		case "call not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "call not found",
+			})
+		case "unsupported recording format":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "unsupported recording format",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "recording uploaded successfully",
+		"data":    call,
+	})
+}
+
+func (rc *RecordingController) DeleteRecording(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	callIDStr := c.Params("id")
+	callID, err := strconv.ParseUint(callIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid call ID",
+		})
+	}
+
+	err = rc.RecordingService.DeleteRecording(tenantID, uint(callID))
+	if err != nil {
+		if err.Error() == "call not found" || err.Error() == "recording not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "recording deleted successfully",
+	})
+}
+
+// GetRecording returns a short-lived signed download link for the call's
+// recording, or streams the audio directly when called with ?stream=true.
+func (rc *RecordingController) GetRecording(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	callIDStr := c.Params("id")
+	callID, err := strconv.ParseUint(callIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid call ID",
+		})
+	}
+
+	if c.QueryBool("stream") {
+		return rc.streamRecording(c, tenantID, uint(callID))
+	}
+
+	call, err := rc.RecordingService.GetRecordedCall(tenantID, uint(callID))
+	if err != nil {
+		if err.Error() == "call not found" || err.Error() == "recording not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	expires, signature := utils.SignRecording(tenantID, call.ID)
+	url := fmt.Sprintf("%s/api/v1/recordings/download?tenant=%d&call=%d&expires=%d&signature=%s",
+		c.BaseURL(), tenantID, call.ID, expires, signature)
+
+	return c.JSON(fiber.Map{
+		"message": "recording link generated successfully",
+		"data": fiber.Map{
+			"url":        url,
+			"expires_at": time.Unix(expires, 0).UTC(),
+		},
+	})
+}
+
+// DownloadRecording serves a recording through a signed link. It is not
+// behind user authentication; the signature, expiry and tenant in the link
+// are what authorize the download.
+func (rc *RecordingController) DownloadRecording(c *fiber.Ctx) error {
+	tenantID, errTenant := strconv.ParseUint(c.Query("tenant"), 10, 32)
+	callID, errCall := strconv.ParseUint(c.Query("call"), 10, 32)
+	expires, errExpires := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if errTenant != nil || errCall != nil || errExpires != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid download link",
+		})
+	}
+
+	if !utils.VerifyRecordingSignature(uint(tenantID), uint(callID), expires, c.Query("signature")) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "download link is invalid or expired",
+		})
+	}
+
+	return rc.streamRecording(c, uint(tenantID), uint(callID))
+}
+
+func (rc *RecordingController) streamRecording(c *fiber.Ctx, tenantID, callID uint) error {
+	reader, info, call, err := rc.RecordingService.OpenRecording(tenantID, callID)
+	if err != nil {
+		if err.Error() == "call not found" || err.Error() == "recording not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if reader == nil {
+		// Kept by the switch rather than our storage; redirecting rather
+		// than proxying keeps the API from fetching arbitrary URLs
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Redirect(call.RemoteRecordingURL(), fiber.StatusFound)
+	}
+
+	c.Set(fiber.HeaderContentType, info.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, path.Base(call.RecordingKey)))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	size := int(info.Size)
+	if info.Size < 0 {
+		size = -1
+	}
+	return c.SendStream(reader, size)
+}
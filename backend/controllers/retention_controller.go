@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"strconv"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type RetentionController struct {
+	RetentionService *services.RetentionService
+}
+
+func NewRetentionController(service *services.RetentionService) *RetentionController {
+	return &RetentionController{RetentionService: service}
+}
+
+func (rc *RetentionController) GetRetentionPolicy(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	policy, err := rc.RetentionService.GetRetentionPolicy(tenantID)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "tenant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "retention policy retrieved successfully",
+		"data":    policy,
+	})
+}
+
+func (rc *RetentionController) GetPurgeLogs(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	logs, err := rc.RetentionService.GetPurgeLogs(tenantID, c.QueryInt("limit", 50))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "purge logs retrieved successfully",
+		"data":    logs,
+	})
+}
+
+func (rc *RetentionController) SetPlanRetention(c *fiber.Ctx) error {
+	planIDStr := c.Params("id")
+	planID, err := strconv.ParseUint(planIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid plan ID",
+		})
+	}
+
+	var req struct {
+		RetentionDays *uint `json:"retention_days"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	if req.RetentionDays == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "retention days is required",
+		})
+	}
+
+	plan, err := rc.RetentionService.SetPlanRetention(uint(planID), *req.RetentionDays)
+	if err != nil {
+		if err.Error() == "plan not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "plan not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "plan retention updated successfully",
+		"data":    plan,
+	})
+}
+
+// SetTenantRetention accepts {"retention_days": null} to drop the override.
+func (rc *RetentionController) SetTenantRetention(c *fiber.Ctx) error {
+	tenantIDStr := c.Params("id")
+	tenantID, err := strconv.ParseUint(tenantIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid tenant ID",
+		})
+	}
+
+	var req struct {
+		RetentionDays *uint `json:"retention_days"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	tenant, err := rc.RetentionService.SetTenantRetention(uint(tenantID), req.RetentionDays)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "tenant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "tenant retention updated successfully",
+		"data":    tenant,
+	})
+}
+
+// RunPurge triggers a purge immediately instead of waiting for the scheduler.
+func (rc *RetentionController) RunPurge(c *fiber.Ctx) error {
+	logs, err := rc.RetentionService.PurgeExpired()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "retention purge completed",
+		"data":    logs,
+	})
+}
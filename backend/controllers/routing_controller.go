@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type RoutingController struct {
+	RoutingService *services.RoutingService
+}
+
+func NewRoutingController(service *services.RoutingService) *RoutingController {
+	return &RoutingController{RoutingService: service}
+}
+
+type routingRuleRequest struct {
+	Name              string `json:"name"`
+	Priority          int    `json:"priority"`
+	PhoneNumberID     *uint  `json:"phone_number_id"`
+	CallerPattern     string `json:"caller_pattern"`
+	ScheduleID        *uint  `json:"schedule_id"`
+	HolidayCalendarID *uint  `json:"holiday_calendar_id"`
+	TargetType        string `json:"target_type"`
+	TargetValue       string `json:"target_value"`
+	IsActive          *bool  `json:"is_active"`
+}
+
+func (r *routingRuleRequest) toModel() *models.RoutingRule {
+	rule := &models.RoutingRule{
+		Name:              r.Name,
+		Priority:          r.Priority,
+		PhoneNumberID:     r.PhoneNumberID,
+		CallerPattern:     r.CallerPattern,
+		ScheduleID:        r.ScheduleID,
+		HolidayCalendarID: r.HolidayCalendarID,
+		TargetType:        r.TargetType,
+		TargetValue:       r.TargetValue,
+		IsActive:          true,
+	}
+	if r.IsActive != nil {
+		rule.IsActive = *r.IsActive
+	}
+	return rule
+}
+
+func routingErrorStatus(err error) int {
+	switch err.Error() {
+	case "routing rule not found", "phone number not found", "schedule not found",
+		"holiday calendar not found", "ivr flow not found", "queue not found", "tenant not found", "no route for this call":
+		return fiber.StatusNotFound
+	case "name is required", "invalid caller pattern", "invalid target type",
+		"target value is required", "invalid target value", "invalid phone number":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (rc *RoutingController) CreateRule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req routingRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	rule, err := rc.RoutingService.CreateRule(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(routingErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "routing rule created successfully",
+		"data":    rule,
+	})
+}
+
+func (rc *RoutingController) GetRules(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	rules, err := rc.RoutingService.GetRules(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "routing rules retrieved successfully",
+		"data":    rules,
+	})
+}
+
+func (rc *RoutingController) GetRule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	ruleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid routing rule ID",
+		})
+	}
+
+	rule, err := rc.RoutingService.GetRuleByID(tenantID, uint(ruleID))
+	if err != nil {
+		return c.Status(routingErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "routing rule retrieved successfully",
+		"data":    rule,
+	})
+}
+
+func (rc *RoutingController) UpdateRule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	ruleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid routing rule ID",
+		})
+	}
+
+	var req routingRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	rule, err := rc.RoutingService.UpdateRule(tenantID, uint(ruleID), req.toModel())
+	if err != nil {
+		return c.Status(routingErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "routing rule updated successfully",
+		"data":    rule,
+	})
+}
+
+func (rc *RoutingController) DeleteRule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	ruleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid routing rule ID",
+		})
+	}
+
+	if err := rc.RoutingService.DeleteRule(tenantID, uint(ruleID)); err != nil {
+		return c.Status(routingErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "routing rule deleted successfully",
+	})
+}
+
+// Evaluate resolves where a call from caller to did would be routed. The
+// optional "at" (RFC 3339) evaluates schedules and holidays at another
+// time than now.
+func (rc *RoutingController) Evaluate(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req struct {
+		DID    string `json:"did"`
+		Caller string `json:"caller"`
+		At     string `json:"at"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	at := time.Now()
+	if req.At != "" {
+		parsed, err := time.Parse(time.RFC3339, req.At)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid at, expected RFC 3339",
+			})
+		}
+		at = parsed
+	}
+
+	result, err := rc.RoutingService.Evaluate(tenantID, req.DID, req.Caller, at)
+	if err != nil {
+		return c.Status(routingErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "routing evaluated successfully",
+		"data":    result,
+	})
+}
@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"strconv"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type RoutingScheduleController struct {
+	RoutingScheduleService *services.RoutingScheduleService
+}
+
+func NewRoutingScheduleController(service *services.RoutingScheduleService) *RoutingScheduleController {
+	return &RoutingScheduleController{RoutingScheduleService: service}
+}
+
+type routingScheduleRequest struct {
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"`
+	Windows  []struct {
+		Weekday   int    `json:"weekday"`
+		StartTime string `json:"start_time"`
+		EndTime   string `json:"end_time"`
+	} `json:"windows"`
+}
+
+func (r *routingScheduleRequest) toModel() *models.RoutingSchedule {
+	schedule := &models.RoutingSchedule{
+		Name:     r.Name,
+		Timezone: r.Timezone,
+	}
+	for _, window := range r.Windows {
+		schedule.Windows = append(schedule.Windows, models.RoutingScheduleWindow{
+			Weekday:   window.Weekday,
+			StartTime: window.StartTime,
+			EndTime:   window.EndTime,
+		})
+	}
+	return schedule
+}
+
+type holidayCalendarRequest struct {
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"`
+	Holidays []struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	} `json:"holidays"`
+}
+
+func (r *holidayCalendarRequest) toModel() *models.HolidayCalendar {
+	calendar := &models.HolidayCalendar{
+		Name:     r.Name,
+		Timezone: r.Timezone,
+	}
+	for _, holiday := range r.Holidays {
+		calendar.Holidays = append(calendar.Holidays, models.Holiday{
+			Date: holiday.Date,
+			Name: holiday.Name,
+		})
+	}
+	return calendar
+}
+
+func routingScheduleErrorStatus(err error) int {
+	switch err.Error() {
+	case "schedule not found", "holiday calendar not found":
+		return fiber.StatusNotFound
+	case "schedule is used by a routing rule", "holiday calendar is used by a routing rule":
+		return fiber.StatusConflict
+	case "name is required", "invalid timezone", "weekday must be 0 (Sunday) to 6",
+		"invalid window time", "window must end after it starts", "invalid holiday date",
+		"duplicate holiday date":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (rsc *RoutingScheduleController) CreateSchedule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req routingScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	schedule, err := rsc.RoutingScheduleService.CreateSchedule(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "schedule created successfully",
+		"data":    schedule,
+	})
+}
+
+func (rsc *RoutingScheduleController) GetSchedules(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	schedules, err := rsc.RoutingScheduleService.GetSchedules(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "schedules retrieved successfully",
+		"data":    schedules,
+	})
+}
+
+func (rsc *RoutingScheduleController) GetSchedule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	scheduleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid schedule ID",
+		})
+	}
+
+	schedule, err := rsc.RoutingScheduleService.GetScheduleByID(tenantID, uint(scheduleID))
+	if err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "schedule retrieved successfully",
+		"data":    schedule,
+	})
+}
+
+func (rsc *RoutingScheduleController) UpdateSchedule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	scheduleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid schedule ID",
+		})
+	}
+
+	var req routingScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	schedule, err := rsc.RoutingScheduleService.UpdateSchedule(tenantID, uint(scheduleID), req.toModel())
+	if err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "schedule updated successfully",
+		"data":    schedule,
+	})
+}
+
+func (rsc *RoutingScheduleController) DeleteSchedule(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	scheduleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid schedule ID",
+		})
+	}
+
+	if err := rsc.RoutingScheduleService.DeleteSchedule(tenantID, uint(scheduleID)); err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "schedule deleted successfully",
+	})
+}
+
+func (rsc *RoutingScheduleController) CreateCalendar(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req holidayCalendarRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	calendar, err := rsc.RoutingScheduleService.CreateCalendar(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "holiday calendar created successfully",
+		"data":    calendar,
+	})
+}
+
+func (rsc *RoutingScheduleController) GetCalendars(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	calendars, err := rsc.RoutingScheduleService.GetCalendars(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "holiday calendars retrieved successfully",
+		"data":    calendars,
+	})
+}
+
+func (rsc *RoutingScheduleController) GetCalendar(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	calendarID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid holiday calendar ID",
+		})
+	}
+
+	calendar, err := rsc.RoutingScheduleService.GetCalendarByID(tenantID, uint(calendarID))
+	if err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "holiday calendar retrieved successfully",
+		"data":    calendar,
+	})
+}
+
+func (rsc *RoutingScheduleController) UpdateCalendar(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	calendarID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid holiday calendar ID",
+		})
+	}
+
+	var req holidayCalendarRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	calendar, err := rsc.RoutingScheduleService.UpdateCalendar(tenantID, uint(calendarID), req.toModel())
+	if err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "holiday calendar updated successfully",
+		"data":    calendar,
+	})
+}
+
+func (rsc *RoutingScheduleController) DeleteCalendar(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	calendarID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid holiday calendar ID",
+		})
+	}
+
+	if err := rsc.RoutingScheduleService.DeleteCalendar(tenantID, uint(calendarID)); err != nil {
+		return c.Status(routingScheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "holiday calendar deleted successfully",
+	})
+}
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+)
+
+type SipEndpointController struct {
+	SipEndpointService *services.SipEndpointService
+}
+
+func NewSipEndpointController(service *services.SipEndpointService) *SipEndpointController {
+	return &SipEndpointController{SipEndpointService: service}
+}
+
+type sipEndpointRequest struct {
+	UserID             *uint    `json:"user_id"`
+	Extension          string   `json:"extension"`
+	CallerIDName       string   `json:"caller_id_name"`
+	CallerIDNumber     string   `json:"caller_id_number"`
+	Codecs             []string `json:"codecs"`
+	MaxConcurrentCalls int      `json:"max_concurrent_calls"`
+	IsActive           *bool    `json:"is_active"`
+}
+
+func (r *sipEndpointRequest) toModel() *models.SipEndpoint {
+	endpoint := &models.SipEndpoint{
+		UserID:             r.UserID,
+		Extension:          r.Extension,
+		CallerIDName:       strings.TrimSpace(r.CallerIDName),
+		CallerIDNumber:     strings.TrimSpace(r.CallerIDNumber),
+		Codecs:             strings.Join(r.Codecs, ","),
+		MaxConcurrentCalls: r.MaxConcurrentCalls,
+		IsActive:           true,
+	}
+	if r.IsActive != nil {
+		endpoint.IsActive = *r.IsActive
+	}
+	return endpoint
+}
+
+func sipEndpointErrorStatus(err error) int {
+	if strings.HasPrefix(err.Error(), "unsupported codec") {
+		return fiber.StatusBadRequest
+	}
+
+	switch err.Error() {
+	case "sip endpoint not found", "user not found", "tenant not found":
+		return fiber.StatusNotFound
+	case "extension already exists":
+		return fiber.StatusConflict
+	case "quota exceeded: max sip endpoints reached", "no active subscription found":
+		return fiber.StatusForbidden
+	case "extension must be 2 to 10 digits", "invalid caller ID number",
+		"max concurrent calls cannot be negative":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (sec *SipEndpointController) CreateEndpoint(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req sipEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	endpoint, password, err := sec.SipEndpointService.CreateEndpoint(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(sipEndpointErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "sip endpoint created successfully",
+		"data":     endpoint,
+		"password": password,
+	})
+}
+
+func (sec *SipEndpointController) GetEndpoints(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	endpoints, err := sec.SipEndpointService.GetEndpoints(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "sip endpoints retrieved successfully",
+		"data":    endpoints,
+	})
+}
+
+func (sec *SipEndpointController) GetEndpoint(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	endpointID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid sip endpoint ID",
+		})
+	}
+
+	endpoint, err := sec.SipEndpointService.GetEndpointByID(tenantID, uint(endpointID))
+	if err != nil {
+		return c.Status(sipEndpointErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "sip endpoint retrieved successfully",
+		"data":    endpoint,
+	})
+}
+
+func (sec *SipEndpointController) UpdateEndpoint(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	endpointID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid sip endpoint ID",
+		})
+	}
+
+	var req sipEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	endpoint, err := sec.SipEndpointService.UpdateEndpoint(tenantID, uint(endpointID), req.toModel())
+	if err != nil {
+		return c.Status(sipEndpointErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "sip endpoint updated successfully",
+		"data":    endpoint,
+	})
+}
+
+func (sec *SipEndpointController) ResetPassword(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	endpointID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid sip endpoint ID",
+		})
+	}
+
+	endpoint, password, err := sec.SipEndpointService.ResetPassword(tenantID, uint(endpointID))
+	if err != nil {
+		return c.Status(sipEndpointErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "sip endpoint password reset successfully",
+		"data":     endpoint,
+		"password": password,
+	})
+}
+
+func (sec *SipEndpointController) DeleteEndpoint(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	endpointID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid sip endpoint ID",
+		})
+	}
+
+	if err := sec.SipEndpointService.DeleteEndpoint(tenantID, uint(endpointID)); err != nil {
+		return c.Status(sipEndpointErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "sip endpoint deleted successfully",
+	})
+}
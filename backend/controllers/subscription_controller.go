@@ -3,6 +3,7 @@ package controllers
 import (
 	"strconv"
 	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
 	"github.com/your-module/backend/services"
 )
 
@@ -14,13 +15,99 @@ func NewSubscriptionController(service *services.SubscriptionService) *Subscript
 	return &SubscriptionController{SubscriptionService: service}
 }
 
-func (sc *SubscriptionController) CreatePlan(c *fiber.Ctx) error {
-	var req struct {
-		Name     string  `json:"name"`
-		MaxUsers uint    `json:"max_users"`
-		MaxCalls uint    `json:"max_calls"`
-		Price    float64 `json:"price"`
+// planRequest is the body of plan create and update. Fields are pointers so
+// an update changes only the fields that were sent; a missing field keeps
+// its value rather than being reset to zero.
+type planRequest struct {
+	Name                      *string  `json:"name"`
+	MaxUsers                  *uint    `json:"max_users"`
+	MaxCalls                  *uint    `json:"max_calls"`
+	MaxPhoneNumbers           *uint    `json:"max_phone_numbers"`
+	MaxSipEndpoints           *uint    `json:"max_sip_endpoints"`
+	MaxConcurrentCalls        *uint    `json:"max_concurrent_calls"`
+	MaxCPS                    *uint    `json:"max_cps"`
+	MaxConferenceRooms        *uint    `json:"max_conference_rooms"`
+	MaxConferenceParticipants *uint    `json:"max_conference_participants"`
+	RetentionDays             *uint    `json:"retention_days"`
+	Price                     *float64 `json:"price"`
+}
+
+// validate returns the first problem with the request, or "" if it is valid.
+// Name, max users and max calls are required on create; on update they are
+// only checked when sent.
+func (r *planRequest) validate(create bool) string {
+	if (create || r.Name != nil) && (r.Name == nil || *r.Name == "") {
+		return "plan name is required"
+	}
+	if (create || r.MaxUsers != nil) && (r.MaxUsers == nil || *r.MaxUsers == 0) {
+		return "max users must be greater than 0"
+	}
+	if (create || r.MaxCalls != nil) && (r.MaxCalls == nil || *r.MaxCalls == 0) {
+		return "max calls must be greater than 0"
+	}
+	if r.Price != nil && *r.Price < 0 {
+		return "price must be greater than or equal to 0"
+	}
+	return ""
+}
+
+func (r *planRequest) toModel() models.Plan {
+	plan := models.Plan{
+		MaxUsers:                  uintValue(r.MaxUsers),
+		MaxCalls:                  uintValue(r.MaxCalls),
+		MaxPhoneNumbers:           uintValue(r.MaxPhoneNumbers),
+		MaxSipEndpoints:           uintValue(r.MaxSipEndpoints),
+		MaxConcurrentCalls:        uintValue(r.MaxConcurrentCalls),
+		MaxCPS:                    uintValue(r.MaxCPS),
+		MaxConferenceRooms:        uintValue(r.MaxConferenceRooms),
+		MaxConferenceParticipants: uintValue(r.MaxConferenceParticipants),
+		RetentionDays:             uintValue(r.RetentionDays),
+	}
+	if r.Name != nil {
+		plan.Name = *r.Name
+	}
+	if r.Price != nil {
+		plan.Price = *r.Price
+	}
+	return plan
+}
+
+// changes maps the columns that were sent to their new values.
+func (r *planRequest) changes() map[string]interface{} {
+	changes := map[string]interface{}{}
+	if r.Name != nil {
+		changes["name"] = *r.Name
 	}
+	if r.Price != nil {
+		changes["price"] = *r.Price
+	}
+	for column, value := range map[string]*uint{
+		"max_users":                   r.MaxUsers,
+		"max_calls":                   r.MaxCalls,
+		"max_phone_numbers":           r.MaxPhoneNumbers,
+		"max_sip_endpoints":           r.MaxSipEndpoints,
+		"max_concurrent_calls":        r.MaxConcurrentCalls,
+		"max_cps":                     r.MaxCPS,
+		"max_conference_rooms":        r.MaxConferenceRooms,
+		"max_conference_participants": r.MaxConferenceParticipants,
+		"retention_days":              r.RetentionDays,
+	} {
+		if value != nil {
+			changes[column] = *value
+		}
+	}
+	return changes
+}
+
+func uintValue(value *uint) uint {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func (sc *SubscriptionController) CreatePlan(c *fiber.Ctx) error {
+	var req planRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -28,39 +115,62 @@ func (sc *SubscriptionController) CreatePlan(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.Name == "" {
+	if message := req.validate(true); message != "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "plan name is required",
+			"error": message,
 		})
 	}
 
-	if req.MaxUsers == 0 {
+	plan, err := sc.SubscriptionService.CreatePlan(req.toModel())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "plan created successfully",
+		"data":    plan,
+	})
+}
+
+func (sc *SubscriptionController) UpdatePlan(c *fiber.Ctx) error {
+	planIDStr := c.Params("id")
+	planID, err := strconv.ParseUint(planIDStr, 10, 32)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "max users must be greater than 0",
+			"error": "invalid plan ID",
 		})
 	}
 
-	if req.MaxCalls == 0 {
+	var req planRequest
+
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "max calls must be greater than 0",
+			"error": "invalid request body",
 		})
 	}
 
-	if req.Price < 0 {
+	if message := req.validate(false); message != "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "price must be greater than or equal to 0",
+			"error": message,
 		})
 	}
 
-	plan, err := sc.SubscriptionService.CreatePlan(req.Name, req.MaxUsers, req.MaxCalls, req.Price)
+	plan, err := sc.SubscriptionService.UpdatePlan(uint(planID), req.changes())
 	if err != nil {
+		if err.Error() == "plan not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "plan not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "plan created successfully",
+	return c.JSON(fiber.Map{
+		"message": "plan updated successfully",
 		"data":    plan,
 	})
 }
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"strconv"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type SwitchCredentialController struct {
+	SwitchCredentialService *services.SwitchCredentialService
+}
+
+func NewSwitchCredentialController(service *services.SwitchCredentialService) *SwitchCredentialController {
+	return &SwitchCredentialController{SwitchCredentialService: service}
+}
+
+func (scc *SwitchCredentialController) CreateCredential(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req struct {
+		Description string `json:"description"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	credential, secret, err := scc.SwitchCredentialService.CreateCredential(tenantID, req.Description)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "switch credential created successfully",
+		"data":    credential,
+		"secret":  secret,
+	})
+}
+
+func (scc *SwitchCredentialController) GetCredentials(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	credentials, err := scc.SwitchCredentialService.GetCredentials(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "switch credentials retrieved successfully",
+		"data":    credentials,
+	})
+}
+
+func (scc *SwitchCredentialController) DeleteCredential(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	credentialIDStr := c.Params("id")
+	credentialID, err := strconv.ParseUint(credentialIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid switch credential ID",
+		})
+	}
+
+	err = scc.SwitchCredentialService.DeleteCredential(tenantID, uint(credentialID))
+	if err != nil {
+		if err.Error() == "switch credential not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "switch credential not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "switch credential deleted successfully",
+	})
+}
@@ -103,8 +103,8 @@ func (tc *TenantController) DeleteTenant(c *fiber.Ctx) error {
 			})
 		}
 		if err.Error() == "cannot delete tenant that has associated users" ||
-		   err.Error() == "cannot delete tenant that has associated roles" ||
-		   err.Error() == "cannot delete tenant that has associated calls" {
+			err.Error() == "cannot delete tenant that has associated roles" ||
+			err.Error() == "cannot delete tenant that has associated calls" {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 				"error": err.Error(),
 			})
@@ -117,4 +117,42 @@ func (tc *TenantController) DeleteTenant(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"message": "tenant deleted successfully",
 	})
-} 
\ No newline at end of file
+}
+
+// SetDefaultCountry updates the current tenant's default country (ISO 3166
+// alpha-2). Its calls are re-normalized in the background.
+func (tc *TenantController) SetDefaultCountry(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req struct {
+		Country string `json:"country"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	tenant, err := tc.TenantService.SetDefaultCountry(tenantID, req.Country)
+	if err != nil {
+		switch err.Error() {
+		case "unsupported country":
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "unsupported country",
+			})
+		case "tenant not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "tenant not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "default country updated successfully, calls are being re-normalized",
+		"data":    tenant,
+	})
+}
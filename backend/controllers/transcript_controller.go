@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"strconv"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type TranscriptController struct {
+	TranscriptionService *services.TranscriptionService
+}
+
+func NewTranscriptController(service *services.TranscriptionService) *TranscriptController {
+	return &TranscriptController{TranscriptionService: service}
+}
+
+func (tc *TranscriptController) GetTranscript(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	callIDStr := c.Params("id")
+	callID, err := strconv.ParseUint(callIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid call ID",
+		})
+	}
+
+	transcript, err := tc.TranscriptionService.GetTranscriptByCallID(tenantID, uint(callID))
+	if err != nil {
+		if err.Error() == "transcript not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "transcript not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "transcript retrieved successfully",
+		"data":    transcript,
+	})
+}
+
+// SearchTranscripts expects the search terms in ?q and an optional ?limit.
+func (tc *TranscriptController) SearchTranscripts(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	matches, err := tc.TranscriptionService.SearchTranscripts(tenantID, c.Query("q"), c.QueryInt("limit", 20))
+	if err != nil {
+		if err.Error() == "search query is required" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "transcripts retrieved successfully",
+		"data":    matches,
+	})
+}
@@ -0,0 +1,435 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/services"
+	"github.com/your-module/backend/storage"
+)
+
+type VoicemailController struct {
+	VoicemailService *services.VoicemailService
+}
+
+func NewVoicemailController(service *services.VoicemailService) *VoicemailController {
+	return &VoicemailController{VoicemailService: service}
+}
+
+type voicemailBoxRequest struct {
+	UserID         *uint  `json:"user_id"`
+	Extension      string `json:"extension"`
+	PIN            string `json:"pin"`
+	EmailOnMessage bool   `json:"email_on_message"`
+	Email          string `json:"email"`
+	IsActive       *bool  `json:"is_active"`
+}
+
+func (r *voicemailBoxRequest) toModel() *models.VoicemailBox {
+	box := &models.VoicemailBox{
+		UserID:         r.UserID,
+		Extension:      r.Extension,
+		PIN:            r.PIN,
+		EmailOnMessage: r.EmailOnMessage,
+		Email:          r.Email,
+		IsActive:       true,
+	}
+	if r.IsActive != nil {
+		box.IsActive = *r.IsActive
+	}
+	return box
+}
+
+func voicemailErrorStatus(err error) int {
+	switch err.Error() {
+	case "voicemail box not found", "voicemail message not found", "greeting not found", "user not found":
+		return fiber.StatusNotFound
+	case "extension already has a voicemail box", "voicemail box is used by a routing rule",
+		"voicemail box is used by an ivr flow", "voicemail box is used by a phone number":
+		return fiber.StatusConflict
+	case "extension must be 2 to 10 digits", "pin must be 4 to 10 digits", "invalid email address",
+		"email is required for message notifications", "unsupported audio format", "duration cannot be negative":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+// parseVoicemailParams reads the box ID and, when the route has one, the
+// message ID.
+func parseVoicemailParams(c *fiber.Ctx) (uint, uint, bool) {
+	boxID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	if c.Params("message_id") == "" {
+		return uint(boxID), 0, true
+	}
+	messageID, err := strconv.ParseUint(c.Params("message_id"), 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(boxID), uint(messageID), true
+}
+
+func (vc *VoicemailController) CreateBox(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	var req voicemailBoxRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	box, err := vc.VoicemailService.CreateBox(tenantID, req.toModel())
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "voicemail box created successfully",
+		"data":    box,
+	})
+}
+
+func (vc *VoicemailController) GetBoxes(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxes, err := vc.VoicemailService.GetBoxes(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "voicemail boxes retrieved successfully",
+		"data":    boxes,
+	})
+}
+
+func (vc *VoicemailController) GetBox(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, _, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box ID",
+		})
+	}
+
+	box, err := vc.VoicemailService.GetBoxByID(tenantID, boxID)
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "voicemail box retrieved successfully",
+		"data":    box,
+	})
+}
+
+// UpdateBox keeps the current PIN when the request leaves it empty.
+func (vc *VoicemailController) UpdateBox(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, _, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box ID",
+		})
+	}
+
+	var req voicemailBoxRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	box, err := vc.VoicemailService.UpdateBox(tenantID, boxID, req.toModel())
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "voicemail box updated successfully",
+		"data":    box,
+	})
+}
+
+func (vc *VoicemailController) DeleteBox(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, _, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box ID",
+		})
+	}
+
+	if err := vc.VoicemailService.DeleteBox(tenantID, boxID); err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "voicemail box deleted successfully",
+	})
+}
+
+// UploadGreeting expects a multipart form with the audio in a "file" field.
+func (vc *VoicemailController) UploadGreeting(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, _, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box ID",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "greeting file is required",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid greeting file",
+		})
+	}
+	defer file.Close()
+
+	box, err := vc.VoicemailService.SetGreeting(tenantID, boxID, fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "greeting uploaded successfully",
+		"data":    box,
+	})
+}
+
+func (vc *VoicemailController) GetGreeting(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, _, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box ID",
+		})
+	}
+
+	reader, info, box, err := vc.VoicemailService.OpenGreeting(tenantID, boxID)
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return sendAudio(c, reader, info, path.Base(box.GreetingKey))
+}
+
+func (vc *VoicemailController) DeleteGreeting(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, _, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box ID",
+		})
+	}
+
+	if err := vc.VoicemailService.DeleteGreeting(tenantID, boxID); err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "greeting deleted successfully",
+	})
+}
+
+// GetMessages lists a box's messages; ?unheard=true leaves out the ones
+// already heard.
+func (vc *VoicemailController) GetMessages(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, _, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box ID",
+		})
+	}
+
+	messages, err := vc.VoicemailService.GetMessages(tenantID, boxID, c.QueryBool("unheard"))
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "voicemail messages retrieved successfully",
+		"data":    messages,
+	})
+}
+
+// MarkHeard takes {"heard": false} to mark a message unheard again; heard
+// is the default.
+func (vc *VoicemailController) MarkHeard(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, messageID, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box or message ID",
+		})
+	}
+
+	var req struct {
+		Heard *bool `json:"heard"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+	heard := req.Heard == nil || *req.Heard
+
+	message, err := vc.VoicemailService.MarkHeard(tenantID, boxID, messageID, heard)
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "voicemail message updated successfully",
+		"data":    message,
+	})
+}
+
+func (vc *VoicemailController) DownloadMessage(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, messageID, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box or message ID",
+		})
+	}
+
+	reader, info, message, err := vc.VoicemailService.OpenMessage(tenantID, boxID, messageID)
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return sendAudio(c, reader, info, fmt.Sprintf("voicemail-%d%s", message.ID, path.Ext(message.StorageKey)))
+}
+
+func (vc *VoicemailController) DeleteMessage(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	boxID, messageID, ok := parseVoicemailParams(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid voicemail box or message ID",
+		})
+	}
+
+	if err := vc.VoicemailService.DeleteMessage(tenantID, boxID, messageID); err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "voicemail message deleted successfully",
+	})
+}
+
+// DepositMessage receives a message recorded by a switch as a multipart
+// form: the audio in "file", plus extension, call_uuid, caller_id_number,
+// caller_id_name and duration (seconds).
+func (vc *VoicemailController) DepositMessage(c *fiber.Ctx) error {
+	tenantID := c.Locals("tenant_id").(uint)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "message file is required",
+		})
+	}
+
+	duration := 0
+	if value := c.FormValue("duration"); value != "" {
+		if duration, err = strconv.Atoi(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid duration",
+			})
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid message file",
+		})
+	}
+	defer file.Close()
+
+	message, err := vc.VoicemailService.DepositMessage(tenantID, services.VoicemailDeposit{
+		Extension:       c.FormValue("extension"),
+		CallUUID:        c.FormValue("call_uuid"),
+		CallerIDNumber:  c.FormValue("caller_id_number"),
+		CallerIDName:    c.FormValue("caller_id_name"),
+		DurationSeconds: duration,
+		Filename:        fileHeader.Filename,
+	}, file, fileHeader.Size)
+	if err != nil {
+		return c.Status(voicemailErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "voicemail message stored successfully",
+		"data":    message,
+	})
+}
+
+func sendAudio(c *fiber.Ctx, reader io.ReadCloser, info *storage.ObjectInfo, filename string) error {
+	c.Set(fiber.HeaderContentType, info.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	size := int(info.Size)
+	if info.Size < 0 {
+		size = -1
+	}
+	return c.SendStream(reader, size)
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"log"
+	"strings"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+)
+
+type XMLCurlController struct {
+	XMLCurlService *services.XMLCurlService
+}
+
+func NewXMLCurlController(service *services.XMLCurlService) *XMLCurlController {
+	return &XMLCurlController{XMLCurlService: service}
+}
+
+// Fetch answers a mod_xml_curl binding. FreeSWITCH posts the lookup as a
+// form; the response is always XML, and errors become a "not found" result
+// so the switch can fall back to its local configuration.
+func (xc *XMLCurlController) Fetch(c *fiber.Ctx) error {
+	domain := c.FormValue("domain")
+	if domain == "" {
+		domain = c.FormValue("key_value")
+	}
+
+	destination := c.FormValue("Hunt-Destination-Number")
+	if destination == "" {
+		destination = c.FormValue("Caller-Destination-Number")
+	}
+
+	context := c.FormValue("Hunt-Context")
+	if context == "" {
+		context = c.FormValue("Caller-Context")
+	}
+
+	// FreeSWITCH posts the channel variables as variable_<name>
+	ivrVariables := map[string]string{}
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		if name, ok := strings.CutPrefix(string(key), "variable_ivr_var_"); ok {
+			ivrVariables[name] = string(value)
+		}
+	})
+
+	body, err := xc.XMLCurlService.Render(services.XMLCurlRequest{
+		Section:           c.FormValue("section"),
+		Domain:            domain,
+		User:              c.FormValue("user"),
+		Context:           context,
+		DestinationNumber: destination,
+		CallerNumber:      c.FormValue("Caller-Caller-ID-Number"),
+		CallerUser:        c.FormValue("variable_user_name"),
+		CallUUID:          c.FormValue("Unique-ID"),
+		ConferencePIN:     c.FormValue("variable_conference_pin"),
+		IvrVariables:      ivrVariables,
+	})
+	if err != nil {
+		log.Printf("xml_curl: %s lookup failed: %v", c.FormValue("section"), err)
+		body = services.NotFoundXML()
+	}
+
+	c.Set(fiber.HeaderContentType, "text/xml; charset=utf-8")
+	return c.Send(body)
+}
@@ -97,6 +97,10 @@ func Connect() {
 		log.Fatal("Failed to migrate database:", err)
 	}
 	
+	if err := createIndexes(); err != nil {
+		log.Fatal("Failed to create database indexes:", err)
+	}
+	
 	log.Println("Database migration completed successfully")
 }
 
@@ -110,9 +114,81 @@ func migrateModels() error {
 		&models.UserTenant{},
 		&models.UserRole{},
 		&models.Call{},
+		&models.SwitchCredential{},
+		&models.RateDeck{},
+		&models.Rate{},
+		&models.Transcript{},
+		&models.TranscriptSegment{},
+		&models.PurgeLog{},
+		&models.FraudRule{},
+		&models.FraudAlert{},
+		&models.PhoneNumber{},
+		&models.PhoneNumberAssignment{},
+		&models.SipEndpoint{},
+		&models.OriginateJob{},
+		&models.ActiveChannel{},
+		&models.CallAdmission{},
+		&models.RoutingSchedule{},
+		&models.RoutingScheduleWindow{},
+		&models.HolidayCalendar{},
+		&models.Holiday{},
+		&models.RoutingRule{},
+		&models.IvrFlow{},
+		&models.IvrFlowVersion{},
+		&models.Queue{},
+		&models.QueueAgent{},
+		&models.CallEvent{},
+		&models.VoicemailBox{},
+		&models.VoicemailMessage{},
+		&models.ConferenceRoom{},
+		&models.ConferenceParticipant{},
+		&models.CallerListEntry{},
 	)
 }
 
+// createIndexes adds composite indexes that struct tags cannot express, such
+// as sort direction, operator classes and partial conditions.
+func createIndexes() error {
+	statements := []string{
+		// Call search: default listing, sorts and number/prefix filters
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_start ON calls (tenant_id, start_time DESC NULLS LAST, id DESC) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_created ON calls (tenant_id, created_at DESC, id DESC) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_billsec ON calls (tenant_id, billsec, id) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_cost ON calls (tenant_id, cost, id) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_caller ON calls (tenant_id, caller text_pattern_ops) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_callee ON calls (tenant_id, callee text_pattern_ops) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_caller_e164 ON calls (tenant_id, caller_e164) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_callee_e164 ON calls (tenant_id, callee_e164 text_pattern_ops) WHERE deleted_at IS NULL`,
+		// Outcome reports: a tenant's calls of one disposition over a period
+		`CREATE INDEX IF NOT EXISTS idx_calls_tenant_disposition ON calls (tenant_id, disposition, start_time) WHERE deleted_at IS NULL`,
+		// SIP extensions are unique per tenant among live endpoints
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_sip_endpoints_tenant_extension ON sip_endpoints (tenant_id, extension) WHERE deleted_at IS NULL`,
+		// Admission check: open admissions and recent admissions per tenant
+		`CREATE INDEX IF NOT EXISTS idx_call_admissions_tenant_open ON call_admissions (tenant_id, admitted_at) WHERE released_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_call_admissions_tenant_admitted ON call_admissions (tenant_id, admitted_at DESC)`,
+		// Routing evaluation: a tenant's active rules in priority order
+		`CREATE INDEX IF NOT EXISTS idx_routing_rules_tenant_priority ON routing_rules (tenant_id, priority, id) WHERE deleted_at IS NULL AND is_active`,
+		// Queue metrics: a queue's events in time order
+		`CREATE INDEX IF NOT EXISTS idx_call_events_queue_occurred ON call_events (queue_id, occurred_at) WHERE queue_id IS NOT NULL`,
+		// Voicemail boxes are unique per tenant extension; a box's messages newest first
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_voicemail_boxes_tenant_extension ON voicemail_boxes (tenant_id, extension) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_voicemail_messages_box_created ON voicemail_messages (box_id, created_at DESC)`,
+		// Conference rooms are unique per tenant extension and dial-in number
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_conference_rooms_tenant_extension ON conference_rooms (tenant_id, extension) WHERE deleted_at IS NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_conference_rooms_phone_number ON conference_rooms (phone_number_id) WHERE deleted_at IS NULL AND phone_number_id IS NOT NULL`,
+		// Transcript full-text search
+		`CREATE INDEX IF NOT EXISTS idx_transcripts_text_search ON transcripts USING GIN (to_tsvector('simple', text))`,
+	}
+	
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
 func GetDB() *gorm.DB {
 	if DB == nil {
 		Connect()
@@ -0,0 +1,341 @@
+package esl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrClosed  = errors.New("esl connection closed")
+	ErrTimeout = errors.New("esl command timed out")
+)
+
+// Client is the part of the FreeSWITCH event socket the application uses.
+// Conn implements it over TCP; tests can point a Conn at FakeServer or
+// substitute their own implementation.
+type Client interface {
+	// API runs a blocking api command and returns its output.
+	API(command string) (string, error)
+	// BgAPI queues a background command under the given job UUID. The
+	// result arrives later as a BACKGROUND_JOB event carrying that UUID, so
+	// callers record the job before sending it.
+	BgAPI(command, jobUUID string) error
+	// Events delivers subscribed events. It is closed when the connection
+	// ends, which is how callers notice a disconnect.
+	Events() <-chan Event
+	Close() error
+}
+
+// Event is a decoded text/event-plain message. Body holds the content that
+// follows the headers, such as the output of a background job.
+type Event struct {
+	Headers map[string]string
+	Body    string
+}
+
+func (e Event) Name() string {
+	return e.Headers["Event-Name"]
+}
+
+func (e Event) Get(name string) string {
+	return e.Headers[name]
+}
+
+type message struct {
+	headers map[string]string
+	body    string
+}
+
+// Conn is an inbound event socket connection.
+type Conn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+
+	mu      sync.Mutex
+	replies chan message
+	events  chan Event
+	done    chan struct{}
+	once    sync.Once
+
+	// Events wait here until the consumer takes them, so a slow consumer
+	// never stops readLoop from delivering command replies.
+	queueMu sync.Mutex
+	queue   []Event
+	queued  chan struct{}
+}
+
+// Dial connects and authenticates to a FreeSWITCH event socket and
+// subscribes to the given events.
+func Dial(address, password string, timeout time.Duration, events ...string) (*Conn, error) {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	netConn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConn(netConn, password, timeout, events...)
+}
+
+// NewConn runs the event socket handshake over an established connection.
+func NewConn(netConn net.Conn, password string, timeout time.Duration, events ...string) (*Conn, error) {
+	c := &Conn{
+		conn:    netConn,
+		reader:  bufio.NewReader(netConn),
+		timeout: timeout,
+		replies: make(chan message),
+		events:  make(chan Event, 256),
+		done:    make(chan struct{}),
+		queued:  make(chan struct{}, 1),
+	}
+
+	netConn.SetReadDeadline(time.Now().Add(timeout))
+	greeting, err := c.readMessage()
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	netConn.SetReadDeadline(time.Time{})
+
+	if greeting.headers["Content-Type"] != "auth/request" {
+		netConn.Close()
+		return nil, fmt.Errorf("esl: unexpected greeting %q", greeting.headers["Content-Type"])
+	}
+
+	go c.readLoop()
+	go c.deliverEvents()
+
+	if _, err := c.command("auth " + password); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("esl: authentication failed: %w", err)
+	}
+
+	if len(events) > 0 {
+		if _, err := c.command("event plain " + strings.Join(events, " ")); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("esl: event subscription failed: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Conn) API(command string) (string, error) {
+	reply, err := c.command("api " + command)
+	if err != nil {
+		return "", err
+	}
+
+	body := strings.TrimSpace(reply.body)
+	if strings.HasPrefix(body, "-ERR") {
+		return "", errors.New(strings.TrimSpace(strings.TrimPrefix(body, "-ERR")))
+	}
+
+	return body, nil
+}
+
+func (c *Conn) BgAPI(command, jobUUID string) error {
+	_, err := c.command("bgapi " + command + "\nJob-UUID: " + jobUUID)
+	return err
+}
+
+func (c *Conn) Events() <-chan Event {
+	return c.events
+}
+
+func (c *Conn) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.done)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// command writes a command and waits for its reply. Replies arrive in
+// command order, so commands are serialized.
+func (c *Conn) command(command string) (message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return message{}, ErrClosed
+	default:
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if _, err := io.WriteString(c.conn, command+"\n\n"); err != nil {
+		c.Close()
+		return message{}, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case reply := <-c.replies:
+		if text := reply.headers["Reply-Text"]; strings.HasPrefix(text, "-ERR") {
+			return reply, errors.New(strings.TrimSpace(strings.TrimPrefix(text, "-ERR")))
+		}
+		return reply, nil
+	case <-c.done:
+		return message{}, ErrClosed
+	case <-timer.C:
+		// The reply may still arrive and would be taken as the answer to
+		// the next command, so the connection cannot be reused.
+		c.Close()
+		return message{}, ErrTimeout
+	}
+}
+
+// readLoop reads messages until the connection ends. It hands replies to
+// the waiting command and queues events without blocking.
+func (c *Conn) readLoop() {
+	defer c.Close()
+
+	for {
+		msg, err := c.readMessage()
+		if err != nil {
+			return
+		}
+
+		switch msg.headers["Content-Type"] {
+		case "command/reply", "api/response":
+			select {
+			case c.replies <- msg:
+			case <-c.done:
+				return
+			}
+		case "text/event-plain":
+			event, err := parseEvent(msg.body)
+			if err != nil {
+				continue
+			}
+			c.queueMu.Lock()
+			c.queue = append(c.queue, event)
+			c.queueMu.Unlock()
+			select {
+			case c.queued <- struct{}{}:
+			default:
+			}
+		case "text/disconnect-notice":
+			return
+		}
+	}
+}
+
+// deliverEvents moves queued events to the Events channel and closes it
+// once the connection ends.
+func (c *Conn) deliverEvents() {
+	defer close(c.events)
+
+	for {
+		c.queueMu.Lock()
+		pending := c.queue
+		c.queue = nil
+		c.queueMu.Unlock()
+
+		for _, event := range pending {
+			select {
+			case c.events <- event:
+			case <-c.done:
+				return
+			}
+		}
+
+		select {
+		case <-c.queued:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Conn) readMessage() (message, error) {
+	headers, err := readHeaders(c.reader)
+	if err != nil {
+		return message{}, err
+	}
+
+	msg := message{headers: headers}
+	if length := headers["Content-Length"]; length != "" {
+		size, err := strconv.Atoi(length)
+		if err != nil || size < 0 {
+			return message{}, fmt.Errorf("esl: invalid content length %q", length)
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, body); err != nil {
+			return message{}, err
+		}
+		msg.body = string(body)
+	}
+
+	return msg, nil
+}
+
+func readHeaders(reader *bufio.Reader) (map[string]string, error) {
+	headers := map[string]string{}
+	tp := textproto.NewReader(reader)
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			if len(headers) == 0 {
+				// Stray blank lines between messages
+				continue
+			}
+			return headers, nil
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		headers[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+}
+
+// parseEvent decodes a text/event-plain body: URL-encoded headers, a blank
+// line and an optional Content-Length-delimited body.
+func parseEvent(data string) (Event, error) {
+	head, rest, _ := strings.Cut(data, "\n\n")
+
+	event := Event{Headers: map[string]string{}}
+	for _, line := range strings.Split(head, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		decoded, err := url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			decoded = strings.TrimSpace(value)
+		}
+		event.Headers[strings.TrimSpace(name)] = decoded
+	}
+
+	if event.Name() == "" {
+		return Event{}, errors.New("esl: event without a name")
+	}
+
+	if length, err := strconv.Atoi(event.Headers["Content-Length"]); err == nil && length <= len(rest) {
+		event.Body = rest[:length]
+	}
+
+	return event, nil
+}
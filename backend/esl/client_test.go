@@ -0,0 +1,115 @@
+package esl
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFakeConn(t *testing.T, events ...string) (*FakeServer, *Conn) {
+	t.Helper()
+
+	server, err := NewFakeServer("ClueCon")
+	if err != nil {
+		t.Fatalf("NewFakeServer: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := Dial(server.Addr(), "ClueCon", time.Second, events...)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return server, conn
+}
+
+func nextEvent(t *testing.T, conn *Conn) Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-conn.Events():
+		if !ok {
+			t.Fatal("event channel closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return Event{}
+}
+
+func TestDialAuthenticatesAndSubscribes(t *testing.T) {
+	server, _ := newFakeConn(t, "BACKGROUND_JOB", "CHANNEL_ANSWER")
+
+	commands := server.Commands()
+	if len(commands) != 2 || commands[0] != "auth ClueCon" || commands[1] != "event plain BACKGROUND_JOB CHANNEL_ANSWER" {
+		t.Errorf("commands = %q", commands)
+	}
+}
+
+func TestDialWrongPassword(t *testing.T) {
+	server, err := NewFakeServer("ClueCon")
+	if err != nil {
+		t.Fatalf("NewFakeServer: %v", err)
+	}
+	defer server.Close()
+
+	if _, err := Dial(server.Addr(), "wrong", time.Second); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Errorf("Dial() = %v, want an authentication error", err)
+	}
+}
+
+func TestBgAPIDeliversJobResult(t *testing.T) {
+	server, conn := newFakeConn(t, "BACKGROUND_JOB")
+	server.JobResult = "-ERR NO_ANSWER"
+
+	if err := conn.BgAPI("originate user/1001 &park", "job-1"); err != nil {
+		t.Fatalf("BgAPI: %v", err)
+	}
+
+	event := nextEvent(t, conn)
+	if event.Name() != "BACKGROUND_JOB" || event.Get("Job-UUID") != "job-1" || event.Get("Job-Command") != "originate" {
+		t.Errorf("event headers = %v", event.Headers)
+	}
+	if strings.TrimSpace(event.Body) != "-ERR NO_ANSWER" {
+		t.Errorf("event body = %q", event.Body)
+	}
+}
+
+func TestEmitDecodesHeaders(t *testing.T) {
+	server, conn := newFakeConn(t, "CHANNEL_HANGUP")
+
+	server.Emit(map[string]string{
+		"Event-Name":            "CHANNEL_HANGUP",
+		"Unique-ID":             "call-1",
+		"Caller-Caller-ID-Name": "Alice Smith",
+	}, "")
+
+	event := nextEvent(t, conn)
+	if event.Name() != "CHANNEL_HANGUP" || event.Get("Unique-ID") != "call-1" || event.Get("Caller-Caller-ID-Name") != "Alice Smith" {
+		t.Errorf("event headers = %v", event.Headers)
+	}
+}
+
+func TestDisconnectClosesEvents(t *testing.T) {
+	server, conn := newFakeConn(t)
+
+	if out, err := conn.API("status"); err != nil || out != "+OK" {
+		t.Fatalf("API() = %q, %v", out, err)
+	}
+
+	server.Disconnect()
+	select {
+	case _, ok := <-conn.Events():
+		if ok {
+			t.Error("unexpected event after disconnect")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events channel not closed after disconnect")
+	}
+
+	if _, err := conn.API("status"); err == nil {
+		t.Error("API succeeded on a closed connection")
+	}
+}
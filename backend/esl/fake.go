@@ -0,0 +1,238 @@
+package esl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FakeServer is an in-process event socket that accepts any command. It
+// records what it receives, answers api commands with what SetAPIResult
+// gave them or "+OK", answers every bgapi job with a BACKGROUND_JOB event
+// carrying JobResult, and lets tests push events of their own.
+type FakeServer struct {
+	Password  string
+	JobResult string
+
+	listener net.Listener
+
+	mu         sync.Mutex
+	commands   []string
+	apiResults map[string]string
+	conns      map[*fakeConn]bool
+}
+
+type fakeConn struct {
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+func NewFakeServer(password string) (*FakeServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FakeServer{
+		Password:   password,
+		JobResult:  "+OK",
+		listener:   listener,
+		apiResults: map[string]string{},
+		conns:      map[*fakeConn]bool{},
+	}
+	go s.accept()
+
+	return s, nil
+}
+
+func (s *FakeServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Commands returns every command received so far, without the trailing
+// blank line.
+func (s *FakeServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+// SetAPIResult sets the output of an api command, matched on the whole
+// command without the "api " prefix.
+func (s *FakeServer) SetAPIResult(command, output string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiResults[command] = output
+}
+
+// Emit sends an event to every authenticated connection.
+func (s *FakeServer) Emit(headers map[string]string, body string) {
+	s.mu.Lock()
+	conns := make([]*fakeConn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+
+	payload := encodeEvent(headers, body)
+	for _, conn := range conns {
+		conn.write("Content-Length: " + strconv.Itoa(len(payload)) + "\nContent-Type: text/event-plain\n\n" + payload)
+	}
+}
+
+// Disconnect drops every client connection, as a switch restart would.
+func (s *FakeServer) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		conn.conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+func (s *FakeServer) Close() error {
+	s.Disconnect()
+	return s.listener.Close()
+}
+
+func (s *FakeServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(&fakeConn{conn: conn})
+	}
+}
+
+func (s *FakeServer) serve(conn *fakeConn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn.conn)
+	conn.write("Content-Type: auth/request\n\n")
+
+	for {
+		command, headers, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		switch {
+		case strings.HasPrefix(command, "auth "):
+			if strings.TrimPrefix(command, "auth ") != s.Password {
+				conn.write("Content-Type: command/reply\nReply-Text: -ERR invalid\n\n")
+				return
+			}
+			s.mu.Lock()
+			s.conns[conn] = true
+			s.mu.Unlock()
+			conn.write("Content-Type: command/reply\nReply-Text: +OK accepted\n\n")
+
+		case strings.HasPrefix(command, "api "):
+			s.mu.Lock()
+			body, ok := s.apiResults[strings.TrimPrefix(command, "api ")]
+			s.mu.Unlock()
+			if !ok {
+				body = "+OK\n"
+			}
+			conn.write("Content-Type: api/response\nContent-Length: " + strconv.Itoa(len(body)) + "\n\n" + body)
+
+		case strings.HasPrefix(command, "bgapi "):
+			jobUUID := headers["Job-Uuid"]
+			name, arg, _ := strings.Cut(strings.TrimPrefix(command, "bgapi "), " ")
+			conn.write("Content-Type: command/reply\nReply-Text: +OK Job-UUID: " + jobUUID + "\nJob-UUID: " + jobUUID + "\n\n")
+			s.Emit(map[string]string{
+				"Event-Name":      "BACKGROUND_JOB",
+				"Job-UUID":        jobUUID,
+				"Job-Command":     name,
+				"Job-Command-Arg": arg,
+			}, s.JobResult+"\n")
+
+		default:
+			conn.write("Content-Type: command/reply\nReply-Text: +OK\n\n")
+		}
+	}
+}
+
+func (c *fakeConn) write(data string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	io.WriteString(c.conn, data)
+}
+
+// readCommand reads a command line and its header lines up to the blank
+// line that ends it.
+func readCommand(reader *bufio.Reader) (string, map[string]string, error) {
+	headers, err := readHeaderBlock(reader)
+	if err != nil {
+		return "", nil, err
+	}
+	return headers[0], parseHeaderLines(headers[1:]), nil
+}
+
+func readHeaderBlock(reader *bufio.Reader) ([]string, error) {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if len(lines) == 0 {
+				continue
+			}
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+func parseHeaderLines(lines []string) map[string]string {
+	headers := map[string]string{}
+	for _, line := range lines {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			headers[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
+
+func encodeEvent(headers map[string]string, body string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, url.QueryEscape(headers[name]))
+	}
+	if body != "" {
+		fmt.Fprintf(&b, "Content-Length: %d\n", len(body))
+	}
+	b.WriteString("\n")
+	b.WriteString(body)
+
+	return b.String()
+}
@@ -0,0 +1,396 @@
+package ivr
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	NodePlay       = "play"
+	NodeCollect    = "collect"
+	NodeBranch     = "branch"
+	NodeTransfer   = "transfer"
+	NodeHangup     = "hangup"
+	NodeHTTPLookup = "http_lookup"
+)
+
+// TransferTargets are the destinations a transfer node can hand a call to.
+// They match the routing rule target types, less IVRs themselves.
+var TransferTargets = map[string]bool{
+	"extension":  true,
+	"ring_group": true,
+	"queue":      true,
+	"voicemail":  true,
+	"external":   true,
+}
+
+var (
+	nodeID       = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	variableName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+	// promptPath is a sound file path relative to the switch's sounds
+	// directory. Path segments cannot start with a dot, which rules out
+	// "..", and the switch's variable and API expansion syntax cannot
+	// appear at all.
+	promptPath    = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*(/[A-Za-z0-9_-][A-Za-z0-9_.-]*)*$`)
+	topLevelLabel = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+// Graph is an IVR flow: nodes joined by their next, branch and on_error
+// references, entered at Start. It is stored as JSON.
+type Graph struct {
+	Start string `json:"start"`
+	Nodes []Node `json:"nodes"`
+}
+
+// Node is one step of a flow. Which fields apply depends on Type:
+//
+//   - play: Prompt, a sound file path such as ivr/welcome.wav, then Next
+//   - collect: Prompt, then waits for MinDigits to MaxDigits DTMF digits
+//     (ended early by Terminator or after Timeout seconds) into Variable,
+//     then Next
+//   - branch: compares Variable to each branch's Match in order and goes to
+//     the first that is equal, or to Default
+//   - transfer: hands the call to TargetType/TargetValue and ends the flow
+//   - hangup: ends the call
+//   - http_lookup: requests URL, which must be on a public host, with the
+//     flow variables as query parameters, stores the response body in
+//     Variable and goes to Next, or to OnError when the request fails
+type Node struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	Prompt      string   `json:"prompt,omitempty"`
+	Next        string   `json:"next,omitempty"`
+	Variable    string   `json:"variable,omitempty"`
+	MinDigits   int      `json:"min_digits,omitempty"`
+	MaxDigits   int      `json:"max_digits,omitempty"`
+	Timeout     int      `json:"timeout,omitempty"`
+	Terminator  string   `json:"terminator,omitempty"`
+	Branches    []Branch `json:"branches,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	TargetType  string   `json:"target_type,omitempty"`
+	TargetValue string   `json:"target_value,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	OnError     string   `json:"on_error,omitempty"`
+}
+
+type Branch struct {
+	Match string `json:"match"`
+	Next  string `json:"next"`
+}
+
+// Problem is a validation failure, attributed to a node when it has one.
+type Problem struct {
+	NodeID string `json:"node_id,omitempty"`
+	Error  string `json:"error"`
+}
+
+// Value stores the graph as JSON text.
+func (g Graph) Value() (driver.Value, error) {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (g *Graph) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*g = Graph{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), g)
+	case []byte:
+		return json.Unmarshal(v, g)
+	}
+	return fmt.Errorf("ivr: cannot scan %T into Graph", value)
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// TransfersTo reports whether a transfer node hands calls to the target.
+func (g *Graph) TransfersTo(targetType, targetValue string) bool {
+	for _, node := range g.Nodes {
+		if node.Type == NodeTransfer && node.TargetType == targetType && node.TargetValue == targetValue {
+			return true
+		}
+	}
+	return false
+}
+
+// edges lists the nodes a node can lead to.
+func (n *Node) edges() []string {
+	var edges []string
+	if n.Next != "" {
+		edges = append(edges, n.Next)
+	}
+	for _, branch := range n.Branches {
+		edges = append(edges, branch.Next)
+	}
+	if n.Default != "" {
+		edges = append(edges, n.Default)
+	}
+	if n.OnError != "" {
+		edges = append(edges, n.OnError)
+	}
+	return edges
+}
+
+// Validate checks a flow before it is published. Besides the per-node
+// fields it rejects references to missing nodes, nodes that cannot be
+// reached from Start and cycles that never wait for the caller: a loop is
+// only allowed through a collect node, as in a menu that repeats after an
+// invalid choice.
+func Validate(g *Graph) []Problem {
+	var problems []Problem
+	add := func(id, format string, args ...interface{}) {
+		problems = append(problems, Problem{NodeID: id, Error: fmt.Sprintf(format, args...)})
+	}
+
+	if len(g.Nodes) == 0 {
+		add("", "flow has no nodes")
+		return problems
+	}
+
+	ids := map[string]bool{}
+	variables := map[string]bool{}
+	for _, node := range g.Nodes {
+		if !nodeID.MatchString(node.ID) {
+			add(node.ID, "invalid node id")
+			continue
+		}
+		if ids[node.ID] {
+			add(node.ID, "duplicate node id")
+		}
+		ids[node.ID] = true
+		if (node.Type == NodeCollect || node.Type == NodeHTTPLookup) && node.Variable != "" {
+			variables[node.Variable] = true
+		}
+	}
+
+	if g.Start == "" {
+		add("", "start node is required")
+	} else if !ids[g.Start] {
+		add("", "start node %q does not exist", g.Start)
+	}
+
+	for i := range g.Nodes {
+		node := &g.Nodes[i]
+		for _, problem := range validateNode(node, variables) {
+			add(node.ID, "%s", problem)
+		}
+		for _, next := range node.edges() {
+			if next == "" {
+				continue
+			}
+			if !ids[next] {
+				add(node.ID, "references missing node %q", next)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		// Reachability and cycles are only meaningful on a well-formed graph
+		return problems
+	}
+
+	reached := map[string]bool{}
+	queue := []string{g.Start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if reached[id] {
+			continue
+		}
+		reached[id] = true
+		queue = append(queue, g.Node(id).edges()...)
+	}
+	for _, node := range g.Nodes {
+		if !reached[node.ID] {
+			add(node.ID, "node is unreachable from the start node")
+		}
+	}
+
+	if cycle := findCycle(g); cycle != "" {
+		add(cycle, "loop without a collect node")
+	}
+
+	return problems
+}
+
+func validateNode(node *Node, variables map[string]bool) []string {
+	var problems []string
+
+	switch node.Type {
+	case NodePlay:
+		problems = append(problems, validatePrompt(node.Prompt)...)
+		if node.Next == "" {
+			problems = append(problems, "next is required")
+		}
+
+	case NodeCollect:
+		problems = append(problems, validatePrompt(node.Prompt)...)
+		if !variableName.MatchString(node.Variable) {
+			problems = append(problems, "invalid variable name")
+		}
+		if node.MaxDigits < 1 || node.MaxDigits > 20 {
+			problems = append(problems, "max_digits must be 1 to 20")
+		}
+		if node.MinDigits < 0 || node.MinDigits > node.MaxDigits {
+			problems = append(problems, "min_digits must be 0 to max_digits")
+		}
+		if node.Timeout < 0 || node.Timeout > 60 {
+			problems = append(problems, "timeout must be 0 to 60 seconds")
+		}
+		if node.Terminator != "" && node.Terminator != "#" && node.Terminator != "*" {
+			problems = append(problems, "terminator must be # or *")
+		}
+		if node.Next == "" {
+			problems = append(problems, "next is required")
+		}
+
+	case NodeBranch:
+		if !variables[node.Variable] {
+			problems = append(problems, "branch variable is not set by any collect or http_lookup node")
+		}
+		if len(node.Branches) == 0 {
+			problems = append(problems, "at least one branch is required")
+		}
+		for _, branch := range node.Branches {
+			if branch.Next == "" {
+				problems = append(problems, "branch next is required")
+			}
+		}
+		if node.Default == "" {
+			problems = append(problems, "default is required")
+		}
+
+	case NodeTransfer:
+		if !TransferTargets[node.TargetType] {
+			problems = append(problems, "invalid target type")
+		}
+		if node.TargetValue == "" {
+			problems = append(problems, "target value is required")
+		}
+
+	case NodeHangup:
+		if node.Next != "" {
+			problems = append(problems, "hangup cannot have a next node")
+		}
+
+	case NodeHTTPLookup:
+		if !ValidLookupURL(node.URL) {
+			problems = append(problems, "url must be an absolute http or https URL on a public host")
+		}
+		if !variableName.MatchString(node.Variable) {
+			problems = append(problems, "invalid variable name")
+		}
+		if node.Next == "" {
+			problems = append(problems, "next is required")
+		}
+		if node.OnError == "" {
+			problems = append(problems, "on_error is required")
+		}
+
+	default:
+		problems = append(problems, "unknown node type")
+	}
+
+	return problems
+}
+
+func validatePrompt(prompt string) []string {
+	if prompt == "" {
+		return []string{"prompt is required"}
+	}
+	if !ValidPrompt(prompt) {
+		return []string{"prompt must be a sound file path such as ivr/welcome.wav"}
+	}
+	return nil
+}
+
+// ValidPrompt reports whether a prompt can be handed to the switch as is.
+func ValidPrompt(prompt string) bool {
+	return len(prompt) <= 255 && promptPath.MatchString(prompt)
+}
+
+// ValidLookupURL reports whether an http_lookup URL is an absolute http or
+// https URL without credentials on a host that is not loopback, private or
+// link-local. The switch makes the request, so this keeps tenants from
+// reaching services on its network.
+func ValidLookupURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.User != nil {
+		return false
+	}
+	if strings.ContainsAny(raw, "${} ") {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+			ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast())
+	}
+	// A dotless name resolves against the switch's search domains, and a
+	// numeric last label is an address in a form curl accepts, like 127.1
+	dot := strings.LastIndex(host, ".")
+	return dot > 0 && topLevelLabel.MatchString(host[dot+1:])
+}
+
+// findCycle returns a node on a loop that does not pass through a collect
+// node, or "" if there is none. Leaving collect nodes out of the graph
+// leaves exactly those loops.
+func findCycle(g *Graph) string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+
+	var visit func(id string) string
+	visit = func(id string) string {
+		node := g.Node(id)
+		if node == nil || node.Type == NodeCollect {
+			return ""
+		}
+		switch state[id] {
+		case visiting:
+			return id
+		case done:
+			return ""
+		}
+		state[id] = visiting
+		for _, next := range node.edges() {
+			if cycle := visit(next); cycle != "" {
+				return cycle
+			}
+		}
+		state[id] = done
+		return ""
+	}
+
+	for _, node := range g.Nodes {
+		if cycle := visit(node.ID); cycle != "" {
+			return cycle
+		}
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package ivr
+
+import (
+	"errors"
+	"strings"
+)
+
+const maxSimulationSteps = 500
+
+const (
+	OutcomeTransfer      = "transfer"
+	OutcomeHangup        = "hangup"
+	OutcomeAwaitingInput = "awaiting_input"
+	OutcomeStepLimit     = "step_limit"
+)
+
+var ErrInvalidInput = errors.New("inputs must be DTMF digits (0-9, * and #)")
+
+// Step is one node visited by the simulator.
+type Step struct {
+	NodeID string `json:"node_id"`
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+// Simulation is the path a call would take through a flow.
+type Simulation struct {
+	Steps       []Step            `json:"steps"`
+	Outcome     string            `json:"outcome"`
+	TargetType  string            `json:"target_type,omitempty"`
+	TargetValue string            `json:"target_value,omitempty"`
+	Variables   map[string]string `json:"variables"`
+}
+
+// Simulate walks a valid flow as a caller entering inputs, one per collect
+// node, would. HTTP lookups are not performed: lookups gives the response
+// for a node ID, and a node without one takes its on_error path. The walk
+// stops at a transfer or hangup, at a collect node once the inputs run
+// out, or after a fixed number of steps.
+func Simulate(g *Graph, inputs []string, lookups map[string]string) (*Simulation, error) {
+	for _, input := range inputs {
+		if strings.Trim(input, "0123456789*#") != "" {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	sim := &Simulation{Steps: []Step{}, Variables: map[string]string{}}
+	node := g.Node(g.Start)
+
+	for len(sim.Steps) < maxSimulationSteps && node != nil {
+		step := Step{NodeID: node.ID, Type: node.Type}
+		next := ""
+
+		switch node.Type {
+		case NodePlay:
+			step.Detail = "play " + node.Prompt
+			next = node.Next
+
+		case NodeCollect:
+			if len(inputs) == 0 {
+				step.Detail = "play " + node.Prompt + " and wait for digits"
+				sim.Steps = append(sim.Steps, step)
+				sim.Outcome = OutcomeAwaitingInput
+				return sim, nil
+			}
+			digits := collectDigits(node, inputs[0])
+			inputs = inputs[1:]
+			sim.Variables[node.Variable] = digits
+			step.Detail = "collected \"" + digits + "\" into " + node.Variable
+			next = node.Next
+
+		case NodeBranch:
+			value := sim.Variables[node.Variable]
+			next = node.Default
+			step.Detail = node.Variable + " = \"" + value + "\", no match, default"
+			for _, branch := range node.Branches {
+				if branch.Match == value {
+					next = branch.Next
+					step.Detail = node.Variable + " = \"" + value + "\" matched \"" + branch.Match + "\""
+					break
+				}
+			}
+
+		case NodeHTTPLookup:
+			if response, ok := lookups[node.ID]; ok {
+				sim.Variables[node.Variable] = response
+				step.Detail = "lookup " + node.URL + " returned \"" + response + "\""
+				next = node.Next
+			} else {
+				step.Detail = "lookup " + node.URL + " failed"
+				next = node.OnError
+			}
+
+		case NodeTransfer:
+			step.Detail = "transfer to " + node.TargetType + " " + node.TargetValue
+			sim.Steps = append(sim.Steps, step)
+			sim.Outcome = OutcomeTransfer
+			sim.TargetType = node.TargetType
+			sim.TargetValue = node.TargetValue
+			return sim, nil
+
+		case NodeHangup:
+			step.Detail = "hang up"
+			sim.Steps = append(sim.Steps, step)
+			sim.Outcome = OutcomeHangup
+			return sim, nil
+		}
+
+		sim.Steps = append(sim.Steps, step)
+		node = g.Node(next)
+	}
+
+	if node == nil {
+		// Validate rules this out; treat a broken reference as a hangup
+		sim.Outcome = OutcomeHangup
+		return sim, nil
+	}
+
+	sim.Outcome = OutcomeStepLimit
+	return sim, nil
+}
+
+// collectDigits applies a collect node's terminator and digit limits to
+// what the caller typed. Too few digits count as no input, as on a timeout.
+func collectDigits(node *Node, input string) string {
+	if node.Terminator != "" {
+		if i := strings.Index(input, node.Terminator); i >= 0 {
+			input = input[:i]
+		}
+	}
+	if len(input) > node.MaxDigits {
+		input = input[:node.MaxDigits]
+	}
+	if len(input) < node.MinDigits {
+		return ""
+	}
+	return input
+}
@@ -0,0 +1,124 @@
+package ivr
+
+import (
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name        string
+		inputs      []string
+		lookups     map[string]string
+		outcome     string
+		targetValue string
+		path        []string
+	}{
+		{
+			name:    "waits at the menu",
+			outcome: OutcomeAwaitingInput,
+			path:    []string{"welcome", "menu"},
+		},
+		{
+			name:        "sales",
+			inputs:      []string{"1"},
+			outcome:     OutcomeTransfer,
+			targetValue: "2000",
+			path:        []string{"welcome", "menu", "route", "sales"},
+		},
+		{
+			name:    "invalid choice repeats the menu",
+			inputs:  []string{"9"},
+			outcome: OutcomeAwaitingInput,
+			path:    []string{"welcome", "menu", "route", "menu"},
+		},
+		{
+			name:        "gold account",
+			inputs:      []string{"2", "12345#99"},
+			lookups:     map[string]string{"lookup": "gold"},
+			outcome:     OutcomeTransfer,
+			targetValue: "2000",
+			path:        []string{"welcome", "menu", "route", "account", "lookup", "by_tier", "sales"},
+		},
+		{
+			name:        "failed lookup",
+			inputs:      []string{"2", "12345"},
+			outcome:     OutcomeTransfer,
+			targetValue: "3000",
+			path:        []string{"welcome", "menu", "route", "account", "lookup", "support"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim, err := Simulate(menuGraph(), tt.inputs, tt.lookups)
+			if err != nil {
+				t.Fatalf("Simulate: %v", err)
+			}
+			if sim.Outcome != tt.outcome || sim.TargetValue != tt.targetValue {
+				t.Errorf("outcome = %s %q, want %s %q", sim.Outcome, sim.TargetValue, tt.outcome, tt.targetValue)
+			}
+			var path []string
+			for _, step := range sim.Steps {
+				path = append(path, step.NodeID)
+			}
+			if len(path) != len(tt.path) {
+				t.Fatalf("path = %v, want %v", path, tt.path)
+			}
+			for i := range path {
+				if path[i] != tt.path[i] {
+					t.Fatalf("path = %v, want %v", path, tt.path)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateVariables(t *testing.T) {
+	sim, err := Simulate(menuGraph(), []string{"2", "12345#99"}, map[string]string{"lookup": "silver"})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	want := map[string]string{"choice": "2", "account": "12345", "tier": "silver"}
+	for name, value := range want {
+		if sim.Variables[name] != value {
+			t.Errorf("variable %s = %q, want %q", name, sim.Variables[name], value)
+		}
+	}
+	if sim.TargetValue != "3000" {
+		t.Errorf("silver tier transferred to %q, want the support queue", sim.TargetValue)
+	}
+}
+
+func TestSimulateTooFewDigits(t *testing.T) {
+	// Three digits are short of the account's four, so they count as no
+	// input and the empty tier lookup still runs
+	sim, err := Simulate(menuGraph(), []string{"2", "123"}, nil)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if account, ok := sim.Variables["account"]; !ok || account != "" {
+		t.Errorf("account = %q, %v, want an empty value", account, ok)
+	}
+}
+
+func TestSimulateStepLimit(t *testing.T) {
+	inputs := make([]string, maxSimulationSteps)
+	for i := range inputs {
+		inputs[i] = "9"
+	}
+
+	sim, err := Simulate(menuGraph(), inputs, nil)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if sim.Outcome != OutcomeStepLimit || len(sim.Steps) != maxSimulationSteps {
+		t.Errorf("outcome = %s after %d steps, want %s after %d", sim.Outcome, len(sim.Steps), OutcomeStepLimit, maxSimulationSteps)
+	}
+}
+
+func TestSimulateRejectsInput(t *testing.T) {
+	if _, err := Simulate(menuGraph(), []string{"1a"}, nil); err != ErrInvalidInput {
+		t.Errorf("Simulate error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import "sync"
+
+// FakeMailer keeps sent messages in memory instead of delivering them. It
+// lets notifications run offline and in tests.
+type FakeMailer struct {
+	Err error
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (m *FakeMailer) Send(msg Message) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+
+	return nil
+}
+
+// Sent returns every message sent so far.
+func (m *FakeMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.sent...)
+}
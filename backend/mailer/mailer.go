@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers notification emails.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Config selects a mailer. Driver is "fake" or "smtp"; an empty driver
+// disables email notifications.
+type Config struct {
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "fake":
+		return &FakeMailer{}, nil
+	case "smtp":
+		if cfg.Host == "" || cfg.From == "" {
+			return nil, errors.New("smtp host and from address are required")
+		}
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", cfg.Driver)
+	}
+}
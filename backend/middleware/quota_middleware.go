@@ -59,6 +59,9 @@ func CheckUserQuota(db *gorm.DB) fiber.Handler {
 	}
 }
 
+// CheckCallQuota caps the number of call records a tenant can create by
+// hand. Calls placed through the switch are limited by call admission
+// instead.
 func CheckCallQuota(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		tenantID := c.Locals("tenant_id")
@@ -75,6 +78,19 @@ func CheckCallQuota(db *gorm.DB) fiber.Handler {
 			})
 		}
 
+		// Block tenants flagged by fraud detection until an admin clears them
+		blocked, err := services.NewFraudService(db).IsTenantBlocked(tenantIDUint)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to check fraud status",
+			})
+		}
+		if blocked {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "call creation blocked: suspected toll fraud",
+			})
+		}
+
 		// Get active subscription for tenant
 		subscriptionService := services.NewSubscriptionService(db)
 		subscription, err := subscriptionService.GetTenantSubscription(tenantIDUint)
@@ -89,10 +105,12 @@ func CheckCallQuota(db *gorm.DB) fiber.Handler {
 			})
 		}
 
-		// Count total calls for the tenant
+		// Count total calls for the tenant. Attempts screening rejected are
+		// kept for reporting but are not calls the tenant made.
 		var callCount int64
 		if err := db.Model(&models.Call{}).
 			Where("tenant_id = ? AND deleted_at IS NULL", tenantIDUint).
+			Where("COALESCE(disposition, '') <> ?", models.CallDispositionBlocked).
 			Count(&callCount).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "failed to count calls",
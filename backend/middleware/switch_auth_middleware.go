@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"strings"
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/services"
+	"gorm.io/gorm"
+)
+
+// SwitchAuthMiddleware authenticates telephony switches with HTTP basic auth
+// against a SwitchCredential and sets the tenant context from it.
+func SwitchAuthMiddleware(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		username, secret, ok := parseBasicAuth(c.Get("Authorization"))
+		if !ok {
+			c.Set("WWW-Authenticate", `Basic realm="switch"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "switch credentials required",
+			})
+		}
+
+		credentialService := services.NewSwitchCredentialService(db)
+		credential, err := credentialService.Authenticate(username, secret)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "invalid switch credentials",
+			})
+		}
+
+		c.Locals("tenant_id", credential.TenantID)
+		c.Locals("switch_credential_id", credential.ID)
+
+		return c.Next()
+	}
+}
+
+func parseBasicAuth(header string) (string, string, bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+
+	username, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok || username == "" {
+		return "", "", false
+	}
+
+	return username, secret, true
+}
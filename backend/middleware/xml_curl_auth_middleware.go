@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log"
+	"net"
+	"strings"
+	"github.com/gofiber/fiber/v2"
+)
+
+// XMLCurlAuthMiddleware admits FreeSWITCH mod_xml_curl fetches from an
+// allowlisted address (IP or CIDR) or carrying the shared secret as the
+// basic auth password (the gateway-credentials binding setting). With
+// neither configured every request is refused.
+func XMLCurlAuthMiddleware(secret string, allowedIPs []string) fiber.Handler {
+	var networks []*net.IPNet
+	for _, entry := range allowedIPs {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if strings.Contains(entry, ":") {
+				entry += "/128"
+			} else {
+				entry += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("xml_curl: ignoring invalid allowlist entry %q: %v", entry, err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+
+	return func(c *fiber.Ctx) error {
+		if ip := net.ParseIP(c.IP()); ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					return c.Next()
+				}
+			}
+		}
+
+		if secret != "" {
+			if _, password, ok := parseBasicAuth(c.Get("Authorization")); ok &&
+				subtle.ConstantTimeCompare([]byte(password), []byte(secret)) == 1 {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "switch not allowed",
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ActiveChannelStateRinging  = "ringing"
+	ActiveChannelStateAnswered = "answered"
+)
+
+// ActiveChannel is a live channel on a switch, keyed by the switch's
+// channel UUID. Rows are removed at hangup, so the table only ever holds
+// calls in progress.
+type ActiveChannel struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UUID       string     `gorm:"not null;uniqueIndex" json:"uuid"`
+	TenantID   uint       `gorm:"not null;index" json:"tenant_id"`
+	Hostname   string     `gorm:"index" json:"hostname"`
+	Direction  string     `json:"direction"`
+	Caller     string     `json:"caller"`
+	Callee     string     `json:"callee"`
+	State      string     `gorm:"not null" json:"state"`
+	StartedAt  time.Time  `json:"started_at"`
+	AnsweredAt *time.Time `json:"answered_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
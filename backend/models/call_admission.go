@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// CallAdmission records a call let through the real-time admission check.
+// Admissions without ReleasedAt count against the plan's concurrent call
+// limit; AdmittedAt drives the calls-per-second limit.
+type CallAdmission struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	TenantID   uint       `gorm:"not null;index" json:"tenant_id"`
+	CallUUID   string     `gorm:"not null;uniqueIndex" json:"call_uuid"`
+	AdmittedAt time.Time  `gorm:"not null" json:"admitted_at"`
+	ReleasedAt *time.Time `json:"released_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+const (
+	CallEventQueueEnter   = "queue_enter"
+	CallEventQueueAnswer  = "queue_answer"
+	CallEventQueueAbandon = "queue_abandon"
+	CallEventQueueExit    = "queue_exit"
+	CallEventAgentHangup  = "agent_hangup"
+)
+
+// CallEvent is a step in a call's life reported by the switch, such as
+// entering a queue or being answered by an agent. Events are keyed by the
+// call UUID and linked to the Call once it exists, since they usually
+// arrive before the CDR.
+type CallEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"not null;index" json:"tenant_id"`
+	CallUUID   string    `gorm:"not null;index" json:"call_uuid"`
+	CallID     *uint     `gorm:"index" json:"call_id,omitempty"`
+	Type       string    `gorm:"not null;size:30" json:"type"`
+	QueueID    *uint     `gorm:"index" json:"queue_id,omitempty"`
+	AgentID    *uint     `json:"agent_id,omitempty"`
+	OccurredAt time.Time `gorm:"not null" json:"occurred_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
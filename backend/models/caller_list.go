@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	CallerListBlock = "block"
+	CallerListAllow = "allow"
+)
+
+const (
+	CallerMatchExact    = "exact"
+	CallerMatchPrefix   = "prefix"
+	CallerMatchWildcard = "wildcard"
+)
+
+// CallerListEntry is a caller ID pattern on a tenant's block or allow list.
+// Exact patterns are stored in E.164 when they parse as a number; prefix
+// patterns match the start of the caller ID; wildcard patterns use ? for
+// one digit and * for any digits. LikePattern is the SQL LIKE form of the
+// pattern that screening matches with. An entry past ExpiresAt no longer
+// applies. An allow entry overrides any block entry.
+type CallerListEntry struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TenantID    uint       `gorm:"not null;uniqueIndex:idx_caller_list_entries_pattern" json:"tenant_id"`
+	ListType    string     `gorm:"not null;size:10;uniqueIndex:idx_caller_list_entries_pattern" json:"list_type"`
+	MatchType   string     `gorm:"not null;size:10;uniqueIndex:idx_caller_list_entries_pattern" json:"match_type"`
+	Pattern     string     `gorm:"not null;size:32;uniqueIndex:idx_caller_list_entries_pattern" json:"pattern"`
+	LikePattern string     `gorm:"not null;size:32" json:"-"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedBy   *uint      `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
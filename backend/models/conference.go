@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+// ConferenceRoom is a reusable meeting bridge. Callers reach it by dialing
+// Extension from the tenant's endpoints, or on the dial-in PhoneNumberID,
+// which then routes to the room instead of through routing rules. The
+// moderator PIN joins as moderator; the participant PIN, when set, is
+// required of everyone else. MaxParticipants of 0 leaves the room limited
+// only by the plan. The room only admits callers between ValidFrom and
+// ValidUntil, when set.
+type ConferenceRoom struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	TenantID         uint           `gorm:"not null;index" json:"tenant_id"`
+	Name             string         `gorm:"not null" json:"name"`
+	Extension        string         `gorm:"not null;size:20" json:"extension"`
+	PhoneNumberID    *uint          `gorm:"index" json:"phone_number_id"`
+	ParticipantPIN   string         `gorm:"size:10" json:"participant_pin"`
+	ModeratorPIN     string         `gorm:"size:10" json:"moderator_pin"`
+	MaxParticipants  int            `gorm:"not null;default:0" json:"max_participants"`
+	RecordingEnabled bool           `gorm:"default:false" json:"recording_enabled"`
+	ValidFrom        *time.Time     `json:"valid_from"`
+	ValidUntil       *time.Time     `json:"valid_until"`
+	IsActive         bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	PhoneNumber *PhoneNumber `gorm:"foreignKey:PhoneNumberID" json:"phone_number,omitempty"`
+}
+
+// ConferenceParticipant is a caller currently in a room, kept from the
+// switch's conference events. Like ActiveChannel, rows are removed when the
+// member leaves, so the table only holds live participants.
+type ConferenceParticipant struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	TenantID       uint      `gorm:"not null;index" json:"tenant_id"`
+	RoomID         uint      `gorm:"not null;index" json:"room_id"`
+	Hostname       string    `gorm:"index" json:"hostname"`
+	ChannelUUID    string    `gorm:"not null;uniqueIndex" json:"channel_uuid"`
+	MemberID       int       `json:"member_id"`
+	CallerIDNumber string    `json:"caller_id_number"`
+	CallerIDName   string    `json:"caller_id_name"`
+	IsModerator    bool      `json:"is_moderator"`
+	IsMuted        bool      `json:"is_muted"`
+	JoinedAt       time.Time `json:"joined_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
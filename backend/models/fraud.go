@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"gorm.io/gorm"
+)
+
+const (
+	// FraudRuleHighRiskPrefix trips on a call to any of Prefixes.
+	FraudRuleHighRiskPrefix = "high_risk_prefix"
+	// FraudRuleHourlySpend trips when the cost of the last hour's calls
+	// exceeds Threshold.
+	FraudRuleHourlySpend = "hourly_spend"
+	// FraudRuleVolumeSpike trips when the last hour's call count exceeds
+	// Threshold times the hourly average of the preceding BaselineHours,
+	// once at least MinCalls were made.
+	FraudRuleVolumeSpike = "volume_spike"
+)
+
+const (
+	FraudAlertStatusOpen     = "open"
+	FraudAlertStatusResolved = "resolved"
+)
+
+// FraudRule is a tenant-configured check run against every new call. When
+// BlockTenant is set, tripping the rule also stops the tenant from creating
+// calls until an admin lifts the block.
+type FraudRule struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	TenantID        uint           `gorm:"not null;index" json:"tenant_id"`
+	Name            string         `gorm:"not null" json:"name"`
+	Type            string         `gorm:"not null" json:"type"`
+	Prefixes        string         `gorm:"type:text" json:"prefixes"`
+	Threshold       float64        `gorm:"type:decimal(12,4);default:0" json:"threshold"`
+	BaselineHours   int            `gorm:"default:168" json:"baseline_hours"`
+	MinCalls        int            `gorm:"default:10" json:"min_calls"`
+	CooldownMinutes int            `gorm:"default:60" json:"cooldown_minutes"`
+	BlockTenant     bool           `gorm:"default:false" json:"block_tenant"`
+	IsActive        bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// PrefixList returns the comma-separated Prefixes as a slice.
+func (r *FraudRule) PrefixList() []string {
+	var prefixes []string
+	for _, prefix := range strings.Split(r.Prefixes, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+type FraudAlert struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TenantID      uint       `gorm:"not null;index" json:"tenant_id"`
+	RuleID        uint       `gorm:"not null;index" json:"rule_id"`
+	CallID        *uint      `gorm:"index" json:"call_id"`
+	RuleType      string     `gorm:"not null" json:"rule_type"`
+	Message       string     `gorm:"not null" json:"message"`
+	Value         float64    `json:"value"`
+	Threshold     float64    `json:"threshold"`
+	Status        string     `gorm:"not null;default:open;index" json:"status"`
+	BlockedTenant bool       `gorm:"default:false" json:"blocked_tenant"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy    *uint      `json:"resolved_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relations
+	Rule FraudRule `gorm:"foreignKey:RuleID" json:"rule,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/ivr"
+)
+
+// IvrFlow is a tenant's IVR menu. Graph is the working draft, which may be
+// incomplete; calls only ever run a published IvrFlowVersion, and
+// PublishedVersion is the latest one (0 until the first publish).
+type IvrFlow struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	TenantID         uint           `gorm:"not null;index" json:"tenant_id"`
+	Name             string         `gorm:"not null" json:"name"`
+	Description      string         `json:"description"`
+	Graph            ivr.Graph      `gorm:"type:text;not null" json:"graph"`
+	PublishedVersion int            `gorm:"not null;default:0" json:"published_version"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// IvrFlowVersion is an immutable snapshot of a flow's graph taken when it
+// was published.
+type IvrFlowVersion struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	FlowID      uint      `gorm:"not null;uniqueIndex:idx_ivr_flow_versions_flow_version" json:"flow_id"`
+	TenantID    uint      `gorm:"not null;index" json:"tenant_id"`
+	Version     int       `gorm:"not null;uniqueIndex:idx_ivr_flow_versions_flow_version" json:"version"`
+	Graph       ivr.Graph `gorm:"type:text;not null" json:"graph"`
+	PublishedBy *uint     `json:"published_by,omitempty"`
+	PublishedAt time.Time `gorm:"not null" json:"published_at"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 	"gorm.io/gorm"
 )
@@ -13,6 +15,16 @@ type Tenant struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 	
+	// DefaultCountry (ISO 3166 alpha-2) resolves numbers dialled in
+	// national format.
+	DefaultCountry string `gorm:"size:2" json:"default_country"`
+	// RetentionDays overrides the plan's retention; nil inherits it and 0
+	// keeps calls forever.
+	RetentionDays *uint `json:"retention_days"`
+	// A tenant with FraudBlockedAt set cannot create calls until an admin
+	// clears it.
+	FraudBlockedAt *time.Time `json:"fraud_blocked_at"`
+	
 	// Relations
 	Users         []User         `gorm:"foreignKey:TenantID" json:"users,omitempty"`
 	Roles         []Role         `gorm:"foreignKey:TenantID" json:"roles,omitempty"`
@@ -122,6 +134,31 @@ type UserRole struct {
 	// gorm:"uniqueIndex:idx_user_role_tenant_unique"
 }
 
+// Call dispositions. Blocked marks a call attempt rejected by the tenant's
+// caller blocklist; it never reached a destination.
+const (
+	CallDispositionAnswered = "answered"
+	CallDispositionBusy     = "busy"
+	CallDispositionNoAnswer = "no_answer"
+	CallDispositionFailed   = "failed"
+	CallDispositionBlocked  = "blocked"
+)
+
+// Call directions, from the tenant's point of view.
+const (
+	CallDirectionInbound  = "inbound"
+	CallDirectionOutbound = "outbound"
+	CallDirectionInternal = "internal"
+)
+
+// Call hangup sides: the caller's leg, the callee's leg, or the switch
+// itself (rejections, timeouts and failures).
+const (
+	CallHangupCaller = "caller"
+	CallHangupCallee = "callee"
+	CallHangupSystem = "system"
+)
+
 type Call struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
 	TenantID     uint           `gorm:"not null;index" json:"tenant_id"`
@@ -132,14 +169,57 @@ type Call struct {
 	AnswerTime   *time.Time     `json:"answer_time"`
 	EndTime      *time.Time     `json:"end_time"`
 	Billsec      int            `gorm:"default:0" json:"billsec"`
-	RecordingURL string         `json:"recording_url"`
+	RecordingURL string         `json:"-"`
+	RecordingKey string         `json:"-"`
 	Cost         float64        `gorm:"type:decimal(10,4);default:0" json:"cost"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 	
+	// Caller and Callee are kept exactly as reported. The E.164 fields
+	// hold the normalized form with country and number type, and are empty
+	// when the raw value is not a resolvable phone number.
+	CallerE164    string `gorm:"size:20" json:"caller_e164"`
+	CallerCountry string `gorm:"size:2" json:"caller_country"`
+	CallerType    string `gorm:"size:20" json:"caller_type"`
+	CalleeE164    string `gorm:"size:20" json:"callee_e164"`
+	CalleeCountry string `gorm:"size:2" json:"callee_country"`
+	CalleeType    string `gorm:"size:20" json:"callee_type"`
+	
+	// Disposition is how the call attempt ended, HangupCause and
+	// HangupCauseCode its Q.850 cause and SIPCode the final SIP response,
+	// each empty when not known.
+	Direction       string `gorm:"size:10" json:"direction,omitempty"`
+	Disposition     string `gorm:"size:20" json:"disposition,omitempty"`
+	HangupCause     string `gorm:"size:40" json:"hangup_cause,omitempty"`
+	HangupCauseCode int    `gorm:"default:0" json:"hangup_cause_code,omitempty"`
+	SIPCode         int    `gorm:"default:0" json:"sip_code,omitempty"`
+	HangupSide      string `gorm:"size:10" json:"hangup_side,omitempty"`
+	
 	// Relations
-	Tenant Tenant `gorm:"foreignKey:TenantID" json:"tenant,omitempty"`
+	Tenant Tenant      `gorm:"foreignKey:TenantID" json:"tenant,omitempty"`
+	Events []CallEvent `gorm:"foreignKey:CallID" json:"events,omitempty"`
+}
+
+// MarshalJSON hides where a recording lives and only reports whether one
+// can be fetched. Recordings are fetched through short-lived signed links
+// instead.
+func (c Call) MarshalJSON() ([]byte, error) {
+	type call Call
+	return json.Marshal(struct {
+		call
+		HasRecording bool `json:"has_recording"`
+	}{call(c), c.RecordingKey != "" || c.RemoteRecordingURL() != ""})
+}
+
+// RemoteRecordingURL is the recording location reported by the switch when
+// it is a web address clients can be sent to. Paths on the switch's own
+// disk are not reachable and give "".
+func (c Call) RemoteRecordingURL() string {
+	if strings.HasPrefix(c.RecordingURL, "https://") || strings.HasPrefix(c.RecordingURL, "http://") {
+		return c.RecordingURL
+	}
+	return ""
 }
 
 // Method to add unique indexes for UserTenant and UserRole models
@@ -161,6 +241,17 @@ type Plan struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 	
+	// Unlike MaxUsers and MaxCalls, these limits are off when 0, so plans
+	// created before a limit existed keep working.
+	MaxPhoneNumbers           uint `gorm:"not null;default:0" json:"max_phone_numbers"`
+	MaxSipEndpoints           uint `gorm:"not null;default:0" json:"max_sip_endpoints"`
+	MaxConcurrentCalls        uint `gorm:"not null;default:0" json:"max_concurrent_calls"`
+	MaxCPS                    uint `gorm:"column:max_cps;not null;default:0" json:"max_cps"`
+	MaxConferenceRooms        uint `gorm:"not null;default:0" json:"max_conference_rooms"`
+	MaxConferenceParticipants uint `gorm:"not null;default:0" json:"max_conference_participants"`
+	// RetentionDays is how long calls are kept; 0 keeps them forever.
+	RetentionDays uint `gorm:"not null;default:0" json:"retention_days"`
+	
 	// Relations
 	Subscriptions []Subscription `gorm:"foreignKey:PlanID" json:"subscriptions,omitempty"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCallMarshalJSONHidesRecordingLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		call Call
+		want bool
+	}{
+		{"no recording", Call{UUID: "call-1"}, false},
+		{"switch recording", Call{UUID: "call-1", RecordingURL: "https://rec.example.com/secret.wav"}, true},
+		{"stored recording", Call{UUID: "call-1", RecordingKey: "recordings/call-1.wav"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.call)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if strings.Contains(string(data), "recording_url") || strings.Contains(string(data), "recording_key") || strings.Contains(string(data), "secret") {
+				t.Errorf("recording location leaked: %s", data)
+			}
+
+			var decoded struct {
+				UUID         string `json:"uuid"`
+				HasRecording bool   `json:"has_recording"`
+			}
+			json.Unmarshal(data, &decoded)
+			if decoded.UUID != "call-1" || decoded.HasRecording != tt.want {
+				t.Errorf("decoded %+v, want has_recording %v", decoded, tt.want)
+			}
+		})
+	}
+}
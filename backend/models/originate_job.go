@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	OriginateJobStatusPending = "pending"
+	OriginateJobStatusStarted = "started"
+	OriginateJobStatusFailed  = "failed"
+)
+
+// OriginateJob tracks a click-to-call request sent to the switch as a
+// background job. CallUUID is the origination UUID given to the A leg, so
+// the switch's CDR for that leg updates the same Call.
+type OriginateJob struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TenantID    uint      `gorm:"not null;index" json:"tenant_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	CallID      uint      `gorm:"not null;index" json:"call_id"`
+	JobUUID     string    `gorm:"not null;uniqueIndex" json:"job_uuid"`
+	CallUUID    string    `gorm:"not null" json:"call_uuid"`
+	Extension   string    `gorm:"not null" json:"extension"`
+	Destination string    `gorm:"not null" json:"destination"`
+	Status      string    `gorm:"not null;default:pending;index" json:"status"`
+	Result      string    `json:"result"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+const (
+	PhoneNumberStatusAvailable = "available"
+	PhoneNumberStatusAssigned  = "assigned"
+	PhoneNumberStatusSuspended = "suspended"
+)
+
+// PhoneNumber is a DID in the platform inventory. Numbers without a TenantID
+// are unassigned stock. RoutingType and RoutingDestination say where inbound
+// calls to the number go.
+type PhoneNumber struct {
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	Number             string         `gorm:"not null;size:20;uniqueIndex" json:"number"`
+	Country            string         `gorm:"size:2;index" json:"country"`
+	NumberType         string         `gorm:"size:20" json:"number_type"`
+	TenantID           *uint          `gorm:"index" json:"tenant_id"`
+	Status             string         `gorm:"not null;default:available;index" json:"status"`
+	VoiceEnabled       bool           `gorm:"default:true" json:"voice_enabled"`
+	SMSEnabled         bool           `gorm:"default:false" json:"sms_enabled"`
+	FaxEnabled         bool           `gorm:"default:false" json:"fax_enabled"`
+	MonthlyCost        float64        `gorm:"type:decimal(10,4);default:0" json:"monthly_cost"`
+	Carrier            string         `json:"carrier"`
+	RoutingType        string         `json:"routing_type"`
+	RoutingDestination string         `json:"routing_destination"`
+	AssignedAt         *time.Time     `json:"assigned_at,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Assignments []PhoneNumberAssignment `gorm:"foreignKey:PhoneNumberID" json:"assignments,omitempty"`
+}
+
+// PhoneNumberAssignment is one period during which a number belonged to a
+// tenant. ReleasedAt is nil for the current assignment.
+type PhoneNumberAssignment struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	PhoneNumberID uint       `gorm:"not null;index" json:"phone_number_id"`
+	TenantID      uint       `gorm:"not null;index" json:"tenant_id"`
+	AssignedAt    time.Time  `gorm:"not null" json:"assigned_at"`
+	AssignedBy    *uint      `json:"assigned_by,omitempty"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+	ReleasedBy    *uint      `json:"released_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
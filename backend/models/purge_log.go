@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// PurgeLog records one retention run for a tenant. Rows are never updated
+// or deleted so they can serve as evidence of deletion.
+type PurgeLog struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	TenantID          uint      `gorm:"not null;index" json:"tenant_id"`
+	RetentionDays     uint      `gorm:"not null" json:"retention_days"`
+	GraceDays         uint      `gorm:"not null" json:"grace_days"`
+	SoftDeleteCutoff  time.Time `gorm:"not null" json:"soft_delete_cutoff"`
+	HardDeleteCutoff  time.Time `gorm:"not null" json:"hard_delete_cutoff"`
+	CallsSoftDeleted  int64     `gorm:"not null;default:0" json:"calls_soft_deleted"`
+	CallsHardDeleted  int64     `gorm:"not null;default:0" json:"calls_hard_deleted"`
+	RecordingsDeleted int64     `gorm:"not null;default:0" json:"recordings_deleted"`
+	Failures          int64     `gorm:"not null;default:0" json:"failures"`
+	Error             string    `json:"error,omitempty"`
+	StartedAt         time.Time `gorm:"not null" json:"started_at"`
+	FinishedAt        time.Time `gorm:"not null" json:"finished_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
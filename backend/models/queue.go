@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+const (
+	QueueStrategyRingAll     = "ring_all"
+	QueueStrategyLongestIdle = "longest_idle"
+	QueueStrategyRoundRobin  = "round_robin"
+)
+
+const (
+	AgentStateAvailable = "available"
+	AgentStatePaused    = "paused"
+	AgentStateOnCall    = "on_call"
+	AgentStateWrapUp    = "wrap_up"
+)
+
+// Queue is an ACD queue. Strategy picks which available agents are offered
+// a call; ServiceLevelSeconds is the answer target used by the service
+// level metric. After a call an agent stays in wrap-up for WrapUpSeconds.
+type Queue struct {
+	ID                  uint           `gorm:"primaryKey" json:"id"`
+	TenantID            uint           `gorm:"not null;index" json:"tenant_id"`
+	Name                string         `gorm:"not null" json:"name"`
+	Strategy            string         `gorm:"not null;size:20;default:ring_all" json:"strategy"`
+	ServiceLevelSeconds int            `gorm:"not null;default:20" json:"service_level_seconds"`
+	WrapUpSeconds       int            `gorm:"not null;default:0" json:"wrap_up_seconds"`
+	AgentTimeout        int            `gorm:"not null;default:20" json:"agent_timeout"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Agents []QueueAgent `gorm:"foreignKey:QueueID" json:"agents,omitempty"`
+}
+
+// QueueAgent is a tenant user's membership of a queue, with the agent's
+// state in it. LastOfferedAt drives round-robin and LastCallEndedAt
+// longest-idle selection. PauseReason is only set while paused.
+type QueueAgent struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	TenantID        uint       `gorm:"not null;index" json:"tenant_id"`
+	QueueID         uint       `gorm:"not null;uniqueIndex:idx_queue_agents_queue_user" json:"queue_id"`
+	UserID          uint       `gorm:"not null;uniqueIndex:idx_queue_agents_queue_user;index" json:"user_id"`
+	State           string     `gorm:"not null;size:20;default:available" json:"state"`
+	PauseReason     string     `json:"pause_reason,omitempty"`
+	StateChangedAt  time.Time  `gorm:"not null" json:"state_changed_at"`
+	LastOfferedAt   *time.Time `json:"last_offered_at,omitempty"`
+	LastCallEndedAt *time.Time `json:"last_call_ended_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
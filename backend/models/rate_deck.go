@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+// RateDeck is a named table of destination prefixes. Decks without a
+// TenantID are global and apply to every tenant that has no matching rate
+// in its own decks.
+type RateDeck struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TenantID  *uint          `gorm:"index" json:"tenant_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Rates []Rate `gorm:"foreignKey:RateDeckID" json:"rates,omitempty"`
+}
+
+// Rate prices calls whose destination starts with Prefix. Billable time is
+// at least MinDuration seconds and is then rounded up to Increment seconds,
+// so a 60/6 rate bills the first minute in full and then every 6 seconds.
+type Rate struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	RateDeckID    uint      `gorm:"not null;uniqueIndex:idx_rate_deck_prefix" json:"rate_deck_id"`
+	Prefix        string    `gorm:"not null;uniqueIndex:idx_rate_deck_prefix;index" json:"prefix"`
+	Description   string    `json:"description"`
+	RatePerMinute float64   `gorm:"type:decimal(10,4);not null" json:"rate_per_minute"`
+	ConnectionFee float64   `gorm:"type:decimal(10,4);default:0" json:"connection_fee"`
+	MinDuration   int       `gorm:"default:0" json:"min_duration"`
+	Increment     int       `gorm:"default:1" json:"increment"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
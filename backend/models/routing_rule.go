@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+// RoutingRule decides where an inbound call to one of the tenant's numbers
+// goes. Rules are tried in ascending Priority and the first one whose
+// conditions all hold wins. Empty conditions always hold: a rule without a
+// PhoneNumberID applies to every number of the tenant, one without a
+// ScheduleID at any time. A rule with a HolidayCalendarID only matches on
+// the calendar's holidays. CallerPattern is a regular expression matched
+// against the caller number.
+type RoutingRule struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	TenantID          uint           `gorm:"not null;index" json:"tenant_id"`
+	Name              string         `gorm:"not null" json:"name"`
+	Priority          int            `gorm:"not null;default:0" json:"priority"`
+	PhoneNumberID     *uint          `gorm:"index" json:"phone_number_id"`
+	CallerPattern     string         `json:"caller_pattern"`
+	ScheduleID        *uint          `gorm:"index" json:"schedule_id"`
+	HolidayCalendarID *uint          `gorm:"index" json:"holiday_calendar_id"`
+	TargetType        string         `gorm:"not null;size:20" json:"target_type"`
+	TargetValue       string         `gorm:"not null" json:"target_value"`
+	IsActive          bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	PhoneNumber     *PhoneNumber     `gorm:"foreignKey:PhoneNumberID" json:"phone_number,omitempty"`
+	Schedule        *RoutingSchedule `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	HolidayCalendar *HolidayCalendar `gorm:"foreignKey:HolidayCalendarID" json:"holiday_calendar,omitempty"`
+}
+
+// RoutingSchedule is a weekly set of time windows in Timezone, such as
+// business hours.
+type RoutingSchedule struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TenantID  uint           `gorm:"not null;index" json:"tenant_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Timezone  string         `gorm:"not null;default:UTC" json:"timezone"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Windows []RoutingScheduleWindow `gorm:"foreignKey:ScheduleID" json:"windows"`
+}
+
+// RoutingScheduleWindow is the span from StartTime up to, but excluding,
+// EndTime ("HH:MM", EndTime may be "24:00") on Weekday, where 0 is Sunday.
+type RoutingScheduleWindow struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	ScheduleID uint   `gorm:"not null;index" json:"schedule_id"`
+	Weekday    int    `gorm:"not null" json:"weekday"`
+	StartTime  string `gorm:"not null;size:5" json:"start_time"`
+	EndTime    string `gorm:"not null;size:5" json:"end_time"`
+}
+
+// HolidayCalendar is a named list of dates, read in Timezone.
+type HolidayCalendar struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TenantID  uint           `gorm:"not null;index" json:"tenant_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Timezone  string         `gorm:"not null;default:UTC" json:"timezone"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Holidays []Holiday `gorm:"foreignKey:CalendarID" json:"holidays"`
+}
+
+// Holiday is a full day, as "YYYY-MM-DD", in a HolidayCalendar.
+type Holiday struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	CalendarID uint   `gorm:"not null;index" json:"calendar_id"`
+	Date       string `gorm:"not null;size:10" json:"date"`
+	Name       string `json:"name"`
+}
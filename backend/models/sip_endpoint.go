@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"gorm.io/gorm"
+)
+
+// SipEndpoint is a SIP account registered under the tenant's domain.
+// Password is the SIP digest password; the switch needs it in clear to
+// authenticate registrations, so it is never serialized. Codecs is a
+// comma-separated preference list. MaxConcurrentCalls of 0 leaves the
+// endpoint limited only by the plan.
+type SipEndpoint struct {
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	TenantID           uint           `gorm:"not null;index" json:"tenant_id"`
+	UserID             *uint          `gorm:"index" json:"user_id"`
+	Extension          string         `gorm:"not null;size:20" json:"extension"`
+	Password           string         `gorm:"not null" json:"-"`
+	CallerIDName       string         `json:"caller_id_name"`
+	CallerIDNumber     string         `json:"caller_id_number"`
+	Codecs             string         `json:"codecs"`
+	MaxConcurrentCalls int            `gorm:"default:0" json:"max_concurrent_calls"`
+	IsActive           bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"tenant,omitempty"`
+	User   *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// CodecList returns the comma-separated Codecs as a slice.
+func (e *SipEndpoint) CodecList() []string {
+	var codecs []string
+	for _, codec := range strings.Split(e.Codecs, ",") {
+		if codec = strings.TrimSpace(codec); codec != "" {
+			codecs = append(codecs, codec)
+		}
+	}
+	return codecs
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+// SwitchCredential authenticates a telephony switch (FreeSWITCH, Asterisk)
+// posting to the machine-facing endpoints on behalf of a tenant.
+type SwitchCredential struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	TenantID    uint           `gorm:"not null;index" json:"tenant_id"`
+	Username    string         `gorm:"not null;unique" json:"username"`
+	SecretHash  string         `gorm:"not null" json:"-"`
+	Description string         `json:"description"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	LastUsedAt  *time.Time     `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"tenant,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+const (
+	TranscriptStatusPending    = "pending"
+	TranscriptStatusProcessing = "processing"
+	TranscriptStatusCompleted  = "completed"
+	TranscriptStatusFailed     = "failed"
+)
+
+// Transcript is both the transcription job for a recorded call and its
+// result. Text holds the concatenated segments for full-text search.
+type Transcript struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	TenantID    uint           `gorm:"not null;index" json:"tenant_id"`
+	CallID      uint           `gorm:"not null;uniqueIndex" json:"call_id"`
+	Status      string         `gorm:"not null;default:pending;index" json:"status"`
+	Provider    string         `json:"provider"`
+	Language    string         `json:"language"`
+	Text        string         `gorm:"type:text" json:"text"`
+	Error       string         `json:"error,omitempty"`
+	Attempts    int            `gorm:"default:0" json:"attempts"`
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	Call     Call                `gorm:"foreignKey:CallID" json:"-"`
+	Segments []TranscriptSegment `gorm:"foreignKey:TranscriptID" json:"segments,omitempty"`
+}
+
+type TranscriptSegment struct {
+	ID           uint    `gorm:"primaryKey" json:"id"`
+	TranscriptID uint    `gorm:"not null;index" json:"transcript_id"`
+	Position     int     `gorm:"not null" json:"position"`
+	Start        float64 `gorm:"not null" json:"start"`
+	End          float64 `gorm:"not null" json:"end"`
+	Speaker      string  `json:"speaker"`
+	Text         string  `gorm:"type:text;not null" json:"text"`
+}
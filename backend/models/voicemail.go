@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+	"gorm.io/gorm"
+)
+
+// VoicemailBox is a mailbox answered at Extension, optionally owned by a
+// tenant user. PIN is the numeric code for listening from a phone; the
+// switch checks it itself, so it is stored in clear and never serialized.
+// GreetingKey is the storage key of a custom greeting, empty for the
+// switch default. When EmailOnMessage is set a notification is sent to
+// Email for every new message.
+type VoicemailBox struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	TenantID       uint           `gorm:"not null;index" json:"tenant_id"`
+	UserID         *uint          `gorm:"index" json:"user_id"`
+	Extension      string         `gorm:"not null;size:20" json:"extension"`
+	PIN            string         `gorm:"not null;size:10" json:"-"`
+	GreetingKey    string         `json:"greeting_key,omitempty"`
+	EmailOnMessage bool           `gorm:"default:false" json:"email_on_message"`
+	Email          string         `json:"email"`
+	IsActive       bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// VoicemailMessage is a message left in a box. The audio lives in the
+// recording storage under StorageKey. HeardAt is nil until the message is
+// marked heard.
+type VoicemailMessage struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	TenantID        uint       `gorm:"not null;index" json:"tenant_id"`
+	BoxID           uint       `gorm:"not null;index" json:"box_id"`
+	CallUUID        string     `gorm:"size:64" json:"call_uuid,omitempty"`
+	CallerIDNumber  string     `json:"caller_id_number"`
+	CallerIDName    string     `json:"caller_id_name"`
+	DurationSeconds int        `gorm:"default:0" json:"duration_seconds"`
+	StorageKey      string     `gorm:"not null" json:"-"`
+	Size            int64      `json:"size"`
+	HeardAt         *time.Time `json:"heard_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
@@ -0,0 +1,251 @@
+package phonenumber
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	TypeMobile      = "mobile"
+	TypeFixedLine   = "fixed_line"
+	TypeTollFree    = "toll_free"
+	TypePremiumRate = "premium_rate"
+	TypeSharedCost  = "shared_cost"
+	TypeVoIP        = "voip"
+	TypePersonal    = "personal_number"
+)
+
+// Number is a parsed phone number. Type is empty when the numbering plan
+// does not tell number types apart for the range.
+type Number struct {
+	E164    string `json:"e164"`
+	Country string `json:"country"`
+	Type    string `json:"type"`
+}
+
+//go:embed data/countries.csv
+var countriesCSV string
+
+//go:embed data/ranges.csv
+var rangesCSV string
+
+type country struct {
+	Code                string
+	CallingCode         string
+	TrunkPrefix         string
+	InternationalPrefix string
+	MinLength           int
+	MaxLength           int
+}
+
+// numberRange assigns a type, and optionally a country, to national numbers
+// starting with Prefix. An "x" in the prefix matches any digit.
+type numberRange struct {
+	Prefix  string
+	Country string
+	Type    string
+}
+
+type numberingPlan struct {
+	countries    map[string]*country
+	callingCodes map[string]*country
+	ranges       map[string][]numberRange
+}
+
+var (
+	plan     *numberingPlan
+	planOnce sync.Once
+)
+
+func loadPlan() *numberingPlan {
+	planOnce.Do(func() {
+		p := &numberingPlan{
+			countries:    map[string]*country{},
+			callingCodes: map[string]*country{},
+			ranges:       map[string][]numberRange{},
+		}
+
+		for _, record := range readTable(countriesCSV) {
+			minLength, errMin := strconv.Atoi(record[4])
+			maxLength, errMax := strconv.Atoi(record[5])
+			if errMin != nil || errMax != nil {
+				panic("phonenumber: invalid length in countries table for " + record[0])
+			}
+			c := &country{
+				Code:                record[0],
+				CallingCode:         record[1],
+				TrunkPrefix:         record[2],
+				InternationalPrefix: record[3],
+				MinLength:           minLength,
+				MaxLength:           maxLength,
+			}
+			p.countries[c.Code] = c
+			// The first country listed for a shared calling code is the
+			// default when no range says otherwise.
+			if _, ok := p.callingCodes[c.CallingCode]; !ok {
+				p.callingCodes[c.CallingCode] = c
+			}
+		}
+
+		for _, record := range readTable(rangesCSV) {
+			p.ranges[record[0]] = append(p.ranges[record[0]], numberRange{
+				Prefix:  record[1],
+				Country: record[2],
+				Type:    record[3],
+			})
+		}
+
+		plan = p
+	})
+	return plan
+}
+
+func readTable(data string) [][]string {
+	reader := csv.NewReader(strings.NewReader(data))
+	records, err := reader.ReadAll()
+	if err != nil {
+		panic("phonenumber: invalid embedded table: " + err.Error())
+	}
+	return records[1:]
+}
+
+// IsKnownCountry reports whether the ISO 3166 alpha-2 code is in the
+// numbering plan.
+func IsKnownCountry(code string) bool {
+	_, ok := loadPlan().countries[strings.ToUpper(code)]
+	return ok
+}
+
+// Normalize converts a dialled or presented number to E.164. Numbers in
+// national format are resolved against defaultCountry. It returns false for
+// values that are not phone numbers, such as extensions or SIP user names.
+func Normalize(raw, defaultCountry string) (Number, bool) {
+	p := loadPlan()
+
+	digits, international, ok := cleanNumber(raw)
+	if !ok {
+		return Number{}, false
+	}
+
+	home := p.countries[strings.ToUpper(defaultCountry)]
+
+	if !international && home != nil && home.InternationalPrefix != "" &&
+		strings.HasPrefix(digits, home.InternationalPrefix) {
+		digits = strings.TrimPrefix(digits, home.InternationalPrefix)
+		international = true
+	}
+	if !international && home == nil && strings.HasPrefix(digits, "00") {
+		digits = strings.TrimPrefix(digits, "00")
+		international = true
+	}
+
+	if international {
+		return p.parseInternational(digits)
+	}
+
+	if home != nil {
+		if home.TrunkPrefix != "" && strings.HasPrefix(digits, home.TrunkPrefix) {
+			national := strings.TrimPrefix(digits, home.TrunkPrefix)
+			if validLength(home, national) {
+				return p.build(home, national), true
+			}
+		}
+		if validLength(home, digits) {
+			return p.build(home, digits), true
+		}
+	}
+
+	// Too long or short to be national: the sender most likely dropped the
+	// "+" from an international number.
+	return p.parseInternational(digits)
+}
+
+// cleanNumber strips visual separators and a leading "+". It fails on
+// anything else that is not a digit.
+func cleanNumber(raw string) (string, bool, bool) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+	raw = strings.TrimPrefix(raw, "+")
+
+	var b strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.' || r == '/':
+		default:
+			return "", false, false
+		}
+	}
+
+	digits := b.String()
+	if len(digits) < 4 || len(digits) > 17 {
+		return "", false, false
+	}
+
+	return digits, international, true
+}
+
+func (p *numberingPlan) parseInternational(digits string) (Number, bool) {
+	// Calling codes are prefix-free, so the first match is the only one.
+	for i := 1; i <= 3 && i < len(digits); i++ {
+		c, ok := p.callingCodes[digits[:i]]
+		if !ok {
+			continue
+		}
+		national := digits[i:]
+		if !validLength(c, national) {
+			return Number{}, false
+		}
+		return p.build(c, national), true
+	}
+
+	return Number{}, false
+}
+
+func (p *numberingPlan) build(c *country, national string) Number {
+	// Countries sharing a calling code (NANP, +7) are told apart by range,
+	// so start from the code's default country rather than the caller's.
+	number := Number{
+		E164:    "+" + c.CallingCode + national,
+		Country: p.callingCodes[c.CallingCode].Code,
+	}
+
+	// The longest matching range wins; country and type may come from
+	// different rows, e.g. a Canadian area code and no type.
+	countryLength, typeLength := -1, -1
+	for _, r := range p.ranges[c.CallingCode] {
+		if !matchPrefix(national, r.Prefix) {
+			continue
+		}
+		if r.Country != "" && len(r.Prefix) > countryLength {
+			number.Country = r.Country
+			countryLength = len(r.Prefix)
+		}
+		if r.Type != "" && len(r.Prefix) > typeLength {
+			number.Type = r.Type
+			typeLength = len(r.Prefix)
+		}
+	}
+
+	return number
+}
+
+func matchPrefix(national, prefix string) bool {
+	if len(national) < len(prefix) {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		if prefix[i] != 'x' && prefix[i] != national[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func validLength(c *country, national string) bool {
+	return len(national) >= c.MinLength && len(national) <= c.MaxLength
+}
@@ -0,0 +1,76 @@
+package phonenumber
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		country string
+		want    Number
+		ok      bool
+	}{
+		{"e164", "+14155550100", "", Number{"+14155550100", "US", ""}, true},
+		{"separators", "+1 (415) 555-0100", "", Number{"+14155550100", "US", ""}, true},
+		{"nanp area code picks the country", "+14165550100", "US", Number{"+14165550100", "CA", ""}, true},
+		{"nanp toll free", "+18005550100", "", Number{"+18005550100", "US", TypeTollFree}, true},
+		{"national with trunk prefix", "07700 900123", "GB", Number{"+447700900123", "GB", TypeMobile}, true},
+		{"national without trunk prefix", "4155550100", "US", Number{"+14155550100", "US", ""}, true},
+		{"national with long distance prefix", "1 415 555 0100", "us", Number{"+14155550100", "US", ""}, true},
+		{"international prefix of the home country", "011 44 20 7946 0958", "US", Number{"+442079460958", "GB", TypeFixedLine}, true},
+		{"00 without a home country", "00351912345678", "", Number{"+351912345678", "PT", TypeMobile}, true},
+		{"wildcard range", "11987654321", "BR", Number{"+5511987654321", "BR", TypeMobile}, true},
+		{"longest range wins", "+447012345678", "", Number{"+447012345678", "GB", TypePersonal}, true},
+		{"missing plus", "442079460958", "", Number{"+442079460958", "GB", TypeFixedLine}, true},
+		{"missing plus with home country", "442079460958", "US", Number{"+442079460958", "GB", TypeFixedLine}, true},
+		{"extension", "1001", "US", Number{}, false},
+		{"sip user", "alice", "US", Number{}, false},
+		{"letters in number", "+1415555010a", "", Number{}, false},
+		{"too long", "+1234567890123456789", "", Number{}, false},
+		{"wrong length for country", "+4412345", "", Number{}, false},
+		{"unknown calling code", "+9991234567", "", Number{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Normalize(tt.raw, tt.country)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %+v, %v, want %+v, %v", tt.raw, tt.country, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIsKnownCountry(t *testing.T) {
+	tests := map[string]bool{
+		"US":  true,
+		"gb":  true,
+		"BR":  true,
+		"":    false,
+		"XX":  false,
+		"USA": false,
+	}
+	for code, want := range tests {
+		if got := IsKnownCountry(code); got != want {
+			t.Errorf("IsKnownCountry(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	tests := []struct {
+		national, prefix string
+		want             bool
+	}{
+		{"11987654321", "xx9", true},
+		{"11387654321", "xx9", false},
+		{"800123", "800", true},
+		{"80", "800", false},
+		{"123", "", true},
+	}
+	for _, tt := range tests {
+		if got := matchPrefix(tt.national, tt.prefix); got != tt.want {
+			t.Errorf("matchPrefix(%q, %q) = %v, want %v", tt.national, tt.prefix, got, tt.want)
+		}
+	}
+}
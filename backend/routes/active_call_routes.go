@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupActiveCallRoutes(app *fiber.App, controller *controllers.ActiveCallController) {
+	api := app.Group("/api/v1")
+
+	api.Get("/calls/active",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+		middleware.RequirePermission("call.read"),
+		controller.GetActiveCalls)
+
+	// Only an admin can hang up a live call
+	api.Post("/admin/calls/active/:uuid/hangup",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("admin.call.hangup"),
+		controller.Hangup)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+	"gorm.io/gorm"
+)
+
+// SetupAdmissionRoutes registers the call admission endpoints the switches
+// query before starting a call and notify when it ends. Like CDR ingestion
+// they authenticate with a SwitchCredential.
+func SetupAdmissionRoutes(app *fiber.App, controller *controllers.AdmissionController, db *gorm.DB) {
+	api := app.Group("/api/v1")
+
+	admission := api.Group("/switch",
+		middleware.SwitchAuthMiddleware(db),
+	)
+
+	admission.Post("/authorize", controller.Authorize)
+	admission.Post("/release", controller.Release)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupAnalyticsRoutes(app *fiber.App, controller *controllers.AnalyticsController) {
+	api := app.Group("/api/v1")
+
+	analytics := api.Group("/analytics",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	analytics.Get("/calls",
+		middleware.RequirePermission("analytics.read"),
+		controller.GetCallStats)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupCallImportRoutes(app *fiber.App, controller *controllers.CallImportController) {
+	api := app.Group("/api/v1")
+
+	calls := api.Group("/calls",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	calls.Post("/import",
+		middleware.RequirePermission("call.import"),
+		controller.ImportCalls)
+}
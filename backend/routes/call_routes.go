@@ -4,9 +4,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/your-module/backend/controllers"
 	"github.com/your-module/backend/middleware"
+	"gorm.io/gorm"
 )
 
-func SetupCallRoutes(app *fiber.App, controller *controllers.CallController) {
+func SetupCallRoutes(app *fiber.App, controller *controllers.CallController, db *gorm.DB) {
 	api := app.Group("/api/v1")
 
 	calls := api.Group("/calls", 
@@ -16,6 +17,7 @@ func SetupCallRoutes(app *fiber.App, controller *controllers.CallController) {
 
 	calls.Post("/", 
 		middleware.RequirePermission("call.create"),
+		middleware.CheckCallQuota(db),
 		controller.CreateCall,
 	)
 	calls.Get("/", 
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupCallerListRoutes(app *fiber.App, controller *controllers.CallerListController) {
+	api := app.Group("/api/v1")
+
+	lists := api.Group("/caller-lists",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	lists.Post("/",
+		middleware.RequirePermission("caller_list.create"),
+		controller.CreateEntry)
+
+	lists.Get("/",
+		middleware.RequirePermission("caller_list.read"),
+		controller.GetEntries)
+
+	lists.Post("/import",
+		middleware.RequirePermission("caller_list.create"),
+		controller.ImportEntries)
+
+	lists.Get("/:id",
+		middleware.RequirePermission("caller_list.read"),
+		controller.GetEntry)
+
+	lists.Put("/:id",
+		middleware.RequirePermission("caller_list.update"),
+		controller.UpdateEntry)
+
+	lists.Delete("/:id",
+		middleware.RequirePermission("caller_list.delete"),
+		controller.DeleteEntry)
+}
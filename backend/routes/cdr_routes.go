@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+	"gorm.io/gorm"
+)
+
+// SetupCdrRoutes registers the machine-facing CDR ingestion endpoints. They
+// are called by the switches themselves, so they authenticate with a
+// SwitchCredential instead of a user token.
+func SetupCdrRoutes(app *fiber.App, controller *controllers.CdrController, db *gorm.DB) {
+	api := app.Group("/api/v1")
+
+	cdr := api.Group("/cdr",
+		middleware.SwitchAuthMiddleware(db),
+	)
+
+	cdr.Post("/freeswitch", controller.IngestFreeSwitchCDR)
+	cdr.Post("/asterisk/cdr", controller.IngestAsteriskCDR)
+	cdr.Post("/asterisk/cel", controller.IngestAsteriskCEL)
+}
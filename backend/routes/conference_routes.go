@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupConferenceRoutes(app *fiber.App, controller *controllers.ConferenceController) {
+	api := app.Group("/api/v1")
+
+	conferences := api.Group("/conferences",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	conferences.Post("/",
+		middleware.RequirePermission("conference.create"),
+		controller.CreateRoom)
+
+	conferences.Get("/",
+		middleware.RequirePermission("conference.read"),
+		controller.GetRooms)
+
+	conferences.Get("/:id",
+		middleware.RequirePermission("conference.read"),
+		controller.GetRoom)
+
+	conferences.Put("/:id",
+		middleware.RequirePermission("conference.update"),
+		controller.UpdateRoom)
+
+	conferences.Delete("/:id",
+		middleware.RequirePermission("conference.delete"),
+		controller.DeleteRoom)
+
+	conferences.Get("/:id/participants",
+		middleware.RequirePermission("conference.read"),
+		controller.GetParticipants)
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupFraudRoutes(app *fiber.App, controller *controllers.FraudController) {
+	api := app.Group("/api/v1")
+
+	fraud := api.Group("/fraud",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	fraud.Post("/rules",
+		middleware.RequirePermission("fraud.rule.create"),
+		controller.CreateRule)
+
+	fraud.Get("/rules",
+		middleware.RequirePermission("fraud.rule.read"),
+		controller.GetRules)
+
+	fraud.Put("/rules/:id",
+		middleware.RequirePermission("fraud.rule.update"),
+		controller.UpdateRule)
+
+	fraud.Delete("/rules/:id",
+		middleware.RequirePermission("fraud.rule.delete"),
+		controller.DeleteRule)
+
+	fraud.Get("/alerts",
+		middleware.RequirePermission("fraud.alert.read"),
+		controller.GetAlerts)
+
+	fraud.Post("/alerts/:id/resolve",
+		middleware.RequirePermission("fraud.alert.resolve"),
+		controller.ResolveAlert)
+
+	// Only an admin can lift a fraud block
+	api.Delete("/admin/tenants/:id/fraud-block",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("admin.tenant.fraud.unblock"),
+		controller.UnblockTenant)
+}
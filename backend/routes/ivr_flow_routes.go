@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupIvrFlowRoutes(app *fiber.App, controller *controllers.IvrFlowController) {
+	api := app.Group("/api/v1")
+
+	flows := api.Group("/ivr-flows",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	flows.Post("/",
+		middleware.RequirePermission("ivr.create"),
+		controller.CreateFlow)
+
+	flows.Get("/",
+		middleware.RequirePermission("ivr.read"),
+		controller.GetFlows)
+
+	flows.Get("/:id",
+		middleware.RequirePermission("ivr.read"),
+		controller.GetFlow)
+
+	flows.Put("/:id",
+		middleware.RequirePermission("ivr.update"),
+		controller.UpdateFlow)
+
+	flows.Delete("/:id",
+		middleware.RequirePermission("ivr.delete"),
+		controller.DeleteFlow)
+
+	flows.Get("/:id/validate",
+		middleware.RequirePermission("ivr.read"),
+		controller.ValidateFlow)
+
+	flows.Post("/:id/publish",
+		middleware.RequirePermission("ivr.publish"),
+		controller.PublishFlow)
+
+	flows.Get("/:id/versions",
+		middleware.RequirePermission("ivr.read"),
+		controller.GetVersions)
+
+	flows.Get("/:id/versions/:version",
+		middleware.RequirePermission("ivr.read"),
+		controller.GetVersion)
+
+	flows.Post("/:id/simulate",
+		middleware.RequirePermission("ivr.read"),
+		controller.Simulate)
+}
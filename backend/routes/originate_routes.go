@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupOriginateRoutes(app *fiber.App, controller *controllers.OriginateController) {
+	api := app.Group("/api/v1")
+
+	api.Post("/calls/originate",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+		middleware.RequirePermission("call.originate"),
+		controller.Originate)
+
+	api.Get("/calls/originate/:job_uuid",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+		middleware.RequirePermission("call.originate"),
+		controller.GetJob)
+}
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupPhoneNumberRoutes(app *fiber.App, controller *controllers.PhoneNumberController) {
+	api := app.Group("/api/v1")
+
+	// Admin inventory management
+	admin := api.Group("/admin/numbers", middleware.AuthMiddleware())
+
+	admin.Post("/",
+		middleware.RequirePermission("admin.number.create"),
+		controller.CreateNumber)
+
+	admin.Post("/import",
+		middleware.RequirePermission("admin.number.create"),
+		controller.ImportNumbers)
+
+	admin.Get("/",
+		middleware.RequirePermission("admin.number.read"),
+		controller.GetNumbers)
+
+	admin.Get("/:id",
+		middleware.RequirePermission("admin.number.read"),
+		controller.GetNumber)
+
+	admin.Put("/:id",
+		middleware.RequirePermission("admin.number.update"),
+		controller.UpdateNumber)
+
+	admin.Delete("/:id",
+		middleware.RequirePermission("admin.number.delete"),
+		controller.DeleteNumber)
+
+	admin.Post("/:id/assign",
+		middleware.RequirePermission("admin.number.assign"),
+		controller.AssignNumber)
+
+	admin.Post("/:id/release",
+		middleware.RequirePermission("admin.number.assign"),
+		controller.ReleaseNumber)
+
+	// Tenant view of its own numbers
+	numbers := api.Group("/numbers",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	numbers.Get("/",
+		middleware.RequirePermission("number.read"),
+		controller.GetTenantNumbers)
+
+	numbers.Get("/:id",
+		middleware.RequirePermission("number.read"),
+		controller.GetTenantNumber)
+
+	numbers.Put("/:id/routing",
+		middleware.RequirePermission("number.update"),
+		controller.SetRouting)
+
+	numbers.Post("/:id/release",
+		middleware.RequirePermission("number.release"),
+		controller.ReleaseTenantNumber)
+}
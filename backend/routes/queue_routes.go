@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+	"gorm.io/gorm"
+)
+
+func SetupQueueRoutes(app *fiber.App, controller *controllers.QueueController, db *gorm.DB) {
+	api := app.Group("/api/v1")
+
+	queues := api.Group("/queues",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	// Agents change their own state in all their queues
+	queues.Put("/agents/me/state",
+		middleware.RequirePermission("queue.agent"),
+		controller.SetMyState)
+
+	queues.Post("/",
+		middleware.RequirePermission("queue.create"),
+		controller.CreateQueue)
+
+	queues.Get("/",
+		middleware.RequirePermission("queue.read"),
+		controller.GetQueues)
+
+	queues.Get("/:id",
+		middleware.RequirePermission("queue.read"),
+		controller.GetQueue)
+
+	queues.Put("/:id",
+		middleware.RequirePermission("queue.update"),
+		controller.UpdateQueue)
+
+	queues.Delete("/:id",
+		middleware.RequirePermission("queue.delete"),
+		controller.DeleteQueue)
+
+	queues.Get("/:id/stats",
+		middleware.RequirePermission("queue.read"),
+		controller.GetQueueStats)
+
+	queues.Get("/:id/agents",
+		middleware.RequirePermission("queue.read"),
+		controller.GetAgents)
+
+	queues.Post("/:id/agents",
+		middleware.RequirePermission("queue.update"),
+		controller.AddAgent)
+
+	queues.Delete("/:id/agents/:agent_id",
+		middleware.RequirePermission("queue.update"),
+		controller.RemoveAgent)
+
+	queues.Put("/:id/agents/:agent_id/state",
+		middleware.RequirePermission("queue.update"),
+		controller.SetAgentState)
+
+	// Queue events reported by the switches themselves
+	api.Post("/switch/call-events",
+		middleware.SwitchAuthMiddleware(db),
+		controller.IngestCallEvent)
+}
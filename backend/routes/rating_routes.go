@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupRatingRoutes(app *fiber.App, controller *controllers.RatingController) {
+	api := app.Group("/api/v1")
+
+	// Tenant rate decks
+	rateDecks := api.Group("/rate-decks",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	rateDecks.Post("/",
+		middleware.RequirePermission("ratedeck.create"),
+		controller.UploadRateDeck)
+
+	rateDecks.Get("/",
+		middleware.RequirePermission("ratedeck.read"),
+		controller.GetRateDecks)
+
+	rateDecks.Get("/:id",
+		middleware.RequirePermission("ratedeck.read"),
+		controller.GetRateDeckByID)
+
+	rateDecks.Delete("/:id",
+		middleware.RequirePermission("ratedeck.delete"),
+		controller.DeleteRateDeck)
+
+	// Admin global rate decks
+	adminRateDecks := api.Group("/admin/rate-decks",
+		middleware.AuthMiddleware(),
+	)
+
+	adminRateDecks.Post("/",
+		middleware.RequirePermission("admin.ratedeck.create"),
+		controller.UploadGlobalRateDeck)
+
+	adminRateDecks.Delete("/:id",
+		middleware.RequirePermission("admin.ratedeck.delete"),
+		controller.DeleteGlobalRateDeck)
+
+	// Re-rating of past calls
+	calls := api.Group("/calls",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	calls.Post("/rerate",
+		middleware.RequirePermission("call.rerate"),
+		controller.RerateCalls)
+}
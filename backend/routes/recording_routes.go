@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupRecordingRoutes(app *fiber.App, controller *controllers.RecordingController) {
+	api := app.Group("/api/v1")
+
+	calls := api.Group("/calls",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	calls.Get("/:id/recording",
+		middleware.RequirePermission("call.recording.read"),
+		controller.GetRecording)
+
+	calls.Post("/:id/recording",
+		middleware.RequirePermission("call.recording.upload"),
+		controller.UploadRecording)
+
+	calls.Delete("/:id/recording",
+		middleware.RequirePermission("call.recording.delete"),
+		controller.DeleteRecording)
+
+	// Signed download links carry their own authorization
+	api.Get("/recordings/download", controller.DownloadRecording)
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupRetentionRoutes(app *fiber.App, controller *controllers.RetentionController) {
+	api := app.Group("/api/v1")
+
+	// Admin retention settings
+	api.Put("/admin/plans/:id/retention",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("admin.plan.update"),
+		controller.SetPlanRetention)
+
+	api.Put("/admin/tenants/:id/retention",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("admin.tenant.update"),
+		controller.SetTenantRetention)
+
+	api.Post("/admin/retention/run",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("admin.retention.run"),
+		controller.RunPurge)
+
+	// Tenant view of its own policy and purge history
+	retention := api.Group("/retention",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	retention.Get("/",
+		middleware.RequirePermission("retention.read"),
+		controller.GetRetentionPolicy)
+
+	retention.Get("/purge-logs",
+		middleware.RequirePermission("retention.read"),
+		controller.GetPurgeLogs)
+}
@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupRoutingRoutes(app *fiber.App, controller *controllers.RoutingController, scheduleController *controllers.RoutingScheduleController) {
+	api := app.Group("/api/v1")
+
+	routing := api.Group("/routing",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	// Rules
+	routing.Post("/rules",
+		middleware.RequirePermission("routing.create"),
+		controller.CreateRule)
+
+	routing.Get("/rules",
+		middleware.RequirePermission("routing.read"),
+		controller.GetRules)
+
+	routing.Get("/rules/:id",
+		middleware.RequirePermission("routing.read"),
+		controller.GetRule)
+
+	routing.Put("/rules/:id",
+		middleware.RequirePermission("routing.update"),
+		controller.UpdateRule)
+
+	routing.Delete("/rules/:id",
+		middleware.RequirePermission("routing.delete"),
+		controller.DeleteRule)
+
+	// Dry run of the rules for a DID and caller
+	routing.Post("/evaluate",
+		middleware.RequirePermission("routing.read"),
+		controller.Evaluate)
+
+	// Schedules
+	routing.Post("/schedules",
+		middleware.RequirePermission("routing.create"),
+		scheduleController.CreateSchedule)
+
+	routing.Get("/schedules",
+		middleware.RequirePermission("routing.read"),
+		scheduleController.GetSchedules)
+
+	routing.Get("/schedules/:id",
+		middleware.RequirePermission("routing.read"),
+		scheduleController.GetSchedule)
+
+	routing.Put("/schedules/:id",
+		middleware.RequirePermission("routing.update"),
+		scheduleController.UpdateSchedule)
+
+	routing.Delete("/schedules/:id",
+		middleware.RequirePermission("routing.delete"),
+		scheduleController.DeleteSchedule)
+
+	// Holiday calendars
+	routing.Post("/holiday-calendars",
+		middleware.RequirePermission("routing.create"),
+		scheduleController.CreateCalendar)
+
+	routing.Get("/holiday-calendars",
+		middleware.RequirePermission("routing.read"),
+		scheduleController.GetCalendars)
+
+	routing.Get("/holiday-calendars/:id",
+		middleware.RequirePermission("routing.read"),
+		scheduleController.GetCalendar)
+
+	routing.Put("/holiday-calendars/:id",
+		middleware.RequirePermission("routing.update"),
+		scheduleController.UpdateCalendar)
+
+	routing.Delete("/holiday-calendars/:id",
+		middleware.RequirePermission("routing.delete"),
+		scheduleController.DeleteCalendar)
+}
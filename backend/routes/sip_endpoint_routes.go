@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupSipEndpointRoutes(app *fiber.App, controller *controllers.SipEndpointController) {
+	api := app.Group("/api/v1")
+
+	endpoints := api.Group("/sip-endpoints",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	endpoints.Post("/",
+		middleware.RequirePermission("sip.endpoint.create"),
+		controller.CreateEndpoint)
+
+	endpoints.Get("/",
+		middleware.RequirePermission("sip.endpoint.read"),
+		controller.GetEndpoints)
+
+	endpoints.Get("/:id",
+		middleware.RequirePermission("sip.endpoint.read"),
+		controller.GetEndpoint)
+
+	endpoints.Put("/:id",
+		middleware.RequirePermission("sip.endpoint.update"),
+		controller.UpdateEndpoint)
+
+	endpoints.Post("/:id/reset-password",
+		middleware.RequirePermission("sip.endpoint.update"),
+		controller.ResetPassword)
+
+	endpoints.Delete("/:id",
+		middleware.RequirePermission("sip.endpoint.delete"),
+		controller.DeleteEndpoint)
+}
@@ -26,6 +26,10 @@ func SetupSubscriptionRoutes(app *fiber.App, controller *controllers.Subscriptio
 		middleware.RequirePermission("admin.plan.read"),
 		controller.GetPlanByID)
 
+	adminPlans.Put("/:id",
+		middleware.RequirePermission("admin.plan.update"),
+		controller.UpdatePlan)
+
 	adminPlans.Delete("/:id",
 		middleware.RequirePermission("admin.plan.delete"),
 		controller.DeletePlan)
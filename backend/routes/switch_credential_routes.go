@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupSwitchCredentialRoutes(app *fiber.App, controller *controllers.SwitchCredentialController) {
+	api := app.Group("/api/v1")
+
+	credentials := api.Group("/switch-credentials",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	credentials.Post("/",
+		middleware.RequirePermission("switch.credential.create"),
+		controller.CreateCredential)
+
+	credentials.Get("/",
+		middleware.RequirePermission("switch.credential.read"),
+		controller.GetCredentials)
+
+	credentials.Delete("/:id",
+		middleware.RequirePermission("switch.credential.delete"),
+		controller.DeleteCredential)
+}
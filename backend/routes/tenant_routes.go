@@ -28,4 +28,14 @@ func SetupTenantRoutes(app *fiber.App, controller *controllers.TenantController)
 	tenants.Delete("/:id", 
 		middleware.RequirePermission("admin.tenant.delete"),
 		controller.DeleteTenant)
-} 
\ No newline at end of file
+
+	// Tenant self-service settings
+	tenant := api.Group("/tenant",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	tenant.Put("/default-country",
+		middleware.RequirePermission("tenant.update"),
+		controller.SetDefaultCountry)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+func SetupTranscriptRoutes(app *fiber.App, controller *controllers.TranscriptController) {
+	api := app.Group("/api/v1")
+
+	api.Get("/calls/:id/transcript",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+		middleware.RequirePermission("call.transcript.read"),
+		controller.GetTranscript)
+
+	api.Get("/transcripts/search",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+		middleware.RequirePermission("call.transcript.read"),
+		controller.SearchTranscripts)
+}
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+	"gorm.io/gorm"
+)
+
+func SetupVoicemailRoutes(app *fiber.App, controller *controllers.VoicemailController, db *gorm.DB) {
+	api := app.Group("/api/v1")
+
+	boxes := api.Group("/voicemail/boxes",
+		middleware.AuthMiddleware(),
+		middleware.TenantMiddleware(),
+	)
+
+	boxes.Post("/",
+		middleware.RequirePermission("voicemail.create"),
+		controller.CreateBox)
+
+	boxes.Get("/",
+		middleware.RequirePermission("voicemail.read"),
+		controller.GetBoxes)
+
+	boxes.Get("/:id",
+		middleware.RequirePermission("voicemail.read"),
+		controller.GetBox)
+
+	boxes.Put("/:id",
+		middleware.RequirePermission("voicemail.update"),
+		controller.UpdateBox)
+
+	boxes.Delete("/:id",
+		middleware.RequirePermission("voicemail.delete"),
+		controller.DeleteBox)
+
+	boxes.Get("/:id/greeting",
+		middleware.RequirePermission("voicemail.read"),
+		controller.GetGreeting)
+
+	boxes.Put("/:id/greeting",
+		middleware.RequirePermission("voicemail.update"),
+		controller.UploadGreeting)
+
+	boxes.Delete("/:id/greeting",
+		middleware.RequirePermission("voicemail.update"),
+		controller.DeleteGreeting)
+
+	boxes.Get("/:id/messages",
+		middleware.RequirePermission("voicemail.read"),
+		controller.GetMessages)
+
+	boxes.Get("/:id/messages/:message_id/audio",
+		middleware.RequirePermission("voicemail.read"),
+		controller.DownloadMessage)
+
+	boxes.Put("/:id/messages/:message_id/heard",
+		middleware.RequirePermission("voicemail.read"),
+		controller.MarkHeard)
+
+	boxes.Delete("/:id/messages/:message_id",
+		middleware.RequirePermission("voicemail.delete"),
+		controller.DeleteMessage)
+
+	// Messages recorded by the switches themselves
+	api.Post("/switch/voicemail",
+		middleware.SwitchAuthMiddleware(db),
+		controller.DepositMessage)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-module/backend/controllers"
+	"github.com/your-module/backend/middleware"
+)
+
+// SetupXMLCurlRoutes registers the mod_xml_curl configuration endpoint used
+// by the FreeSWITCH switches for their directory and dialplan lookups.
+func SetupXMLCurlRoutes(app *fiber.App, controller *controllers.XMLCurlController, secret string, allowedIPs []string) {
+	api := app.Group("/api/v1")
+
+	api.Post("/freeswitch/xml-curl",
+		middleware.XMLCurlAuthMiddleware(secret, allowedIPs),
+		controller.Fetch)
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/esl"
+	"github.com/your-module/backend/models"
+)
+
+// ActiveCallEvents are the channel events ActiveCallService subscribes to,
+// plus the conference member events. The CUSTOM subclass has to come last.
+var ActiveCallEvents = []string{"CHANNEL_CREATE", "CHANNEL_ANSWER", "CHANNEL_HANGUP", "CUSTOM", ConferenceEventSubclass}
+
+// ActiveCallService keeps the set of live channels from switch events. The
+// channels are held in memory for this process and mirrored to the
+// active_channels table so other API instances, and this one after a
+// restart, see the same state. Channels are attributed to a tenant through
+// the tenant_id channel variable set by the directory, dialplan and
+// originate paths; channels without one are not tracked.
+type ActiveCallService struct {
+	DB *gorm.DB
+	// Dial opens a connection subscribed to ActiveCallEvents.
+	Dial func() (esl.Client, error)
+	// DialAPI opens a connection for api commands. Keeping commands off the
+	// event connection means a burst of events cannot hold up their replies.
+	DialAPI func() (esl.Client, error)
+
+	mu       sync.RWMutex
+	client   esl.Client
+	channels map[string]models.ActiveChannel
+}
+
+func NewActiveCallService(db *gorm.DB, dial, dialAPI func() (esl.Client, error)) *ActiveCallService {
+	return &ActiveCallService{
+		DB:       db,
+		Dial:     dial,
+		DialAPI:  dialAPI,
+		channels: map[string]models.ActiveChannel{},
+	}
+}
+
+// Run connects to the switch, reconciles state and then follows channel
+// events, reconnecting every interval after a failure, until stop is closed.
+func (s *ActiveCallService) Run(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for {
+		if err := s.session(stop); err != nil {
+			log.Printf("active calls: cannot connect to switch: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// session follows one pair of connections until either ends.
+func (s *ActiveCallService) session(stop <-chan struct{}) error {
+	events, err := s.Dial()
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+
+	api, err := s.DialAPI()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	// Reconcile after subscribing, so nothing that happens in between is
+	// missed; events for channels already reconciled are idempotent. The
+	// event connection queues what arrives meanwhile.
+	if err := s.Reconcile(api); err != nil {
+		log.Printf("active calls: reconcile failed: %v", err)
+	}
+
+	s.setClient(api)
+	defer s.setClient(nil)
+	s.consume(events, stop)
+	return nil
+}
+
+func (s *ActiveCallService) consume(client esl.Client, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-client.Events():
+			if !ok {
+				log.Printf("active calls: switch connection lost")
+				return
+			}
+			if err := s.HandleEvent(event); err != nil {
+				log.Printf("active calls: %s %s: %v", event.Name(), event.Get("Unique-ID"), err)
+			}
+		}
+	}
+}
+
+func (s *ActiveCallService) setClient(client esl.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+func (s *ActiveCallService) currentClient() esl.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// eventTime reads Event-Date-Timestamp, which is in microseconds.
+func eventTime(event esl.Event) time.Time {
+	micros, err := strconv.ParseInt(event.Get("Event-Date-Timestamp"), 10, 64)
+	if err != nil || micros <= 0 {
+		return time.Now()
+	}
+	return time.UnixMicro(micros)
+}
+
+func (s *ActiveCallService) HandleEvent(event esl.Event) error {
+	if event.Name() == "CUSTOM" {
+		if event.Get("Event-Subclass") == ConferenceEventSubclass {
+			return NewConferenceService(s.DB).HandleEvent(event)
+		}
+		return nil
+	}
+
+	channelUUID := event.Get("Unique-ID")
+	if channelUUID == "" {
+		return nil
+	}
+
+	if event.Name() == "CHANNEL_HANGUP" {
+		return s.removeChannel(channelUUID)
+	}
+
+	tenantID, err := strconv.ParseUint(event.Get("variable_tenant_id"), 10, 32)
+	if err != nil || tenantID == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	channel, exists := s.channels[channelUUID]
+	s.mu.RUnlock()
+
+	if !exists {
+		channel = models.ActiveChannel{
+			UUID:      channelUUID,
+			StartedAt: eventTime(event),
+			State:     models.ActiveChannelStateRinging,
+		}
+		if created, err := strconv.ParseInt(event.Get("Caller-Channel-Created-Time"), 10, 64); err == nil && created > 0 {
+			channel.StartedAt = time.UnixMicro(created)
+		}
+	}
+
+	channel.TenantID = uint(tenantID)
+	channel.Hostname = event.Get("FreeSWITCH-Hostname")
+	channel.Direction = event.Get("Call-Direction")
+	channel.Caller = event.Get("Caller-Caller-ID-Number")
+	channel.Callee = event.Get("Caller-Destination-Number")
+
+	if event.Name() == "CHANNEL_ANSWER" && channel.AnsweredAt == nil {
+		answeredAt := eventTime(event)
+		channel.State = models.ActiveChannelStateAnswered
+		channel.AnsweredAt = &answeredAt
+	}
+
+	return s.saveChannel(channel)
+}
+
+func (s *ActiveCallService) saveChannel(channel models.ActiveChannel) error {
+	if err := s.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"tenant_id", "hostname", "direction", "caller", "callee", "state", "answered_at", "updated_at",
+		}),
+	}).Create(&channel).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.channels[channel.UUID] = channel
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *ActiveCallService) removeChannel(channelUUID string) error {
+	s.mu.Lock()
+	delete(s.channels, channelUUID)
+	s.mu.Unlock()
+
+	if err := NewAdmissionService(s.DB).Release(channelUUID, nil); err != nil {
+		return err
+	}
+
+	// Covers a missed del-member event
+	if err := NewConferenceService(s.DB).removeParticipant(channelUUID); err != nil {
+		return err
+	}
+
+	return s.DB.Where("uuid = ?", channelUUID).Delete(&models.ActiveChannel{}).Error
+}
+
+// switchChannel is a row of "show channels as json".
+type switchChannel struct {
+	UUID         string `json:"uuid"`
+	Direction    string `json:"direction"`
+	CreatedEpoch string `json:"created_epoch"`
+	CallerNumber string `json:"cid_num"`
+	Destination  string `json:"dest"`
+	CallState    string `json:"callstate"`
+}
+
+// Reconcile replaces the tracked state for the switch with its live
+// channels and conference participants. It runs after every (re)connect,
+// since hangups that happened while disconnected were never seen.
+func (s *ActiveCallService) Reconcile(client esl.Client) error {
+	hostname, err := client.API("hostname")
+	if err != nil {
+		return err
+	}
+
+	output, err := client.API("show channels as json")
+	if err != nil {
+		return err
+	}
+
+	var listing struct {
+		Rows []switchChannel `json:"rows"`
+	}
+	if strings.HasPrefix(output, "{") {
+		if err := json.Unmarshal([]byte(output), &listing); err != nil {
+			return err
+		}
+	}
+
+	var tracked []models.ActiveChannel
+	if err := s.DB.Where("hostname = ?", hostname).Find(&tracked).Error; err != nil {
+		return err
+	}
+	known := map[string]models.ActiveChannel{}
+	for _, channel := range tracked {
+		known[channel.UUID] = channel
+	}
+
+	live := map[string]models.ActiveChannel{}
+	for _, row := range listing.Rows {
+		channel, ok := known[row.UUID]
+		if !ok {
+			value, err := client.API("uuid_getvar " + row.UUID + " tenant_id")
+			if err != nil {
+				continue
+			}
+			tenantID, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+			if err != nil || tenantID == 0 {
+				continue
+			}
+			channel = models.ActiveChannel{UUID: row.UUID, TenantID: uint(tenantID)}
+			if epoch, err := strconv.ParseInt(row.CreatedEpoch, 10, 64); err == nil {
+				channel.StartedAt = time.Unix(epoch, 0)
+			} else {
+				channel.StartedAt = time.Now()
+			}
+		}
+
+		channel.Hostname = hostname
+		channel.Direction = row.Direction
+		channel.Caller = row.CallerNumber
+		channel.Callee = row.Destination
+		channel.State = models.ActiveChannelStateRinging
+		if row.CallState == "ACTIVE" || row.CallState == "HELD" {
+			channel.State = models.ActiveChannelStateAnswered
+			if channel.AnsweredAt == nil {
+				now := time.Now()
+				channel.AnsweredAt = &now
+			}
+		}
+
+		if err := s.saveChannel(channel); err != nil {
+			return err
+		}
+		live[channel.UUID] = channel
+	}
+
+	// Channels that hung up while disconnected go through the same cleanup
+	// as a hangup event, releasing their admission and conference seat
+	var ended []string
+	for channelUUID := range known {
+		if _, ok := live[channelUUID]; !ok {
+			ended = append(ended, channelUUID)
+			if err := s.removeChannel(channelUUID); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.mu.Lock()
+	for channelUUID, channel := range s.channels {
+		if channel.Hostname == hostname {
+			if _, ok := live[channelUUID]; !ok {
+				delete(s.channels, channelUUID)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	log.Printf("active calls: reconciled %d live channels on %s, removed %d ended", len(live), hostname, len(ended))
+
+	return NewConferenceService(s.DB).Reconcile(client, hostname)
+}
+
+// GetActiveCalls lists the tenant's live channels. The table is read rather
+// than memory so channels tracked by other instances are included.
+func (s *ActiveCallService) GetActiveCalls(tenantID uint) ([]models.ActiveChannel, error) {
+	var channels []models.ActiveChannel
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("started_at").
+		Find(&channels).Error; err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// Hangup kills a live channel on the switch. The hangup event then removes
+// it from the tracked state.
+func (s *ActiveCallService) Hangup(channelUUID string) error {
+	if _, err := uuid.Parse(channelUUID); err != nil {
+		return errors.New("invalid channel uuid")
+	}
+
+	var channel models.ActiveChannel
+	if err := s.DB.Where("uuid = ?", channelUUID).First(&channel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("active call not found")
+		}
+		return err
+	}
+
+	client := s.currentClient()
+	if client == nil {
+		return errors.New("switch not connected")
+	}
+
+	if _, err := client.API("uuid_kill " + channelUUID); err != nil {
+		if strings.Contains(err.Error(), "No such channel") {
+			// Already gone; the hangup event was missed
+			return s.removeChannel(channelUUID)
+		}
+		return err
+	}
+
+	return nil
+}
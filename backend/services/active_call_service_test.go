@@ -0,0 +1,264 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/esl"
+	"github.com/your-module/backend/models"
+)
+
+// startActiveCalls runs an ActiveCallService against a fake switch and
+// returns once the first session has reconciled.
+func startActiveCalls(t *testing.T, db *gorm.DB, server *esl.FakeServer) *ActiveCallService {
+	t.Helper()
+
+	service := NewActiveCallService(db, func() (esl.Client, error) {
+		return esl.Dial(server.Addr(), "ClueCon", time.Second, ActiveCallEvents...)
+	}, func() (esl.Client, error) {
+		return esl.Dial(server.Addr(), "ClueCon", time.Second)
+	})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		service.Run(50*time.Millisecond, stop)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(stop)
+		<-done
+	})
+
+	waitFor(t, "the switch connection", func() bool { return service.currentClient() != nil })
+
+	return service
+}
+
+func newActiveCallSwitch(t *testing.T) *esl.FakeServer {
+	t.Helper()
+
+	server, err := esl.NewFakeServer("ClueCon")
+	if err != nil {
+		t.Fatalf("starting fake switch: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	server.SetAPIResult("hostname", "switch-a")
+	return server
+}
+
+func countCommand(server *esl.FakeServer, command string) int {
+	count := 0
+	for _, sent := range server.Commands() {
+		if sent == command {
+			count++
+		}
+	}
+	return count
+}
+
+func TestActiveCallsReconnect(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	server := newActiveCallSwitch(t)
+	startActiveCalls(t, db, server)
+
+	server.Disconnect()
+
+	// Every session starts with a reconcile, so a second hostname lookup
+	// means the service came back
+	waitFor(t, "the reconnect", func() bool { return countCommand(server, "api hostname") >= 2 })
+	waitFor(t, "the event subscription", func() bool {
+		return countCommand(server, "event plain "+strings.Join(ActiveCallEvents, " ")) >= 2
+	})
+
+	server.Emit(map[string]string{
+		"Event-Name":                "CHANNEL_CREATE",
+		"Unique-ID":                 "after-reconnect",
+		"FreeSWITCH-Hostname":       "switch-a",
+		"Call-Direction":            "inbound",
+		"Caller-Caller-ID-Number":   "+14155550100",
+		"Caller-Destination-Number": "1001",
+		"variable_tenant_id":        strconv.FormatUint(uint64(tenant.ID), 10),
+	}, "")
+
+	waitFor(t, "the channel event", func() bool {
+		var count int64
+		db.Model(&models.ActiveChannel{}).Where("uuid = ? AND tenant_id = ?", "after-reconnect", tenant.ID).Count(&count)
+		return count == 1
+	})
+}
+
+func TestActiveCallsReconcile(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	server := newActiveCallSwitch(t)
+
+	now := time.Now()
+	for _, channel := range []models.ActiveChannel{
+		{UUID: "still-up", TenantID: tenant.ID, Hostname: "switch-a", State: models.ActiveChannelStateRinging, StartedAt: now},
+		{UUID: "hung-up", TenantID: tenant.ID, Hostname: "switch-a", State: models.ActiveChannelStateAnswered, StartedAt: now},
+		{UUID: "other-switch", TenantID: tenant.ID, Hostname: "switch-b", State: models.ActiveChannelStateAnswered, StartedAt: now},
+	} {
+		if err := db.Create(&channel).Error; err != nil {
+			t.Fatalf("creating channel: %v", err)
+		}
+		if err := db.Create(&models.CallAdmission{TenantID: tenant.ID, CallUUID: channel.UUID, AdmittedAt: now}).Error; err != nil {
+			t.Fatalf("creating admission: %v", err)
+		}
+	}
+	if err := db.Create(&models.ConferenceParticipant{TenantID: tenant.ID, RoomID: 1, ChannelUUID: "hung-up", JoinedAt: now}).Error; err != nil {
+		t.Fatalf("creating participant: %v", err)
+	}
+
+	server.SetAPIResult("show channels as json", `{"row_count":3,"rows":[`+
+		`{"uuid":"still-up","direction":"inbound","created_epoch":"1700000000","cid_num":"+14155550100","dest":"1001","callstate":"ACTIVE"},`+
+		`{"uuid":"new-call","direction":"outbound","created_epoch":"1700000000","cid_num":"1001","dest":"+14155550101","callstate":"RINGING"},`+
+		`{"uuid":"untracked","direction":"inbound","created_epoch":"1700000000","cid_num":"100","dest":"200","callstate":"ACTIVE"}]}`)
+	server.SetAPIResult("uuid_getvar new-call tenant_id", strconv.FormatUint(uint64(tenant.ID), 10))
+	server.SetAPIResult("uuid_getvar untracked tenant_id", "_undef_")
+
+	service := startActiveCalls(t, db, server)
+
+	var channels []models.ActiveChannel
+	db.Order("uuid").Find(&channels)
+	states := map[string]string{}
+	for _, channel := range channels {
+		states[channel.UUID] = channel.State
+	}
+	want := map[string]string{
+		"still-up":     models.ActiveChannelStateAnswered,
+		"new-call":     models.ActiveChannelStateRinging,
+		"other-switch": models.ActiveChannelStateAnswered,
+	}
+	if len(states) != len(want) {
+		t.Errorf("tracked channels = %v, want %v", states, want)
+	}
+	for channelUUID, state := range want {
+		if states[channelUUID] != state {
+			t.Errorf("channel %s state = %q, want %q", channelUUID, states[channelUUID], state)
+		}
+	}
+
+	released := map[string]bool{}
+	var admissions []models.CallAdmission
+	db.Find(&admissions)
+	for _, admission := range admissions {
+		released[admission.CallUUID] = admission.ReleasedAt != nil
+	}
+	if !released["hung-up"] {
+		t.Error("admission of an ended channel was not released")
+	}
+	if released["still-up"] || released["other-switch"] {
+		t.Errorf("admission of a live channel was released: %v", released)
+	}
+
+	var participants int64
+	db.Model(&models.ConferenceParticipant{}).Where("channel_uuid = ?", "hung-up").Count(&participants)
+	if participants != 0 {
+		t.Error("conference seat of an ended channel was kept")
+	}
+
+	active, err := service.GetActiveCalls(tenant.ID)
+	if err != nil {
+		t.Fatalf("GetActiveCalls: %v", err)
+	}
+	if len(active) != 3 {
+		t.Errorf("GetActiveCalls returned %d channels, want 3", len(active))
+	}
+}
+
+func TestActiveCallsFollowEvents(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewActiveCallService(db, nil, nil)
+	tenantID := strconv.FormatUint(uint64(tenant.ID), 10)
+
+	channel := func(name string) map[string]string {
+		return map[string]string{
+			"Event-Name":                  name,
+			"Unique-ID":                   "call-1",
+			"FreeSWITCH-Hostname":         "switch-a",
+			"Call-Direction":              "inbound",
+			"Caller-Caller-ID-Number":     "+14155550100",
+			"Caller-Destination-Number":   "1001",
+			"Caller-Channel-Created-Time": "1700000000000000",
+			"Event-Date-Timestamp":        "1700000005000000",
+			"variable_tenant_id":          tenantID,
+		}
+	}
+
+	for _, name := range []string{"CHANNEL_CREATE", "CHANNEL_ANSWER", "CHANNEL_ANSWER"} {
+		if err := service.HandleEvent(esl.Event{Headers: channel(name)}); err != nil {
+			t.Fatalf("HandleEvent(%s): %v", name, err)
+		}
+	}
+	untracked := channel("CHANNEL_CREATE")
+	untracked["Unique-ID"] = "no-tenant"
+	delete(untracked, "variable_tenant_id")
+	if err := service.HandleEvent(esl.Event{Headers: untracked}); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+
+	active, err := service.GetActiveCalls(tenant.ID)
+	if err != nil {
+		t.Fatalf("GetActiveCalls: %v", err)
+	}
+	if len(active) != 1 {
+		t.Fatalf("tracking %d channels, want 1", len(active))
+	}
+	if got := active[0]; got.State != models.ActiveChannelStateAnswered || got.Caller != "+14155550100" ||
+		!got.StartedAt.Equal(time.UnixMicro(1700000000000000)) || got.AnsweredAt == nil || !got.AnsweredAt.Equal(time.UnixMicro(1700000005000000)) {
+		t.Errorf("channel = %+v", got)
+	}
+
+	if err := service.HandleEvent(esl.Event{Headers: channel("CHANNEL_HANGUP")}); err != nil {
+		t.Fatalf("HandleEvent(CHANNEL_HANGUP): %v", err)
+	}
+	if active, _ := service.GetActiveCalls(tenant.ID); len(active) != 0 {
+		t.Errorf("hung up channel still tracked: %+v", active)
+	}
+}
+
+func TestActiveCallsHangup(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	server := newActiveCallSwitch(t)
+
+	live := "5e2b0c4e-0000-4000-8000-000000000001"
+	gone := "5e2b0c4e-0000-4000-8000-000000000002"
+	server.SetAPIResult("show channels as json", `{"row_count":1,"rows":[`+
+		`{"uuid":"`+live+`","direction":"inbound","created_epoch":"1700000000","cid_num":"100","dest":"200","callstate":"ACTIVE"}]}`)
+	server.SetAPIResult("uuid_getvar "+live+" tenant_id", strconv.FormatUint(uint64(tenant.ID), 10))
+	server.SetAPIResult("uuid_kill "+gone, "-ERR No such channel!\n")
+	service := startActiveCalls(t, db, server)
+
+	if err := db.Create(&models.ActiveChannel{UUID: gone, TenantID: tenant.ID, Hostname: "switch-b", State: models.ActiveChannelStateAnswered, StartedAt: time.Now()}).Error; err != nil {
+		t.Fatalf("creating channel: %v", err)
+	}
+
+	if err := service.Hangup(live); err != nil {
+		t.Fatalf("Hangup: %v", err)
+	}
+	if countCommand(server, "api uuid_kill "+live) != 1 {
+		t.Error("uuid_kill not sent")
+	}
+
+	if err := service.Hangup(gone); err != nil {
+		t.Fatalf("Hangup of a missed hangup: %v", err)
+	}
+	var count int64
+	db.Model(&models.ActiveChannel{}).Where("uuid = ?", gone).Count(&count)
+	if count != 0 {
+		t.Error("channel the switch no longer has was kept")
+	}
+
+	for channelUUID, want := range map[string]string{"not-a-uuid": "invalid channel uuid", gone: "active call not found"} {
+		if err := service.Hangup(channelUUID); err == nil || err.Error() != want {
+			t.Errorf("Hangup(%q) = %v, want %q", channelUUID, err, want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/models"
+)
+
+// admissionMaxAge bounds how long an admission counts as a live call when
+// its release was never reported, so lost hangups cannot pin a tenant at
+// its concurrency limit.
+const admissionMaxAge = 24 * time.Hour
+
+const (
+	AdmissionReasonFraudBlocked   = "tenant blocked for suspected toll fraud"
+	AdmissionReasonNoSubscription = "no active subscription"
+	AdmissionReasonConcurrent     = "concurrent call limit reached"
+	AdmissionReasonCPS            = "calls per second limit reached"
+	AdmissionReasonCallerBlocked  = "caller is blocked"
+)
+
+type AdmissionService struct {
+	DB *gorm.DB
+}
+
+func NewAdmissionService(db *gorm.DB) *AdmissionService {
+	return &AdmissionService{DB: db}
+}
+
+// AdmissionDecision is the answer to an authorize request. Reason is set
+// when the call is denied.
+type AdmissionDecision struct {
+	Allow              bool   `json:"allow"`
+	Reason             string `json:"reason,omitempty"`
+	ConcurrentCalls    int64  `json:"concurrent_calls"`
+	MaxConcurrentCalls uint   `json:"max_concurrent_calls"`
+	MaxCPS             uint   `json:"max_cps"`
+}
+
+// AdmissionCall is the call being authorized. Caller and Callee are
+// optional; when the caller is known it is screened against the tenant's
+// caller lists.
+type AdmissionCall struct {
+	UUID   string
+	Caller string
+	Callee string
+}
+
+// Authorize decides whether a new call may start for the tenant and, if so,
+// records its admission. The tenant row is locked for the duration, so
+// concurrent requests for the same tenant, from any API instance, are
+// checked one after another against up-to-date counts. Authorizing the same
+// call UUID again returns the original decision to allow without counting
+// the call twice; a UUID already admitted for another tenant is an error.
+func (s *AdmissionService) Authorize(tenantID uint, callUUID string) (*AdmissionDecision, error) {
+	return s.AuthorizeCall(tenantID, AdmissionCall{UUID: callUUID})
+}
+
+// AuthorizeCall is Authorize for a call whose numbers are known. A caller
+// on the tenant's blocklist is denied and the attempt is recorded as a
+// blocked call.
+func (s *AdmissionService) AuthorizeCall(tenantID uint, call AdmissionCall) (*AdmissionDecision, error) {
+	callUUID := call.UUID
+	if callUUID == "" {
+		return nil, errors.New("call uuid is required")
+	}
+
+	decision := &AdmissionDecision{}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&tenant, tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tenant not found")
+			}
+			return err
+		}
+
+		var existing models.CallAdmission
+		if err := tx.Where("call_uuid = ?", callUUID).
+			Limit(1).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+		if existing.ID != 0 {
+			if existing.TenantID != tenantID {
+				return errors.New("call uuid belongs to another tenant")
+			}
+			decision.Allow = true
+			return nil
+		}
+
+		if call.Caller != "" {
+			blocked, err := NewCallerListService(tx).Screen(&tenant, call.Caller, time.Now())
+			if err != nil {
+				return err
+			}
+			if blocked != nil {
+				decision.Reason = AdmissionReasonCallerBlocked
+				return recordBlockedCall(tx, &tenant, callUUID, call.Caller, call.Callee)
+			}
+		}
+
+		if tenant.FraudBlockedAt != nil {
+			decision.Reason = AdmissionReasonFraudBlocked
+			return nil
+		}
+
+		subscription, err := NewSubscriptionService(tx).GetTenantSubscription(tenantID)
+		if err != nil {
+			if err.Error() == "subscription not found" {
+				decision.Reason = AdmissionReasonNoSubscription
+				return nil
+			}
+			return err
+		}
+		decision.MaxConcurrentCalls = subscription.Plan.MaxConcurrentCalls
+		decision.MaxCPS = subscription.Plan.MaxCPS
+
+		now := time.Now()
+
+		if err := tx.Model(&models.CallAdmission{}).
+			Where("tenant_id = ? AND released_at IS NULL AND admitted_at > ?", tenantID, now.Add(-admissionMaxAge)).
+			Count(&decision.ConcurrentCalls).Error; err != nil {
+			return err
+		}
+		if decision.MaxConcurrentCalls > 0 && decision.ConcurrentCalls >= int64(decision.MaxConcurrentCalls) {
+			decision.Reason = AdmissionReasonConcurrent
+			return nil
+		}
+
+		if decision.MaxCPS > 0 {
+			var lastSecond int64
+			if err := tx.Model(&models.CallAdmission{}).
+				Where("tenant_id = ? AND admitted_at > ?", tenantID, now.Add(-time.Second)).
+				Count(&lastSecond).Error; err != nil {
+				return err
+			}
+			if lastSecond >= int64(decision.MaxCPS) {
+				decision.Reason = AdmissionReasonCPS
+				return nil
+			}
+		}
+
+		admission := models.CallAdmission{
+			TenantID:   tenantID,
+			CallUUID:   callUUID,
+			AdmittedAt: now,
+		}
+		// Another tenant's request for the same UUID holds a different lock
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "call_uuid"}},
+			DoNothing: true,
+		}).Create(&admission)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("call uuid belongs to another tenant")
+		}
+
+		decision.Allow = true
+		decision.ConcurrentCalls++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return decision, nil
+}
+
+// Release ends the admission of a call, freeing its concurrent call slot.
+// A nil tenantID releases the call for any tenant. Releasing an unknown or
+// already released call is not an error, since hangup events, switch
+// notifications and CDRs may all report the same call.
+func (s *AdmissionService) Release(callUUID string, tenantID *uint) error {
+	query := s.DB.Model(&models.CallAdmission{}).
+		Where("call_uuid = ? AND released_at IS NULL", callUUID)
+	if tenantID != nil {
+		query = query.Where("tenant_id = ?", *tenantID)
+	}
+
+	return query.Update("released_at", time.Now()).Error
+}
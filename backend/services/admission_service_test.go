@@ -0,0 +1,269 @@
+package services
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/esl"
+	"github.com/your-module/backend/models"
+)
+
+func authorize(t *testing.T, service *AdmissionService, tenantID uint, callUUID string) *AdmissionDecision {
+	t.Helper()
+
+	decision, err := service.Authorize(tenantID, callUUID)
+	if err != nil {
+		t.Fatalf("Authorize(%s): %v", callUUID, err)
+	}
+	return decision
+}
+
+func admissionReleased(t *testing.T, db *gorm.DB, callUUID string) bool {
+	t.Helper()
+
+	var admission models.CallAdmission
+	if err := db.Where("call_uuid = ?", callUUID).First(&admission).Error; err != nil {
+		t.Fatalf("loading admission of %s: %v", callUUID, err)
+	}
+	return admission.ReleasedAt != nil
+}
+
+func TestAuthorizeConcurrentLimit(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConcurrentCalls: 2})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{MaxConcurrentCalls: 1})
+	service := NewAdmissionService(db)
+
+	for _, callUUID := range []string{"call-1", "call-2"} {
+		if decision := authorize(t, service, tenant.ID, callUUID); !decision.Allow {
+			t.Fatalf("%s denied: %s", callUUID, decision.Reason)
+		}
+	}
+
+	decision := authorize(t, service, tenant.ID, "call-3")
+	if decision.Allow || decision.Reason != AdmissionReasonConcurrent || decision.ConcurrentCalls != 2 || decision.MaxConcurrentCalls != 2 {
+		t.Errorf("third call = %+v, want the concurrent call limit", decision)
+	}
+
+	// Asking again for an admitted call does not take another slot
+	if decision := authorize(t, service, tenant.ID, "call-1"); !decision.Allow {
+		t.Errorf("repeated authorization denied: %s", decision.Reason)
+	}
+	var admissions int64
+	db.Model(&models.CallAdmission{}).Where("tenant_id = ?", tenant.ID).Count(&admissions)
+	if admissions != 2 {
+		t.Errorf("%d admissions stored, want 2", admissions)
+	}
+
+	// Other tenants have their own limit
+	if decision := authorize(t, service, other.ID, "other-1"); !decision.Allow {
+		t.Errorf("other tenant denied: %s", decision.Reason)
+	}
+
+	if err := service.Release("call-1", &other.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if admissionReleased(t, db, "call-1") {
+		t.Error("another tenant released the call")
+	}
+	if err := service.Release("call-1", &tenant.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if err := service.Release("call-1", &tenant.ID); err != nil {
+		t.Fatalf("releasing twice: %v", err)
+	}
+	if decision := authorize(t, service, tenant.ID, "call-3"); !decision.Allow {
+		t.Errorf("call after a release denied: %s", decision.Reason)
+	}
+
+	// Admissions whose hangup was never reported stop counting eventually
+	db.Model(&models.CallAdmission{}).Where("call_uuid = ?", "call-2").
+		Update("admitted_at", time.Now().Add(-admissionMaxAge-time.Minute))
+	if decision := authorize(t, service, tenant.ID, "call-4"); !decision.Allow {
+		t.Errorf("call after a stale admission denied: %s", decision.Reason)
+	}
+}
+
+func TestAuthorizeCPSLimit(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxCPS: 2})
+	service := NewAdmissionService(db)
+
+	authorize(t, service, tenant.ID, "call-1")
+	authorize(t, service, tenant.ID, "call-2")
+	service.Release("call-1", &tenant.ID)
+
+	// Released calls still count towards the rate
+	decision := authorize(t, service, tenant.ID, "call-3")
+	if decision.Allow || decision.Reason != AdmissionReasonCPS || decision.MaxCPS != 2 {
+		t.Errorf("third call in a second = %+v, want the CPS limit", decision)
+	}
+
+	db.Model(&models.CallAdmission{}).Where("tenant_id = ?", tenant.ID).
+		Update("admitted_at", time.Now().Add(-2*time.Second))
+	if decision := authorize(t, service, tenant.ID, "call-3"); !decision.Allow {
+		t.Errorf("call in the next second denied: %s", decision.Reason)
+	}
+}
+
+// authorizeInParallel authorizes n calls at once and returns how many were
+// allowed.
+func authorizeInParallel(t *testing.T, service *AdmissionService, tenantID uint, n int) int {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			decision, err := service.Authorize(tenantID, "call-"+strconv.Itoa(i))
+			if err != nil {
+				t.Errorf("Authorize: %v", err)
+				return
+			}
+			if decision.Allow {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return allowed
+}
+
+func TestAuthorizeConcurrentLimitInParallel(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConcurrentCalls: 3})
+	service := NewAdmissionService(db)
+
+	if allowed := authorizeInParallel(t, service, tenant.ID, 20); allowed != 3 {
+		t.Errorf("%d of 20 parallel calls allowed, want 3", allowed)
+	}
+	var admissions int64
+	db.Model(&models.CallAdmission{}).Count(&admissions)
+	if admissions != 3 {
+		t.Errorf("%d admissions stored, want 3", admissions)
+	}
+}
+
+func TestAuthorizeCPSLimitInParallel(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxCPS: 4})
+	service := NewAdmissionService(db)
+
+	if allowed := authorizeInParallel(t, service, tenant.ID, 20); allowed != 4 {
+		t.Errorf("%d of 20 parallel calls allowed, want 4", allowed)
+	}
+}
+
+func TestAuthorizeUUIDOfAnotherTenant(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewAdmissionService(db)
+
+	authorize(t, service, tenant.ID, "call-1")
+	if _, err := service.Authorize(other.ID, "call-1"); err == nil || err.Error() != "call uuid belongs to another tenant" {
+		t.Errorf("reusing another tenant's call uuid = %v", err)
+	}
+}
+
+func TestAuthorizeDenials(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	unsubscribed := models.Tenant{Name: "unsubscribed", Domain: "unsubscribed.example.com"}
+	db.Create(&unsubscribed)
+	service := NewAdmissionService(db)
+
+	if decision := authorize(t, service, unsubscribed.ID, "call-1"); decision.Allow || decision.Reason != AdmissionReasonNoSubscription {
+		t.Errorf("tenant without a subscription = %+v", decision)
+	}
+
+	now := time.Now()
+	db.Model(tenant).Update("fraud_blocked_at", &now)
+	if decision := authorize(t, service, tenant.ID, "call-2"); decision.Allow || decision.Reason != AdmissionReasonFraudBlocked {
+		t.Errorf("blocked tenant = %+v", decision)
+	}
+
+	var admissions int64
+	db.Model(&models.CallAdmission{}).Count(&admissions)
+	if admissions != 0 {
+		t.Errorf("%d admissions stored for denied calls", admissions)
+	}
+
+	if _, err := service.Authorize(tenant.ID, ""); err == nil || err.Error() != "call uuid is required" {
+		t.Errorf("Authorize without a uuid = %v", err)
+	}
+	if _, err := service.Authorize(tenant.ID+100, "call-3"); err == nil || err.Error() != "tenant not found" {
+		t.Errorf("Authorize for an unknown tenant = %v", err)
+	}
+}
+
+func TestXMLCurlDialplanAdmission(t *testing.T) {
+	db, service, tenant := newXMLCurlFixture(t)
+	db.Model(&models.Plan{}).Where("name = ?", tenant.Domain).Update("max_concurrent_calls", 1)
+
+	req := XMLCurlRequest{Section: "dialplan", Context: tenant.Domain, DestinationNumber: "1002", CallerUser: "1001", CallUUID: "call-1"}
+	if section := renderedSection(t, service, req); section.Context == nil || section.Context.Extensions[0].Name == "admission_denied" {
+		t.Fatalf("admitted call = %+v", section)
+	}
+
+	req.CallUUID = "call-2"
+	section := renderedSection(t, service, req)
+	if section.Context == nil || section.Context.Extensions[0].Name != "admission_denied" {
+		t.Fatalf("call over the limit = %+v", section)
+	}
+	actions := section.Context.Extensions[0].Condition.Actions
+	if len(actions) != 2 || actions[1] != (fsAction{Application: "hangup", Data: "NORMAL_CIRCUIT_CONGESTION"}) {
+		t.Errorf("denied call actions = %+v", actions)
+	}
+
+	db.Where("tenant_id = ?", tenant.ID).Delete(&models.Subscription{})
+	req.CallUUID = "call-3"
+	section = renderedSection(t, service, req)
+	if section.Context == nil || section.Context.Extensions[0].Condition.Actions[1].Data != "CALL_REJECTED" {
+		t.Errorf("call without a subscription = %+v", section)
+	}
+}
+
+func TestAdmissionReleasedAtHangup(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	admissions := NewAdmissionService(db)
+	authorize(t, admissions, tenant.ID, "by-event")
+	authorize(t, admissions, tenant.ID, "by-cdr")
+
+	activeCalls := NewActiveCallService(db, nil, nil)
+	if err := activeCalls.HandleEvent(esl.Event{Headers: map[string]string{
+		"Event-Name":         "CHANNEL_HANGUP",
+		"Unique-ID":          "by-event",
+		"variable_tenant_id": strconv.FormatUint(uint64(tenant.ID), 10),
+	}}); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+	if !admissionReleased(t, db, "by-event") {
+		t.Error("hangup event did not release the admission")
+	}
+
+	calls := NewCallService(db)
+	start := time.Now().Add(-time.Minute)
+	if _, _, err := calls.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "by-cdr", Caller: "1001", Callee: "1002", StartTime: &start}); err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	if admissionReleased(t, db, "by-cdr") {
+		t.Error("report of a call in progress released the admission")
+	}
+	end := time.Now()
+	if _, _, err := calls.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "by-cdr", Caller: "1001", Callee: "1002", StartTime: &start, EndTime: &end}); err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	if !admissionReleased(t, db, "by-cdr") {
+		t.Error("final CDR did not release the admission")
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+const maxAnalyticsRows = 10000
+
+var analyticsIntervals = map[string]bool{
+	"hour":  true,
+	"day":   true,
+	"month": true,
+}
+
+var analyticsGroups = map[string]bool{
+	"prefix":       true,
+	"country":      true,
+	"caller":       true,
+	"callee":       true,
+	"direction":    true,
+	"disposition":  true,
+	"hangup_cause": true,
+	"sip_code":     true,
+	"hangup_side":  true,
+}
+
+type AnalyticsService struct {
+	DB *gorm.DB
+}
+
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	return &AnalyticsService{DB: db}
+}
+
+// CallStatsQuery selects the calls that started within [From, To). Interval
+// buckets the results by hour, day or month in Location; GroupBy splits them
+// by destination prefix (the first PrefixLength digits of the callee in E.164
+// form), destination country, caller, callee, or one of the outcome fields:
+// direction, disposition, hangup_cause, sip_code and hangup_side. Both are
+// optional. Direction and Disposition narrow the calls counted, so that for
+// example failed calls can be broken down by hangup cause.
+type CallStatsQuery struct {
+	From         time.Time
+	To           time.Time
+	Interval     string
+	GroupBy      string
+	PrefixLength int
+	Direction    string
+	Disposition  string
+	Location     *time.Location
+}
+
+// CallStats are the NOC metrics for a set of calls. ASR is the percentage of
+// calls answered and ACD the average billable seconds of answered calls.
+// Share is a group's percentage of all the calls in the report.
+type CallStats struct {
+	Bucket        *time.Time `json:"bucket,omitempty"`
+	Group         string     `json:"group,omitempty"`
+	Share         float64    `json:"share,omitempty"`
+	TotalCalls    int64      `json:"total_calls"`
+	AnsweredCalls int64      `json:"answered_calls"`
+	TotalBillsec  int64      `json:"total_billsec"`
+	TotalMinutes  float64    `json:"total_minutes"`
+	TotalCost     float64    `json:"total_cost"`
+	ASR           float64    `json:"asr"`
+	ACD           float64    `json:"acd"`
+}
+
+type CallStatsReport struct {
+	Summary CallStats   `json:"summary"`
+	Series  []CallStats `json:"series"`
+}
+
+const callStatsAggregates = "COUNT(*) AS total_calls, " +
+	"COUNT(*) FILTER (WHERE " + AnsweredCallCondition + ") AS answered_calls, " +
+	"COALESCE(SUM(billsec), 0) AS total_billsec, " +
+	"COALESCE(SUM(cost), 0) AS total_cost"
+
+type callStatsRow struct {
+	Bucket        *time.Time
+	GroupKey      string
+	TotalCalls    int64
+	AnsweredCalls int64
+	TotalBillsec  int64
+	TotalCost     float64
+}
+
+func (s *AnalyticsService) GetCallStats(tenantID uint, q CallStatsQuery) (*CallStatsReport, error) {
+	if q.From.IsZero() || q.To.IsZero() || !q.To.After(q.From) {
+		return nil, errors.New("a valid from/to range is required")
+	}
+	if q.Interval != "" && !analyticsIntervals[q.Interval] {
+		return nil, errors.New("invalid interval")
+	}
+	if q.GroupBy != "" && !analyticsGroups[q.GroupBy] {
+		return nil, errors.New("invalid group_by")
+	}
+	if q.Direction != "" && !callDirections[q.Direction] {
+		return nil, errors.New("invalid direction")
+	}
+	if q.Disposition != "" && !callDispositions[q.Disposition] {
+		return nil, errors.New("invalid disposition")
+	}
+	if q.PrefixLength <= 0 {
+		q.PrefixLength = 2
+	}
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+
+	bucketExpr := "NULL::timestamp"
+	var bucketArgs []interface{}
+	if q.Interval != "" {
+		bucketExpr = "date_trunc(?, start_time AT TIME ZONE ?)"
+		bucketArgs = []interface{}{q.Interval, q.Location.String()}
+	}
+
+	groupExpr := "''"
+	var groupArgs []interface{}
+	switch q.GroupBy {
+	case "prefix":
+		groupExpr = "LEFT(regexp_replace(COALESCE(NULLIF(callee_e164, ''), callee), '[^0-9]', '', 'g'), ?)"
+		groupArgs = []interface{}{q.PrefixLength}
+	case "country":
+		groupExpr = "callee_country"
+	case "caller":
+		groupExpr = "COALESCE(NULLIF(caller_e164, ''), caller)"
+	case "callee":
+		groupExpr = "COALESCE(NULLIF(callee_e164, ''), callee)"
+	case "direction", "disposition", "hangup_cause", "hangup_side":
+		groupExpr = "COALESCE(" + q.GroupBy + ", '')"
+	case "sip_code":
+		groupExpr = "COALESCE(NULLIF(sip_code, 0)::text, '')"
+	}
+
+	base := s.DB.Model(&models.Call{}).
+		Where("tenant_id = ? AND start_time >= ? AND start_time < ?", tenantID, q.From, q.To)
+	if q.Direction != "" {
+		base = base.Where("direction = ?", q.Direction)
+	}
+	if q.Disposition != "" {
+		base = base.Where("disposition = ?", q.Disposition)
+	}
+
+	var summary callStatsRow
+	if err := base.Session(&gorm.Session{}).
+		Select(callStatsAggregates).
+		Scan(&summary).Error; err != nil {
+		return nil, err
+	}
+
+	args := append(bucketArgs, groupArgs...)
+	var rows []callStatsRow
+	if err := base.Session(&gorm.Session{}).
+		Select(bucketExpr+" AS bucket, "+groupExpr+" AS group_key, "+callStatsAggregates, args...).
+		Group("bucket, group_key").
+		Order("bucket, total_calls DESC").
+		Limit(maxAnalyticsRows).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	report := &CallStatsReport{
+		Summary: newCallStats(summary),
+		Series:  make([]CallStats, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		stats := newCallStats(row)
+		stats.Group = row.GroupKey
+		if q.GroupBy != "" && summary.TotalCalls > 0 {
+			stats.Share = roundTo(float64(row.TotalCalls)/float64(summary.TotalCalls)*100, 2)
+		}
+		if row.Bucket != nil {
+			b := row.Bucket
+			bucket := time.Date(b.Year(), b.Month(), b.Day(), b.Hour(), 0, 0, 0, q.Location)
+			stats.Bucket = &bucket
+		}
+		report.Series = append(report.Series, stats)
+	}
+
+	return report, nil
+}
+
+func newCallStats(row callStatsRow) CallStats {
+	stats := CallStats{
+		TotalCalls:    row.TotalCalls,
+		AnsweredCalls: row.AnsweredCalls,
+		TotalBillsec:  row.TotalBillsec,
+		TotalMinutes:  roundTo(float64(row.TotalBillsec)/60, 2),
+		TotalCost:     roundTo(row.TotalCost, 4),
+	}
+
+	if row.TotalCalls > 0 {
+		stats.ASR = roundTo(float64(row.AnsweredCalls)/float64(row.TotalCalls)*100, 2)
+	}
+	if row.AnsweredCalls > 0 {
+		stats.ACD = roundTo(float64(row.TotalBillsec)/float64(row.AnsweredCalls), 2)
+	}
+
+	return stats
+}
+
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
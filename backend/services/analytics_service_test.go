@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"github.com/your-module/backend/models"
+)
+
+func TestNewCallStats(t *testing.T) {
+	tests := []struct {
+		name string
+		row  callStatsRow
+		want CallStats
+	}{
+		{
+			name: "no calls",
+			want: CallStats{},
+		},
+		{
+			name: "none answered",
+			row:  callStatsRow{TotalCalls: 4},
+			want: CallStats{TotalCalls: 4},
+		},
+		{
+			name: "mixed",
+			row:  callStatsRow{TotalCalls: 3, AnsweredCalls: 2, TotalBillsec: 125, TotalCost: 0.123456},
+			want: CallStats{TotalCalls: 3, AnsweredCalls: 2, TotalBillsec: 125, TotalMinutes: 2.08, TotalCost: 0.1235, ASR: 66.67, ACD: 62.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newCallStats(tt.row); got != tt.want {
+				t.Errorf("newCallStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCallStatsRejects(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewAnalyticsService(db)
+
+	from := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name  string
+		query CallStatsQuery
+		want  string
+	}{
+		{"no range", CallStatsQuery{}, "a valid from/to range is required"},
+		{"reversed range", CallStatsQuery{From: to, To: from}, "a valid from/to range is required"},
+		{"interval", CallStatsQuery{From: from, To: to, Interval: "week"}, "invalid interval"},
+		{"group", CallStatsQuery{From: from, To: to, GroupBy: "tenant"}, "invalid group_by"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetCallStats(tenant.ID, tt.query)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+// asteriskCSVColumns is the column order of cdr_csv's Master.csv, used when a
+// CSV upload has no header row. cdr_adaptive_odbc exports are expected to
+// carry their own header.
+var asteriskCSVColumns = []string{
+	"accountcode", "src", "dst", "dcontext", "clid", "channel", "dstchannel",
+	"lastapp", "lastdata", "start", "answer", "end", "duration", "billsec",
+	"disposition", "amaflags", "uniqueid", "userfield", "linkedid",
+	"peeraccount", "sequence",
+}
+
+var asteriskTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+// AsteriskRow is one CDR row or CEL event keyed by lower-cased column name.
+type AsteriskRow map[string]string
+
+func (r AsteriskRow) get(names ...string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(r[name]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// AsteriskIngestResult summarises a batch of Asterisk records. Skipped
+// lists the linkedids of calls whose numbers were neither in the batch nor
+// already stored, such as CEL events arriving without their CHAN_START.
+type AsteriskIngestResult struct {
+	Calls   []models.Call `json:"calls"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped []string      `json:"skipped"`
+}
+
+// ParseAsteriskRows reads a batch of Asterisk records sent either as a JSON
+// array of objects or as CSV. CSV input may start with a header row; CDR
+// uploads without one are read in Master.csv column order.
+func ParseAsteriskRows(body []byte, requireHeader bool) ([]AsteriskRow, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, errors.New("empty asterisk payload")
+	}
+
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		return parseAsteriskJSON(trimmed)
+	}
+
+	return parseAsteriskCSV(trimmed, requireHeader)
+}
+
+func parseAsteriskJSON(body []byte) ([]AsteriskRow, error) {
+	var raw []map[string]interface{}
+	if body[0] == '{' {
+		var single map[string]interface{}
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, errors.New("invalid asterisk payload")
+		}
+		raw = append(raw, single)
+	} else if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, errors.New("invalid asterisk payload")
+	}
+
+	rows := make([]AsteriskRow, 0, len(raw))
+	for _, item := range raw {
+		row := AsteriskRow{}
+		for key, value := range item {
+			switch v := value.(type) {
+			case string:
+				row[strings.ToLower(key)] = v
+			case float64:
+				row[strings.ToLower(key)] = strconv.FormatFloat(v, 'f', -1, 64)
+			case map[string]interface{}:
+				// CEL extra, sent as an object rather than a JSON string
+				encoded, _ := json.Marshal(v)
+				row[strings.ToLower(key)] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseAsteriskCSV(body []byte, requireHeader bool) ([]AsteriskRow, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	columns := asteriskCSVColumns
+	var rows []AsteriskRow
+	first := true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("invalid asterisk csv")
+		}
+
+		if first {
+			first = false
+			if isAsteriskHeader(record) {
+				columns = make([]string, len(record))
+				for i, name := range record {
+					columns[i] = strings.ToLower(strings.TrimSpace(name))
+				}
+				continue
+			}
+			if requireHeader {
+				return nil, errors.New("asterisk csv header row is required")
+			}
+		}
+
+		row := AsteriskRow{}
+		for i, value := range record {
+			if i < len(columns) {
+				row[columns[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func isAsteriskHeader(record []string) bool {
+	for _, name := range record {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "uniqueid", "linkedid", "eventtype":
+			return true
+		}
+	}
+	return false
+}
+
+// IngestAsteriskCDR stores CDR rows for the tenant. Rows sharing a linkedid
+// are legs of the same call and are merged into a single models.Call keyed
+// by that linkedid.
+func (s *CdrService) IngestAsteriskCDR(tenantID uint, rows []AsteriskRow, loc *time.Location) (*AsteriskIngestResult, error) {
+	groups := map[string][]AsteriskRow{}
+	var order []string
+
+	for _, row := range rows {
+		key := row.get("linkedid", "uniqueid")
+		if key == "" {
+			return nil, errors.New("cdr row is missing uniqueid")
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+
+	calls := make([]*models.Call, 0, len(order))
+	for _, linkedID := range order {
+		call, err := asteriskCDRToCall(linkedID, groups[linkedID], loc)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+
+	return s.storeAsteriskCalls(tenantID, calls)
+}
+
+func asteriskCDRToCall(linkedID string, legs []AsteriskRow, loc *time.Location) (*models.Call, error) {
+	call := &models.Call{UUID: linkedID}
+
+	// The originating channel is the one whose uniqueid equals the linkedid;
+	// fall back to the earliest leg when it is not part of this batch.
+	origin := legs[0]
+	originStart := parseAsteriskTime(origin.get("start", "calldate"), loc)
+	for _, leg := range legs {
+		if leg.get("uniqueid") == linkedID {
+			origin = leg
+			break
+		}
+		start := parseAsteriskTime(leg.get("start", "calldate"), loc)
+		if start != nil && (originStart == nil || start.Before(*originStart)) {
+			origin = leg
+			originStart = start
+		}
+	}
+
+	call.Caller = origin.get("src", "cid_num")
+	call.Callee = origin.get("dst", "exten")
+	if call.Caller == "" || call.Callee == "" {
+		return nil, errors.New("cdr row is missing src or dst")
+	}
+
+	// Stock CDRs carry only the disposition; hangupcause is read when a
+	// cdr_adaptive_odbc export adds it
+	call.Disposition = asteriskDisposition(origin.get("disposition"))
+	setHangupCause(call, origin.get("hangupcause"))
+
+	for _, leg := range legs {
+		billsec, _ := strconv.Atoi(leg.get("billsec"))
+		mergeCallTimes(call,
+			parseAsteriskTime(leg.get("start", "calldate"), loc),
+			parseAsteriskTime(leg.get("answer"), loc),
+			parseAsteriskTime(leg.get("end"), loc),
+			billsec,
+		)
+	}
+
+	return call, nil
+}
+
+// IngestAsteriskCEL builds calls from CEL events. Events are grouped by
+// linkedid; CHAN_START of the originating channel gives the start time and
+// numbers, the first ANSWER the answer time and LINKEDID_END (or the last
+// HANGUP) the end time.
+func (s *CdrService) IngestAsteriskCEL(tenantID uint, events []AsteriskRow, loc *time.Location) (*AsteriskIngestResult, error) {
+	groups := map[string][]AsteriskRow{}
+	var order []string
+
+	for _, event := range events {
+		key := event.get("linkedid", "uniqueid")
+		if key == "" {
+			return nil, errors.New("cel event is missing linkedid")
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], event)
+	}
+
+	calls := make([]*models.Call, 0, len(order))
+	for _, linkedID := range order {
+		calls = append(calls, asteriskCELToCall(linkedID, groups[linkedID], loc))
+	}
+
+	return s.storeAsteriskCalls(tenantID, calls)
+}
+
+// asteriskCELToCall leaves the numbers empty when the events do not include
+// a CHAN_START; they are then taken from the stored call, if any.
+func asteriskCELToCall(linkedID string, events []AsteriskRow, loc *time.Location) *models.Call {
+	sort.SliceStable(events, func(i, j int) bool {
+		a := parseAsteriskTime(events[i].get("eventtime"), loc)
+		b := parseAsteriskTime(events[j].get("eventtime"), loc)
+		return a != nil && b != nil && a.Before(*b)
+	})
+
+	call := &models.Call{UUID: linkedID}
+	var start, answer, end *time.Time
+	var originChannel string
+	var originHangup *asteriskHangupExtra
+
+	for _, event := range events {
+		at := parseAsteriskTime(event.get("eventtime"), loc)
+		switch strings.ToUpper(event.get("eventtype")) {
+		case "CHAN_START":
+			if event.get("uniqueid") == linkedID || call.Caller == "" {
+				call.Caller = event.get("cid_num", "cid_ani")
+				call.Callee = event.get("exten", "cid_dnid")
+				originChannel = event.get("channame")
+			}
+			if start == nil {
+				start = at
+			}
+		case "ANSWER":
+			if answer == nil {
+				answer = at
+			}
+		case "HANGUP", "CHAN_END":
+			if strings.EqualFold(event.get("eventtype"), "HANGUP") && event.get("uniqueid") == linkedID {
+				originHangup = parseAsteriskHangupExtra(event.get("extra"))
+			}
+			if at != nil && (end == nil || at.After(*end)) {
+				end = at
+			}
+		case "LINKEDID_END":
+			end = at
+		}
+	}
+
+	billsec := 0
+	if answer != nil && end != nil && end.After(*answer) {
+		billsec = int(end.Sub(*answer).Seconds())
+	}
+
+	mergeCallTimes(call, start, answer, end, billsec)
+
+	if originHangup != nil {
+		call.HangupCauseCode = originHangup.HangupCause
+		call.Disposition = asteriskDisposition(originHangup.DialStatus)
+		if originHangup.HangupSource != nil {
+			switch *originHangup.HangupSource {
+			case "":
+				call.HangupSide = models.CallHangupSystem
+			case originChannel:
+				call.HangupSide = models.CallHangupCaller
+			default:
+				call.HangupSide = models.CallHangupCallee
+			}
+		}
+	}
+
+	return call
+}
+
+// asteriskHangupExtra is the extra field of a CEL HANGUP event. An empty
+// hangupsource means the dialplan or the system ended the channel.
+type asteriskHangupExtra struct {
+	HangupCause  int     `json:"hangupcause"`
+	HangupSource *string `json:"hangupsource"`
+	DialStatus   string  `json:"dialstatus"`
+}
+
+func parseAsteriskHangupExtra(extra string) *asteriskHangupExtra {
+	var parsed asteriskHangupExtra
+	if err := json.Unmarshal([]byte(extra), &parsed); err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+// asteriskDisposition maps a CDR disposition or a Dial() DIALSTATUS onto a
+// call disposition.
+func asteriskDisposition(value string) string {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "ANSWERED", "ANSWER":
+		return models.CallDispositionAnswered
+	case "BUSY":
+		return models.CallDispositionBusy
+	case "NO ANSWER", "NOANSWER", "CANCEL":
+		return models.CallDispositionNoAnswer
+	case "FAILED", "CONGESTION", "CHANUNAVAIL":
+		return models.CallDispositionFailed
+	}
+	return ""
+}
+
+// storeAsteriskCalls merges each call with any record already stored for its
+// linkedid, so legs delivered in separate batches still end up on one call.
+// The batch is stored in one transaction so a failure leaves none of it.
+func (s *CdrService) storeAsteriskCalls(tenantID uint, calls []*models.Call) (*AsteriskIngestResult, error) {
+	result := &AsteriskIngestResult{Calls: []models.Call{}, Skipped: []string{}}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// s.CallService runs outside the transaction; this one, and the
+		// rating, fraud and admission services it uses, run inside it
+		callService := NewCallService(tx)
+
+		for _, call := range calls {
+			var existing models.Call
+			err := tx.Where("uuid = ? AND tenant_id = ?", call.UUID, tenantID).
+				First(&existing).Error
+			if err == nil {
+				// The numbers are the originating leg's, so the stored ones
+				// stay when this batch has none or only later legs
+				laterLegs := call.StartTime != nil && existing.StartTime != nil &&
+					call.StartTime.After(*existing.StartTime)
+				if call.Caller == "" || laterLegs {
+					call.Caller = existing.Caller
+				}
+				if call.Callee == "" || laterLegs {
+					call.Callee = existing.Callee
+				}
+				if call.RecordingURL == "" {
+					call.RecordingURL = existing.RecordingURL
+				}
+				mergeCallTimes(call, existing.StartTime, existing.AnswerTime, existing.EndTime, existing.Billsec)
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			if call.Caller == "" || call.Callee == "" {
+				result.Skipped = append(result.Skipped, call.UUID)
+				continue
+			}
+
+			stored, created, err := callService.UpsertCallByUUID(tenantID, call)
+			if err != nil {
+				return err
+			}
+
+			if created {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+			result.Calls = append(result.Calls, *stored)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// mergeCallTimes folds one leg's timing into the call: the earliest start and
+// answer, the latest end and the longest billable duration win.
+func mergeCallTimes(call *models.Call, start, answer, end *time.Time, billsec int) {
+	if start != nil && (call.StartTime == nil || start.Before(*call.StartTime)) {
+		call.StartTime = start
+	}
+	if answer != nil && (call.AnswerTime == nil || answer.Before(*call.AnswerTime)) {
+		call.AnswerTime = answer
+	}
+	if end != nil && (call.EndTime == nil || end.After(*call.EndTime)) {
+		call.EndTime = end
+	}
+	if billsec > call.Billsec {
+		call.Billsec = billsec
+	}
+}
+
+// parseAsteriskTime accepts the "YYYY-MM-DD HH:MM:SS" timestamps written by
+// the CDR backends as well as the epoch form CEL uses for eventtime.
+func parseAsteriskTime(value string, loc *time.Location) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "0000-00-00") {
+		return nil
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return nil
+		}
+		t := time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+		return &t
+	}
+
+	for _, layout := range asteriskTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+
+	return nil
+}
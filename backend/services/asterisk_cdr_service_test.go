@@ -0,0 +1,248 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"github.com/your-module/backend/models"
+)
+
+func TestParseAsteriskRows(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		requireHeader bool
+		want          []AsteriskRow
+		wantErr       bool
+	}{
+		{
+			name: "json array",
+			body: `[{"UniqueID": "1700000000.1", "billsec": 30}]`,
+			want: []AsteriskRow{{"uniqueid": "1700000000.1", "billsec": "30"}},
+		},
+		{
+			name: "json object",
+			body: `{"linkedid": "1700000000.1"}`,
+			want: []AsteriskRow{{"linkedid": "1700000000.1"}},
+		},
+		{
+			name: "csv with header",
+			body: "uniqueid,src,dst\n1700000000.1,1001,1002\n",
+			want: []AsteriskRow{{"uniqueid": "1700000000.1", "src": "1001", "dst": "1002"}},
+		},
+		{
+			name: "master.csv without header",
+			body: `"","1001","1002","default","""Alice"" <1001>","SIP/1001-0001","SIP/1002-0002","Dial","SIP/1002","2023-11-14 22:13:20","2023-11-14 22:13:25","2023-11-14 22:14:25",65,60,"ANSWERED","DOCUMENTATION","1700000000.1","","1700000000.1","",1`,
+			want: []AsteriskRow{{
+				"accountcode": "", "src": "1001", "dst": "1002", "dcontext": "default", "clid": `"Alice" <1001>`,
+				"channel": "SIP/1001-0001", "dstchannel": "SIP/1002-0002", "lastapp": "Dial", "lastdata": "SIP/1002",
+				"start": "2023-11-14 22:13:20", "answer": "2023-11-14 22:13:25", "end": "2023-11-14 22:14:25",
+				"duration": "65", "billsec": "60", "disposition": "ANSWERED", "amaflags": "DOCUMENTATION",
+				"uniqueid": "1700000000.1", "userfield": "", "linkedid": "1700000000.1", "peeraccount": "", "sequence": "1",
+			}},
+		},
+		{
+			name:          "cel csv without header",
+			body:          "CHAN_START,1700000000.1\n",
+			requireHeader: true,
+			wantErr:       true,
+		},
+		{name: "empty", body: "  ", wantErr: true},
+		{name: "broken json", body: `[{"uniqueid": `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseAsteriskRows([]byte(tt.body), tt.requireHeader)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseAsteriskRows() = %v, want an error", rows)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAsteriskRows: %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("ParseAsteriskRows() = %v, want %v", rows, tt.want)
+			}
+			for i := range rows {
+				if len(rows[i]) != len(tt.want[i]) {
+					t.Errorf("row %d = %v, want %v", i, rows[i], tt.want[i])
+				}
+				for key, value := range tt.want[i] {
+					if rows[i][key] != value {
+						t.Errorf("row %d %s = %q, want %q", i, key, rows[i][key], value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIngestAsteriskCDRMergesLegs(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCdrService(db)
+
+	// The transfer leg comes first and started later; the originating leg
+	// is the one whose uniqueid is the linkedid
+	result, err := service.IngestAsteriskCDR(tenant.ID, []AsteriskRow{
+		{"uniqueid": "1700000000.2", "linkedid": "1700000000.1", "src": "1002", "dst": "1003",
+			"start": "2023-11-14 22:13:40", "answer": "2023-11-14 22:13:45", "end": "2023-11-14 22:15:45", "billsec": "120"},
+		{"uniqueid": "1700000000.1", "linkedid": "1700000000.1", "src": "+14155550100", "dst": "1002",
+			"start": "2023-11-14 22:13:20", "answer": "2023-11-14 22:13:25", "end": "2023-11-14 22:14:25", "billsec": "60"},
+		{"uniqueid": "1700000100.1", "src": "1001", "dst": "1004", "start": "2023-11-14 23:00:00", "end": "2023-11-14 23:00:20"},
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("IngestAsteriskCDR: %v", err)
+	}
+	if result.Created != 2 || result.Updated != 0 {
+		t.Errorf("created %d updated %d, want 2 calls created", result.Created, result.Updated)
+	}
+
+	var call models.Call
+	if err := db.Where("uuid = ?", "1700000000.1").First(&call).Error; err != nil {
+		t.Fatalf("loading merged call: %v", err)
+	}
+	if call.Caller != "+14155550100" || call.Callee != "1002" {
+		t.Errorf("numbers = %q -> %q, want the originating leg's", call.Caller, call.Callee)
+	}
+	if call.StartTime.Format(time.DateTime) != "2023-11-14 22:13:20" || call.AnswerTime.Format(time.DateTime) != "2023-11-14 22:13:25" {
+		t.Errorf("start %v answer %v, want the earliest", call.StartTime, call.AnswerTime)
+	}
+	if call.EndTime.Format(time.DateTime) != "2023-11-14 22:15:45" || call.Billsec != 120 {
+		t.Errorf("end %v billsec %d, want the latest end and longest billsec", call.EndTime, call.Billsec)
+	}
+}
+
+func TestIngestAsteriskCDRLegsInSeparateBatches(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCdrService(db)
+
+	if _, err := service.IngestAsteriskCDR(tenant.ID, []AsteriskRow{
+		{"uniqueid": "1700000000.1", "linkedid": "1700000000.1", "src": "+14155550100", "dst": "1002",
+			"start": "2023-11-14 22:13:20", "end": "2023-11-14 22:14:25", "billsec": "60"},
+	}, time.UTC); err != nil {
+		t.Fatalf("first batch: %v", err)
+	}
+
+	result, err := service.IngestAsteriskCDR(tenant.ID, []AsteriskRow{
+		{"uniqueid": "1700000000.2", "linkedid": "1700000000.1", "src": "1002", "dst": "1003",
+			"start": "2023-11-14 22:13:40", "end": "2023-11-14 22:15:45", "billsec": "120"},
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("second batch: %v", err)
+	}
+	if result.Created != 0 || result.Updated != 1 {
+		t.Errorf("created %d updated %d, want the call updated", result.Created, result.Updated)
+	}
+
+	var calls []models.Call
+	db.Find(&calls)
+	if len(calls) != 1 {
+		t.Fatalf("%d calls stored for one linkedid", len(calls))
+	}
+	if calls[0].Caller != "+14155550100" || calls[0].StartTime.Format(time.DateTime) != "2023-11-14 22:13:20" {
+		t.Errorf("later leg replaced the originating leg: %q from %v", calls[0].Caller, calls[0].StartTime)
+	}
+	if calls[0].EndTime.Format(time.DateTime) != "2023-11-14 22:15:45" || calls[0].Billsec != 120 {
+		t.Errorf("end %v billsec %d, want the later leg's", calls[0].EndTime, calls[0].Billsec)
+	}
+}
+
+func TestIngestAsteriskCEL(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCdrService(db)
+
+	events := []AsteriskRow{
+		{"eventtype": "HANGUP", "eventtime": "1700000065", "uniqueid": "1700000000.2", "linkedid": "1700000000.1"},
+		{"eventtype": "CHAN_START", "eventtime": "1700000000", "uniqueid": "1700000000.1", "linkedid": "1700000000.1", "cid_num": "+14155550100", "exten": "1002"},
+		{"eventtype": "CHAN_START", "eventtime": "1700000001", "uniqueid": "1700000000.2", "linkedid": "1700000000.1", "cid_num": "1002", "exten": "s"},
+		{"eventtype": "ANSWER", "eventtime": "1700000005", "uniqueid": "1700000000.2", "linkedid": "1700000000.1"},
+		{"eventtype": "LINKEDID_END", "eventtime": "1700000066", "uniqueid": "1700000000.1", "linkedid": "1700000000.1"},
+	}
+	result, err := service.IngestAsteriskCEL(tenant.ID, events, time.UTC)
+	if err != nil {
+		t.Fatalf("IngestAsteriskCEL: %v", err)
+	}
+	if len(result.Calls) != 1 {
+		t.Fatalf("%d calls, want 1", len(result.Calls))
+	}
+
+	call := result.Calls[0]
+	if call.UUID != "1700000000.1" || call.Caller != "+14155550100" || call.Callee != "1002" {
+		t.Errorf("call %q = %q -> %q", call.UUID, call.Caller, call.Callee)
+	}
+	if call.StartTime.Unix() != 1700000000 || call.AnswerTime.Unix() != 1700000005 || call.EndTime.Unix() != 1700000066 {
+		t.Errorf("times = %v %v %v", call.StartTime, call.AnswerTime, call.EndTime)
+	}
+	if call.Billsec != 61 {
+		t.Errorf("billsec = %d, want answer to LINKEDID_END", call.Billsec)
+	}
+}
+
+func TestIngestAsteriskCELWithoutChanStart(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCdrService(db)
+
+	hangup := []AsteriskRow{
+		{"eventtype": "ANSWER", "eventtime": "1700000005", "uniqueid": "1700000000.1", "linkedid": "1700000000.1"},
+		{"eventtype": "LINKEDID_END", "eventtime": "1700000066", "uniqueid": "1700000000.1", "linkedid": "1700000000.1"},
+	}
+	result, err := service.IngestAsteriskCEL(tenant.ID, hangup, time.UTC)
+	if err != nil {
+		t.Fatalf("IngestAsteriskCEL: %v", err)
+	}
+	if len(result.Calls) != 0 || len(result.Skipped) != 1 || result.Skipped[0] != "1700000000.1" {
+		t.Fatalf("calls %d skipped %v, want the call skipped", len(result.Calls), result.Skipped)
+	}
+
+	// Once the start is stored, later events complete the call
+	start := []AsteriskRow{
+		{"eventtype": "CHAN_START", "eventtime": "1700000000", "uniqueid": "1700000000.1", "linkedid": "1700000000.1", "cid_num": "+14155550100", "exten": "1002"},
+	}
+	if _, err := service.IngestAsteriskCEL(tenant.ID, start, time.UTC); err != nil {
+		t.Fatalf("IngestAsteriskCEL: %v", err)
+	}
+	result, err = service.IngestAsteriskCEL(tenant.ID, hangup, time.UTC)
+	if err != nil {
+		t.Fatalf("IngestAsteriskCEL: %v", err)
+	}
+	if result.Updated != 1 || len(result.Skipped) != 0 {
+		t.Fatalf("updated %d skipped %v, want the stored call updated", result.Updated, result.Skipped)
+	}
+	call := result.Calls[0]
+	if call.Caller != "+14155550100" || call.Callee != "1002" || call.EndTime == nil || call.EndTime.Unix() != 1700000066 {
+		t.Errorf("call = %q -> %q ending %v", call.Caller, call.Callee, call.EndTime)
+	}
+}
+
+func TestIngestAsteriskCDRBatchIsAtomic(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewCdrService(db)
+
+	if _, _, err := service.CallService.UpsertCallByUUID(other.ID, &models.Call{UUID: "1700000000.9", Caller: "1001", Callee: "1002"}); err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+
+	_, err := service.IngestAsteriskCDR(tenant.ID, []AsteriskRow{
+		{"uniqueid": "1700000000.1", "linkedid": "1700000000.1", "src": "+14155550100", "dst": "1002",
+			"start": "2023-11-14 22:13:20", "end": "2023-11-14 22:14:25", "billsec": "60"},
+		{"uniqueid": "1700000000.9", "linkedid": "1700000000.9", "src": "+14155550100", "dst": "1003",
+			"start": "2023-11-14 22:15:20", "end": "2023-11-14 22:16:25", "billsec": "60"},
+	}, time.UTC)
+	if err == nil || err.Error() != "call uuid belongs to another tenant" {
+		t.Fatalf("batch with another tenant's call = %v", err)
+	}
+
+	var stored int64
+	db.Model(&models.Call{}).Where("tenant_id = ?", tenant.ID).Count(&stored)
+	if stored != 0 {
+		t.Errorf("%d calls of a failed batch stored, want none", stored)
+	}
+}
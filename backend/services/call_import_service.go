@@ -0,0 +1,327 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+const (
+	callImportBatchSize = 1000
+	// Keeps the report bounded for files where most rows are broken.
+	callImportMaxErrors = 1000
+)
+
+// CallImportFields are the call fields a CSV column can be mapped onto.
+var CallImportFields = []string{
+	"uuid", "caller", "callee", "start_time", "answer_time", "end_time",
+	"billsec", "recording_url", "direction", "disposition", "hangup_cause",
+	"sip_code", "hangup_side",
+}
+
+// CallImportOptions controls how an import CSV is read. Columns maps a call
+// field to a CSV header name, or to a 1-based column index when the file has
+// no header. Unmapped fields default to a header of the same name.
+type CallImportOptions struct {
+	Columns    map[string]string
+	HasHeader  bool
+	TimeFormat string
+	Location   *time.Location
+}
+
+type CallImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+type CallImportReport struct {
+	TotalRows       int                  `json:"total_rows"`
+	Imported        int                  `json:"imported"`
+	Failed          int                  `json:"failed"`
+	Errors          []CallImportRowError `json:"errors"`
+	ErrorsTruncated bool                 `json:"errors_truncated"`
+}
+
+func (r *CallImportReport) addError(row int, field, message string) {
+	r.Failed++
+	if len(r.Errors) >= callImportMaxErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	r.Errors = append(r.Errors, CallImportRowError{Row: row, Field: field, Error: message})
+}
+
+type CallImportService struct {
+	DB          *gorm.DB
+	CallService *CallService
+}
+
+func NewCallImportService(db *gorm.DB) *CallImportService {
+	return &CallImportService{
+		DB:          db,
+		CallService: NewCallService(db),
+	}
+}
+
+// ImportCSV streams calls from r into the tenant, inserting them in batches
+// so that only one batch is held in memory at a time. Row problems are
+// collected in the report; only reader or database failures abort the import.
+func (s *CallImportService) ImportCSV(tenantID uint, r io.Reader, opts CallImportOptions) (*CallImportReport, error) {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var header []string
+	if opts.HasHeader {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, errors.New("import file is empty")
+		}
+		if err != nil {
+			return nil, errors.New("invalid import csv header")
+		}
+		header = make([]string, len(record))
+		for i, name := range record {
+			header[i] = strings.ToLower(strings.TrimSpace(name))
+		}
+	}
+
+	indexes, err := resolveImportColumns(opts, header)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CallImportReport{Errors: []CallImportRowError{}}
+	batch := make([]*models.Call, 0, callImportBatchSize)
+	batchRows := make(map[string]int, callImportBatchSize)
+
+	flush := func() error {
+		duplicates, err := s.CallService.InsertCallBatch(tenantID, batch)
+		if err != nil {
+			return err
+		}
+		for _, call := range batch {
+			if duplicates[call.UUID] {
+				report.addError(batchRows[call.UUID], "uuid", "duplicate uuid")
+				continue
+			}
+			report.Imported++
+		}
+		batch = batch[:0]
+		batchRows = make(map[string]int, callImportBatchSize)
+		return nil
+	}
+
+	row := 0
+	if opts.HasHeader {
+		row = 1
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				report.TotalRows++
+				report.addError(row, "", "malformed csv row")
+				continue
+			}
+			return nil, err
+		}
+		report.TotalRows++
+
+		call, field, rowErr := importRowToCall(record, indexes, opts)
+		if rowErr != nil {
+			report.addError(row, field, rowErr.Error())
+			continue
+		}
+
+		if _, ok := batchRows[call.UUID]; ok {
+			report.addError(row, "uuid", "duplicate uuid")
+			continue
+		}
+
+		batch = append(batch, call)
+		batchRows[call.UUID] = row
+
+		if len(batch) >= callImportBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func resolveImportColumns(opts CallImportOptions, header []string) (map[string]int, error) {
+	positions := map[string]int{}
+	for i, name := range header {
+		positions[name] = i
+	}
+
+	indexes := map[string]int{}
+	for _, field := range CallImportFields {
+		source, mapped := opts.Columns[field]
+		if !mapped {
+			source = field
+		}
+		source = strings.ToLower(strings.TrimSpace(source))
+
+		if position, err := strconv.Atoi(source); err == nil {
+			if position < 1 {
+				return nil, errors.New("invalid column index for " + field)
+			}
+			indexes[field] = position - 1
+			continue
+		}
+
+		if position, ok := positions[source]; ok {
+			indexes[field] = position
+		} else if mapped {
+			return nil, errors.New("mapped column " + source + " not found for " + field)
+		}
+	}
+
+	if _, ok := indexes["caller"]; !ok {
+		return nil, errors.New("a caller column mapping is required")
+	}
+	if _, ok := indexes["callee"]; !ok {
+		return nil, errors.New("a callee column mapping is required")
+	}
+
+	return indexes, nil
+}
+
+func importRowToCall(record []string, indexes map[string]int, opts CallImportOptions) (*models.Call, string, error) {
+	value := func(field string) string {
+		i, ok := indexes[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	call := &models.Call{
+		UUID:         value("uuid"),
+		Caller:       value("caller"),
+		Callee:       value("callee"),
+		RecordingURL: value("recording_url"),
+	}
+
+	if call.UUID == "" {
+		call.UUID = uuid.New().String()
+	}
+
+	if !isDialableNumber(call.Caller) {
+		return nil, "caller", errors.New("invalid caller number")
+	}
+	if !isDialableNumber(call.Callee) {
+		return nil, "callee", errors.New("invalid callee number")
+	}
+
+	var err error
+	if call.StartTime, err = parseImportTime(value("start_time"), opts); err != nil {
+		return nil, "start_time", err
+	}
+	if call.AnswerTime, err = parseImportTime(value("answer_time"), opts); err != nil {
+		return nil, "answer_time", err
+	}
+	if call.EndTime, err = parseImportTime(value("end_time"), opts); err != nil {
+		return nil, "end_time", err
+	}
+
+	if billsec := value("billsec"); billsec != "" {
+		if call.Billsec, err = strconv.Atoi(billsec); err != nil || call.Billsec < 0 {
+			return nil, "billsec", errors.New("invalid billsec")
+		}
+	} else if call.AnswerTime != nil && call.EndTime != nil && call.EndTime.After(*call.AnswerTime) {
+		call.Billsec = int(call.EndTime.Sub(*call.AnswerTime).Seconds())
+	}
+
+	outcome := CallOutcome{
+		Direction:   value("direction"),
+		Disposition: value("disposition"),
+		HangupCause: value("hangup_cause"),
+		HangupSide:  value("hangup_side"),
+	}
+	if sipCode := value("sip_code"); sipCode != "" {
+		if outcome.SIPCode, err = strconv.Atoi(sipCode); err != nil {
+			return nil, "sip_code", errors.New("invalid sip code")
+		}
+	}
+	if field, err := outcome.apply(call); err != nil {
+		return nil, field, err
+	}
+
+	return call, "", nil
+}
+
+// parseImportTime reads a timestamp using opts.TimeFormat, which is a Go
+// layout or one of "rfc3339" (the default) and "epoch".
+func parseImportTime(value string, opts CallImportOptions) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	switch opts.TimeFormat {
+	case "", "rfc3339":
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New("unparseable timestamp")
+		}
+		t = t.UTC()
+		return &t, nil
+	case "epoch":
+		t := parseEpoch(value)
+		if t == nil {
+			return nil, errors.New("unparseable timestamp")
+		}
+		return t, nil
+	default:
+		t, err := time.ParseInLocation(opts.TimeFormat, value, opts.Location)
+		if err != nil {
+			return nil, errors.New("unparseable timestamp")
+		}
+		t = t.UTC()
+		return &t, nil
+	}
+}
+
+// isDialableNumber accepts digits with an optional leading "+", ignoring
+// common visual separators.
+func isDialableNumber(number string) bool {
+	number = strings.TrimPrefix(number, "+")
+	digits := 0
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return false
+		}
+	}
+	return digits >= 2 && digits <= 20
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"github.com/your-module/backend/models"
+)
+
+func TestImportCSV(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallImportService(db)
+
+	if _, _, err := service.CallService.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "stored", Caller: "1001", Callee: "1002"}); err != nil {
+		t.Fatalf("storing existing call: %v", err)
+	}
+
+	csv := "UUID,Caller,Callee,Start_Time,Answer_Time,End_Time,Billsec,Recording_URL\n" +
+		"call-1,+1 (415) 555-0100,1002,2023-11-14T22:13:20Z,2023-11-14T22:13:25Z,2023-11-14T22:14:25Z,,https://rec.example.com/1.wav\n" +
+		"call-2,1001,abc,,,,,\n" +
+		"call-3,1001,1002,yesterday,,,,\n" +
+		"call-4,1001,1002,,,,-5,\n" +
+		"call-1,1001,1002,,,,,\n" +
+		"stored,1001,1002,,,,,\n" +
+		"call-5,1001,\"10\"02,,,,,\n" +
+		",1001,1003,,,,7,\n"
+
+	report, err := service.ImportCSV(tenant.ID, strings.NewReader(csv), CallImportOptions{HasHeader: true})
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+
+	if report.TotalRows != 8 || report.Imported != 2 || report.Failed != 6 {
+		t.Errorf("total %d imported %d failed %d, want 8, 2 and 6", report.TotalRows, report.Imported, report.Failed)
+	}
+	want := []CallImportRowError{
+		{Row: 3, Field: "callee", Error: "invalid callee number"},
+		{Row: 4, Field: "start_time", Error: "unparseable timestamp"},
+		{Row: 5, Field: "billsec", Error: "invalid billsec"},
+		{Row: 6, Field: "uuid", Error: "duplicate uuid"},
+		{Row: 8, Error: "malformed csv row"},
+		{Row: 7, Field: "uuid", Error: "duplicate uuid"},
+	}
+	if !reflect.DeepEqual(report.Errors, want) {
+		t.Errorf("errors = %+v, want %+v", report.Errors, want)
+	}
+
+	var call models.Call
+	if err := db.Where("uuid = ?", "call-1").First(&call).Error; err != nil {
+		t.Fatalf("loading imported call: %v", err)
+	}
+	if call.Caller != "+1 (415) 555-0100" || call.RecordingURL != "https://rec.example.com/1.wav" {
+		t.Errorf("imported call = %q, %q", call.Caller, call.RecordingURL)
+	}
+	if call.Billsec != 60 {
+		t.Errorf("billsec = %d, want it derived from answer and end", call.Billsec)
+	}
+
+	var generated models.Call
+	if err := db.Where("callee = ?", "1003").First(&generated).Error; err != nil {
+		t.Fatalf("loading call without uuid: %v", err)
+	}
+	if generated.UUID == "" || generated.Billsec != 7 {
+		t.Errorf("call without uuid = %q, billsec %d", generated.UUID, generated.Billsec)
+	}
+}
+
+func TestImportCSVColumnMapping(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallImportService(db)
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("no zone database: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		csv   string
+		opts  CallImportOptions
+		start string
+	}{
+		{
+			name:  "renamed headers and epoch",
+			csv:   "id,src,dst,calldate\nmapped-1,1001,1002,1700000000\n",
+			opts:  CallImportOptions{HasHeader: true, TimeFormat: "epoch", Columns: map[string]string{"uuid": "id", "caller": "src", "callee": "dst", "start_time": "calldate"}},
+			start: "2023-11-14T22:13:20Z",
+		},
+		{
+			name:  "indexes and layout in a zone",
+			csv:   "2023-11-14 19:13:20,1002,1001,mapped-2\n",
+			opts:  CallImportOptions{TimeFormat: "2006-01-02 15:04:05", Location: saoPaulo, Columns: map[string]string{"uuid": "4", "caller": "3", "callee": "2", "start_time": "1"}},
+			start: "2023-11-14T22:13:20Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := service.ImportCSV(tenant.ID, strings.NewReader(tt.csv), tt.opts)
+			if err != nil {
+				t.Fatalf("ImportCSV: %v", err)
+			}
+			if report.Imported != 1 {
+				t.Fatalf("report = %+v, want one call imported", report)
+			}
+
+			var call models.Call
+			db.Order("id DESC").First(&call)
+			if call.Caller != "1001" || call.Callee != "1002" {
+				t.Errorf("numbers = %q -> %q", call.Caller, call.Callee)
+			}
+			if call.StartTime == nil || call.StartTime.UTC().Format(time.RFC3339) != tt.start {
+				t.Errorf("start = %v, want %s", call.StartTime, tt.start)
+			}
+		})
+	}
+}
+
+func TestImportCSVRejectsFile(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallImportService(db)
+
+	tests := []struct {
+		name string
+		csv  string
+		opts CallImportOptions
+		want string
+	}{
+		{"empty", "", CallImportOptions{HasHeader: true}, "import file is empty"},
+		{"no callee", "caller,to\n1001,1002\n", CallImportOptions{HasHeader: true}, "a callee column mapping is required"},
+		{"missing mapped column", "caller,callee\n1001,1002\n", CallImportOptions{HasHeader: true, Columns: map[string]string{"start_time": "calldate"}}, "mapped column calldate not found for start_time"},
+		{"bad index", "1001,1002\n", CallImportOptions{Columns: map[string]string{"caller": "0", "callee": "2"}}, "invalid column index for caller"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ImportCSV(tenant.ID, strings.NewReader(tt.csv), tt.opts)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+// q850Causes names the Q.850 cause codes switches commonly report, using
+// FreeSWITCH's names. ORIGINATOR_CANCEL is FreeSWITCH's own code for a
+// caller hanging up before answer.
+var q850Causes = map[int]string{
+	1:   "UNALLOCATED_NUMBER",
+	2:   "NO_ROUTE_TRANSIT_NET",
+	3:   "NO_ROUTE_DESTINATION",
+	6:   "CHANNEL_UNACCEPTABLE",
+	16:  "NORMAL_CLEARING",
+	17:  "USER_BUSY",
+	18:  "NO_USER_RESPONSE",
+	19:  "NO_ANSWER",
+	20:  "SUBSCRIBER_ABSENT",
+	21:  "CALL_REJECTED",
+	22:  "NUMBER_CHANGED",
+	27:  "DESTINATION_OUT_OF_ORDER",
+	28:  "INVALID_NUMBER_FORMAT",
+	29:  "FACILITY_REJECTED",
+	31:  "NORMAL_UNSPECIFIED",
+	34:  "NORMAL_CIRCUIT_CONGESTION",
+	38:  "NETWORK_OUT_OF_ORDER",
+	41:  "NORMAL_TEMPORARY_FAILURE",
+	42:  "SWITCH_CONGESTION",
+	44:  "REQUESTED_CHAN_UNAVAIL",
+	50:  "FACILITY_NOT_SUBSCRIBED",
+	52:  "OUTGOING_CALL_BARRED",
+	54:  "INCOMING_CALL_BARRED",
+	57:  "BEARERCAPABILITY_NOTAUTH",
+	58:  "BEARERCAPABILITY_NOTAVAIL",
+	65:  "BEARERCAPABILITY_NOTIMPL",
+	66:  "CHAN_NOT_IMPLEMENTED",
+	69:  "FACILITY_NOT_IMPLEMENTED",
+	79:  "SERVICE_NOT_IMPLEMENTED",
+	88:  "INCOMPATIBLE_DESTINATION",
+	102: "RECOVERY_ON_TIMER_EXPIRE",
+	111: "PROTOCOL_ERROR",
+	127: "INTERWORKING",
+	487: "ORIGINATOR_CANCEL",
+}
+
+var callDirections = map[string]bool{
+	models.CallDirectionInbound:  true,
+	models.CallDirectionOutbound: true,
+	models.CallDirectionInternal: true,
+}
+
+var callDispositions = map[string]bool{
+	models.CallDispositionAnswered: true,
+	models.CallDispositionBusy:     true,
+	models.CallDispositionNoAnswer: true,
+	models.CallDispositionFailed:   true,
+	models.CallDispositionBlocked:  true,
+}
+
+var callHangupSides = map[string]bool{
+	models.CallHangupCaller: true,
+	models.CallHangupCallee: true,
+	models.CallHangupSystem: true,
+}
+
+// CallOutcome is how a call ended, as reported by an API client or an
+// import file. Empty fields are derived from the call where possible.
+type CallOutcome struct {
+	Direction   string `json:"direction"`
+	Disposition string `json:"disposition"`
+	HangupCause string `json:"hangup_cause"`
+	SIPCode     int    `json:"sip_code"`
+	HangupSide  string `json:"hangup_side"`
+}
+
+// apply validates the outcome and copies it onto the call. On error it also
+// returns the name of the offending field.
+func (o CallOutcome) apply(call *models.Call) (string, error) {
+	direction := strings.ToLower(strings.TrimSpace(o.Direction))
+	if direction != "" && !callDirections[direction] {
+		return "direction", errors.New("invalid direction")
+	}
+
+	disposition := strings.ToLower(strings.TrimSpace(o.Disposition))
+	if disposition != "" && !callDispositions[disposition] {
+		return "disposition", errors.New("invalid disposition")
+	}
+
+	side := strings.ToLower(strings.TrimSpace(o.HangupSide))
+	if side != "" && !callHangupSides[side] {
+		return "hangup_side", errors.New("invalid hangup side")
+	}
+
+	if o.SIPCode != 0 && (o.SIPCode < 100 || o.SIPCode > 699) {
+		return "sip_code", errors.New("invalid sip code")
+	}
+
+	if !setHangupCause(call, o.HangupCause) {
+		return "hangup_cause", errors.New("invalid hangup cause")
+	}
+
+	call.Direction = direction
+	call.Disposition = disposition
+	call.HangupSide = side
+	call.SIPCode = o.SIPCode
+	return "", nil
+}
+
+// setHangupCause reads a cause given either as a Q.850 code or by name and
+// fills both fields of the call. An empty value is accepted and changes
+// nothing; names are kept even when not in the table.
+func setHangupCause(call *models.Call, value string) bool {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return true
+	}
+
+	if code, err := strconv.Atoi(value); err == nil {
+		if code <= 0 || code > 999 {
+			return false
+		}
+		call.HangupCauseCode = code
+		call.HangupCause = q850Causes[code]
+		return true
+	}
+
+	if len(value) > 40 || strings.Trim(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") != "" {
+		return false
+	}
+	call.HangupCause = value
+	call.HangupCauseCode = q850CauseCode(value)
+	return true
+}
+
+func q850CauseCode(name string) int {
+	for code, causeName := range q850Causes {
+		if causeName == name {
+			return code
+		}
+	}
+	return 0
+}
+
+// tenantNumberSet is the set of the tenant's phone numbers in E.164, used to
+// tell inbound calls from outbound ones.
+func tenantNumberSet(db *gorm.DB, tenantID uint) (map[string]bool, error) {
+	var numbers []string
+	if err := db.Model(&models.PhoneNumber{}).
+		Where("tenant_id = ?", tenantID).
+		Pluck("number", &numbers).Error; err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool, len(numbers))
+	for _, number := range numbers {
+		set[number] = true
+	}
+	return set, nil
+}
+
+// classifyCall fills in the outcome fields the source did not report. It
+// expects the E.164 fields to be set already.
+func classifyCall(call *models.Call, tenantNumbers map[string]bool) {
+	if call.HangupCause == "" && call.HangupCauseCode != 0 {
+		call.HangupCause = q850Causes[call.HangupCauseCode]
+	}
+	if call.HangupCauseCode == 0 && call.HangupCause != "" {
+		call.HangupCauseCode = q850CauseCode(call.HangupCause)
+	}
+
+	if call.Direction == "" {
+		call.Direction = callDirection(call, tenantNumbers)
+	}
+
+	call.Disposition = callDisposition(call)
+}
+
+// callDirection infers the direction from the numbers: a call to one of
+// the tenant's numbers, or from an outside number to an extension, is
+// inbound; a call between extensions, which do not resolve as phone
+// numbers, is internal; anything else is outbound.
+func callDirection(call *models.Call, tenantNumbers map[string]bool) string {
+	switch {
+	case call.CalleeE164 != "" && tenantNumbers[call.CalleeE164]:
+		return models.CallDirectionInbound
+	case call.CalleeE164 == "" && call.CallerE164 == "":
+		return models.CallDirectionInternal
+	case call.CalleeE164 == "":
+		return models.CallDirectionInbound
+	}
+	return models.CallDirectionOutbound
+}
+
+// callDisposition keeps a blocked disposition and treats any call with an
+// answer time or billable seconds as answered, whatever the source said.
+// Otherwise a reported disposition stands, and failing that the Q.850 cause
+// and SIP code decide. A call with no end time and nothing reported is
+// still in progress and has no disposition yet.
+func callDisposition(call *models.Call) string {
+	if call.Disposition == models.CallDispositionBlocked {
+		return call.Disposition
+	}
+	if call.AnswerTime != nil || call.Billsec > 0 {
+		return models.CallDispositionAnswered
+	}
+	if call.Disposition != "" {
+		return call.Disposition
+	}
+
+	switch call.HangupCauseCode {
+	case 17:
+		return models.CallDispositionBusy
+	case 16, 18, 19, 487:
+		return models.CallDispositionNoAnswer
+	}
+
+	switch call.SIPCode {
+	case 486, 600:
+		return models.CallDispositionBusy
+	case 408, 480, 487:
+		return models.CallDispositionNoAnswer
+	}
+
+	if call.EndTime == nil && call.HangupCauseCode == 0 && call.HangupCause == "" && call.SIPCode == 0 {
+		return ""
+	}
+	return models.CallDispositionFailed
+}
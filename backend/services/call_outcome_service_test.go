@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"github.com/your-module/backend/models"
+)
+
+func TestCallDisposition(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		call models.Call
+		want string
+	}{
+		{"in progress", models.Call{}, ""},
+		{"answered", models.Call{AnswerTime: &now, EndTime: &now}, models.CallDispositionAnswered},
+		{"billed", models.Call{Billsec: 30, EndTime: &now}, models.CallDispositionAnswered},
+		{"answer overrides reported", models.Call{AnswerTime: &now, Disposition: models.CallDispositionNoAnswer}, models.CallDispositionAnswered},
+		{"blocked kept", models.Call{AnswerTime: &now, Disposition: models.CallDispositionBlocked}, models.CallDispositionBlocked},
+		{"reported", models.Call{Disposition: models.CallDispositionBusy, HangupCauseCode: 16}, models.CallDispositionBusy},
+		{"user busy", models.Call{HangupCauseCode: 17}, models.CallDispositionBusy},
+		{"normal clearing unanswered", models.Call{HangupCauseCode: 16}, models.CallDispositionNoAnswer},
+		{"originator cancel", models.Call{HangupCauseCode: 487}, models.CallDispositionNoAnswer},
+		{"sip busy", models.Call{SIPCode: 486}, models.CallDispositionBusy},
+		{"sip declined", models.Call{SIPCode: 600}, models.CallDispositionBusy},
+		{"sip timeout", models.Call{SIPCode: 408}, models.CallDispositionNoAnswer},
+		{"cause before sip code", models.Call{HangupCauseCode: 17, SIPCode: 480}, models.CallDispositionBusy},
+		{"other cause", models.Call{HangupCauseCode: 34}, models.CallDispositionFailed},
+		{"cause name only", models.Call{HangupCause: "CALL_REJECTED"}, models.CallDispositionFailed},
+		{"ended without cause", models.Call{EndTime: &now}, models.CallDispositionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callDisposition(&tt.call); got != tt.want {
+				t.Errorf("callDisposition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallOutcomeApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		outcome   CallOutcome
+		wantField string
+		wantCode  int
+		wantCause string
+	}{
+		{"empty", CallOutcome{}, "", 0, ""},
+		{"cause code", CallOutcome{HangupCause: "17"}, "", 17, "USER_BUSY"},
+		{"cause name", CallOutcome{HangupCause: "normal_clearing"}, "", 16, "NORMAL_CLEARING"},
+		{"unknown cause name", CallOutcome{HangupCause: "LOSE_RACE"}, "", 0, "LOSE_RACE"},
+		{"cause out of range", CallOutcome{HangupCause: "1000"}, "hangup_cause", 0, ""},
+		{"cause with spaces", CallOutcome{HangupCause: "USER BUSY"}, "hangup_cause", 0, ""},
+		{"direction", CallOutcome{Direction: "sideways"}, "direction", 0, ""},
+		{"disposition", CallOutcome{Disposition: "voicemail"}, "disposition", 0, ""},
+		{"hangup side", CallOutcome{HangupSide: "both"}, "hangup_side", 0, ""},
+		{"sip code", CallOutcome{SIPCode: 99}, "sip_code", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var call models.Call
+			field, err := tt.outcome.apply(&call)
+			if field != tt.wantField || (err != nil) != (tt.wantField != "") {
+				t.Fatalf("apply() = %q, %v, want field %q", field, err, tt.wantField)
+			}
+			if err == nil && (call.HangupCauseCode != tt.wantCode || call.HangupCause != tt.wantCause) {
+				t.Errorf("cause = %d %q, want %d %q", call.HangupCauseCode, call.HangupCause, tt.wantCode, tt.wantCause)
+			}
+		})
+	}
+}
+
+func TestCallDirection(t *testing.T) {
+	numbers := map[string]bool{"+14155550100": true}
+
+	tests := []struct {
+		name string
+		call models.Call
+		want string
+	}{
+		{"to a tenant number", models.Call{CallerE164: "+442079460958", CalleeE164: "+14155550100"}, models.CallDirectionInbound},
+		{"outside caller to extension", models.Call{CallerE164: "+442079460958"}, models.CallDirectionInbound},
+		{"extension to extension", models.Call{}, models.CallDirectionInternal},
+		{"to an outside number", models.Call{CalleeE164: "+442079460958"}, models.CallDirectionOutbound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callDirection(&tt.call, numbers); got != tt.want {
+				t.Errorf("callDirection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+const (
+	DefaultCallPageSize = 50
+	MaxCallPageSize     = 500
+)
+
+// AnsweredCallCondition is the SQL predicate for an answered call. Calls
+// created through the API carry only billsec, so either field counts.
+const AnsweredCallCondition = "(answer_time IS NOT NULL OR billsec > 0)"
+
+// RecordedCallCondition is the SQL predicate for a call with a recording
+// that can be fetched, matching models.Call.RemoteRecordingURL.
+const RecordedCallCondition = "COALESCE(recording_key, '') <> '' OR COALESCE(recording_url, '') LIKE 'http://%' OR COALESCE(recording_url, '') LIKE 'https://%'"
+
+// callSortColumns maps the public sort keys to columns. Every sort is
+// tie-broken on id so the keyset cursor is stable.
+var callSortColumns = map[string]string{
+	"start_time": "start_time",
+	"billsec":    "billsec",
+	"cost":       "cost",
+	"created_at": "created_at",
+}
+
+// CallFilter narrows a call search. Nil pointers and empty strings mean "no
+// filter". From/To apply to StartTime. Caller, Callee and Prefix match the
+// raw number or its E.164 form; Country is the callee's country. HangupCause
+// is the Q.850 cause name.
+type CallFilter struct {
+	Caller       string
+	Callee       string
+	Prefix       string
+	Country      string
+	Direction    string
+	Disposition  string
+	HangupCause  string
+	SIPCode      *int
+	From         *time.Time
+	To           *time.Time
+	MinBillsec   *int
+	MaxBillsec   *int
+	Answered     *bool
+	HasRecording *bool
+	Sort         string
+	Order        string
+	Cursor       string
+	Limit        int
+}
+
+type CallPage struct {
+	Calls      []models.Call `json:"calls"`
+	Total      int64         `json:"total"`
+	Limit      int           `json:"limit"`
+	HasMore    bool          `json:"has_more"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// callCursor is the position after the last row of a page.
+type callCursor struct {
+	Sort  string  `json:"s"`
+	Order string  `json:"o"`
+	Value *string `json:"v"`
+	ID    uint    `json:"id"`
+}
+
+// SearchCalls returns one page of the tenant's calls matching the filter,
+// plus the total number of matches.
+func (s *CallService) SearchCalls(tenantID uint, filter CallFilter) (*CallPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = "start_time"
+	}
+	column, ok := callSortColumns[filter.Sort]
+	if !ok {
+		return nil, errors.New("invalid sort field")
+	}
+
+	if filter.Order == "" {
+		filter.Order = "desc"
+	}
+	if filter.Order != "asc" && filter.Order != "desc" {
+		return nil, errors.New("invalid sort order")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultCallPageSize
+	}
+	if filter.Limit > MaxCallPageSize {
+		filter.Limit = MaxCallPageSize
+	}
+
+	query := s.applyCallFilter(s.DB.Model(&models.Call{}).Where("tenant_id = ?", tenantID), filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCallCursor(filter.Cursor)
+		if err != nil || cursor.Sort != filter.Sort || cursor.Order != filter.Order {
+			return nil, errors.New("invalid cursor")
+		}
+		if query, err = applyCallCursor(query, column, filter.Order, cursor); err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+
+	// NULLs (calls without a start time) always sort last.
+	var calls []models.Call
+	if err := query.
+		Order(column + " " + filter.Order + " NULLS LAST").
+		Order("id " + filter.Order).
+		Limit(filter.Limit + 1).
+		Find(&calls).Error; err != nil {
+		return nil, err
+	}
+
+	page := &CallPage{
+		Calls: calls,
+		Total: total,
+		Limit: filter.Limit,
+	}
+
+	if len(calls) > filter.Limit {
+		page.Calls = calls[:filter.Limit]
+		page.HasMore = true
+		page.NextCursor = encodeCallCursor(filter.Sort, filter.Order, page.Calls[len(page.Calls)-1])
+	}
+
+	return page, nil
+}
+
+func (s *CallService) applyCallFilter(query *gorm.DB, filter CallFilter) *gorm.DB {
+	if filter.Caller != "" {
+		query = query.Where("(caller = ? OR caller_e164 = ?)", filter.Caller, filter.Caller)
+	}
+	if filter.Callee != "" {
+		query = query.Where("(callee = ? OR callee_e164 = ?)", filter.Callee, filter.Callee)
+	}
+	if filter.Prefix != "" {
+		query = query.Where("(callee LIKE ? OR callee_e164 LIKE ?)",
+			escapeLike(filter.Prefix)+"%",
+			"+"+escapeLike(strings.TrimPrefix(filter.Prefix, "+"))+"%")
+	}
+	if filter.Country != "" {
+		query = query.Where("callee_country = ?", strings.ToUpper(filter.Country))
+	}
+	if filter.Direction != "" {
+		query = query.Where("direction = ?", filter.Direction)
+	}
+	if filter.Disposition != "" {
+		query = query.Where("disposition = ?", filter.Disposition)
+	}
+	if filter.HangupCause != "" {
+		query = query.Where("hangup_cause = ?", filter.HangupCause)
+	}
+	if filter.SIPCode != nil {
+		query = query.Where("sip_code = ?", *filter.SIPCode)
+	}
+	if filter.From != nil {
+		query = query.Where("start_time >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_time < ?", *filter.To)
+	}
+	if filter.MinBillsec != nil {
+		query = query.Where("billsec >= ?", *filter.MinBillsec)
+	}
+	if filter.MaxBillsec != nil {
+		query = query.Where("billsec <= ?", *filter.MaxBillsec)
+	}
+	if filter.Answered != nil {
+		if *filter.Answered {
+			query = query.Where(AnsweredCallCondition)
+		} else {
+			query = query.Where("NOT " + AnsweredCallCondition)
+		}
+	}
+	if filter.HasRecording != nil {
+		if *filter.HasRecording {
+			query = query.Where(RecordedCallCondition)
+		} else {
+			query = query.Where("NOT (" + RecordedCallCondition + ")")
+		}
+	}
+	return query
+}
+
+// applyCallCursor restricts the query to rows after the cursor for an
+// ORDER BY column <order> NULLS LAST, id <order>.
+func applyCallCursor(query *gorm.DB, column, order string, cursor *callCursor) (*gorm.DB, error) {
+	op := "<"
+	if order == "asc" {
+		op = ">"
+	}
+
+	if cursor.Value == nil {
+		return query.Where(column+" IS NULL AND id "+op+" ?", cursor.ID), nil
+	}
+
+	value, err := cursor.typedValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return query.Where(
+		"("+column+" "+op+" ? OR "+column+" IS NULL OR ("+column+" = ? AND id "+op+" ?))",
+		value, value, cursor.ID,
+	), nil
+}
+
+// typedValue parses the cursor value back into the sort column's type, so
+// the database compares it as a time or a number rather than as text.
+func (c *callCursor) typedValue() (interface{}, error) {
+	switch c.Sort {
+	case "start_time", "created_at":
+		return time.Parse(time.RFC3339Nano, *c.Value)
+	case "billsec":
+		return strconv.Atoi(*c.Value)
+	case "cost":
+		return strconv.ParseFloat(*c.Value, 64)
+	}
+	return nil, errors.New("invalid cursor sort")
+}
+
+func encodeCallCursor(sort, order string, last models.Call) string {
+	cursor := callCursor{Sort: sort, Order: order, ID: last.ID}
+
+	var value string
+	switch sort {
+	case "start_time":
+		if last.StartTime != nil {
+			value = last.StartTime.UTC().Format(time.RFC3339Nano)
+			cursor.Value = &value
+		}
+	case "created_at":
+		value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+		cursor.Value = &value
+	case "billsec":
+		value = strconv.Itoa(last.Billsec)
+		cursor.Value = &value
+	case "cost":
+		value = strconv.FormatFloat(last.Cost, 'f', -1, 64)
+		cursor.Value = &value
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCallCursor(encoded string) (*callCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor callCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func escapeLike(value string) string {
+	escaped := make([]rune, 0, len(value))
+	for _, r := range value {
+		if r == '%' || r == '_' || r == '\\' {
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, r)
+	}
+	return string(escaped)
+}
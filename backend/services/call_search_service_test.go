@@ -0,0 +1,243 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+// createSearchCalls stores calls for the tenant in the given order and
+// returns their IDs.
+func createSearchCalls(t *testing.T, db *gorm.DB, tenantID uint, calls []models.Call) []uint {
+	t.Helper()
+
+	ids := make([]uint, len(calls))
+	for i := range calls {
+		calls[i].TenantID = tenantID
+		if calls[i].UUID == "" {
+			calls[i].UUID = fmt.Sprintf("call-%d", i)
+		}
+		if calls[i].Caller == "" {
+			calls[i].Caller = "1001"
+		}
+		if calls[i].Callee == "" {
+			calls[i].Callee = "1002"
+		}
+		if err := db.Create(&calls[i]).Error; err != nil {
+			t.Fatalf("creating call: %v", err)
+		}
+		ids[i] = calls[i].ID
+	}
+	return ids
+}
+
+// searchAll follows the cursor through every page and returns the IDs in
+// the order they were listed.
+func searchAll(t *testing.T, service *CallService, tenantID uint, filter CallFilter) []uint {
+	t.Helper()
+
+	var ids []uint
+	for pages := 0; ; pages++ {
+		if pages > 20 {
+			t.Fatal("cursor does not advance")
+		}
+		page, err := service.SearchCalls(tenantID, filter)
+		if err != nil {
+			t.Fatalf("SearchCalls: %v", err)
+		}
+		for _, call := range page.Calls {
+			ids = append(ids, call.ID)
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Error("last page has a cursor")
+			}
+			return ids
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+func sameIDs(got, want []uint) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchCallsPagesThroughTies(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallService(db)
+
+	ids := createSearchCalls(t, db, tenant.ID, []models.Call{
+		{Billsec: 30, Cost: 0.3},
+		{Billsec: 10, Cost: 0.1},
+		{Billsec: 30, Cost: 0.3},
+		{Billsec: 0},
+		{Billsec: 30, Cost: 0.3},
+		{Billsec: 10, Cost: 0.1},
+	})
+
+	tests := []struct {
+		sort  string
+		order string
+		want  []uint
+	}{
+		{"billsec", "asc", []uint{ids[3], ids[1], ids[5], ids[0], ids[2], ids[4]}},
+		{"billsec", "desc", []uint{ids[4], ids[2], ids[0], ids[5], ids[1], ids[3]}},
+		{"cost", "asc", []uint{ids[3], ids[1], ids[5], ids[0], ids[2], ids[4]}},
+		{"cost", "desc", []uint{ids[4], ids[2], ids[0], ids[5], ids[1], ids[3]}},
+	}
+
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 4, 10} {
+			t.Run(fmt.Sprintf("%s %s by %d", tt.sort, tt.order, limit), func(t *testing.T) {
+				got := searchAll(t, service, tenant.ID, CallFilter{Sort: tt.sort, Order: tt.order, Limit: limit})
+				if !sameIDs(got, tt.want) {
+					t.Errorf("listed %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchCallsPagesByStartTime(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallService(db)
+
+	early := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	late := early.Add(90 * time.Minute)
+	ids := createSearchCalls(t, db, tenant.ID, []models.Call{
+		{StartTime: &late},
+		{},
+		{StartTime: &early},
+		{StartTime: &late},
+		{},
+		{StartTime: &early},
+	})
+
+	// Calls without a start time come last either way
+	tests := []struct {
+		order string
+		want  []uint
+	}{
+		{"asc", []uint{ids[2], ids[5], ids[0], ids[3], ids[1], ids[4]}},
+		{"desc", []uint{ids[3], ids[0], ids[5], ids[2], ids[4], ids[1]}},
+	}
+
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 3, 5, 10} {
+			t.Run(fmt.Sprintf("%s by %d", tt.order, limit), func(t *testing.T) {
+				got := searchAll(t, service, tenant.ID, CallFilter{Sort: "start_time", Order: tt.order, Limit: limit})
+				if !sameIDs(got, tt.want) {
+					t.Errorf("listed %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchCallsFilters(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewCallService(db)
+
+	day := time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)
+	morning, evening := day.Add(9*time.Hour), day.Add(20*time.Hour)
+	ids := createSearchCalls(t, db, tenant.ID, []models.Call{
+		{Caller: "1001", Callee: "+442079460958", StartTime: &morning, AnswerTime: &morning, Billsec: 60, RecordingURL: "https://rec.example.com/1.wav"},
+		{Caller: "1002", Callee: "+14155550100", StartTime: &evening},
+		{Caller: "1001", Callee: "+442071838750", StartTime: &evening, Billsec: 5},
+	})
+	createSearchCalls(t, db, other.ID, []models.Call{{UUID: "other", Caller: "1001", Callee: "+442079460958"}})
+
+	answered, unanswered := true, false
+	minBillsec, maxBillsec := 5, 30
+	noon := day.Add(12 * time.Hour)
+
+	tests := []struct {
+		name   string
+		filter CallFilter
+		want   []uint
+	}{
+		{"tenant only", CallFilter{Sort: "billsec"}, []uint{ids[0], ids[2], ids[1]}},
+		{"caller", CallFilter{Caller: "1001", Sort: "billsec"}, []uint{ids[0], ids[2]}},
+		{"prefix", CallFilter{Prefix: "+44", Sort: "billsec"}, []uint{ids[0], ids[2]}},
+		{"from", CallFilter{From: &noon, Sort: "billsec"}, []uint{ids[2], ids[1]}},
+		{"to", CallFilter{To: &noon, Sort: "billsec"}, []uint{ids[0]}},
+		{"billsec range", CallFilter{MinBillsec: &minBillsec, MaxBillsec: &maxBillsec, Sort: "billsec"}, []uint{ids[2]}},
+		{"answered", CallFilter{Answered: &answered, Sort: "billsec"}, []uint{ids[0], ids[2]}},
+		{"unanswered", CallFilter{Answered: &unanswered, Sort: "billsec"}, []uint{ids[1]}},
+		{"recorded", CallFilter{HasRecording: &answered, Sort: "billsec"}, []uint{ids[0]}},
+		{"not recorded", CallFilter{HasRecording: &unanswered, Sort: "billsec"}, []uint{ids[2], ids[1]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.SearchCalls(tenant.ID, tt.filter)
+			if err != nil {
+				t.Fatalf("SearchCalls: %v", err)
+			}
+			var got []uint
+			for _, call := range page.Calls {
+				got = append(got, call.ID)
+			}
+			if !sameIDs(got, tt.want) {
+				t.Errorf("listed %v, want %v", got, tt.want)
+			}
+			if page.Total != int64(len(tt.want)) {
+				t.Errorf("total = %d, want %d", page.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+// encodeCallCursorValue builds a cursor with the raw value given.
+func encodeCallCursorValue(sort, order, value string) string {
+	data, _ := json.Marshal(callCursor{Sort: sort, Order: order, Value: &value, ID: 1})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestSearchCallsRejects(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallService(db)
+	createSearchCalls(t, db, tenant.ID, []models.Call{{Billsec: 1}, {Billsec: 2}})
+
+	page, err := service.SearchCalls(tenant.ID, CallFilter{Sort: "billsec", Limit: 1})
+	if err != nil {
+		t.Fatalf("SearchCalls: %v", err)
+	}
+
+	tests := map[string]CallFilter{
+		"sort":                  {Sort: "caller"},
+		"order":                 {Order: "sideways"},
+		"cursor":                {Cursor: "not a cursor"},
+		"cursor of other sort":  {Sort: "cost", Cursor: page.NextCursor},
+		"cursor of other order": {Sort: "billsec", Order: "asc", Cursor: page.NextCursor},
+		"cursor value":          {Sort: "billsec", Cursor: encodeCallCursorValue("billsec", "desc", "ten")},
+	}
+	for name, filter := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := service.SearchCalls(tenant.ID, filter); err == nil {
+				t.Error("SearchCalls accepted the filter")
+			}
+		})
+	}
+
+	if page, err := service.SearchCalls(tenant.ID, CallFilter{Limit: MaxCallPageSize + 1}); err != nil || page.Limit != MaxCallPageSize {
+		t.Errorf("oversized limit: %v, %v", page, err)
+	}
+}
@@ -2,8 +2,13 @@ package services
 
 import (
 	"errors"
+	"log"
+	"time"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/phonenumber"
 )
 
 type CallService struct {
@@ -14,22 +19,385 @@ func NewCallService(db *gorm.DB) *CallService {
 	return &CallService{DB: db}
 }
 
-func (s *CallService) CreateCall(tenantID uint, caller string, callee string, duration uint, recordingURL string) (*models.Call, error) {
+func (s *CallService) CreateCall(tenantID uint, caller string, callee string, duration uint, recordingURL string, outcome CallOutcome) (*models.Call, error) {
+	// Listings, reports and retention all go by start time
+	now := time.Now()
 	call := models.Call{
 		TenantID:     tenantID,
+		UUID:         uuid.New().String(),
 		Caller:       caller,
 		Callee:       callee,
+		StartTime:    &now,
 		Billsec:      int(duration),
 		RecordingURL: recordingURL,
 	}
+	if _, err := outcome.apply(&call); err != nil {
+		return nil, err
+	}
+
+	country, err := s.tenantDefaultCountry(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	normalizeCallNumbers(&call, country)
+
+	numbers, err := tenantNumberSet(s.DB, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	classifyCall(&call, numbers)
+
+	if err := NewRatingService(s.DB).RateCall(&call); err != nil {
+		return nil, err
+	}
 
 	if err := s.DB.Create(&call).Error; err != nil {
 		return nil, err
 	}
 
+	s.evaluateFraud(&call)
+
 	return &call, nil
 }
 
+// UpsertCallByUUID stores a call reported by a switch. Reports are keyed by
+// UUID, so a retried or repeated report updates the existing record instead
+// of creating a duplicate. The returned flag is true when a new row was inserted.
+func (s *CallService) UpsertCallByUUID(tenantID uint, record *models.Call) (*models.Call, bool, error) {
+	if record.UUID == "" {
+		return nil, false, errors.New("call uuid is required")
+	}
+
+	record.TenantID = tenantID
+	reported := record.Disposition
+
+	country, err := s.tenantDefaultCountry(tenantID)
+	if err != nil {
+		return nil, false, err
+	}
+	normalizeCallNumbers(record, country)
+
+	numbers, err := tenantNumberSet(s.DB, tenantID)
+	if err != nil {
+		return nil, false, err
+	}
+	classifyCall(record, numbers)
+
+	if err := NewRatingService(s.DB).RateCall(record); err != nil {
+		return nil, false, err
+	}
+
+	result := s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "uuid"}},
+		DoNothing: true,
+	}).Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+
+	if result.RowsAffected == 1 {
+		if err := linkCallEvents(s.DB, record); err != nil {
+			log.Printf("call events: linking call %s: %v", record.UUID, err)
+		}
+		s.evaluateFraud(record)
+		s.releaseAdmission(record)
+		return record, true, nil
+	}
+
+	var existing models.Call
+	if err := s.DB.Unscoped().Where("uuid = ?", record.UUID).
+		First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+
+	if existing.TenantID != tenantID {
+		return nil, false, errors.New("call uuid belongs to another tenant")
+	}
+
+	merged := mergeCallReport(&existing, record, reported)
+	normalizeCallNumbers(merged, country)
+	if merged.Direction == "" {
+		merged.Direction = callDirection(merged, numbers)
+	}
+	merged.Disposition = callDisposition(merged)
+	if err := NewRatingService(s.DB).RateCall(merged); err != nil {
+		return nil, false, err
+	}
+
+	if err := s.DB.Unscoped().Model(&existing).Select(
+		"Caller", "Callee", "CallerE164", "CallerCountry", "CallerType", "CalleeE164", "CalleeCountry", "CalleeType",
+		"StartTime", "AnswerTime", "EndTime", "Billsec", "Cost", "RecordingURL", "Direction", "Disposition",
+		"HangupCause", "HangupCauseCode", "SIPCode", "HangupSide",
+	).Updates(merged).Error; err != nil {
+		return nil, false, err
+	}
+
+	// Fraud was checked when the call was first stored; repeated reports
+	// would raise the same alert again
+	s.releaseAdmission(merged)
+
+	return merged, false, nil
+}
+
+// mergeCallReport applies a later report of a stored call. Reports can be
+// partial, such as a hangup notice without the answer time, so only what
+// the report has replaces the stored values. A stored direction is kept,
+// and so is the stored disposition unless the report gives one; a blocked
+// call stays blocked.
+func mergeCallReport(existing, record *models.Call, reportedDisposition string) *models.Call {
+	merged := *existing
+
+	if record.Caller != "" {
+		merged.Caller = record.Caller
+	}
+	if record.Callee != "" {
+		merged.Callee = record.Callee
+	}
+	if record.StartTime != nil {
+		merged.StartTime = record.StartTime
+	}
+	if record.AnswerTime != nil {
+		merged.AnswerTime = record.AnswerTime
+	}
+	if record.EndTime != nil {
+		merged.EndTime = record.EndTime
+	}
+	if record.Billsec > 0 {
+		merged.Billsec = record.Billsec
+	}
+	if record.RecordingURL != "" {
+		merged.RecordingURL = record.RecordingURL
+	}
+	if merged.Direction == "" {
+		merged.Direction = record.Direction
+	}
+	if reportedDisposition != "" && existing.Disposition != models.CallDispositionBlocked {
+		merged.Disposition = reportedDisposition
+	}
+	if record.HangupCause != "" || record.HangupCauseCode != 0 {
+		merged.HangupCause = record.HangupCause
+		merged.HangupCauseCode = record.HangupCauseCode
+	}
+	if record.SIPCode != 0 {
+		merged.SIPCode = record.SIPCode
+	}
+	if record.HangupSide != "" {
+		merged.HangupSide = record.HangupSide
+	}
+
+	return &merged
+}
+
+// InsertCallBatch rates and inserts a batch of calls for the tenant. Calls
+// whose UUID already exists, or is stored concurrently, are skipped and
+// returned in the duplicates set.
+func (s *CallService) InsertCallBatch(tenantID uint, calls []*models.Call) (map[string]bool, error) {
+	duplicates := map[string]bool{}
+	if len(calls) == 0 {
+		return duplicates, nil
+	}
+
+	uuids := make([]string, 0, len(calls))
+	for _, call := range calls {
+		uuids = append(uuids, call.UUID)
+	}
+
+	var existing []string
+	if err := s.DB.Unscoped().Model(&models.Call{}).
+		Where("uuid IN ?", uuids).
+		Pluck("uuid", &existing).Error; err != nil {
+		return nil, err
+	}
+	for _, existingUUID := range existing {
+		duplicates[existingUUID] = true
+	}
+
+	country, err := s.tenantDefaultCountry(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers, err := tenantNumberSet(s.DB, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	rating := NewRatingService(s.DB)
+	batch := make([]*models.Call, 0, len(calls))
+	seen := map[string]bool{}
+	for _, call := range calls {
+		if duplicates[call.UUID] || seen[call.UUID] {
+			duplicates[call.UUID] = true
+			continue
+		}
+		seen[call.UUID] = true
+
+		call.TenantID = tenantID
+		normalizeCallNumbers(call, country)
+		classifyCall(call, numbers)
+		if err := rating.RateCall(call); err != nil {
+			return nil, err
+		}
+		batch = append(batch, call)
+	}
+
+	if len(batch) == 0 {
+		return duplicates, nil
+	}
+
+	// A call stored by another report between the check above and the
+	// insert is skipped by the conflict clause without saying which. The
+	// batch is then rolled back and inserted call by call to find out.
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "uuid"}},
+			DoNothing: true,
+		}).Create(&batch)
+		if result.Error != nil {
+			return result.Error
+		}
+		if int(result.RowsAffected) != len(batch) {
+			return errCallBatchConflict
+		}
+		return nil
+	})
+	if errors.Is(err, errCallBatchConflict) {
+		for _, call := range batch {
+			call.ID = 0
+			result := s.DB.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "uuid"}},
+				DoNothing: true,
+			}).Create(call)
+			if result.Error != nil {
+				return nil, result.Error
+			}
+			if result.RowsAffected == 0 {
+				call.ID = 0
+				duplicates[call.UUID] = true
+			}
+		}
+		return duplicates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+var errCallBatchConflict = errors.New("call batch conflicts with stored calls")
+
+// evaluateFraud runs fraud detection on a stored call. Detection problems
+// are logged rather than failing the call, which has already been recorded.
+func (s *CallService) evaluateFraud(call *models.Call) {
+	if _, err := NewFraudService(s.DB).EvaluateCall(call); err != nil {
+		log.Printf("fraud: evaluating call %s: %v", call.UUID, err)
+	}
+}
+
+// releaseAdmission frees the call's concurrent call slot once the switch
+// reports it ended, covering hangups the event socket missed.
+func (s *CallService) releaseAdmission(call *models.Call) {
+	if call.EndTime == nil {
+		return
+	}
+	if err := NewAdmissionService(s.DB).Release(call.UUID, &call.TenantID); err != nil {
+		log.Printf("admission: releasing call %s: %v", call.UUID, err)
+	}
+}
+
+func (s *CallService) tenantDefaultCountry(tenantID uint) (string, error) {
+	var tenant models.Tenant
+	if err := s.DB.Select("id, default_country").First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("tenant not found")
+		}
+		return "", err
+	}
+	return tenant.DefaultCountry, nil
+}
+
+// normalizeCallNumbers fills the E.164 fields from the raw caller and
+// callee. Numbers that cannot be resolved leave the fields empty.
+func normalizeCallNumbers(call *models.Call, defaultCountry string) {
+	caller, _ := phonenumber.Normalize(call.Caller, defaultCountry)
+	call.CallerE164 = caller.E164
+	call.CallerCountry = caller.Country
+	call.CallerType = caller.Type
+
+	callee, _ := phonenumber.Normalize(call.Callee, defaultCountry)
+	call.CalleeE164 = callee.E164
+	call.CalleeCountry = callee.Country
+	call.CalleeType = callee.Type
+}
+
+// NormalizeCalls recomputes the E.164 fields of all the tenant's calls, for
+// example after its default country changes. Calls whose numbers resolve
+// differently are classified and rated again. It returns how many calls
+// changed.
+func (s *CallService) NormalizeCalls(tenantID uint) (int, error) {
+	country, err := s.tenantDefaultCountry(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	numbers, err := tenantNumberSet(s.DB, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	rating := NewRatingService(s.DB)
+
+	var calls []models.Call
+	changed := 0
+
+	result := s.DB.Where("tenant_id = ?", tenantID).
+		FindInBatches(&calls, 500, func(tx *gorm.DB, batch int) error {
+			for i := range calls {
+				previous := calls[i]
+				normalizeCallNumbers(&calls[i], country)
+				if calls[i].CallerE164 == previous.CallerE164 && calls[i].CalleeE164 == previous.CalleeE164 &&
+					calls[i].CallerCountry == previous.CallerCountry && calls[i].CalleeCountry == previous.CalleeCountry &&
+					calls[i].CallerType == previous.CallerType && calls[i].CalleeType == previous.CalleeType {
+					continue
+				}
+
+				updates := map[string]interface{}{
+					"caller_e164":    calls[i].CallerE164,
+					"caller_country": calls[i].CallerCountry,
+					"caller_type":    calls[i].CallerType,
+					"callee_e164":    calls[i].CalleeE164,
+					"callee_country": calls[i].CalleeCountry,
+					"callee_type":    calls[i].CalleeType,
+				}
+
+				if calls[i].CallerE164 != previous.CallerE164 || calls[i].CalleeE164 != previous.CalleeE164 {
+					// A direction that matches what the old numbers gave was
+					// inferred rather than reported, so infer it again
+					if previous.Direction == callDirection(&previous, numbers) {
+						calls[i].Direction = ""
+					}
+					classifyCall(&calls[i], numbers)
+					if err := rating.RateCall(&calls[i]); err != nil {
+						return err
+					}
+					updates["direction"] = calls[i].Direction
+					updates["cost"] = calls[i].Cost
+				}
+
+				if err := s.DB.Model(&calls[i]).UpdateColumns(updates).Error; err != nil {
+					return err
+				}
+				changed++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return changed, result.Error
+	}
+
+	return changed, nil
+}
+
 func (s *CallService) GetAllCalls(tenantID uint) ([]models.Call, error) {
 	var calls []models.Call
 	
@@ -44,7 +412,9 @@ func (s *CallService) GetAllCalls(tenantID uint) ([]models.Call, error) {
 func (s *CallService) GetCallByID(tenantID uint, callID uint) (*models.Call, error) {
 	var call models.Call
 	
-	if err := s.DB.Where("id = ? AND tenant_id = ?", callID, tenantID).
+	if err := s.DB.Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("occurred_at, id")
+	}).Where("id = ? AND tenant_id = ?", callID, tenantID).
 		First(&call).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("call not found")
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+func TestCreateCall(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+
+	before := time.Now()
+	call, err := NewCallService(db).CreateCall(tenant.ID, "1001", "1002", 30, "", CallOutcome{})
+	if err != nil {
+		t.Fatalf("CreateCall: %v", err)
+	}
+
+	var stored models.Call
+	db.First(&stored, call.ID)
+	if stored.StartTime == nil || stored.StartTime.Before(before.Add(-time.Second)) || stored.StartTime.After(time.Now().Add(time.Second)) {
+		t.Errorf("start time = %v, want the time the call was created", stored.StartTime)
+	}
+}
+
+func TestUpsertCallByUUIDRepeatedReport(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallService(db)
+
+	start := time.Unix(1700000000, 0).UTC()
+	first, created, err := service.UpsertCallByUUID(tenant.ID, &models.Call{
+		UUID: "call-1", Caller: "1001", Callee: "1002", StartTime: &start, RecordingURL: "https://switch.example.com/call-1.wav",
+	})
+	if err != nil || !created {
+		t.Fatalf("first report: created %v, %v", created, err)
+	}
+
+	end := start.Add(time.Minute)
+	second, created, err := service.UpsertCallByUUID(tenant.ID, &models.Call{
+		UUID: "call-1", Caller: "1001", Callee: "1002", StartTime: &start, EndTime: &end, Billsec: 55,
+	})
+	if err != nil {
+		t.Fatalf("repeated report: %v", err)
+	}
+	if created {
+		t.Error("repeated report was reported as created")
+	}
+	if second.ID != first.ID {
+		t.Errorf("repeated report returned call %d, want %d", second.ID, first.ID)
+	}
+
+	var calls []models.Call
+	db.Find(&calls)
+	if len(calls) != 1 {
+		t.Fatalf("%d calls stored for one uuid", len(calls))
+	}
+	if calls[0].Billsec != 55 || calls[0].EndTime == nil {
+		t.Errorf("repeated report not applied: billsec %d, end %v", calls[0].Billsec, calls[0].EndTime)
+	}
+	if calls[0].RecordingURL != "https://switch.example.com/call-1.wav" {
+		t.Errorf("recording url = %q, want the first report's kept", calls[0].RecordingURL)
+	}
+}
+
+func TestUpsertCallByUUIDPartialReport(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallService(db)
+
+	start := time.Unix(1700000000, 0).UTC()
+	answer := start.Add(5 * time.Second)
+	end := answer.Add(time.Minute)
+	if _, _, err := service.UpsertCallByUUID(tenant.ID, &models.Call{
+		UUID: "call-1", Caller: "+14155550100", Callee: "1002", Direction: models.CallDirectionInbound,
+		StartTime: &start, AnswerTime: &answer, EndTime: &end, Billsec: 60,
+	}); err != nil {
+		t.Fatalf("first report: %v", err)
+	}
+
+	// A later hangup notice knows neither the answer nor the direction
+	call, _, err := service.UpsertCallByUUID(tenant.ID, &models.Call{
+		UUID: "call-1", Caller: "+14155550100", Callee: "+14155550199", EndTime: &end,
+		HangupCause: "NORMAL_CLEARING", SIPCode: 200, HangupSide: "caller",
+	})
+	if err != nil {
+		t.Fatalf("partial report: %v", err)
+	}
+
+	var stored models.Call
+	db.First(&stored, call.ID)
+	for _, got := range []models.Call{*call, stored} {
+		if got.Disposition != models.CallDispositionAnswered || got.AnswerTime == nil || got.Billsec != 60 {
+			t.Errorf("answer lost: disposition %q, answer %v, billsec %d", got.Disposition, got.AnswerTime, got.Billsec)
+		}
+		if got.Direction != models.CallDirectionInbound {
+			t.Errorf("direction = %q, want the stored one kept", got.Direction)
+		}
+		if got.Callee != "+14155550199" || got.CalleeE164 != "+14155550199" || got.SIPCode != 200 || got.HangupCauseCode != 16 || got.HangupSide != "caller" {
+			t.Errorf("reported fields not applied: %+v", got)
+		}
+	}
+}
+
+func TestUpsertCallByUUIDOtherTenant(t *testing.T) {
+	db := newTestDB(t)
+	acme := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewCallService(db)
+
+	if _, _, err := service.UpsertCallByUUID(acme.ID, &models.Call{UUID: "call-1", Caller: "1001", Callee: "1002"}); err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+
+	_, _, err := service.UpsertCallByUUID(other.ID, &models.Call{UUID: "call-1", Caller: "666", Callee: "667", Billsec: 99})
+	if err == nil || err.Error() != "call uuid belongs to another tenant" {
+		t.Fatalf("error = %v, want the other tenant refused", err)
+	}
+
+	var call models.Call
+	db.Where("uuid = ?", "call-1").First(&call)
+	if call.TenantID != acme.ID || call.Caller != "1001" || call.Billsec != 0 {
+		t.Errorf("call changed by another tenant's report: %+v", call)
+	}
+}
+
+func TestUpsertCallByUUIDRequiresUUID(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+
+	if _, _, err := NewCallService(db).UpsertCallByUUID(tenant.ID, &models.Call{Caller: "1001", Callee: "1002"}); err == nil {
+		t.Error("UpsertCallByUUID accepted a call without uuid")
+	}
+}
+
+func TestInsertCallBatch(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCallService(db)
+
+	if _, _, err := service.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "stored", Caller: "1001", Callee: "1002"}); err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+
+	// Another report stores call-2 after the batch looked for duplicates
+	raced := false
+	db.Callback().Query().After("gorm:query").Register("test:store_concurrently", func(tx *gorm.DB) {
+		if tx.Statement.Table == "calls" && !raced {
+			raced = true
+			db.Create(&models.Call{TenantID: tenant.ID, UUID: "call-2", Caller: "1003", Callee: "1004"})
+		}
+	})
+
+	calls := []*models.Call{
+		{UUID: "call-1", Caller: "1001", Callee: "1002"},
+		{UUID: "stored", Caller: "1001", Callee: "1002"},
+		{UUID: "call-2", Caller: "1001", Callee: "1002"},
+		{UUID: "call-1", Caller: "1001", Callee: "1002"},
+		{UUID: "call-3", Caller: "1001", Callee: "1002"},
+	}
+	duplicates, err := service.InsertCallBatch(tenant.ID, calls)
+	if err != nil {
+		t.Fatalf("InsertCallBatch: %v", err)
+	}
+	if !raced {
+		t.Fatal("no concurrent report was simulated")
+	}
+	if len(duplicates) != 3 || !duplicates["stored"] || !duplicates["call-2"] || !duplicates["call-1"] {
+		t.Errorf("duplicates = %v, want stored, call-2 and the repeated call-1", duplicates)
+	}
+
+	var stored []models.Call
+	db.Order("uuid").Find(&stored)
+	if len(stored) != 4 {
+		t.Fatalf("%d calls stored, want 4", len(stored))
+	}
+	if stored[1].UUID != "call-2" || stored[1].Caller != "1003" {
+		t.Errorf("concurrently stored call = %+v, want it kept", stored[1])
+	}
+	if calls[0].ID == 0 || calls[4].ID == 0 || calls[2].ID != 0 {
+		t.Errorf("call ids = %d, %d and %d, want the inserted calls' only", calls[0].ID, calls[4].ID, calls[2].ID)
+	}
+}
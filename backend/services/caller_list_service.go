@@ -0,0 +1,473 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/phonenumber"
+)
+
+const (
+	callerListImportBatchSize = 1000
+	maxCallerPatternDigits    = 20
+)
+
+// CallerListService manages the tenant's caller ID block and allow lists
+// and screens callers against them.
+type CallerListService struct {
+	DB *gorm.DB
+}
+
+func NewCallerListService(db *gorm.DB) *CallerListService {
+	return &CallerListService{DB: db}
+}
+
+// cleanCallerPattern drops the separators people write numbers with.
+func cleanCallerPattern(pattern string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(pattern))
+}
+
+// isCallerNumber accepts digits with an optional leading +.
+func isCallerNumber(value string) bool {
+	digits := strings.TrimPrefix(value, "+")
+	return isDigits(digits) && len(digits) <= maxCallerPatternDigits
+}
+
+// normalizeCallerPattern checks the entry's pattern against its match type
+// and sets the stored and LIKE forms. Exact numbers are stored in E.164
+// when they parse, so "011 5555 1234" and "+5511..." are the same entry.
+func normalizeCallerPattern(entry *models.CallerListEntry, defaultCountry string) error {
+	pattern := cleanCallerPattern(entry.Pattern)
+	if pattern == "" {
+		return errors.New("pattern is required")
+	}
+
+	switch entry.MatchType {
+	case models.CallerMatchExact:
+		if number, ok := phonenumber.Normalize(pattern, defaultCountry); ok {
+			pattern = number.E164
+		} else if !isCallerNumber(pattern) {
+			return errors.New("invalid pattern")
+		}
+		entry.LikePattern = pattern
+
+	case models.CallerMatchPrefix:
+		if !isCallerNumber(pattern) {
+			return errors.New("invalid pattern")
+		}
+		entry.LikePattern = pattern + "%"
+
+	case models.CallerMatchWildcard:
+		if !strings.ContainsAny(pattern, "*?") {
+			return errors.New("wildcard pattern needs a * or ?")
+		}
+		digits := 0
+		for _, r := range strings.TrimPrefix(pattern, "+") {
+			switch {
+			case r >= '0' && r <= '9':
+				digits++
+			case r == '*' || r == '?':
+			default:
+				return errors.New("invalid pattern")
+			}
+		}
+		// A pattern of wildcards alone would match every caller
+		if digits == 0 {
+			return errors.New("wildcard pattern needs at least one digit")
+		}
+		if len(pattern) > maxCallerPatternDigits+1 {
+			return errors.New("invalid pattern")
+		}
+		entry.LikePattern = strings.NewReplacer("*", "%", "?", "_").Replace(pattern)
+
+	default:
+		return errors.New("invalid match type")
+	}
+
+	entry.Pattern = pattern
+	return nil
+}
+
+func validateCallerListEntry(entry *models.CallerListEntry, defaultCountry string, now time.Time) error {
+	if entry.ListType != models.CallerListBlock && entry.ListType != models.CallerListAllow {
+		return errors.New("invalid list type")
+	}
+
+	if entry.MatchType == "" {
+		entry.MatchType = models.CallerMatchExact
+	}
+	if err := normalizeCallerPattern(entry, defaultCountry); err != nil {
+		return err
+	}
+
+	entry.Reason = strings.TrimSpace(entry.Reason)
+	if len(entry.Reason) > 255 {
+		return errors.New("reason is too long")
+	}
+
+	if entry.ExpiresAt != nil && !entry.ExpiresAt.After(now) {
+		return errors.New("expiry must be in the future")
+	}
+
+	return nil
+}
+
+func (s *CallerListService) tenant(tenantID uint) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := s.DB.First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (s *CallerListService) entryExists(entry *models.CallerListEntry, excludeID uint) (bool, error) {
+	var count int64
+	if err := s.DB.Model(&models.CallerListEntry{}).
+		Where("tenant_id = ? AND list_type = ? AND match_type = ? AND pattern = ? AND id <> ?",
+			entry.TenantID, entry.ListType, entry.MatchType, entry.Pattern, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *CallerListService) CreateEntry(tenantID uint, userID *uint, entry *models.CallerListEntry) (*models.CallerListEntry, error) {
+	tenant, err := s.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.ID = 0
+	entry.TenantID = tenantID
+	entry.CreatedBy = userID
+	if err := validateCallerListEntry(entry, tenant.DefaultCountry, time.Now()); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.entryExists(entry, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("entry already exists")
+	}
+
+	if err := s.DB.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetEntries lists the tenant's entries, optionally of one list. Expired
+// entries are left out unless includeExpired is set.
+func (s *CallerListService) GetEntries(tenantID uint, listType string, includeExpired bool) ([]models.CallerListEntry, error) {
+	query := s.DB.Where("tenant_id = ?", tenantID)
+	if listType != "" {
+		query = query.Where("list_type = ?", listType)
+	}
+	if !includeExpired {
+		query = query.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	}
+
+	var entries []models.CallerListEntry
+	if err := query.Order("list_type, pattern, id").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *CallerListService) GetEntryByID(tenantID, entryID uint) (*models.CallerListEntry, error) {
+	var entry models.CallerListEntry
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", entryID, tenantID).
+		First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("caller list entry not found")
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (s *CallerListService) UpdateEntry(tenantID, entryID uint, changes *models.CallerListEntry) (*models.CallerListEntry, error) {
+	entry, err := s.GetEntryByID(tenantID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.TenantID = tenantID
+	if err := validateCallerListEntry(changes, tenant.DefaultCountry, time.Now()); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.entryExists(changes, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("entry already exists")
+	}
+
+	if err := s.DB.Model(entry).Updates(map[string]interface{}{
+		"list_type":    changes.ListType,
+		"match_type":   changes.MatchType,
+		"pattern":      changes.Pattern,
+		"like_pattern": changes.LikePattern,
+		"reason":       changes.Reason,
+		"expires_at":   changes.ExpiresAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *CallerListService) DeleteEntry(tenantID, entryID uint) error {
+	entry, err := s.GetEntryByID(tenantID, entryID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Delete(entry).Error
+}
+
+// callerForms are the caller ID as received, without separators, and in
+// E.164, which is how exact entries are stored.
+func callerForms(caller, defaultCountry string) []string {
+	var forms []string
+	seen := map[string]bool{}
+	add := func(form string) {
+		if form != "" && !seen[form] {
+			seen[form] = true
+			forms = append(forms, form)
+		}
+	}
+
+	add(strings.TrimSpace(caller))
+	add(cleanCallerPattern(caller))
+	if number, ok := phonenumber.Normalize(caller, defaultCountry); ok {
+		add(number.E164)
+	}
+
+	return forms
+}
+
+// Screen returns the block entry that stops the caller at the given time,
+// or nil if the caller may go through: either no unexpired block entry
+// matches, or an unexpired allow entry does.
+func (s *CallerListService) Screen(tenant *models.Tenant, caller string, at time.Time) (*models.CallerListEntry, error) {
+	forms := callerForms(caller, tenant.DefaultCountry)
+	if len(forms) == 0 {
+		return nil, nil
+	}
+
+	conditions := make([]string, len(forms))
+	args := make([]interface{}, len(forms))
+	for i, form := range forms {
+		conditions[i] = "? LIKE like_pattern"
+		args[i] = form
+	}
+
+	var entries []models.CallerListEntry
+	if err := s.DB.Where("tenant_id = ?", tenant.ID).
+		Where("expires_at IS NULL OR expires_at > ?", at).
+		Where(strings.Join(conditions, " OR "), args...).
+		Order("id").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	var blocked *models.CallerListEntry
+	for i := range entries {
+		if entries[i].ListType == models.CallerListAllow {
+			return nil, nil
+		}
+		if blocked == nil {
+			blocked = &entries[i]
+		}
+	}
+
+	return blocked, nil
+}
+
+// recordBlockedCall keeps a call the blocklist rejected, so blocked
+// attempts show up in call history and reports. A call without a UUID gets
+// a new one; a UUID already recorded is left alone.
+func recordBlockedCall(db *gorm.DB, tenant *models.Tenant, callUUID, caller, callee string) error {
+	if callUUID == "" {
+		callUUID = uuid.New().String()
+	}
+
+	now := time.Now()
+	call := models.Call{
+		TenantID:        tenant.ID,
+		UUID:            callUUID,
+		Caller:          caller,
+		Callee:          callee,
+		StartTime:       &now,
+		EndTime:         &now,
+		Disposition:     models.CallDispositionBlocked,
+		HangupCauseCode: 21,
+		HangupSide:      models.CallHangupSystem,
+	}
+	normalizeCallNumbers(&call, tenant.DefaultCountry)
+
+	numbers, err := tenantNumberSet(db, tenant.ID)
+	if err != nil {
+		return err
+	}
+	classifyCall(&call, numbers)
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "uuid"}},
+		DoNothing: true,
+	}).Create(&call).Error
+}
+
+// ImportCSV adds or updates entries from a CSV with a header row. The list
+// and pattern columns are required; match_type (default exact), reason and
+// expires_at (RFC 3339 or YYYY-MM-DD) are optional. A row for an existing
+// entry updates its reason and expiry. The report has the same shape as a
+// call import's.
+func (s *CallerListService) ImportCSV(tenantID uint, userID *uint, r io.Reader) (*CallImportReport, error) {
+	tenant, err := s.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("import file is empty")
+	}
+	if err != nil {
+		return nil, errors.New("invalid import csv header")
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"list", "pattern"} {
+		if _, ok := columns[required]; !ok {
+			return nil, errors.New("a " + required + " column is required")
+		}
+	}
+
+	report := &CallImportReport{Errors: []CallImportRowError{}}
+	batch := map[string]*models.CallerListEntry{}
+	now := time.Now()
+
+	flush := func() error {
+		entries := make([]*models.CallerListEntry, 0, len(batch))
+		for _, entry := range batch {
+			entries = append(entries, entry)
+		}
+		if err := s.DB.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tenant_id"}, {Name: "list_type"}, {Name: "match_type"}, {Name: "pattern"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"like_pattern", "reason", "expires_at", "updated_at",
+			}),
+		}).Create(&entries).Error; err != nil {
+			return err
+		}
+		report.Imported += len(entries)
+		batch = map[string]*models.CallerListEntry{}
+		return nil
+	}
+
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				report.TotalRows++
+				report.addError(row, "", "malformed csv row")
+				continue
+			}
+			return nil, err
+		}
+		report.TotalRows++
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		entry := &models.CallerListEntry{
+			TenantID:  tenantID,
+			ListType:  strings.ToLower(value("list")),
+			MatchType: strings.ToLower(value("match_type")),
+			Pattern:   value("pattern"),
+			Reason:    value("reason"),
+			CreatedBy: userID,
+		}
+		if expires := value("expires_at"); expires != "" {
+			expiresAt, err := time.Parse(time.RFC3339, expires)
+			if err != nil {
+				if expiresAt, err = time.Parse("2006-01-02", expires); err != nil {
+					report.addError(row, "expires_at", "invalid expires_at")
+					continue
+				}
+			}
+			entry.ExpiresAt = &expiresAt
+		}
+
+		if err := validateCallerListEntry(entry, tenant.DefaultCountry, now); err != nil {
+			report.addError(row, "", err.Error())
+			continue
+		}
+
+		// A later row for the same entry wins; one upsert cannot touch a row twice
+		key := entry.ListType + "|" + entry.MatchType + "|" + entry.Pattern
+		batch[key] = entry
+
+		if len(batch) >= callerListImportBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
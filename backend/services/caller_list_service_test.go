@@ -0,0 +1,142 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"github.com/your-module/backend/models"
+)
+
+func TestNormalizeCallerPattern(t *testing.T) {
+	tests := []struct {
+		matchType   string
+		pattern     string
+		wantPattern string
+		wantLike    string
+		wantErr     string
+	}{
+		{models.CallerMatchExact, "(415) 555-0100", "+14155550100", "+14155550100", ""},
+		{models.CallerMatchExact, "+44 20 7946 0958", "+442079460958", "+442079460958", ""},
+		{models.CallerMatchExact, "100", "100", "100", ""},
+		{models.CallerMatchExact, "anonymous", "", "", "invalid pattern"},
+		{models.CallerMatchPrefix, "+1 900", "+1900", "+1900%", ""},
+		{models.CallerMatchPrefix, "+1900*", "", "", "invalid pattern"},
+		{models.CallerMatchWildcard, "+1415555????", "+1415555????", "+1415555____", ""},
+		{models.CallerMatchWildcard, "*0100", "*0100", "%0100", ""},
+		{models.CallerMatchWildcard, "+1415", "", "", "wildcard pattern needs a * or ?"},
+		{models.CallerMatchWildcard, "+*", "", "", "wildcard pattern needs at least one digit"},
+		{models.CallerMatchWildcard, "+1a*", "", "", "invalid pattern"},
+		{"regex", "+1.*", "", "", "invalid match type"},
+		{models.CallerMatchExact, " ", "", "", "pattern is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.matchType+" "+tt.pattern, func(t *testing.T) {
+			entry := models.CallerListEntry{MatchType: tt.matchType, Pattern: tt.pattern}
+			err := normalizeCallerPattern(&entry, "US")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if entry.Pattern != tt.wantPattern || entry.LikePattern != tt.wantLike {
+				t.Errorf("pattern = %q like %q, want %q like %q", entry.Pattern, entry.LikePattern, tt.wantPattern, tt.wantLike)
+			}
+		})
+	}
+}
+
+func TestScreen(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	db.Model(tenant).Update("default_country", "US")
+
+	service := NewCallerListService(db)
+	now := time.Now()
+	inAnHour := now.Add(time.Hour)
+
+	for _, entry := range []models.CallerListEntry{
+		{ListType: models.CallerListBlock, MatchType: models.CallerMatchExact, Pattern: "+1 415 555 0100"},
+		{ListType: models.CallerListBlock, MatchType: models.CallerMatchPrefix, Pattern: "+1900"},
+		{ListType: models.CallerListAllow, MatchType: models.CallerMatchExact, Pattern: "+19005550123"},
+		{ListType: models.CallerListBlock, MatchType: models.CallerMatchWildcard, Pattern: "+1212555??99"},
+		{ListType: models.CallerListBlock, MatchType: models.CallerMatchExact, Pattern: "+14155550199", ExpiresAt: &inAnHour},
+	} {
+		if _, err := service.CreateEntry(tenant.ID, nil, &entry); err != nil {
+			t.Fatalf("CreateEntry %s: %v", entry.Pattern, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		caller  string
+		at      time.Time
+		blocked string
+	}{
+		{"exact in E.164", "+14155550100", now, "+14155550100"},
+		{"exact as dialed nationally", "(415) 555-0100", now, "+14155550100"},
+		{"prefix", "+19005550100", now, "+1900"},
+		{"allow overrides block", "+19005550123", now, ""},
+		{"wildcard", "+12125551299", now, "+1212555??99"},
+		{"wildcard length", "+121255512999", now, ""},
+		{"before expiry", "+14155550199", now, "+14155550199"},
+		{"after expiry", "+14155550199", now.Add(2 * time.Hour), ""},
+		{"unlisted", "+14155550111", now, ""},
+		{"empty", " ", now, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := service.Screen(tenant, tt.caller, tt.at)
+			if err != nil {
+				t.Fatalf("Screen: %v", err)
+			}
+			got := ""
+			if entry != nil {
+				got = entry.Pattern
+			}
+			if got != tt.blocked {
+				t.Errorf("blocked by %q, want %q", got, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestAuthorizeBlockedCaller(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	db.Model(tenant).Update("default_country", "US")
+
+	entry := models.CallerListEntry{ListType: models.CallerListBlock, MatchType: models.CallerMatchExact, Pattern: "+14155550100"}
+	if _, err := NewCallerListService(db).CreateEntry(tenant.ID, nil, &entry); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	service := NewAdmissionService(db)
+	decision, err := service.AuthorizeCall(tenant.ID, AdmissionCall{UUID: "call-1", Caller: "(415) 555-0100", Callee: "+14155550101"})
+	if err != nil {
+		t.Fatalf("AuthorizeCall: %v", err)
+	}
+	if decision.Allow || decision.Reason != AdmissionReasonCallerBlocked {
+		t.Fatalf("blocked caller decision = %+v", decision)
+	}
+
+	var call models.Call
+	if err := db.Where("uuid = ?", "call-1").First(&call).Error; err != nil {
+		t.Fatalf("blocked call not recorded: %v", err)
+	}
+	if call.Disposition != models.CallDispositionBlocked || call.StartTime == nil {
+		t.Errorf("blocked call = %+v", call)
+	}
+
+	decision, err = service.AuthorizeCall(tenant.ID, AdmissionCall{UUID: "call-2", Caller: "+14155550111", Callee: "+14155550101"})
+	if err != nil {
+		t.Fatalf("AuthorizeCall: %v", err)
+	}
+	if !decision.Allow {
+		t.Errorf("unlisted caller decision = %+v", decision)
+	}
+}
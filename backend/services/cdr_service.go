@@ -0,0 +1,235 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+// CdrService turns call detail records pushed by telephony switches into
+// models.Call rows for a tenant.
+type CdrService struct {
+	DB          *gorm.DB
+	CallService *CallService
+}
+
+func NewCdrService(db *gorm.DB) *CdrService {
+	return &CdrService{
+		DB:          db,
+		CallService: NewCallService(db),
+	}
+}
+
+// freeSwitchCDR holds the parts of a mod_json_cdr document that are mapped
+// onto a call. Callflow is an array on current FreeSWITCH releases and a
+// single object on older ones, so it is decoded lazily.
+type freeSwitchCDR struct {
+	Variables map[string]interface{} `json:"variables"`
+	Callflow  json.RawMessage        `json:"callflow"`
+}
+
+type freeSwitchCallflow struct {
+	CallerProfile struct {
+		CallerIDNumber    string `json:"caller_id_number"`
+		DestinationNumber string `json:"destination_number"`
+	} `json:"caller_profile"`
+}
+
+// Channel variables checked, in order, for the recording location. Set one of
+// them in the dialplan (e.g. after record_session) to have it stored on the call.
+var freeSwitchRecordingVariables = []string{
+	"recording_url",
+	"record_path",
+	"record_file",
+	"recording_file",
+}
+
+// DecodeFreeSwitchPayload extracts the JSON document from a mod_json_cdr POST.
+// Depending on the "encode" setting the document is sent raw, or as the
+// url-encoded or base64-encoded "cdr" form field.
+func DecodeFreeSwitchPayload(body []byte, formValue string) ([]byte, error) {
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "{") {
+		return []byte(trimmed), nil
+	}
+
+	value := strings.TrimSpace(formValue)
+	if value == "" {
+		return nil, errors.New("empty cdr payload")
+	}
+
+	if strings.HasPrefix(value, "{") {
+		return []byte(value), nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("invalid cdr payload")
+	}
+
+	return decoded, nil
+}
+
+// IngestFreeSwitchCDR stores a mod_json_cdr document for the tenant. The
+// returned flag is false when the UUID had already been ingested.
+func (s *CdrService) IngestFreeSwitchCDR(tenantID uint, payload []byte) (*models.Call, bool, error) {
+	var cdr freeSwitchCDR
+	if err := json.Unmarshal(payload, &cdr); err != nil {
+		return nil, false, errors.New("invalid cdr payload")
+	}
+
+	call, err := cdr.toCall()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return s.CallService.UpsertCallByUUID(tenantID, call)
+}
+
+func (cdr *freeSwitchCDR) toCall() (*models.Call, error) {
+	uuid := cdr.variable("uuid")
+	if uuid == "" {
+		return nil, errors.New("cdr is missing the uuid variable")
+	}
+
+	profile := cdr.callerProfile()
+
+	caller := profile.CallerProfile.CallerIDNumber
+	if caller == "" {
+		caller = cdr.firstVariable("effective_caller_id_number", "sip_from_user")
+	}
+
+	callee := profile.CallerProfile.DestinationNumber
+	if callee == "" {
+		callee = cdr.firstVariable("sip_req_user", "sip_to_user")
+	}
+
+	if caller == "" {
+		return nil, errors.New("cdr is missing the caller number")
+	}
+
+	if callee == "" {
+		return nil, errors.New("cdr is missing the destination number")
+	}
+
+	billsec, _ := strconv.Atoi(cdr.variable("billsec"))
+
+	call := &models.Call{
+		UUID:         uuid,
+		Caller:       caller,
+		Callee:       callee,
+		StartTime:    parseEpoch(cdr.variable("start_epoch")),
+		AnswerTime:   parseEpoch(cdr.variable("answer_epoch")),
+		EndTime:      parseEpoch(cdr.variable("end_epoch")),
+		Billsec:      billsec,
+		RecordingURL: cdr.firstVariable(freeSwitchRecordingVariables...),
+		HangupSide:   freeSwitchHangupSide(cdr.variable("sip_hangup_disposition")),
+	}
+
+	setHangupCause(call, cdr.variable("hangup_cause"))
+	if code, err := strconv.Atoi(cdr.variable("hangup_cause_q850")); err == nil && code > 0 {
+		call.HangupCauseCode = code
+	}
+	call.SIPCode = freeSwitchSIPCode(cdr)
+
+	// Set by the dialplan when the caller was on the tenant's blocklist
+	if cdr.variable("caller_blocked") != "" {
+		call.Disposition = models.CallDispositionBlocked
+	}
+
+	return call, nil
+}
+
+// freeSwitchSIPCode is the final SIP response of the call: sip_term_status
+// when set, otherwise the code in proto_specific_hangup_cause ("sip:486").
+func freeSwitchSIPCode(cdr *freeSwitchCDR) int {
+	if code, err := strconv.Atoi(cdr.variable("sip_term_status")); err == nil {
+		return code
+	}
+	if cause := cdr.variable("proto_specific_hangup_cause"); strings.HasPrefix(cause, "sip:") {
+		code, _ := strconv.Atoi(strings.TrimPrefix(cause, "sip:"))
+		return code
+	}
+	return 0
+}
+
+// freeSwitchHangupSide maps sip_hangup_disposition, which the CDR reports
+// for the caller's leg: a received BYE or CANCEL came from the caller, a
+// BYE sent to the caller means the other party hung up, and a refusal sent
+// to the caller means the switch rejected the call.
+func freeSwitchHangupSide(disposition string) string {
+	switch disposition {
+	case "recv_bye", "recv_cancel", "recv_refuse":
+		return models.CallHangupCaller
+	case "send_bye":
+		return models.CallHangupCallee
+	case "send_refuse", "send_cancel":
+		return models.CallHangupSystem
+	}
+	return ""
+}
+
+// variable returns a channel variable as a string. mod_json_cdr url-encodes
+// values by default, so they are unescaped here.
+func (cdr *freeSwitchCDR) variable(name string) string {
+	raw, ok := cdr.Variables[name]
+	if !ok || raw == nil {
+		return ""
+	}
+
+	var value string
+	switch v := raw.(type) {
+	case string:
+		value = v
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		value = unescaped
+	}
+
+	return strings.TrimSpace(value)
+}
+
+func (cdr *freeSwitchCDR) firstVariable(names ...string) string {
+	for _, name := range names {
+		if value := cdr.variable(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// callerProfile returns the first callflow entry, which FreeSWITCH writes
+// for the most recent caller profile of the channel.
+func (cdr *freeSwitchCDR) callerProfile() freeSwitchCallflow {
+	var flows []freeSwitchCallflow
+	if err := json.Unmarshal(cdr.Callflow, &flows); err == nil && len(flows) > 0 {
+		return flows[0]
+	}
+
+	var flow freeSwitchCallflow
+	json.Unmarshal(cdr.Callflow, &flow)
+	return flow
+}
+
+// parseEpoch converts a unix timestamp in seconds into a time. Switches
+// report "0" for events that never happened, such as an unanswered call.
+func parseEpoch(value string) *time.Time {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || seconds <= 0 {
+		return nil
+	}
+
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/base64"
+	"testing"
+	"github.com/your-module/backend/models"
+)
+
+// freeSwitchCDRJSON is a trimmed mod_json_cdr document, with the variables
+// url-encoded as the module sends them by default.
+const freeSwitchCDRJSON = `{
+	"variables": {
+		"uuid": "0b2c6e3a-5f7d-4c1e-9a3b-2d8f1e6c4a70",
+		"start_epoch": "1700000000",
+		"answer_epoch": "1700000005",
+		"end_epoch": "1700000065",
+		"billsec": "60",
+		"record_path": "%2Fvar%2Frecordings%2Fcall.wav"
+	},
+	"callflow": [
+		{"caller_profile": {"caller_id_number": "+14155550100", "destination_number": "1001"}},
+		{"caller_profile": {"caller_id_number": "older", "destination_number": "older"}}
+	]
+}`
+
+func TestDecodeFreeSwitchPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		form    string
+		wantErr bool
+	}{
+		{"raw body", freeSwitchCDRJSON, "", false},
+		{"form field", "cdr=...", freeSwitchCDRJSON, false},
+		{"base64 form field", "cdr=...", base64.StdEncoding.EncodeToString([]byte(freeSwitchCDRJSON)), false},
+		{"empty", "", "", true},
+		{"garbage", "", "not base64!", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := DecodeFreeSwitchPayload([]byte(tt.body), tt.form)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("DecodeFreeSwitchPayload() = %q, want an error", payload)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeFreeSwitchPayload: %v", err)
+			}
+			if string(payload) != freeSwitchCDRJSON {
+				t.Errorf("DecodeFreeSwitchPayload() = %q", payload)
+			}
+		})
+	}
+}
+
+func TestIngestFreeSwitchCDR(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCdrService(db)
+
+	call, created, err := service.IngestFreeSwitchCDR(tenant.ID, []byte(freeSwitchCDRJSON))
+	if err != nil {
+		t.Fatalf("IngestFreeSwitchCDR: %v", err)
+	}
+	if !created {
+		t.Error("first report was not reported as created")
+	}
+
+	if call.UUID != "0b2c6e3a-5f7d-4c1e-9a3b-2d8f1e6c4a70" || call.TenantID != tenant.ID {
+		t.Errorf("call uuid %q tenant %d", call.UUID, call.TenantID)
+	}
+	if call.Caller != "+14155550100" || call.Callee != "1001" {
+		t.Errorf("numbers = %q -> %q, want the latest caller profile", call.Caller, call.Callee)
+	}
+	if call.StartTime == nil || call.StartTime.Unix() != 1700000000 || call.AnswerTime == nil || call.EndTime == nil {
+		t.Errorf("times = %v %v %v", call.StartTime, call.AnswerTime, call.EndTime)
+	}
+	if call.Billsec != 60 || call.RecordingURL != "/var/recordings/call.wav" {
+		t.Errorf("billsec %d recording %q", call.Billsec, call.RecordingURL)
+	}
+}
+
+func TestIngestFreeSwitchCDROlderCallflow(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+
+	call, _, err := NewCdrService(db).IngestFreeSwitchCDR(tenant.ID, []byte(`{
+		"variables": {"uuid": "call-1", "answer_epoch": "0", "billsec": "0"},
+		"callflow": {"caller_profile": {"caller_id_number": "1001", "destination_number": "1002"}}
+	}`))
+	if err != nil {
+		t.Fatalf("IngestFreeSwitchCDR: %v", err)
+	}
+	if call.Caller != "1001" || call.Callee != "1002" {
+		t.Errorf("numbers = %q -> %q", call.Caller, call.Callee)
+	}
+	if call.AnswerTime != nil {
+		t.Errorf("unanswered call has answer time %v", call.AnswerTime)
+	}
+}
+
+func TestIngestFreeSwitchCDRVariablesOnly(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+
+	call, _, err := NewCdrService(db).IngestFreeSwitchCDR(tenant.ID, []byte(`{
+		"variables": {"uuid": "call-1", "effective_caller_id_number": "%2B14155550100", "sip_req_user": "1002", "billsec": 12}
+	}`))
+	if err != nil {
+		t.Fatalf("IngestFreeSwitchCDR: %v", err)
+	}
+	if call.Caller != "+14155550100" || call.Callee != "1002" || call.Billsec != 12 {
+		t.Errorf("call = %q -> %q, billsec %d", call.Caller, call.Callee, call.Billsec)
+	}
+}
+
+func TestIngestFreeSwitchCDRRejects(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewCdrService(db)
+
+	tests := map[string]string{
+		"not json":       `{"variables":`,
+		"no uuid":        `{"variables": {"sip_from_user": "1001", "sip_to_user": "1002"}}`,
+		"no caller":      `{"variables": {"uuid": "call-1", "sip_to_user": "1002"}}`,
+		"no destination": `{"variables": {"uuid": "call-1", "sip_from_user": "1001"}}`,
+	}
+	for name, payload := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := service.IngestFreeSwitchCDR(tenant.ID, []byte(payload)); err == nil {
+				t.Error("IngestFreeSwitchCDR accepted the payload")
+			}
+		})
+	}
+
+	var count int64
+	db.Model(&models.Call{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d calls stored from rejected payloads", count)
+	}
+}
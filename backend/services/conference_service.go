@@ -0,0 +1,477 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/esl"
+	"github.com/your-module/backend/models"
+)
+
+// ConferenceEventSubclass is the CUSTOM event subclass mod_conference
+// reports member changes with.
+const ConferenceEventSubclass = "conference::maniacal"
+
+// Reasons a caller is refused entry to a room.
+const (
+	ConferenceDeniedClosed = "closed"
+	ConferenceDeniedFull   = "full"
+)
+
+type ConferenceService struct {
+	DB *gorm.DB
+}
+
+func NewConferenceService(db *gorm.DB) *ConferenceService {
+	return &ConferenceService{DB: db}
+}
+
+// ConferenceName is the room's conference name on the switch. Room IDs are
+// unique across tenants, so names never collide.
+func ConferenceName(roomID uint) string {
+	return "room_" + strconv.FormatUint(uint64(roomID), 10)
+}
+
+func conferenceRoomID(name string) (uint, bool) {
+	value, ok := strings.CutPrefix(name, "room_")
+	if !ok {
+		return 0, false
+	}
+	roomID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || roomID == 0 {
+		return 0, false
+	}
+	return uint(roomID), true
+}
+
+func validateConferenceRoom(room *models.ConferenceRoom) error {
+	room.Name = strings.TrimSpace(room.Name)
+	if room.Name == "" {
+		return errors.New("name is required")
+	}
+
+	room.Extension = strings.TrimSpace(room.Extension)
+	if len(room.Extension) < 2 || len(room.Extension) > 10 || !isDigits(room.Extension) {
+		return errors.New("extension must be 2 to 10 digits")
+	}
+
+	room.ParticipantPIN = strings.TrimSpace(room.ParticipantPIN)
+	room.ModeratorPIN = strings.TrimSpace(room.ModeratorPIN)
+	for _, pin := range []string{room.ParticipantPIN, room.ModeratorPIN} {
+		if pin != "" && (len(pin) < 4 || len(pin) > 10 || !isDigits(pin)) {
+			return errors.New("pin must be 4 to 10 digits")
+		}
+	}
+	if room.ModeratorPIN != "" && room.ModeratorPIN == room.ParticipantPIN {
+		return errors.New("moderator and participant pins must differ")
+	}
+
+	if room.MaxParticipants < 0 {
+		return errors.New("max participants cannot be negative")
+	}
+
+	if room.ValidFrom != nil && room.ValidUntil != nil && !room.ValidUntil.After(*room.ValidFrom) {
+		return errors.New("valid until must be after valid from")
+	}
+
+	return nil
+}
+
+// checkConferenceRoom checks what a room refers to and shares with other
+// records: the dial-in number must be the tenant's and not another room's,
+// and the extension must not be taken by another room or a SIP endpoint,
+// which would be dialed instead. The plan caps the room size.
+func checkConferenceRoom(db *gorm.DB, tenantID uint, room *models.ConferenceRoom, plan *models.Plan, excludeID uint) error {
+	if plan.MaxConferenceParticipants > 0 && uint(room.MaxParticipants) > plan.MaxConferenceParticipants {
+		return errors.New("max participants exceeds the plan limit")
+	}
+
+	var count int64
+	if err := db.Model(&models.ConferenceRoom{}).
+		Where("tenant_id = ? AND extension = ? AND id <> ?", tenantID, room.Extension, excludeID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("extension already has a conference room")
+	}
+
+	taken, err := extensionTaken(db, tenantID, room.Extension, 0)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errors.New("extension is used by a sip endpoint")
+	}
+
+	if room.PhoneNumberID == nil {
+		return nil
+	}
+
+	if err := db.Model(&models.PhoneNumber{}).
+		Where("id = ? AND tenant_id = ?", *room.PhoneNumberID, tenantID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("phone number not found")
+	}
+
+	if err := db.Model(&models.ConferenceRoom{}).
+		Where("phone_number_id = ? AND id <> ?", *room.PhoneNumberID, excludeID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("phone number already has a conference room")
+	}
+
+	return nil
+}
+
+func tenantPlan(db *gorm.DB, tenantID uint) (*models.Plan, error) {
+	subscription, err := NewSubscriptionService(db).GetTenantSubscription(tenantID)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			return nil, errors.New("no active subscription found")
+		}
+		return nil, err
+	}
+	return &subscription.Plan, nil
+}
+
+// CreateRoom adds a room, enforcing the MaxConferenceRooms limit of the
+// tenant's plan. The tenant row is locked so concurrent creates cannot both
+// pass the limit.
+func (s *ConferenceService) CreateRoom(tenantID uint, room *models.ConferenceRoom) (*models.ConferenceRoom, error) {
+	room.ID = 0
+	room.TenantID = tenantID
+	if err := validateConferenceRoom(room); err != nil {
+		return nil, err
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&tenant, tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tenant not found")
+			}
+			return err
+		}
+
+		plan, err := tenantPlan(tx, tenantID)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.ConferenceRoom{}).
+			Where("tenant_id = ?", tenantID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if plan.MaxConferenceRooms > 0 && uint(count) >= plan.MaxConferenceRooms {
+			return errors.New("quota exceeded: max conference rooms reached")
+		}
+
+		if err := checkConferenceRoom(tx, tenantID, room, plan, 0); err != nil {
+			return err
+		}
+
+		return tx.Create(room).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+func (s *ConferenceService) GetRooms(tenantID uint) ([]models.ConferenceRoom, error) {
+	var rooms []models.ConferenceRoom
+
+	if err := s.DB.Preload("PhoneNumber").
+		Where("tenant_id = ?", tenantID).
+		Order("name").
+		Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func (s *ConferenceService) GetRoomByID(tenantID, roomID uint) (*models.ConferenceRoom, error) {
+	var room models.ConferenceRoom
+
+	if err := s.DB.Preload("PhoneNumber").
+		Where("id = ? AND tenant_id = ?", roomID, tenantID).
+		First(&room).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("conference room not found")
+		}
+		return nil, err
+	}
+
+	return &room, nil
+}
+
+// UpdateRoom applies to callers joining from then on; participants already
+// in the room stay.
+func (s *ConferenceService) UpdateRoom(tenantID, roomID uint, changes *models.ConferenceRoom) (*models.ConferenceRoom, error) {
+	room, err := s.GetRoomByID(tenantID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateConferenceRoom(changes); err != nil {
+		return nil, err
+	}
+
+	plan, err := tenantPlan(s.DB, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkConferenceRoom(s.DB, tenantID, changes, plan, room.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Model(room).Updates(map[string]interface{}{
+		"name":              changes.Name,
+		"extension":         changes.Extension,
+		"phone_number_id":   changes.PhoneNumberID,
+		"participant_pin":   changes.ParticipantPIN,
+		"moderator_pin":     changes.ModeratorPIN,
+		"max_participants":  changes.MaxParticipants,
+		"recording_enabled": changes.RecordingEnabled,
+		"valid_from":        changes.ValidFrom,
+		"valid_until":       changes.ValidUntil,
+		"is_active":         changes.IsActive,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetRoomByID(tenantID, roomID)
+}
+
+func (s *ConferenceService) DeleteRoom(tenantID, roomID uint) error {
+	room, err := s.GetRoomByID(tenantID, roomID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("room_id = ?", room.ID).
+			Delete(&models.ConferenceParticipant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(room).Error
+	})
+}
+
+// GetParticipants lists who is in the room now, in joining order.
+func (s *ConferenceService) GetParticipants(tenantID, roomID uint) ([]models.ConferenceParticipant, error) {
+	if _, err := s.GetRoomByID(tenantID, roomID); err != nil {
+		return nil, err
+	}
+
+	var participants []models.ConferenceParticipant
+	if err := s.DB.Where("room_id = ? AND tenant_id = ?", roomID, tenantID).
+		Order("joined_at, id").
+		Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	return participants, nil
+}
+
+// CheckJoin reports why a caller cannot enter the room at the given time,
+// or "" if they can. The room size is the smaller of the room's and the
+// plan's limits, either of which may be off.
+func (s *ConferenceService) CheckJoin(room *models.ConferenceRoom, at time.Time) (string, error) {
+	if !room.IsActive {
+		return ConferenceDeniedClosed, nil
+	}
+	if (room.ValidFrom != nil && at.Before(*room.ValidFrom)) || (room.ValidUntil != nil && !at.Before(*room.ValidUntil)) {
+		return ConferenceDeniedClosed, nil
+	}
+
+	plan, err := tenantPlan(s.DB, room.TenantID)
+	if err != nil {
+		if err.Error() == "no active subscription found" {
+			return ConferenceDeniedClosed, nil
+		}
+		return "", err
+	}
+
+	limit := room.MaxParticipants
+	if plan.MaxConferenceParticipants > 0 && (limit == 0 || uint(limit) > plan.MaxConferenceParticipants) {
+		limit = int(plan.MaxConferenceParticipants)
+	}
+	if limit == 0 {
+		return "", nil
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.ConferenceParticipant{}).
+		Where("room_id = ?", room.ID).
+		Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count >= int64(limit) {
+		return ConferenceDeniedFull, nil
+	}
+
+	return "", nil
+}
+
+// HandleEvent applies a mod_conference member event to the tracked
+// participants. Conferences that are not rooms are ignored.
+func (s *ConferenceService) HandleEvent(event esl.Event) error {
+	roomID, ok := conferenceRoomID(event.Get("Conference-Name"))
+	if !ok {
+		return nil
+	}
+	hostname := event.Get("FreeSWITCH-Hostname")
+
+	switch event.Get("Action") {
+	case "add-member":
+		var room models.ConferenceRoom
+		if err := s.DB.First(&room, roomID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		memberID, _ := strconv.Atoi(event.Get("Member-ID"))
+		return s.saveParticipant(models.ConferenceParticipant{
+			TenantID:       room.TenantID,
+			RoomID:         room.ID,
+			Hostname:       hostname,
+			ChannelUUID:    event.Get("Unique-ID"),
+			MemberID:       memberID,
+			CallerIDNumber: event.Get("Caller-Caller-ID-Number"),
+			CallerIDName:   event.Get("Caller-Caller-ID-Name"),
+			IsModerator:    event.Get("Member-Type") == "moderator",
+			IsMuted:        event.Get("Speak") == "false",
+			JoinedAt:       eventTime(event),
+		})
+
+	case "del-member":
+		return s.removeParticipant(event.Get("Unique-ID"))
+
+	case "mute-member", "unmute-member":
+		return s.DB.Model(&models.ConferenceParticipant{}).
+			Where("channel_uuid = ?", event.Get("Unique-ID")).
+			Update("is_muted", event.Get("Action") == "mute-member").Error
+
+	case "conference-destroy":
+		return s.DB.Where("room_id = ? AND hostname = ?", roomID, hostname).
+			Delete(&models.ConferenceParticipant{}).Error
+	}
+
+	return nil
+}
+
+func (s *ConferenceService) saveParticipant(participant models.ConferenceParticipant) error {
+	if participant.ChannelUUID == "" {
+		return nil
+	}
+
+	return s.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "channel_uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"tenant_id", "room_id", "hostname", "member_id", "caller_id_number", "caller_id_name",
+			"is_moderator", "is_muted", "updated_at",
+		}),
+	}).Create(&participant).Error
+}
+
+func (s *ConferenceService) removeParticipant(channelUUID string) error {
+	if channelUUID == "" {
+		return nil
+	}
+	return s.DB.Where("channel_uuid = ?", channelUUID).
+		Delete(&models.ConferenceParticipant{}).Error
+}
+
+// switchConference is an entry of "conference json_list".
+type switchConference struct {
+	Name    string `json:"conference_name"`
+	Members []struct {
+		Type           string `json:"type"`
+		ID             int    `json:"id"`
+		UUID           string `json:"uuid"`
+		CallerIDName   string `json:"caller_id_name"`
+		CallerIDNumber string `json:"caller_id_number"`
+		JoinTime       int64  `json:"join_time"`
+		Flags          struct {
+			CanSpeak    bool `json:"can_speak"`
+			IsModerator bool `json:"is_moderator"`
+		} `json:"flags"`
+	} `json:"members"`
+}
+
+// Reconcile replaces the tracked participants on the switch with its live
+// conference members, for the events missed while disconnected.
+func (s *ConferenceService) Reconcile(client esl.Client, hostname string) error {
+	output, err := client.API("conference json_list")
+	if err != nil {
+		return err
+	}
+
+	// Without conferences the switch answers with a plain message
+	var conferences []switchConference
+	if strings.HasPrefix(strings.TrimSpace(output), "[") {
+		if err := json.Unmarshal([]byte(output), &conferences); err != nil {
+			return err
+		}
+	}
+
+	if err := s.DB.Where("hostname = ?", hostname).
+		Delete(&models.ConferenceParticipant{}).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, conference := range conferences {
+		roomID, ok := conferenceRoomID(conference.Name)
+		if !ok {
+			continue
+		}
+		var room models.ConferenceRoom
+		if err := s.DB.First(&room, roomID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+
+		for _, member := range conference.Members {
+			if member.Type != "caller" {
+				continue
+			}
+			if err := s.saveParticipant(models.ConferenceParticipant{
+				TenantID:       room.TenantID,
+				RoomID:         room.ID,
+				Hostname:       hostname,
+				ChannelUUID:    member.UUID,
+				MemberID:       member.ID,
+				CallerIDNumber: member.CallerIDNumber,
+				CallerIDName:   member.CallerIDName,
+				IsModerator:    member.Flags.IsModerator,
+				IsMuted:        !member.Flags.CanSpeak,
+				JoinedAt:       now.Add(-time.Duration(member.JoinTime) * time.Second),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
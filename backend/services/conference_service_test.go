@@ -0,0 +1,227 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+	"github.com/your-module/backend/esl"
+	"github.com/your-module/backend/models"
+)
+
+func TestValidateConferenceRoom(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		room models.ConferenceRoom
+		want string
+	}{
+		{"no name", models.ConferenceRoom{Extension: "800"}, "name is required"},
+		{"bad extension", models.ConferenceRoom{Name: "Standup", Extension: "8"}, "extension must be 2 to 10 digits"},
+		{"bad pin", models.ConferenceRoom{Name: "Standup", Extension: "800", ParticipantPIN: "12a4"}, "pin must be 4 to 10 digits"},
+		{"same pins", models.ConferenceRoom{Name: "Standup", Extension: "800", ParticipantPIN: "1234", ModeratorPIN: "1234"}, "moderator and participant pins must differ"},
+		{"negative size", models.ConferenceRoom{Name: "Standup", Extension: "800", MaxParticipants: -1}, "max participants cannot be negative"},
+		{"reversed validity", models.ConferenceRoom{Name: "Standup", Extension: "800", ValidFrom: &now, ValidUntil: &earlier}, "valid until must be after valid from"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateConferenceRoom(&tt.room); err == nil || err.Error() != tt.want {
+				t.Errorf("validateConferenceRoom() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateRoomLimits(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConferenceRooms: 2, MaxConferenceParticipants: 10, MaxSipEndpoints: 1})
+	service := NewConferenceService(db)
+
+	if _, _, err := NewSipEndpointService(db).CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1001", IsActive: true}); err != nil {
+		t.Fatalf("CreateEndpoint: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		room models.ConferenceRoom
+		want string
+	}{
+		{"too large for the plan", models.ConferenceRoom{Name: "All hands", Extension: "800", MaxParticipants: 11}, "max participants exceeds the plan limit"},
+		{"endpoint extension", models.ConferenceRoom{Name: "All hands", Extension: "1001"}, "extension is used by a sip endpoint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateRoom(tenant.ID, &tt.room); err == nil || err.Error() != tt.want {
+				t.Errorf("CreateRoom() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := service.CreateRoom(tenant.ID, &models.ConferenceRoom{Name: "Standup", Extension: "800"}); err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if _, err := service.CreateRoom(tenant.ID, &models.ConferenceRoom{Name: "Standup 2", Extension: "800"}); err == nil || err.Error() != "extension already has a conference room" {
+		t.Errorf("second room on an extension = %v", err)
+	}
+	if _, err := service.CreateRoom(tenant.ID, &models.ConferenceRoom{Name: "Planning", Extension: "801"}); err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if _, err := service.CreateRoom(tenant.ID, &models.ConferenceRoom{Name: "Retro", Extension: "802"}); err == nil || err.Error() != "quota exceeded: max conference rooms reached" {
+		t.Errorf("room over the plan limit = %v", err)
+	}
+}
+
+func TestCheckJoin(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConferenceRooms: 5, MaxConferenceParticipants: 3})
+	service := NewConferenceService(db)
+
+	now := time.Now()
+	tomorrow := now.Add(24 * time.Hour)
+	room, err := service.CreateRoom(tenant.ID, &models.ConferenceRoom{Name: "Standup", Extension: "800", MaxParticipants: 2, ValidUntil: &tomorrow, IsActive: true})
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	unlimited, err := service.CreateRoom(tenant.ID, &models.ConferenceRoom{Name: "Town hall", Extension: "801", IsActive: true})
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	join := func(room *models.ConferenceRoom, channelUUID string) {
+		t.Helper()
+		if err := db.Create(&models.ConferenceParticipant{TenantID: tenant.ID, RoomID: room.ID, ChannelUUID: channelUUID, JoinedAt: now}).Error; err != nil {
+			t.Fatalf("creating participant: %v", err)
+		}
+	}
+	check := func(room *models.ConferenceRoom, at time.Time, want string) {
+		t.Helper()
+		reason, err := service.CheckJoin(room, at)
+		if err != nil {
+			t.Fatalf("CheckJoin: %v", err)
+		}
+		if reason != want {
+			t.Errorf("CheckJoin(%s) = %q, want %q", room.Name, reason, want)
+		}
+	}
+
+	check(room, now, "")
+	check(room, tomorrow, ConferenceDeniedClosed)
+	join(room, "a")
+	join(room, "b")
+	check(room, now, ConferenceDeniedFull)
+
+	// A room without its own limit still has the plan's
+	join(unlimited, "c")
+	join(unlimited, "d")
+	check(unlimited, now, "")
+	join(unlimited, "e")
+	check(unlimited, now, ConferenceDeniedFull)
+
+	room.IsActive = false
+	check(room, now, ConferenceDeniedClosed)
+}
+
+func TestConferenceEvents(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConferenceRooms: 1})
+	service := NewConferenceService(db)
+	activeCalls := NewActiveCallService(db, nil, nil)
+
+	room, err := service.CreateRoom(tenant.ID, &models.ConferenceRoom{Name: "Standup", Extension: "800"})
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	member := func(action string) esl.Event {
+		return esl.Event{Headers: map[string]string{
+			"Event-Name":              "CUSTOM",
+			"Event-Subclass":          ConferenceEventSubclass,
+			"Action":                  action,
+			"Conference-Name":         ConferenceName(room.ID),
+			"FreeSWITCH-Hostname":     "switch-a",
+			"Unique-ID":               "channel-1",
+			"Member-ID":               "7",
+			"Member-Type":             "moderator",
+			"Caller-Caller-ID-Number": "+14155550100",
+			"Speak":                   "true",
+		}}
+	}
+	participants := func() []models.ConferenceParticipant {
+		t.Helper()
+		list, err := service.GetParticipants(tenant.ID, room.ID)
+		if err != nil {
+			t.Fatalf("GetParticipants: %v", err)
+		}
+		return list
+	}
+
+	for _, action := range []string{"add-member", "mute-member"} {
+		if err := activeCalls.HandleEvent(member(action)); err != nil {
+			t.Fatalf("HandleEvent(%s): %v", action, err)
+		}
+	}
+	list := participants()
+	if len(list) != 1 || list[0].MemberID != 7 || !list[0].IsModerator || !list[0].IsMuted || list[0].CallerIDNumber != "+14155550100" {
+		t.Fatalf("participants = %+v", list)
+	}
+
+	other := member("add-member")
+	other.Headers["Conference-Name"] = "3000-acme.example.com"
+	if err := activeCalls.HandleEvent(other); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+
+	// A hangup covers a missed del-member
+	if err := activeCalls.HandleEvent(esl.Event{Headers: map[string]string{"Event-Name": "CHANNEL_HANGUP", "Unique-ID": "channel-1"}}); err != nil {
+		t.Fatalf("HandleEvent(CHANNEL_HANGUP): %v", err)
+	}
+	if list := participants(); len(list) != 0 {
+		t.Errorf("participant kept after hangup: %+v", list)
+	}
+}
+
+func TestXMLCurlConferencePIN(t *testing.T) {
+	db, service, tenant := newXMLCurlFixture(t)
+	db.Model(&models.Plan{}).Where("name = ?", tenant.Domain).Update("max_conference_rooms", 1)
+
+	room, err := NewConferenceService(db).CreateRoom(tenant.ID, &models.ConferenceRoom{
+		Name: "Standup", Extension: "800", ParticipantPIN: "1111", ModeratorPIN: "2222", IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	lastAction := func(req XMLCurlRequest) fsAction {
+		t.Helper()
+		section := renderedSection(t, service, req)
+		if section.Context == nil {
+			t.Fatalf("dialplan for %+v = %+v", req, section)
+		}
+		actions := section.Context.Extensions[0].Condition.Actions
+		return actions[len(actions)-1]
+	}
+
+	entry := XMLCurlRequest{Section: "dialplan", Context: tenant.Domain, DestinationNumber: "800", CallerUser: "1001"}
+	if got := lastAction(entry); got.Application != "transfer" || got.Data != "conference_"+strconv.FormatUint(uint64(room.ID), 10)+" XML acme.example.com" {
+		t.Errorf("room entry ends with %+v, want a transfer to the join pass", got)
+	}
+
+	join := XMLCurlRequest{Section: "dialplan", Context: tenant.Domain, DestinationNumber: "conference_" + strconv.FormatUint(uint64(room.ID), 10), CallerUser: "1001"}
+	tests := []struct {
+		pin  string
+		want fsAction
+	}{
+		{"1111", fsAction{Application: "conference", Data: ConferenceName(room.ID) + "@default"}},
+		{"2222", fsAction{Application: "conference", Data: ConferenceName(room.ID) + "@default+flags{moderator}"}},
+		{"9999", fsAction{Application: "hangup", Data: "CALL_REJECTED"}},
+		{"", fsAction{Application: "hangup", Data: "CALL_REJECTED"}},
+	}
+	for _, tt := range tests {
+		join.ConferencePIN = tt.pin
+		if got := lastAction(join); got != tt.want {
+			t.Errorf("joining with pin %q ends with %+v, want %+v", tt.pin, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+var fraudRuleTypes = map[string]bool{
+	models.FraudRuleHighRiskPrefix: true,
+	models.FraudRuleHourlySpend:    true,
+	models.FraudRuleVolumeSpike:    true,
+}
+
+type FraudService struct {
+	DB *gorm.DB
+}
+
+func NewFraudService(db *gorm.DB) *FraudService {
+	return &FraudService{DB: db}
+}
+
+func validateFraudRule(rule *models.FraudRule) error {
+	if rule.Name == "" {
+		return errors.New("rule name is required")
+	}
+	if !fraudRuleTypes[rule.Type] {
+		return errors.New("invalid rule type")
+	}
+
+	switch rule.Type {
+	case models.FraudRuleHighRiskPrefix:
+		if len(rule.PrefixList()) == 0 {
+			return errors.New("at least one prefix is required")
+		}
+	case models.FraudRuleHourlySpend:
+		if rule.Threshold <= 0 {
+			return errors.New("threshold must be greater than 0")
+		}
+	case models.FraudRuleVolumeSpike:
+		if rule.Threshold <= 1 {
+			return errors.New("spike threshold must be greater than 1")
+		}
+		if rule.BaselineHours < 0 {
+			return errors.New("baseline hours cannot be negative")
+		}
+		// Without a floor a quiet tenant's first call is a spike
+		if rule.MinCalls < 1 {
+			return errors.New("min calls must be at least 1")
+		}
+	}
+
+	if rule.CooldownMinutes < 0 {
+		return errors.New("cooldown cannot be negative")
+	}
+
+	return nil
+}
+
+func (s *FraudService) CreateRule(tenantID uint, rule *models.FraudRule) (*models.FraudRule, error) {
+	rule.ID = 0
+	rule.TenantID = tenantID
+	if err := validateFraudRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Create(rule).Error; err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *FraudService) GetRules(tenantID uint) ([]models.FraudRule, error) {
+	var rules []models.FraudRule
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("id").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s *FraudService) GetRuleByID(tenantID, ruleID uint) (*models.FraudRule, error) {
+	var rule models.FraudRule
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", ruleID, tenantID).
+		First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("fraud rule not found")
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// UpdateRule replaces the rule's settings. All fields are written so that
+// false and zero values can be set.
+func (s *FraudService) UpdateRule(tenantID, ruleID uint, changes *models.FraudRule) (*models.FraudRule, error) {
+	rule, err := s.GetRuleByID(tenantID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateFraudRule(changes); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Model(rule).Updates(map[string]interface{}{
+		"name":             changes.Name,
+		"type":             changes.Type,
+		"prefixes":         changes.Prefixes,
+		"threshold":        changes.Threshold,
+		"baseline_hours":   changes.BaselineHours,
+		"min_calls":        changes.MinCalls,
+		"cooldown_minutes": changes.CooldownMinutes,
+		"block_tenant":     changes.BlockTenant,
+		"is_active":        changes.IsActive,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *FraudService) DeleteRule(tenantID, ruleID uint) error {
+	rule, err := s.GetRuleByID(tenantID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Delete(rule).Error
+}
+
+func (s *FraudService) GetAlerts(tenantID uint, status string) ([]models.FraudAlert, error) {
+	var alerts []models.FraudAlert
+
+	query := s.DB.Where("tenant_id = ?", tenantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Order("id DESC").
+		Limit(500).
+		Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+func (s *FraudService) ResolveAlert(tenantID, alertID, userID uint) (*models.FraudAlert, error) {
+	var alert models.FraudAlert
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", alertID, tenantID).
+		First(&alert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("fraud alert not found")
+		}
+		return nil, err
+	}
+
+	if alert.Status == models.FraudAlertStatusResolved {
+		return nil, errors.New("fraud alert already resolved")
+	}
+
+	now := time.Now()
+	if err := s.DB.Model(&alert).Updates(map[string]interface{}{
+		"status":      models.FraudAlertStatusResolved,
+		"resolved_at": now,
+		"resolved_by": userID,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &alert, nil
+}
+
+func (s *FraudService) IsTenantBlocked(tenantID uint) (bool, error) {
+	var tenant models.Tenant
+
+	if err := s.DB.Select("id, fraud_blocked_at").First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, errors.New("tenant not found")
+		}
+		return false, err
+	}
+
+	return tenant.FraudBlockedAt != nil, nil
+}
+
+// UnblockTenant lifts a fraud block. Open alerts are left for the tenant to
+// review.
+func (s *FraudService) UnblockTenant(tenantID uint) error {
+	var tenant models.Tenant
+
+	if err := s.DB.First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("tenant not found")
+		}
+		return err
+	}
+
+	if tenant.FraudBlockedAt == nil {
+		return errors.New("tenant is not blocked")
+	}
+
+	return s.DB.Model(&tenant).Update("fraud_blocked_at", nil).Error
+}
+
+// EvaluateCall runs the tenant's active rules against a newly stored call
+// and raises an alert for each rule that trips, unless the rule already has
+// an open alert within its cooldown.
+func (s *FraudService) EvaluateCall(call *models.Call) ([]models.FraudAlert, error) {
+	var rules []models.FraudRule
+	if err := s.DB.Where("tenant_id = ? AND is_active = ?", call.TenantID, true).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	alerts := []models.FraudAlert{}
+	now := time.Now()
+
+	for i := range rules {
+		rule := &rules[i]
+
+		alert, err := s.checkRule(rule, call, now)
+		if err != nil {
+			return alerts, err
+		}
+		if alert == nil {
+			continue
+		}
+
+		var recent int64
+		if err := s.DB.Model(&models.FraudAlert{}).
+			Where("rule_id = ? AND status = ? AND created_at > ?",
+				rule.ID, models.FraudAlertStatusOpen, now.Add(-time.Duration(rule.CooldownMinutes)*time.Minute)).
+			Count(&recent).Error; err != nil {
+			return alerts, err
+		}
+		if recent > 0 {
+			continue
+		}
+
+		alert.TenantID = call.TenantID
+		alert.RuleID = rule.ID
+		alert.RuleType = rule.Type
+		alert.Status = models.FraudAlertStatusOpen
+
+		if rule.BlockTenant {
+			if err := s.DB.Model(&models.Tenant{}).
+				Where("id = ? AND fraud_blocked_at IS NULL", call.TenantID).
+				Update("fraud_blocked_at", now).Error; err != nil {
+				return alerts, err
+			}
+			alert.BlockedTenant = true
+		}
+
+		if err := s.DB.Create(alert).Error; err != nil {
+			return alerts, err
+		}
+
+		log.Printf("fraud: tenant %d: rule %d (%s): %s", call.TenantID, rule.ID, rule.Type, alert.Message)
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts, nil
+}
+
+// checkRule returns an unsaved alert when the rule trips for the call.
+func (s *FraudService) checkRule(rule *models.FraudRule, call *models.Call, now time.Time) (*models.FraudAlert, error) {
+	switch rule.Type {
+	case models.FraudRuleHighRiskPrefix:
+		destination := destinationDigits(call.CalleeE164)
+		if destination == "" {
+			destination = destinationDigits(call.Callee)
+		}
+		for _, prefix := range rule.PrefixList() {
+			prefix = strings.TrimPrefix(prefix, "+")
+			if destination != "" && strings.HasPrefix(destination, prefix) {
+				callID := call.ID
+				return &models.FraudAlert{
+					CallID:  &callID,
+					Message: fmt.Sprintf("call %s to high-risk destination %s matched prefix %s", call.UUID, call.Callee, prefix),
+					Value:   call.Cost,
+				}, nil
+			}
+		}
+
+	case models.FraudRuleHourlySpend:
+		var spend float64
+		if err := s.DB.Model(&models.Call{}).
+			Where("tenant_id = ? AND COALESCE(start_time, created_at) >= ?", call.TenantID, now.Add(-time.Hour)).
+			Select("COALESCE(SUM(cost), 0)").
+			Scan(&spend).Error; err != nil {
+			return nil, err
+		}
+		if spend > rule.Threshold {
+			return &models.FraudAlert{
+				Message:   fmt.Sprintf("spend in the last hour is %.4f, above the %.4f limit", spend, rule.Threshold),
+				Value:     roundTo(spend, 4),
+				Threshold: rule.Threshold,
+			}, nil
+		}
+
+	case models.FraudRuleVolumeSpike:
+		baselineHours := rule.BaselineHours
+		if baselineHours <= 0 {
+			baselineHours = 168
+		}
+		windowStart := now.Add(-time.Hour)
+		baselineStart := windowStart.Add(-time.Duration(baselineHours) * time.Hour)
+
+		var counts struct {
+			Current  int64
+			Baseline int64
+		}
+		if err := s.DB.Model(&models.Call{}).
+			Where("tenant_id = ? AND COALESCE(start_time, created_at) >= ?", call.TenantID, baselineStart).
+			Select("COUNT(*) FILTER (WHERE COALESCE(start_time, created_at) >= ?) AS current, "+
+				"COUNT(*) FILTER (WHERE COALESCE(start_time, created_at) < ?) AS baseline", windowStart, windowStart).
+			Scan(&counts).Error; err != nil {
+			return nil, err
+		}
+
+		average := float64(counts.Baseline) / float64(baselineHours)
+		limit := rule.Threshold * average
+		if counts.Current >= int64(rule.MinCalls) && float64(counts.Current) > limit {
+			return &models.FraudAlert{
+				Message: fmt.Sprintf("%d calls in the last hour against an hourly average of %.2f over %d hours",
+					counts.Current, average, baselineHours),
+				Value:     float64(counts.Current),
+				Threshold: roundTo(limit, 2),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+func TestValidateFraudRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule models.FraudRule
+		want string
+	}{
+		{"valid prefix rule", models.FraudRule{Name: "premium", Type: models.FraudRuleHighRiskPrefix, Prefixes: "+882, 979"}, ""},
+		{"no name", models.FraudRule{Type: models.FraudRuleHighRiskPrefix, Prefixes: "882"}, "rule name is required"},
+		{"unknown type", models.FraudRule{Name: "x", Type: "geo"}, "invalid rule type"},
+		{"blank prefixes", models.FraudRule{Name: "x", Type: models.FraudRuleHighRiskPrefix, Prefixes: " , "}, "at least one prefix is required"},
+		{"no spend threshold", models.FraudRule{Name: "x", Type: models.FraudRuleHourlySpend}, "threshold must be greater than 0"},
+		{"spike of one", models.FraudRule{Name: "x", Type: models.FraudRuleVolumeSpike, Threshold: 1}, "spike threshold must be greater than 1"},
+		{"negative baseline", models.FraudRule{Name: "x", Type: models.FraudRuleVolumeSpike, Threshold: 3, BaselineHours: -1, MinCalls: 5}, "baseline hours cannot be negative"},
+		{"spike without min calls", models.FraudRule{Name: "x", Type: models.FraudRuleVolumeSpike, Threshold: 3}, "min calls must be at least 1"},
+		{"negative cooldown", models.FraudRule{Name: "x", Type: models.FraudRuleHourlySpend, Threshold: 5, CooldownMinutes: -1}, "cooldown cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFraudRule(&tt.rule)
+			if tt.want == "" && err != nil {
+				t.Errorf("validateFraudRule() = %v", err)
+			}
+			if tt.want != "" && (err == nil || err.Error() != tt.want) {
+				t.Errorf("validateFraudRule() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func createFraudRule(t *testing.T, service *FraudService, tenantID uint, rule models.FraudRule) *models.FraudRule {
+	t.Helper()
+
+	created, err := service.CreateRule(tenantID, &rule)
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	return created
+}
+
+func countAlerts(db *gorm.DB, ruleID uint) int64 {
+	var count int64
+	db.Model(&models.FraudAlert{}).Where("rule_id = ?", ruleID).Count(&count)
+	return count
+}
+
+func TestHighRiskPrefixBlocksTenant(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewFraudService(db)
+	calls := NewCallService(db)
+
+	rule := createFraudRule(t, service, tenant.ID, models.FraudRule{
+		Name: "satellite", Type: models.FraudRuleHighRiskPrefix, Prefixes: "+881,+882",
+		CooldownMinutes: 60, BlockTenant: true, IsActive: true,
+	})
+
+	if _, _, err := calls.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "safe", Caller: "1001", Callee: "+14155550100"}); err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	if blocked, _ := service.IsTenantBlocked(tenant.ID); blocked || countAlerts(db, rule.ID) != 0 {
+		t.Fatal("a safe destination tripped the rule")
+	}
+
+	call, _, err := calls.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "risky", Caller: "1001", Callee: "+8821234567"})
+	if err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	alerts, err := service.GetAlerts(tenant.ID, models.FraudAlertStatusOpen)
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("open alerts = %+v, %v", alerts, err)
+	}
+	if alerts[0].CallID == nil || *alerts[0].CallID != call.ID || !alerts[0].BlockedTenant || alerts[0].RuleType != models.FraudRuleHighRiskPrefix {
+		t.Errorf("alert = %+v", alerts[0])
+	}
+	if blocked, _ := service.IsTenantBlocked(tenant.ID); !blocked {
+		t.Error("tenant not blocked")
+	}
+
+	// Within the cooldown the open alert covers further matches
+	if _, _, err := calls.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "risky-2", Caller: "1001", Callee: "+8811234567"}); err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	if count := countAlerts(db, rule.ID); count != 1 {
+		t.Errorf("%d alerts within the cooldown, want 1", count)
+	}
+
+	if _, err := service.ResolveAlert(tenant.ID, alerts[0].ID, 7); err != nil {
+		t.Fatalf("ResolveAlert: %v", err)
+	}
+	if _, err := service.ResolveAlert(tenant.ID, alerts[0].ID, 7); err == nil || err.Error() != "fraud alert already resolved" {
+		t.Errorf("resolving twice = %v", err)
+	}
+	if blocked, _ := service.IsTenantBlocked(tenant.ID); !blocked {
+		t.Error("resolving the alert lifted the block")
+	}
+
+	if err := service.UnblockTenant(tenant.ID); err != nil {
+		t.Fatalf("UnblockTenant: %v", err)
+	}
+	if err := service.UnblockTenant(tenant.ID); err == nil || err.Error() != "tenant is not blocked" {
+		t.Errorf("unblocking twice = %v", err)
+	}
+}
+
+func TestHourlySpendRule(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewFraudService(db)
+
+	rule := createFraudRule(t, service, tenant.ID, models.FraudRule{
+		Name: "spend", Type: models.FraudRuleHourlySpend, Threshold: 10, IsActive: true,
+	})
+
+	longAgo := time.Now().Add(-3 * time.Hour)
+	recent := time.Now().Add(-10 * time.Minute)
+	for i, call := range []models.Call{
+		{StartTime: &longAgo, Cost: 50},
+		{StartTime: &recent, Cost: 6},
+		{StartTime: &recent, Cost: 4},
+	} {
+		call.TenantID, call.UUID, call.Caller, call.Callee = tenant.ID, fmt.Sprintf("call-%d", i), "1001", "1002"
+		db.Create(&call)
+	}
+
+	var last models.Call
+	db.Last(&last)
+	if alerts, err := service.EvaluateCall(&last); err != nil || len(alerts) != 0 {
+		t.Fatalf("spend at the limit raised %+v, %v", alerts, err)
+	}
+
+	over := models.Call{TenantID: tenant.ID, UUID: "over", Caller: "1001", Callee: "1002", StartTime: &recent, Cost: 0.5}
+	db.Create(&over)
+	alerts, err := service.EvaluateCall(&over)
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("EvaluateCall() = %+v, %v", alerts, err)
+	}
+	if alerts[0].RuleID != rule.ID || alerts[0].Value != 10.5 || alerts[0].Threshold != 10 || alerts[0].BlockedTenant {
+		t.Errorf("alert = %+v", alerts[0])
+	}
+}
+
+func TestVolumeSpikeRule(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewFraudService(db)
+
+	createFraudRule(t, service, tenant.ID, models.FraudRule{
+		Name: "spike", Type: models.FraudRuleVolumeSpike, Threshold: 3, BaselineHours: 10, MinCalls: 5, IsActive: true,
+	})
+
+	// 20 calls over the 10 baseline hours average 2 an hour, so more than
+	// 6 in the last hour is a spike
+	addCalls := func(count int, age time.Duration) *models.Call {
+		var call models.Call
+		for i := 0; i < count; i++ {
+			start := time.Now().Add(-age)
+			call = models.Call{TenantID: tenant.ID, UUID: fmt.Sprintf("%s-%d", age, i), Caller: "1001", Callee: "1002", StartTime: &start}
+			db.Create(&call)
+		}
+		return &call
+	}
+	addCalls(20, 5*time.Hour)
+	addCalls(100, 20*time.Hour)
+
+	last := addCalls(6, 10*time.Minute)
+	if alerts, err := service.EvaluateCall(last); err != nil || len(alerts) != 0 {
+		t.Fatalf("6 calls raised %+v, %v", alerts, err)
+	}
+
+	last = addCalls(1, 5*time.Minute)
+	alerts, err := service.EvaluateCall(last)
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("EvaluateCall() = %+v, %v", alerts, err)
+	}
+	if alerts[0].Value != 7 || alerts[0].Threshold != 6 {
+		t.Errorf("alert value %v threshold %v, want 7 against 6", alerts[0].Value, alerts[0].Threshold)
+	}
+}
+
+func TestVolumeSpikeRuleNeedsMinCalls(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewFraudService(db)
+
+	createFraudRule(t, service, tenant.ID, models.FraudRule{
+		Name: "spike", Type: models.FraudRuleVolumeSpike, Threshold: 3, MinCalls: 5, IsActive: true,
+	})
+
+	// No history at all: any call is infinitely above the baseline
+	var call models.Call
+	for i := 0; i < 4; i++ {
+		call = models.Call{TenantID: tenant.ID, UUID: fmt.Sprintf("call-%d", i), Caller: "1001", Callee: "1002"}
+		db.Create(&call)
+	}
+	if alerts, err := service.EvaluateCall(&call); err != nil || len(alerts) != 0 {
+		t.Errorf("4 calls below min_calls raised %+v, %v", alerts, err)
+	}
+}
+
+func TestFraudRulesAreTenantScoped(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewFraudService(db)
+
+	rule := createFraudRule(t, service, tenant.ID, models.FraudRule{
+		Name: "satellite", Type: models.FraudRuleHighRiskPrefix, Prefixes: "882", IsActive: true,
+	})
+
+	if _, err := service.GetRuleByID(other.ID, rule.ID); err == nil || err.Error() != "fraud rule not found" {
+		t.Errorf("other tenant read the rule: %v", err)
+	}
+	if err := service.DeleteRule(other.ID, rule.ID); err == nil {
+		t.Error("other tenant deleted the rule")
+	}
+
+	call := models.Call{TenantID: other.ID, UUID: "risky", Caller: "1001", Callee: "+8821234567"}
+	db.Create(&call)
+	if alerts, _ := service.EvaluateCall(&call); len(alerts) != 0 {
+		t.Errorf("another tenant's rule raised %+v", alerts)
+	}
+
+	changes := *rule
+	changes.IsActive = false
+	if _, err := service.UpdateRule(tenant.ID, rule.ID, &changes); err != nil {
+		t.Fatalf("UpdateRule: %v", err)
+	}
+	call = models.Call{TenantID: tenant.ID, UUID: "risky-own", Caller: "1001", Callee: "+8821234567"}
+	db.Create(&call)
+	if alerts, _ := service.EvaluateCall(&call); len(alerts) != 0 {
+		t.Errorf("inactive rule raised %+v", alerts)
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"github.com/your-module/backend/models"
+)
+
+// newTestDB opens a private in-memory SQLite database with the schema
+// migrated and foreign keys enforced. It stands in for PostgreSQL in service
+// tests, so the paths tested here must stick to SQL both understand.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared&_foreign_keys=1", t.Name())), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	// One connection keeps every query on the same in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(
+		&models.Tenant{},
+		&models.Plan{},
+		&models.Subscription{},
+		&models.Call{},
+		&models.SwitchCredential{},
+		&models.RateDeck{},
+		&models.Rate{},
+		&models.Transcript{},
+		&models.TranscriptSegment{},
+		&models.PurgeLog{},
+		&models.FraudRule{},
+		&models.FraudAlert{},
+		&models.PhoneNumber{},
+		&models.PhoneNumberAssignment{},
+		&models.Role{},
+		&models.User{},
+		&models.SipEndpoint{},
+		&models.OriginateJob{},
+		&models.ActiveChannel{},
+		&models.CallAdmission{},
+		&models.RoutingSchedule{},
+		&models.RoutingScheduleWindow{},
+		&models.HolidayCalendar{},
+		&models.Holiday{},
+		&models.RoutingRule{},
+		&models.IvrFlow{},
+		&models.IvrFlowVersion{},
+		&models.Queue{},
+		&models.QueueAgent{},
+		&models.CallEvent{},
+		&models.VoicemailBox{},
+		&models.VoicemailMessage{},
+		&models.ConferenceRoom{},
+		&models.ConferenceParticipant{},
+		&models.CallerListEntry{},
+	); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+
+	return db
+}
+
+// createTestTenant adds a tenant subscribed to a plan with the given limits.
+func createTestTenant(t *testing.T, db *gorm.DB, domain string, plan models.Plan) *models.Tenant {
+	t.Helper()
+
+	tenant := models.Tenant{Name: domain, Domain: domain}
+	if err := db.Create(&tenant).Error; err != nil {
+		t.Fatalf("creating tenant: %v", err)
+	}
+
+	plan.Name = domain
+	if err := db.Create(&plan).Error; err != nil {
+		t.Fatalf("creating plan: %v", err)
+	}
+
+	if err := db.Create(&models.Subscription{TenantID: tenant.ID, PlanID: plan.ID, IsActive: true}).Error; err != nil {
+		t.Fatalf("creating subscription: %v", err)
+	}
+
+	return &tenant
+}
+
+// waitFor polls until done reports true, failing the test after a few
+// seconds. Services driven by the fake switch update the database from
+// their own goroutines.
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
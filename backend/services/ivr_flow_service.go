@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/ivr"
+	"github.com/your-module/backend/models"
+)
+
+type IvrFlowService struct {
+	DB *gorm.DB
+}
+
+func NewIvrFlowService(db *gorm.DB) *IvrFlowService {
+	return &IvrFlowService{DB: db}
+}
+
+// IvrSimulation is a simulator run request. Version 0 runs the draft.
+type IvrSimulation struct {
+	Version int
+	Inputs  []string
+	Lookups map[string]string
+}
+
+// validateIvrGraph runs the graph checks and also holds transfer targets to
+// the same format as routing rule targets.
+func validateIvrGraph(graph *ivr.Graph) []ivr.Problem {
+	problems := ivr.Validate(graph)
+
+	for _, node := range graph.Nodes {
+		if node.Type != ivr.NodeTransfer || !ivr.TransferTargets[node.TargetType] || node.TargetValue == "" {
+			continue
+		}
+		if _, err := validateRoutingTarget(node.TargetType, node.TargetValue); err != nil {
+			problems = append(problems, ivr.Problem{NodeID: node.ID, Error: err.Error()})
+		}
+	}
+
+	return problems
+}
+
+func (s *IvrFlowService) CreateFlow(tenantID uint, flow *models.IvrFlow) (*models.IvrFlow, error) {
+	flow.ID = 0
+	flow.TenantID = tenantID
+	flow.PublishedVersion = 0
+	flow.Name = strings.TrimSpace(flow.Name)
+	if flow.Name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	if err := s.DB.Create(flow).Error; err != nil {
+		return nil, err
+	}
+
+	return flow, nil
+}
+
+func (s *IvrFlowService) GetFlows(tenantID uint) ([]models.IvrFlow, error) {
+	var flows []models.IvrFlow
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("name").
+		Find(&flows).Error; err != nil {
+		return nil, err
+	}
+
+	return flows, nil
+}
+
+func (s *IvrFlowService) GetFlowByID(tenantID, flowID uint) (*models.IvrFlow, error) {
+	var flow models.IvrFlow
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", flowID, tenantID).
+		First(&flow).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ivr flow not found")
+		}
+		return nil, err
+	}
+
+	return &flow, nil
+}
+
+// UpdateFlow saves the draft. The draft is not validated here so a flow can
+// be built up over several saves; ValidateFlow reports what is missing.
+func (s *IvrFlowService) UpdateFlow(tenantID, flowID uint, changes *models.IvrFlow) (*models.IvrFlow, error) {
+	flow, err := s.GetFlowByID(tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.Name = strings.TrimSpace(changes.Name)
+	if changes.Name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	if err := s.DB.Model(flow).Updates(map[string]interface{}{
+		"name":        changes.Name,
+		"description": changes.Description,
+		"graph":       changes.Graph,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return flow, nil
+}
+
+// ValidateFlow lists the problems that would stop the draft from being
+// published.
+func (s *IvrFlowService) ValidateFlow(tenantID, flowID uint) ([]ivr.Problem, error) {
+	flow, err := s.GetFlowByID(tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateIvrGraph(&flow.Graph), nil
+}
+
+// PublishFlow snapshots the draft as the next version. The flow row is
+// locked so concurrent publishes get distinct version numbers. An invalid
+// draft is not published and its problems are returned.
+func (s *IvrFlowService) PublishFlow(tenantID, flowID uint, userID *uint) (*models.IvrFlowVersion, []ivr.Problem, error) {
+	var version models.IvrFlowVersion
+	var problems []ivr.Problem
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var flow models.IvrFlow
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", flowID, tenantID).
+			First(&flow).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("ivr flow not found")
+			}
+			return err
+		}
+
+		if problems = validateIvrGraph(&flow.Graph); len(problems) > 0 {
+			return errors.New("ivr flow has validation errors")
+		}
+
+		version = models.IvrFlowVersion{
+			FlowID:      flow.ID,
+			TenantID:    tenantID,
+			Version:     flow.PublishedVersion + 1,
+			Graph:       flow.Graph,
+			PublishedBy: userID,
+			PublishedAt: time.Now(),
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&flow).Update("published_version", version.Version).Error
+	})
+	if err != nil {
+		return nil, problems, err
+	}
+
+	return &version, nil, nil
+}
+
+func (s *IvrFlowService) GetVersions(tenantID, flowID uint) ([]models.IvrFlowVersion, error) {
+	if _, err := s.GetFlowByID(tenantID, flowID); err != nil {
+		return nil, err
+	}
+
+	var versions []models.IvrFlowVersion
+	if err := s.DB.Where("flow_id = ? AND tenant_id = ?", flowID, tenantID).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (s *IvrFlowService) GetVersion(tenantID, flowID uint, version int) (*models.IvrFlowVersion, error) {
+	var flowVersion models.IvrFlowVersion
+
+	if err := s.DB.Where("flow_id = ? AND tenant_id = ? AND version = ?", flowID, tenantID, version).
+		First(&flowVersion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ivr flow version not found")
+		}
+		return nil, err
+	}
+
+	return &flowVersion, nil
+}
+
+// DeleteFlow refuses to delete a flow a routing rule still sends calls to.
+// Published versions are kept for the record.
+func (s *IvrFlowService) DeleteFlow(tenantID, flowID uint) error {
+	flow, err := s.GetFlowByID(tenantID, flowID)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.RoutingRule{}).
+		Where("tenant_id = ? AND target_type = ? AND target_value = ?", tenantID, "ivr", strconv.FormatUint(uint64(flow.ID), 10)).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("ivr flow is used by a routing rule")
+	}
+
+	return s.DB.Delete(flow).Error
+}
+
+// ivrFlowsTransferTo reports whether the draft or the published version of
+// one of the tenant's flows transfers calls to the target.
+func ivrFlowsTransferTo(db *gorm.DB, tenantID uint, targetType, targetValue string) (bool, error) {
+	var flows []models.IvrFlow
+	if err := db.Where("tenant_id = ?", tenantID).Find(&flows).Error; err != nil {
+		return false, err
+	}
+	for _, flow := range flows {
+		if flow.Graph.TransfersTo(targetType, targetValue) {
+			return true, nil
+		}
+	}
+
+	var versions []models.IvrFlowVersion
+	if err := db.Joins("JOIN ivr_flows ON ivr_flows.id = ivr_flow_versions.flow_id AND ivr_flows.deleted_at IS NULL").
+		Where("ivr_flow_versions.tenant_id = ? AND ivr_flow_versions.version = ivr_flows.published_version", tenantID).
+		Find(&versions).Error; err != nil {
+		return false, err
+	}
+	for _, version := range versions {
+		if version.Graph.TransfersTo(targetType, targetValue) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Simulate walks the draft or a published version with the given inputs.
+// The graph must be valid; otherwise its problems are returned.
+func (s *IvrFlowService) Simulate(tenantID, flowID uint, req IvrSimulation) (*ivr.Simulation, []ivr.Problem, error) {
+	var graph ivr.Graph
+	if req.Version == 0 {
+		flow, err := s.GetFlowByID(tenantID, flowID)
+		if err != nil {
+			return nil, nil, err
+		}
+		graph = flow.Graph
+	} else {
+		version, err := s.GetVersion(tenantID, flowID, req.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+		graph = version.Graph
+	}
+
+	if problems := validateIvrGraph(&graph); len(problems) > 0 {
+		return nil, problems, errors.New("ivr flow has validation errors")
+	}
+
+	simulation, err := ivr.Simulate(&graph, req.Inputs, req.Lookups)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return simulation, nil, nil
+}
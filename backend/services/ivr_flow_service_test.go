@@ -0,0 +1,148 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+	"github.com/your-module/backend/ivr"
+	"github.com/your-module/backend/models"
+)
+
+func transferGraph(targetValue string) ivr.Graph {
+	return ivr.Graph{
+		Start: "menu",
+		Nodes: []ivr.Node{
+			{ID: "menu", Type: ivr.NodeCollect, Prompt: "menu.wav", Variable: "choice", MinDigits: 1, MaxDigits: 1, Next: "route"},
+			{ID: "route", Type: ivr.NodeBranch, Variable: "choice", Branches: []ivr.Branch{{Match: "1", Next: "sales"}}, Default: "bye"},
+			{ID: "sales", Type: ivr.NodeTransfer, TargetType: "extension", TargetValue: targetValue},
+			{ID: "bye", Type: ivr.NodeHangup},
+		},
+	}
+}
+
+func TestPublishIvrFlow(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewIvrFlowService(db)
+
+	flow, err := service.CreateFlow(tenant.ID, &models.IvrFlow{Name: "Main menu", Graph: transferGraph("1001 XML other.example.com")})
+	if err != nil {
+		t.Fatalf("CreateFlow: %v", err)
+	}
+
+	// Transfer targets are held to the routing rule format
+	_, problems, err := service.PublishFlow(tenant.ID, flow.ID, nil)
+	if err == nil || len(problems) != 1 || problems[0].NodeID != "sales" {
+		t.Fatalf("publishing an invalid draft = %v, %v", problems, err)
+	}
+
+	for want := 1; want <= 2; want++ {
+		if _, err := service.UpdateFlow(tenant.ID, flow.ID, &models.IvrFlow{Name: "Main menu", Graph: transferGraph(strconv.Itoa(1000 + want))}); err != nil {
+			t.Fatalf("UpdateFlow: %v", err)
+		}
+		version, _, err := service.PublishFlow(tenant.ID, flow.ID, nil)
+		if err != nil {
+			t.Fatalf("PublishFlow: %v", err)
+		}
+		if version.Version != want {
+			t.Errorf("published version %d, want %d", version.Version, want)
+		}
+	}
+
+	// Editing the draft leaves published versions alone
+	if _, err := service.UpdateFlow(tenant.ID, flow.ID, &models.IvrFlow{Name: "Main menu", Graph: transferGraph("1009")}); err != nil {
+		t.Fatalf("UpdateFlow: %v", err)
+	}
+	for version, want := range map[int]string{0: "1009", 1: "1001", 2: "1002"} {
+		simulation, _, err := service.Simulate(tenant.ID, flow.ID, IvrSimulation{Version: version, Inputs: []string{"1"}})
+		if err != nil {
+			t.Fatalf("Simulate version %d: %v", version, err)
+		}
+		if simulation.TargetValue != want {
+			t.Errorf("version %d transfers to %q, want %q", version, simulation.TargetValue, want)
+		}
+	}
+
+	stored, err := service.GetFlowByID(tenant.ID, flow.ID)
+	if err != nil {
+		t.Fatalf("GetFlowByID: %v", err)
+	}
+	if stored.PublishedVersion != 2 {
+		t.Errorf("published version = %d, want 2", stored.PublishedVersion)
+	}
+	if _, err := service.GetVersion(tenant.ID+1, flow.ID, 1); err == nil {
+		t.Error("another tenant read a published version")
+	}
+}
+
+func TestDeleteIvrFlowInUse(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewIvrFlowService(db)
+	routing := NewRoutingService(db)
+
+	flow, err := service.CreateFlow(tenant.ID, &models.IvrFlow{Name: "Main menu", Graph: transferGraph("1001")})
+	if err != nil {
+		t.Fatalf("CreateFlow: %v", err)
+	}
+	flowID := strconv.FormatUint(uint64(flow.ID), 10)
+
+	if _, err := routing.CreateRule(other.ID, &models.RoutingRule{Name: "Borrowed", TargetType: "ivr", TargetValue: flowID}); err == nil || err.Error() != "ivr flow not found" {
+		t.Errorf("rule to another tenant's flow = %v", err)
+	}
+	rule, err := routing.CreateRule(tenant.ID, &models.RoutingRule{Name: "Menu", TargetType: "ivr", TargetValue: flowID})
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	if err := service.DeleteFlow(tenant.ID, flow.ID); err == nil || err.Error() != "ivr flow is used by a routing rule" {
+		t.Errorf("deleting a flow in use = %v", err)
+	}
+	if err := routing.DeleteRule(tenant.ID, rule.ID); err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if err := service.DeleteFlow(tenant.ID, flow.ID); err != nil {
+		t.Errorf("DeleteFlow: %v", err)
+	}
+}
+
+func TestXMLCurlIvrSkipsUnsafeSteps(t *testing.T) {
+	db, service, tenant := newXMLCurlFixture(t)
+
+	// A version stored before prompts and lookup URLs were checked
+	flow := models.IvrFlow{TenantID: tenant.ID, Name: "Legacy", PublishedVersion: 1}
+	db.Create(&flow)
+	version := models.IvrFlowVersion{FlowID: flow.ID, TenantID: tenant.ID, Version: 1, PublishedAt: time.Now(), Graph: ivr.Graph{
+		Start: "welcome",
+		Nodes: []ivr.Node{
+			{ID: "welcome", Type: ivr.NodePlay, Prompt: "${system(id)}", Next: "lookup"},
+			{ID: "lookup", Type: ivr.NodeHTTPLookup, URL: "http://169.254.169.254/latest", Variable: "tier", Next: "bye", OnError: "fallback"},
+			{ID: "fallback", Type: ivr.NodeHangup},
+			{ID: "bye", Type: ivr.NodeHangup},
+		},
+	}}
+	if err := db.Create(&version).Error; err != nil {
+		t.Fatalf("creating version: %v", err)
+	}
+
+	tests := []struct {
+		node string
+		want fsAction
+	}{
+		{"welcome", fsAction{Application: "transfer", Data: ivrDestination(flow.ID, 1, "lookup") + " XML acme.example.com"}},
+		{"lookup", fsAction{Application: "transfer", Data: ivrDestination(flow.ID, 1, "fallback") + " XML acme.example.com"}},
+	}
+	for _, tt := range tests {
+		section := renderedSection(t, service, XMLCurlRequest{
+			Section: "dialplan", Context: tenant.Domain, DestinationNumber: ivrDestination(flow.ID, 1, tt.node),
+		})
+		if section.Context == nil || len(section.Context.Extensions) != 1 {
+			t.Fatalf("%s step = %+v", tt.node, section)
+		}
+		actions := section.Context.Extensions[0].Condition.Actions
+		if len(actions) != 1 || actions[0] != tt.want {
+			t.Errorf("%s step actions = %+v, want only %+v", tt.node, actions, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/esl"
+	"github.com/your-module/backend/models"
+)
+
+var originateDestination = regexp.MustCompile(`^\+?[0-9*#]{2,20}$`)
+
+// OriginateService places click-to-call calls through a FreeSWITCH event
+// socket. Run keeps the connection up and applies job results; Originate
+// fails with "switch not connected" while it is down.
+type OriginateService struct {
+	DB *gorm.DB
+	// Dial opens a new event socket connection subscribed to
+	// BACKGROUND_JOB events.
+	Dial func() (esl.Client, error)
+	// RingTimeout is how long the user's extension rings, in seconds.
+	RingTimeout int
+
+	mu     sync.RWMutex
+	client esl.Client
+}
+
+func NewOriginateService(db *gorm.DB, dial func() (esl.Client, error), ringTimeout int) *OriginateService {
+	if ringTimeout <= 0 {
+		ringTimeout = 30
+	}
+	return &OriginateService{DB: db, Dial: dial, RingTimeout: ringTimeout}
+}
+
+type OriginateRequest struct {
+	EndpointID  *uint
+	Destination string
+}
+
+// Run connects to the switch and processes background job results,
+// reconnecting every interval after a failure, until stop is closed.
+func (s *OriginateService) Run(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for {
+		client, err := s.Dial()
+		if err != nil {
+			log.Printf("originate: cannot connect to switch: %v", err)
+		} else {
+			s.setClient(client)
+			s.consume(client, stop)
+			s.setClient(nil)
+			client.Close()
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (s *OriginateService) consume(client esl.Client, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-client.Events():
+			if !ok {
+				log.Printf("originate: switch connection lost")
+				return
+			}
+			if event.Name() != "BACKGROUND_JOB" {
+				continue
+			}
+			if err := s.HandleJobResult(event.Get("Job-UUID"), event.Body); err != nil {
+				log.Printf("originate: job %s: %v", event.Get("Job-UUID"), err)
+			}
+		}
+	}
+}
+
+func (s *OriginateService) setClient(client esl.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+func (s *OriginateService) currentClient() esl.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// originateEndpoint picks the extension to ring: the one requested, or the
+// user's own endpoint.
+func (s *OriginateService) originateEndpoint(tenantID, userID uint, endpointID *uint) (*models.SipEndpoint, error) {
+	var endpoint models.SipEndpoint
+
+	query := s.DB.Where("tenant_id = ? AND is_active = ?", tenantID, true)
+	if endpointID != nil {
+		query = query.Where("id = ?", *endpointID)
+	} else {
+		query = query.Where("user_id = ?", userID).Order("extension")
+	}
+
+	if err := query.First(&endpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("sip endpoint not found")
+		}
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// Originate rings the endpoint and, once it answers, bridges it to the
+// destination through the tenant's dialplan. The Call and job are stored
+// before the command is sent so neither the job result nor the CDR can
+// arrive for a record that does not exist yet.
+func (s *OriginateService) Originate(tenantID, userID uint, req OriginateRequest) (*models.OriginateJob, error) {
+	destination := strings.TrimSpace(req.Destination)
+	if !originateDestination.MatchString(destination) {
+		return nil, errors.New("invalid destination")
+	}
+
+	client := s.currentClient()
+	if client == nil {
+		return nil, errors.New("switch not connected")
+	}
+
+	endpoint, err := s.originateEndpoint(tenantID, userID, req.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant models.Tenant
+	if err := s.DB.First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+
+	numbers, err := tenantNumberSet(s.DB, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	callUUID := uuid.New().String()
+	decision, err := NewAdmissionService(s.DB).Authorize(tenantID, callUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !decision.Allow {
+		return nil, errors.New("call not admitted: " + decision.Reason)
+	}
+
+	now := time.Now()
+	call := models.Call{
+		TenantID:  tenantID,
+		UUID:      callUUID,
+		Caller:    endpoint.Extension,
+		Callee:    destination,
+		StartTime: &now,
+	}
+	normalizeCallNumbers(&call, tenant.DefaultCountry)
+	classifyCall(&call, numbers)
+
+	job := models.OriginateJob{
+		TenantID:    tenantID,
+		UserID:      userID,
+		JobUUID:     uuid.New().String(),
+		CallUUID:    call.UUID,
+		Extension:   endpoint.Extension,
+		Destination: destination,
+		Status:      models.OriginateJobStatusPending,
+	}
+
+	if err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&call).Error; err != nil {
+			return err
+		}
+		job.CallID = call.ID
+		return tx.Create(&job).Error
+	}); err != nil {
+		if releaseErr := NewAdmissionService(s.DB).Release(callUUID, &tenantID); releaseErr != nil {
+			log.Printf("originate: releasing call %s: %v", callUUID, releaseErr)
+		}
+		return nil, err
+	}
+
+	command := fmt.Sprintf("originate {origination_uuid=%s,tenant_id=%d,originate_timeout=%d,"+
+		"origination_caller_id_name=%s,origination_caller_id_number=%s}user/%s@%s %s XML %s",
+		call.UUID, tenantID, s.RingTimeout, destination, destination,
+		endpoint.Extension, tenant.Domain, destination, tenant.Domain)
+
+	if err := client.BgAPI(command, job.JobUUID); err != nil {
+		if failErr := s.failJob(&job, err.Error()); failErr != nil {
+			log.Printf("originate: job %s: %v", job.JobUUID, failErr)
+		}
+		return nil, errors.New("switch rejected originate: " + err.Error())
+	}
+
+	return &job, nil
+}
+
+// HandleJobResult applies the output of a BACKGROUND_JOB event, "+OK
+// <uuid>" on success or "-ERR <cause>", to the matching job. Jobs that are
+// not originate jobs are ignored.
+func (s *OriginateService) HandleJobResult(jobUUID, body string) error {
+	var job models.OriginateJob
+	if err := s.DB.Where("job_uuid = ?", jobUUID).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	result := strings.TrimSpace(body)
+	if strings.HasPrefix(result, "+OK") {
+		return s.DB.Model(&job).Updates(map[string]interface{}{
+			"status": models.OriginateJobStatusStarted,
+			"result": result,
+		}).Error
+	}
+
+	return s.failJob(&job, strings.TrimSpace(strings.TrimPrefix(result, "-ERR")))
+}
+
+// failJob records the failure, closes the call and releases its admission,
+// since the switch will not send a CDR or hangup for a channel it never
+// created.
+func (s *OriginateService) failJob(job *models.OriginateJob, result string) error {
+	now := time.Now()
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(job).Updates(map[string]interface{}{
+			"status": models.OriginateJobStatusFailed,
+			"result": result,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Call{}).
+			Where("id = ? AND end_time IS NULL", job.CallID).
+			Updates(map[string]interface{}{
+				"end_time":    now,
+				"disposition": models.CallDispositionFailed,
+				"hangup_side": models.CallHangupSystem,
+			}).Error; err != nil {
+			return err
+		}
+
+		return NewAdmissionService(tx).Release(job.CallUUID, &job.TenantID)
+	})
+}
+
+func (s *OriginateService) GetJob(tenantID uint, jobUUID string) (*models.OriginateJob, error) {
+	var job models.OriginateJob
+
+	if err := s.DB.Where("job_uuid = ? AND tenant_id = ?", jobUUID, tenantID).
+		First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("originate job not found")
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
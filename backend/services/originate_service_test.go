@@ -0,0 +1,220 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/esl"
+	"github.com/your-module/backend/models"
+)
+
+// startOriginate runs an OriginateService against a fake switch that
+// answers every job with jobResult, and returns once it is connected.
+func startOriginate(t *testing.T, db *gorm.DB, jobResult string) (*OriginateService, *esl.FakeServer) {
+	t.Helper()
+
+	server, err := esl.NewFakeServer("ClueCon")
+	if err != nil {
+		t.Fatalf("starting fake switch: %v", err)
+	}
+	server.JobResult = jobResult
+	t.Cleanup(func() { server.Close() })
+
+	service := NewOriginateService(db, func() (esl.Client, error) {
+		return esl.Dial(server.Addr(), "ClueCon", time.Second, "BACKGROUND_JOB")
+	}, 20)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		service.Run(50*time.Millisecond, stop)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(stop)
+		<-done
+	})
+
+	waitFor(t, "the switch connection", func() bool { return service.currentClient() != nil })
+
+	return service, server
+}
+
+func createOriginateEndpoint(t *testing.T, db *gorm.DB, tenantID, userID uint) {
+	t.Helper()
+
+	endpoint := models.SipEndpoint{TenantID: tenantID, UserID: &userID, Extension: "1001", Password: "secret", IsActive: true}
+	if err := db.Create(&endpoint).Error; err != nil {
+		t.Fatalf("creating endpoint: %v", err)
+	}
+}
+
+func jobStatus(t *testing.T, db *gorm.DB, jobUUID string) models.OriginateJob {
+	t.Helper()
+
+	var job models.OriginateJob
+	if err := db.Where("job_uuid = ?", jobUUID).First(&job).Error; err != nil {
+		t.Fatalf("loading job: %v", err)
+	}
+	return job
+}
+
+func TestOriginateStartsJob(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConcurrentCalls: 5})
+	user := createTestUser(t, db, tenant.ID, "alice")
+	createOriginateEndpoint(t, db, tenant.ID, user.ID)
+	service, server := startOriginate(t, db, "+OK 5e2b0c4e")
+
+	job, err := service.Originate(tenant.ID, user.ID, OriginateRequest{Destination: " +14155550100 "})
+	if err != nil {
+		t.Fatalf("Originate: %v", err)
+	}
+	if job.Status != models.OriginateJobStatusPending || job.Extension != "1001" || job.Destination != "+14155550100" {
+		t.Errorf("new job = %+v", job)
+	}
+
+	waitFor(t, "the job result", func() bool {
+		return jobStatus(t, db, job.JobUUID).Status == models.OriginateJobStatusStarted
+	})
+	if result := jobStatus(t, db, job.JobUUID).Result; result != "+OK 5e2b0c4e" {
+		t.Errorf("job result = %q", result)
+	}
+
+	var sent string
+	for _, command := range server.Commands() {
+		if strings.HasPrefix(command, "bgapi originate ") {
+			sent = command
+		}
+	}
+	if !strings.Contains(sent, "origination_uuid="+job.CallUUID) || !strings.Contains(sent, "originate_timeout=20") ||
+		!strings.Contains(sent, "user/1001@acme.example.com +14155550100 XML acme.example.com") {
+		t.Errorf("originate command = %q", sent)
+	}
+
+	var call models.Call
+	if err := db.First(&call, job.CallID).Error; err != nil {
+		t.Fatalf("loading call: %v", err)
+	}
+	if call.UUID != job.CallUUID || call.Caller != "1001" || call.CalleeE164 != "+14155550100" || call.StartTime == nil || call.EndTime != nil {
+		t.Errorf("call = %+v", call)
+	}
+
+	if admissionReleased(t, db, job.CallUUID) {
+		t.Error("admission of a started call was released")
+	}
+
+	if _, err := service.GetJob(tenant.ID+1, job.JobUUID); err == nil || err.Error() != "originate job not found" {
+		t.Errorf("another tenant read the job: %v", err)
+	}
+}
+
+func TestOriginateFailedJobClosesCall(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConcurrentCalls: 1})
+	user := createTestUser(t, db, tenant.ID, "alice")
+	createOriginateEndpoint(t, db, tenant.ID, user.ID)
+	service, _ := startOriginate(t, db, "-ERR USER_BUSY")
+
+	job, err := service.Originate(tenant.ID, user.ID, OriginateRequest{Destination: "+14155550100"})
+	if err != nil {
+		t.Fatalf("Originate: %v", err)
+	}
+
+	waitFor(t, "the job result", func() bool {
+		return jobStatus(t, db, job.JobUUID).Status == models.OriginateJobStatusFailed
+	})
+	if result := jobStatus(t, db, job.JobUUID).Result; result != "USER_BUSY" {
+		t.Errorf("job result = %q, want USER_BUSY", result)
+	}
+
+	var call models.Call
+	if err := db.First(&call, job.CallID).Error; err != nil {
+		t.Fatalf("loading call: %v", err)
+	}
+	if call.EndTime == nil {
+		t.Error("call of a failed job was left open")
+	}
+	if !admissionReleased(t, db, job.CallUUID) {
+		t.Fatal("admission of a failed call was not released")
+	}
+
+	// The plan allows one call, so the next one only goes through because
+	// the failed call gave its slot back
+	if _, err := service.Originate(tenant.ID, user.ID, OriginateRequest{Destination: "+14155550100"}); err != nil {
+		t.Errorf("Originate after a failed job: %v", err)
+	}
+}
+
+func TestOriginateDeniedByAdmission(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxConcurrentCalls: 1})
+	user := createTestUser(t, db, tenant.ID, "alice")
+	createOriginateEndpoint(t, db, tenant.ID, user.ID)
+	service, server := startOriginate(t, db, "+OK")
+
+	if err := db.Create(&models.CallAdmission{TenantID: tenant.ID, CallUUID: "live-call", AdmittedAt: time.Now()}).Error; err != nil {
+		t.Fatalf("creating admission: %v", err)
+	}
+
+	_, err := service.Originate(tenant.ID, user.ID, OriginateRequest{Destination: "+14155550100"})
+	if err == nil || err.Error() != "call not admitted: "+AdmissionReasonConcurrent {
+		t.Fatalf("Originate error = %v, want the concurrent call limit", err)
+	}
+
+	var calls int64
+	db.Model(&models.Call{}).Count(&calls)
+	if calls != 0 {
+		t.Errorf("%d calls stored for a denied originate", calls)
+	}
+	for _, command := range server.Commands() {
+		if strings.HasPrefix(command, "bgapi ") {
+			t.Errorf("denied originate was sent to the switch: %q", command)
+		}
+	}
+}
+
+func TestOriginateRejects(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	user := createTestUser(t, db, tenant.ID, "alice")
+	other := createTestUser(t, db, tenant.ID, "bob")
+	createOriginateEndpoint(t, db, tenant.ID, user.ID)
+
+	disconnected := NewOriginateService(db, nil, 0)
+	if _, err := disconnected.Originate(tenant.ID, user.ID, OriginateRequest{Destination: "+14155550100"}); err == nil || err.Error() != "switch not connected" {
+		t.Errorf("Originate without a switch = %v", err)
+	}
+
+	service, server := startOriginate(t, db, "+OK")
+	tests := []struct {
+		name   string
+		userID uint
+		req    OriginateRequest
+		want   string
+	}{
+		{"dial string injection", user.ID, OriginateRequest{Destination: "1002 XML other.example.com"}, "invalid destination"},
+		{"empty destination", user.ID, OriginateRequest{}, "invalid destination"},
+		{"user without endpoint", other.ID, OriginateRequest{Destination: "1002"}, "sip endpoint not found"},
+		{"unknown endpoint", user.ID, OriginateRequest{EndpointID: new(uint), Destination: "1002"}, "sip endpoint not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Originate(tenant.ID, tt.userID, tt.req); err == nil || err.Error() != tt.want {
+				t.Errorf("Originate() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	var calls int64
+	db.Model(&models.Call{}).Count(&calls)
+	if calls != 0 {
+		t.Errorf("%d calls stored for rejected requests", calls)
+	}
+	for _, command := range server.Commands() {
+		if strings.HasPrefix(command, "bgapi ") {
+			t.Errorf("rejected request was sent to the switch: %q", command)
+		}
+	}
+}
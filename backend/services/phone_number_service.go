@@ -0,0 +1,520 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/phonenumber"
+)
+
+const (
+	maxPhoneNumberImport = 10000
+	// Keeps the import report bounded when a whole block is rejected.
+	maxPhoneNumberImportErrors = 1000
+)
+
+// PhoneNumberRoutingTypes are the accepted values of
+// PhoneNumber.RoutingType. An empty type leaves the number unrouted.
+var PhoneNumberRoutingTypes = map[string]bool{
+	"":          true,
+	"extension": true,
+	"sip_uri":   true,
+	"external":  true,
+}
+
+type PhoneNumberService struct {
+	DB *gorm.DB
+}
+
+func NewPhoneNumberService(db *gorm.DB) *PhoneNumberService {
+	return &PhoneNumberService{DB: db}
+}
+
+// PhoneNumberFilter narrows an inventory listing. Empty values mean "no
+// filter"; Prefix matches the start of the E.164 number.
+type PhoneNumberFilter struct {
+	TenantID *uint
+	Status   string
+	Country  string
+	Prefix   string
+}
+
+// PhoneNumberImport adds inventory either as a list of Numbers or as a
+// block of BlockSize consecutive numbers starting at BlockStart. Every
+// imported number gets the capabilities, cost and carrier of Template.
+type PhoneNumberImport struct {
+	Numbers    []string
+	BlockStart string
+	BlockSize  int
+	Template   models.PhoneNumber
+}
+
+type PhoneNumberImportResult struct {
+	Requested  int      `json:"requested"`
+	Created    int      `json:"created"`
+	Duplicates []string `json:"duplicates"`
+	Invalid    []string `json:"invalid"`
+}
+
+// parseInventoryNumber accepts numbers in international format only, since
+// inventory is not tied to a tenant's default country.
+func parseInventoryNumber(raw string) (phonenumber.Number, error) {
+	number, ok := phonenumber.Normalize(raw, "")
+	if !ok {
+		return phonenumber.Number{}, errors.New("invalid phone number")
+	}
+	return number, nil
+}
+
+func newInventoryNumber(number phonenumber.Number, template models.PhoneNumber) models.PhoneNumber {
+	return models.PhoneNumber{
+		Number:       number.E164,
+		Country:      number.Country,
+		NumberType:   number.Type,
+		Status:       models.PhoneNumberStatusAvailable,
+		VoiceEnabled: template.VoiceEnabled,
+		SMSEnabled:   template.SMSEnabled,
+		FaxEnabled:   template.FaxEnabled,
+		MonthlyCost:  template.MonthlyCost,
+		Carrier:      template.Carrier,
+	}
+}
+
+func (s *PhoneNumberService) CreateNumber(raw string, template models.PhoneNumber) (*models.PhoneNumber, error) {
+	parsed, err := parseInventoryNumber(raw)
+	if err != nil {
+		return nil, err
+	}
+	if template.MonthlyCost < 0 {
+		return nil, errors.New("monthly cost cannot be negative")
+	}
+
+	number := newInventoryNumber(parsed, template)
+	result := s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "number"}},
+		DoNothing: true,
+	}).Create(&number)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("phone number already exists")
+	}
+
+	return &number, nil
+}
+
+// ImportNumbers adds a list or block of numbers to the inventory. Numbers
+// already in the inventory are reported as duplicates and left untouched.
+func (s *PhoneNumberService) ImportNumbers(req PhoneNumberImport) (*PhoneNumberImportResult, error) {
+	if req.Template.MonthlyCost < 0 {
+		return nil, errors.New("monthly cost cannot be negative")
+	}
+
+	raws := req.Numbers
+	if req.BlockStart != "" {
+		block, err := expandNumberBlock(req.BlockStart, req.BlockSize)
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, block...)
+	}
+
+	if len(raws) == 0 {
+		return nil, errors.New("no phone numbers to import")
+	}
+	if len(raws) > maxPhoneNumberImport {
+		return nil, errors.New("too many phone numbers to import")
+	}
+
+	result := &PhoneNumberImportResult{
+		Requested:  len(raws),
+		Duplicates: []string{},
+		Invalid:    []string{},
+	}
+
+	seen := map[string]bool{}
+	numbers := make([]models.PhoneNumber, 0, len(raws))
+	for _, raw := range raws {
+		parsed, err := parseInventoryNumber(raw)
+		if err != nil {
+			if len(result.Invalid) < maxPhoneNumberImportErrors {
+				result.Invalid = append(result.Invalid, raw)
+			}
+			continue
+		}
+		if seen[parsed.E164] {
+			result.Duplicates = append(result.Duplicates, parsed.E164)
+			continue
+		}
+		seen[parsed.E164] = true
+		numbers = append(numbers, newInventoryNumber(parsed, req.Template))
+	}
+
+	if len(numbers) == 0 {
+		return result, nil
+	}
+
+	values := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		values = append(values, number.Number)
+	}
+
+	var existing []string
+	if err := s.DB.Unscoped().Model(&models.PhoneNumber{}).
+		Where("number IN ?", values).
+		Pluck("number", &existing).Error; err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, number := range existing {
+		exists[number] = true
+		result.Duplicates = append(result.Duplicates, number)
+	}
+
+	batch := make([]models.PhoneNumber, 0, len(numbers))
+	for _, number := range numbers {
+		if !exists[number.Number] {
+			batch = append(batch, number)
+		}
+	}
+
+	if len(batch) > 0 {
+		insert := s.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "number"}},
+			DoNothing: true,
+		}).CreateInBatches(&batch, 500)
+		if insert.Error != nil {
+			return nil, insert.Error
+		}
+		result.Created = int(insert.RowsAffected)
+	}
+
+	return result, nil
+}
+
+// expandNumberBlock returns size consecutive numbers starting at start,
+// keeping its length, e.g. +551130000000 … +551130000099.
+func expandNumberBlock(start string, size int) ([]string, error) {
+	if size <= 0 || size > maxPhoneNumberImport {
+		return nil, errors.New("invalid block size")
+	}
+
+	parsed, err := parseInventoryNumber(start)
+	if err != nil {
+		return nil, errors.New("invalid block start")
+	}
+
+	digits := strings.TrimPrefix(parsed.E164, "+")
+	first, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid block start")
+	}
+
+	block := make([]string, 0, size)
+	for i := 0; i < size; i++ {
+		next := strconv.FormatUint(first+uint64(i), 10)
+		if len(next) != len(digits) {
+			return nil, errors.New("number block overflows its range")
+		}
+		block = append(block, "+"+next)
+	}
+
+	return block, nil
+}
+
+func (s *PhoneNumberService) GetNumbers(filter PhoneNumberFilter) ([]models.PhoneNumber, error) {
+	var numbers []models.PhoneNumber
+
+	query := s.DB.Model(&models.PhoneNumber{})
+	if filter.TenantID != nil {
+		query = query.Where("tenant_id = ?", *filter.TenantID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Country != "" {
+		query = query.Where("country = ?", strings.ToUpper(filter.Country))
+	}
+	if filter.Prefix != "" {
+		query = query.Where("number LIKE ?", "+"+escapeLike(strings.TrimPrefix(filter.Prefix, "+"))+"%")
+	}
+
+	if err := query.Order("number").Find(&numbers).Error; err != nil {
+		return nil, err
+	}
+
+	return numbers, nil
+}
+
+// GetNumberByID returns a number with its assignment history. When tenantID
+// is set the number must belong to that tenant and only its own
+// assignments are included.
+func (s *PhoneNumberService) GetNumberByID(numberID uint, tenantID *uint) (*models.PhoneNumber, error) {
+	var number models.PhoneNumber
+
+	query := s.DB.Where("id = ?", numberID)
+	if tenantID != nil {
+		query = query.Where("tenant_id = ?", *tenantID)
+	}
+
+	if err := query.Preload("Assignments", func(db *gorm.DB) *gorm.DB {
+		if tenantID != nil {
+			db = db.Where("tenant_id = ?", *tenantID)
+		}
+		return db.Order("assigned_at DESC")
+	}).First(&number).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("phone number not found")
+		}
+		return nil, err
+	}
+
+	return &number, nil
+}
+
+// UpdateNumber changes the inventory attributes of a number. Status may
+// only toggle suspension; assignment goes through AssignNumber and
+// ReleaseNumber.
+func (s *PhoneNumberService) UpdateNumber(numberID uint, changes models.PhoneNumber) (*models.PhoneNumber, error) {
+	number, err := s.GetNumberByID(numberID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.MonthlyCost < 0 {
+		return nil, errors.New("monthly cost cannot be negative")
+	}
+
+	status := number.Status
+	switch changes.Status {
+	case "":
+	case models.PhoneNumberStatusSuspended:
+		status = models.PhoneNumberStatusSuspended
+	case models.PhoneNumberStatusAvailable, models.PhoneNumberStatusAssigned:
+		// Lifting a suspension restores the state implied by the tenant
+		status = models.PhoneNumberStatusAvailable
+		if number.TenantID != nil {
+			status = models.PhoneNumberStatusAssigned
+		}
+	default:
+		return nil, errors.New("invalid phone number status")
+	}
+
+	if err := s.DB.Model(number).Updates(map[string]interface{}{
+		"status":        status,
+		"voice_enabled": changes.VoiceEnabled,
+		"sms_enabled":   changes.SMSEnabled,
+		"fax_enabled":   changes.FaxEnabled,
+		"monthly_cost":  changes.MonthlyCost,
+		"carrier":       changes.Carrier,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return number, nil
+}
+
+func (s *PhoneNumberService) DeleteNumber(numberID uint) error {
+	number, err := s.GetNumberByID(numberID, nil)
+	if err != nil {
+		return err
+	}
+
+	if number.TenantID != nil {
+		return errors.New("cannot delete an assigned phone number")
+	}
+
+	return s.DB.Delete(number).Error
+}
+
+// AssignNumber gives an available number to a tenant, enforcing the
+// MaxPhoneNumbers limit of the tenant's plan. The tenant row is locked so
+// concurrent assignments cannot both slip under the limit.
+func (s *PhoneNumberService) AssignNumber(numberID, tenantID uint, userID *uint) (*models.PhoneNumber, error) {
+	var number models.PhoneNumber
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&tenant, tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tenant not found")
+			}
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&number, numberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("phone number not found")
+			}
+			return err
+		}
+
+		if number.Status != models.PhoneNumberStatusAvailable || number.TenantID != nil {
+			return errors.New("phone number is not available")
+		}
+
+		subscription, err := NewSubscriptionService(tx).GetTenantSubscription(tenantID)
+		if err != nil {
+			if err.Error() == "subscription not found" {
+				return errors.New("no active subscription found")
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.PhoneNumber{}).
+			Where("tenant_id = ?", tenantID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+
+		if subscription.Plan.MaxPhoneNumbers > 0 && uint(count) >= subscription.Plan.MaxPhoneNumbers {
+			return errors.New("quota exceeded: max phone numbers reached")
+		}
+
+		now := time.Now()
+		if err := tx.Model(&number).Updates(map[string]interface{}{
+			"tenant_id":           tenantID,
+			"status":              models.PhoneNumberStatusAssigned,
+			"assigned_at":         now,
+			"routing_type":        "",
+			"routing_destination": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.PhoneNumberAssignment{
+			PhoneNumberID: number.ID,
+			TenantID:      tenantID,
+			AssignedAt:    now,
+			AssignedBy:    userID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &number, nil
+}
+
+// ReleaseNumber returns a number to the available inventory and closes its
+// assignment. When tenantID is set the number must belong to that tenant.
+func (s *PhoneNumberService) ReleaseNumber(numberID uint, tenantID *uint, userID *uint) (*models.PhoneNumber, error) {
+	var number models.PhoneNumber
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", numberID)
+		if tenantID != nil {
+			query = query.Where("tenant_id = ?", *tenantID)
+		}
+
+		if err := query.First(&number).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("phone number not found")
+			}
+			return err
+		}
+
+		if number.TenantID == nil {
+			return errors.New("phone number is not assigned")
+		}
+		previousTenant := *number.TenantID
+
+		status := models.PhoneNumberStatusAvailable
+		if number.Status == models.PhoneNumberStatusSuspended {
+			status = models.PhoneNumberStatusSuspended
+		}
+
+		if err := tx.Model(&number).Updates(map[string]interface{}{
+			"tenant_id":           nil,
+			"status":              status,
+			"assigned_at":         nil,
+			"routing_type":        "",
+			"routing_destination": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.PhoneNumberAssignment{}).
+			Where("phone_number_id = ? AND tenant_id = ? AND released_at IS NULL", number.ID, previousTenant).
+			Updates(map[string]interface{}{
+				"released_at": time.Now(),
+				"released_by": userID,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &number, nil
+}
+
+// SetRouting points inbound calls for one of the tenant's numbers at a
+// destination.
+func (s *PhoneNumberService) SetRouting(tenantID, numberID uint, routingType, destination string) (*models.PhoneNumber, error) {
+	if !PhoneNumberRoutingTypes[routingType] {
+		return nil, errors.New("invalid routing type")
+	}
+	destination = strings.TrimSpace(destination)
+	if routingType != "" && destination == "" {
+		return nil, errors.New("routing destination is required")
+	}
+	if routingType == "" {
+		destination = ""
+	}
+
+	// The destination ends up in switch dialplan arguments, so it is held
+	// to a strict format per type.
+	switch routingType {
+	case "extension":
+		if !isDigits(destination) {
+			return nil, errors.New("invalid routing destination")
+		}
+	case "sip_uri":
+		destination = strings.TrimPrefix(destination, "sip:")
+		if !validSipURI(destination) {
+			return nil, errors.New("invalid routing destination")
+		}
+	case "external":
+		parsed, err := parseInventoryNumber(destination)
+		if err != nil {
+			return nil, errors.New("invalid routing destination")
+		}
+		destination = parsed.E164
+	}
+
+	var number models.PhoneNumber
+	if err := s.DB.Where("id = ? AND tenant_id = ?", numberID, tenantID).
+		First(&number).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("phone number not found")
+		}
+		return nil, err
+	}
+
+	if err := s.DB.Model(&number).Updates(map[string]interface{}{
+		"routing_type":        routingType,
+		"routing_destination": destination,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &number, nil
+}
+
+// validSipURI accepts user@host[:port] without whitespace or characters
+// that would break out of a dial string.
+func validSipURI(uri string) bool {
+	user, host, ok := strings.Cut(uri, "@")
+	if !ok || user == "" || host == "" {
+		return false
+	}
+	return !strings.ContainsAny(uri, " \t\r\n,;{}[]'\"|<>")
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"github.com/your-module/backend/models"
+)
+
+func TestExpandNumberBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		size  int
+		want  []string
+		err   string
+	}{
+		{"block", "+55 11 3000-0098", 3, []string{"+551130000098", "+551130000099", "+551130000100"}, ""},
+		{"zero size", "+551130000000", 0, nil, "invalid block size"},
+		{"too large", "+551130000000", maxPhoneNumberImport + 1, nil, "invalid block size"},
+		{"bad start", "3000", 2, nil, "invalid block start"},
+		{"carry", "+14155559999", 2, []string{"+14155559999", "+14155560000"}, ""},
+		{"unknown calling code", "+999999999999", 2, nil, "invalid block start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandNumberBlock(tt.start, tt.size)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expandNumberBlock() = %v, %v, want %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandNumberBlock() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportNumbers(t *testing.T) {
+	db := newTestDB(t)
+	service := NewPhoneNumberService(db)
+
+	if _, err := service.CreateNumber("+1 415 555 0101", models.PhoneNumber{}); err != nil {
+		t.Fatalf("CreateNumber: %v", err)
+	}
+	if _, err := service.CreateNumber("+14155550101", models.PhoneNumber{}); err == nil || err.Error() != "phone number already exists" {
+		t.Errorf("creating a duplicate = %v", err)
+	}
+
+	result, err := service.ImportNumbers(PhoneNumberImport{
+		Numbers:    []string{"+14155550100", "+1 (415) 555-0100", "1001", "+447700900123"},
+		BlockStart: "+14155550100",
+		BlockSize:  3,
+		Template:   models.PhoneNumber{VoiceEnabled: true, SMSEnabled: true, MonthlyCost: 1.5, Carrier: "acme"},
+	})
+	if err != nil {
+		t.Fatalf("ImportNumbers: %v", err)
+	}
+	if result.Requested != 7 || result.Created != 3 {
+		t.Errorf("requested %d created %d, want 7 and 3", result.Requested, result.Created)
+	}
+	if !reflect.DeepEqual(result.Invalid, []string{"1001"}) {
+		t.Errorf("invalid = %v", result.Invalid)
+	}
+	if len(result.Duplicates) != 3 {
+		t.Errorf("duplicates = %v, want the repeated entries and the stored number", result.Duplicates)
+	}
+
+	numbers, err := service.GetNumbers(PhoneNumberFilter{Country: "us", Prefix: "1415555010"})
+	if err != nil || len(numbers) != 3 {
+		t.Fatalf("GetNumbers() = %d numbers, %v", len(numbers), err)
+	}
+	if numbers[0].Number != "+14155550100" || numbers[0].Status != models.PhoneNumberStatusAvailable ||
+		!numbers[0].SMSEnabled || numbers[0].MonthlyCost != 1.5 || numbers[0].Carrier != "acme" {
+		t.Errorf("imported number = %+v", numbers[0])
+	}
+
+	mobile, _ := service.GetNumbers(PhoneNumberFilter{Country: "GB"})
+	if len(mobile) != 1 || mobile[0].NumberType != "mobile" {
+		t.Errorf("GB numbers = %+v", mobile)
+	}
+
+	if _, err := service.ImportNumbers(PhoneNumberImport{}); err == nil || err.Error() != "no phone numbers to import" {
+		t.Errorf("empty import = %v", err)
+	}
+}
+
+func TestAssignAndReleaseNumber(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxPhoneNumbers: 1})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{MaxPhoneNumbers: 5})
+	service := NewPhoneNumberService(db)
+
+	first, _ := service.CreateNumber("+14155550100", models.PhoneNumber{})
+	second, _ := service.CreateNumber("+14155550101", models.PhoneNumber{})
+	admin := uint(1)
+
+	assigned, err := service.AssignNumber(first.ID, tenant.ID, &admin)
+	if err != nil {
+		t.Fatalf("AssignNumber: %v", err)
+	}
+	if assigned.Status != models.PhoneNumberStatusAssigned || assigned.TenantID == nil || *assigned.TenantID != tenant.ID {
+		t.Errorf("assigned number = %+v", assigned)
+	}
+
+	if _, err := service.AssignNumber(second.ID, tenant.ID, &admin); err == nil || err.Error() != "quota exceeded: max phone numbers reached" {
+		t.Errorf("assigning past the plan limit = %v", err)
+	}
+	if _, err := service.AssignNumber(first.ID, other.ID, &admin); err == nil || err.Error() != "phone number is not available" {
+		t.Errorf("assigning a taken number = %v", err)
+	}
+	if err := service.DeleteNumber(first.ID); err == nil || err.Error() != "cannot delete an assigned phone number" {
+		t.Errorf("deleting an assigned number = %v", err)
+	}
+
+	if _, err := service.SetRouting(tenant.ID, first.ID, "extension", " 1001 "); err != nil {
+		t.Fatalf("SetRouting: %v", err)
+	}
+	if _, err := service.SetRouting(other.ID, first.ID, "extension", "1001"); err == nil || err.Error() != "phone number not found" {
+		t.Errorf("routing another tenant's number = %v", err)
+	}
+	if _, err := service.SetRouting(tenant.ID, first.ID, "queue", "1001"); err == nil || err.Error() != "invalid routing type" {
+		t.Errorf("unknown routing type = %v", err)
+	}
+
+	otherID := other.ID
+	if _, err := service.ReleaseNumber(first.ID, &otherID, &admin); err == nil || err.Error() != "phone number not found" {
+		t.Errorf("other tenant released the number: %v", err)
+	}
+	tenantID := tenant.ID
+	released, err := service.ReleaseNumber(first.ID, &tenantID, &admin)
+	if err != nil {
+		t.Fatalf("ReleaseNumber: %v", err)
+	}
+	if released.Status != models.PhoneNumberStatusAvailable || released.TenantID != nil || released.RoutingType != "" {
+		t.Errorf("released number = %+v", released)
+	}
+
+	if _, err := service.AssignNumber(first.ID, other.ID, &admin); err != nil {
+		t.Fatalf("reassigning: %v", err)
+	}
+
+	history, err := service.GetNumberByID(first.ID, nil)
+	if err != nil {
+		t.Fatalf("GetNumberByID: %v", err)
+	}
+	if len(history.Assignments) != 2 {
+		t.Fatalf("%d assignments, want 2", len(history.Assignments))
+	}
+	for _, assignment := range history.Assignments {
+		if (assignment.TenantID == tenant.ID) != (assignment.ReleasedAt != nil) {
+			t.Errorf("assignment %+v, want only the first one released", assignment)
+		}
+	}
+
+	// A tenant only sees its own assignments
+	own, err := service.GetNumberByID(first.ID, &otherID)
+	if err != nil || len(own.Assignments) != 1 || own.Assignments[0].TenantID != other.ID {
+		t.Errorf("tenant view = %+v, %v", own, err)
+	}
+	if _, err := service.GetNumberByID(first.ID, &tenantID); err == nil {
+		t.Error("previous tenant still sees the number")
+	}
+}
+
+func TestUpdateNumberSuspension(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxPhoneNumbers: 5})
+	service := NewPhoneNumberService(db)
+
+	number, _ := service.CreateNumber("+14155550100", models.PhoneNumber{})
+	service.AssignNumber(number.ID, tenant.ID, nil)
+
+	suspended, err := service.UpdateNumber(number.ID, models.PhoneNumber{Status: models.PhoneNumberStatusSuspended})
+	if err != nil || suspended.Status != models.PhoneNumberStatusSuspended {
+		t.Fatalf("suspending = %+v, %v", suspended, err)
+	}
+
+	restored, err := service.UpdateNumber(number.ID, models.PhoneNumber{Status: models.PhoneNumberStatusAvailable})
+	if err != nil || restored.Status != models.PhoneNumberStatusAssigned {
+		t.Errorf("lifting the suspension = %+v, %v, want the number assigned again", restored, err)
+	}
+
+	if _, err := service.UpdateNumber(number.ID, models.PhoneNumber{Status: "ported"}); err == nil || err.Error() != "invalid phone number status" {
+		t.Errorf("unknown status = %v", err)
+	}
+}
@@ -0,0 +1,569 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+// queueWaitingMaxAge bounds how long a call counts as waiting when its
+// leaving the queue was never reported.
+const queueWaitingMaxAge = 24 * time.Hour
+
+var queueStrategies = map[string]bool{
+	models.QueueStrategyRingAll:     true,
+	models.QueueStrategyLongestIdle: true,
+	models.QueueStrategyRoundRobin:  true,
+}
+
+var callEventTypes = map[string]bool{
+	models.CallEventQueueEnter:   true,
+	models.CallEventQueueAnswer:  true,
+	models.CallEventQueueAbandon: true,
+	models.CallEventQueueExit:    true,
+	models.CallEventAgentHangup:  true,
+}
+
+type QueueService struct {
+	DB *gorm.DB
+}
+
+func NewQueueService(db *gorm.DB) *QueueService {
+	return &QueueService{DB: db}
+}
+
+// QueueStats are a queue's contact-center metrics. Waiting is the calls in
+// the queue now; the rest cover the calls that entered it within the
+// requested range. Service level is the percentage of those calls answered
+// within the queue's ServiceLevelSeconds, and abandon rate the percentage
+// the caller hung up on, both out of the calls that have left the queue.
+type QueueStats struct {
+	QueueID             uint           `json:"queue_id"`
+	Waiting             int64          `json:"waiting"`
+	Offered             int64          `json:"offered"`
+	Answered            int64          `json:"answered"`
+	Abandoned           int64          `json:"abandoned"`
+	Exited              int64          `json:"exited"`
+	AverageWait         float64        `json:"average_wait"`
+	ServiceLevelSeconds int            `json:"service_level_seconds"`
+	ServiceLevel        float64        `json:"service_level"`
+	AbandonRate         float64        `json:"abandon_rate"`
+	Agents              map[string]int `json:"agents"`
+}
+
+type queueStatsRow struct {
+	Offered          int64
+	Answered         int64
+	Abandoned        int64
+	Exited           int64
+	AnsweredWithinSL int64
+	AverageWait      float64
+}
+
+func validateQueue(queue *models.Queue) error {
+	queue.Name = strings.TrimSpace(queue.Name)
+	if queue.Name == "" {
+		return errors.New("name is required")
+	}
+	if queue.Strategy == "" {
+		queue.Strategy = models.QueueStrategyRingAll
+	}
+	if !queueStrategies[queue.Strategy] {
+		return errors.New("invalid queue strategy")
+	}
+	if queue.ServiceLevelSeconds <= 0 {
+		queue.ServiceLevelSeconds = 20
+	}
+	if queue.AgentTimeout <= 0 {
+		queue.AgentTimeout = 20
+	}
+	if queue.WrapUpSeconds < 0 {
+		return errors.New("wrap-up seconds cannot be negative")
+	}
+	return nil
+}
+
+func (s *QueueService) CreateQueue(tenantID uint, queue *models.Queue) (*models.Queue, error) {
+	queue.ID = 0
+	queue.TenantID = tenantID
+	queue.Agents = nil
+	if err := validateQueue(queue); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Create(queue).Error; err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+func (s *QueueService) GetQueues(tenantID uint) ([]models.Queue, error) {
+	var queues []models.Queue
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("name").
+		Find(&queues).Error; err != nil {
+		return nil, err
+	}
+
+	return queues, nil
+}
+
+func (s *QueueService) GetQueueByID(tenantID, queueID uint) (*models.Queue, error) {
+	var queue models.Queue
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", queueID, tenantID).
+		First(&queue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("queue not found")
+		}
+		return nil, err
+	}
+
+	return &queue, nil
+}
+
+func (s *QueueService) UpdateQueue(tenantID, queueID uint, changes *models.Queue) (*models.Queue, error) {
+	queue, err := s.GetQueueByID(tenantID, queueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateQueue(changes); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Model(queue).Updates(map[string]interface{}{
+		"name":                  changes.Name,
+		"strategy":              changes.Strategy,
+		"service_level_seconds": changes.ServiceLevelSeconds,
+		"wrap_up_seconds":       changes.WrapUpSeconds,
+		"agent_timeout":         changes.AgentTimeout,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// DeleteQueue refuses to delete a queue a routing rule still sends calls
+// to. Its agents are removed; its call events are kept for reporting.
+func (s *QueueService) DeleteQueue(tenantID, queueID uint) error {
+	queue, err := s.GetQueueByID(tenantID, queueID)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.RoutingRule{}).
+		Where("tenant_id = ? AND target_type = ? AND target_value = ?", tenantID, "queue", strconv.FormatUint(uint64(queue.ID), 10)).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("queue is used by a routing rule")
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("queue_id = ?", queue.ID).Delete(&models.QueueAgent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(queue).Error
+	})
+}
+
+// expireWrapUps returns agents whose wrap-up time has run out to available.
+// Wrap-up is ended lazily, whenever agent state is read or used.
+func (s *QueueService) expireWrapUps(tenantID uint) error {
+	now := time.Now()
+
+	return s.DB.Exec(`UPDATE queue_agents SET state = ?, state_changed_at = ?, updated_at = ?
+		FROM queues
+		WHERE queues.id = queue_agents.queue_id
+		AND queue_agents.tenant_id = ?
+		AND queue_agents.state = ?
+		AND queue_agents.state_changed_at + queues.wrap_up_seconds * interval '1 second' <= ?`,
+		models.AgentStateAvailable, now, now, tenantID, models.AgentStateWrapUp, now).Error
+}
+
+func (s *QueueService) GetAgents(tenantID, queueID uint) ([]models.QueueAgent, error) {
+	if _, err := s.GetQueueByID(tenantID, queueID); err != nil {
+		return nil, err
+	}
+	if err := s.expireWrapUps(tenantID); err != nil {
+		return nil, err
+	}
+
+	var agents []models.QueueAgent
+	if err := s.DB.Preload("User").
+		Where("queue_id = ? AND tenant_id = ?", queueID, tenantID).
+		Order("id").
+		Find(&agents).Error; err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+// AddAgent makes a user of the tenant an agent of the queue. New agents
+// start available.
+func (s *QueueService) AddAgent(tenantID, queueID, userID uint) (*models.QueueAgent, error) {
+	if _, err := s.GetQueueByID(tenantID, queueID); err != nil {
+		return nil, err
+	}
+	if err := checkEndpointUser(s.DB, tenantID, &userID); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.QueueAgent{}).
+		Where("queue_id = ? AND user_id = ?", queueID, userID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("user is already an agent of this queue")
+	}
+
+	agent := models.QueueAgent{
+		TenantID:       tenantID,
+		QueueID:        queueID,
+		UserID:         userID,
+		State:          models.AgentStateAvailable,
+		StateChangedAt: time.Now(),
+	}
+	if err := s.DB.Create(&agent).Error; err != nil {
+		return nil, err
+	}
+
+	return &agent, nil
+}
+
+func (s *QueueService) getAgent(tenantID, queueID, agentID uint) (*models.QueueAgent, error) {
+	var agent models.QueueAgent
+
+	if err := s.DB.Where("id = ? AND queue_id = ? AND tenant_id = ?", agentID, queueID, tenantID).
+		First(&agent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("queue agent not found")
+		}
+		return nil, err
+	}
+
+	return &agent, nil
+}
+
+func (s *QueueService) RemoveAgent(tenantID, queueID, agentID uint) error {
+	agent, err := s.getAgent(tenantID, queueID, agentID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Delete(agent).Error
+}
+
+// agentStateUpdate checks a manual state change. Agents can only make
+// themselves available or pause, with a reason; on-call and wrap-up follow
+// the calls they take.
+func agentStateUpdate(state, reason string) (map[string]interface{}, error) {
+	reason = strings.TrimSpace(reason)
+
+	switch state {
+	case models.AgentStateAvailable:
+		reason = ""
+	case models.AgentStatePaused:
+		if reason == "" {
+			return nil, errors.New("pause reason is required")
+		}
+	default:
+		return nil, errors.New("invalid agent state")
+	}
+
+	return map[string]interface{}{
+		"state":            state,
+		"pause_reason":     reason,
+		"state_changed_at": time.Now(),
+	}, nil
+}
+
+func (s *QueueService) SetAgentState(tenantID, queueID, agentID uint, state, reason string) (*models.QueueAgent, error) {
+	updates, err := agentStateUpdate(state, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.getAgent(tenantID, queueID, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent.State == models.AgentStateOnCall {
+		return nil, errors.New("agent is on a call")
+	}
+
+	if err := s.DB.Model(agent).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return agent, nil
+}
+
+// SetUserState changes the state of all of a user's queue memberships
+// that are not on a call, as when an agent pauses from their console.
+func (s *QueueService) SetUserState(tenantID, userID uint, state, reason string) ([]models.QueueAgent, error) {
+	updates, err := agentStateUpdate(state, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.DB.Model(&models.QueueAgent{}).
+		Where("tenant_id = ? AND user_id = ? AND state <> ?", tenantID, userID, models.AgentStateOnCall).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var agents []models.QueueAgent
+	if err := s.DB.Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("queue_id").
+		Find(&agents).Error; err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, errors.New("queue agent not found")
+	}
+
+	return agents, nil
+}
+
+// QueueMember is an available agent and the extension to ring.
+type QueueMember struct {
+	AgentID   uint
+	Extension string
+}
+
+// OfferCall lists the available agents to ring for a new call, in the
+// order the queue's strategy gives them: all at once for ring-all, by time
+// since their last call for longest-idle, and by time since they were last
+// offered a call for round-robin. Only agents with an active SIP endpoint
+// are included. The first agent is marked as offered.
+func (s *QueueService) OfferCall(tenantID, queueID uint) (*models.Queue, []QueueMember, error) {
+	queue, err := s.GetQueueByID(tenantID, queueID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.expireWrapUps(tenantID); err != nil {
+		return nil, nil, err
+	}
+
+	order := "queue_agents.id"
+	switch queue.Strategy {
+	case models.QueueStrategyLongestIdle:
+		order = "queue_agents.last_call_ended_at ASC NULLS FIRST, queue_agents.id"
+	case models.QueueStrategyRoundRobin:
+		order = "queue_agents.last_offered_at ASC NULLS FIRST, queue_agents.id"
+	}
+
+	// One endpoint per agent: the lowest active extension of the user
+	var members []QueueMember
+	if err := s.DB.Table("queue_agents").
+		Select("queue_agents.id AS agent_id, MIN(sip_endpoints.extension) AS extension").
+		Joins("JOIN sip_endpoints ON sip_endpoints.user_id = queue_agents.user_id AND sip_endpoints.tenant_id = queue_agents.tenant_id AND sip_endpoints.is_active AND sip_endpoints.deleted_at IS NULL").
+		Where("queue_agents.queue_id = ? AND queue_agents.tenant_id = ? AND queue_agents.state = ?", queueID, tenantID, models.AgentStateAvailable).
+		Group("queue_agents.id").
+		Order(order).
+		Scan(&members).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if len(members) > 0 {
+		if err := s.DB.Model(&models.QueueAgent{}).
+			Where("id = ?", members[0].AgentID).
+			Update("last_offered_at", time.Now()).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return queue, members, nil
+}
+
+// RecordCallEvent stores an event reported by the switch and applies it to
+// the agent involved: answering puts the agent on a call, and hanging up
+// starts wrap-up, or makes the agent available if the queue has none.
+func (s *QueueService) RecordCallEvent(tenantID uint, event *models.CallEvent) (*models.CallEvent, error) {
+	event.ID = 0
+	event.TenantID = tenantID
+	event.CallUUID = strings.TrimSpace(event.CallUUID)
+	if event.CallUUID == "" {
+		return nil, errors.New("call uuid is required")
+	}
+	if !callEventTypes[event.Type] {
+		return nil, errors.New("invalid call event type")
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.QueueID == nil {
+		return nil, errors.New("queue is required")
+	}
+
+	queue, err := s.GetQueueByID(tenantID, *event.QueueID)
+	if err != nil {
+		return nil, err
+	}
+
+	var agent *models.QueueAgent
+	if event.AgentID != nil {
+		agent, err = s.getAgent(tenantID, queue.ID, *event.AgentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if agent == nil && (event.Type == models.CallEventQueueAnswer || event.Type == models.CallEventAgentHangup) {
+		return nil, errors.New("agent is required")
+	}
+
+	var call models.Call
+	err = s.DB.Select("id").Where("uuid = ? AND tenant_id = ?", event.CallUUID, tenantID).First(&call).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		event.CallID = &call.ID
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case models.CallEventQueueAnswer:
+			return tx.Model(agent).Updates(map[string]interface{}{
+				"state":            models.AgentStateOnCall,
+				"pause_reason":     "",
+				"state_changed_at": event.OccurredAt,
+			}).Error
+		case models.CallEventAgentHangup:
+			state := models.AgentStateWrapUp
+			if queue.WrapUpSeconds == 0 {
+				state = models.AgentStateAvailable
+			}
+			return tx.Model(agent).Updates(map[string]interface{}{
+				"state":              state,
+				"state_changed_at":   event.OccurredAt,
+				"last_call_ended_at": event.OccurredAt,
+			}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// linkCallEvents attaches events that arrived before their call was stored.
+func linkCallEvents(db *gorm.DB, call *models.Call) error {
+	return db.Model(&models.CallEvent{}).
+		Where("call_uuid = ? AND tenant_id = ? AND call_id IS NULL", call.UUID, call.TenantID).
+		Update("call_id", call.ID).Error
+}
+
+func (s *QueueService) GetQueueStats(tenantID, queueID uint, from, to time.Time) (*QueueStats, error) {
+	if from.IsZero() || to.IsZero() || !to.After(from) {
+		return nil, errors.New("a valid from/to range is required")
+	}
+
+	queue, err := s.GetQueueByID(tenantID, queueID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.expireWrapUps(tenantID); err != nil {
+		return nil, err
+	}
+
+	outcomes := []string{models.CallEventQueueAnswer, models.CallEventQueueAbandon, models.CallEventQueueExit}
+
+	// Each call's first entry into the queue in the range, paired with the
+	// first way it left the queue afterwards
+	var row queueStatsRow
+	if err := s.DB.Raw(`WITH entered AS (
+			SELECT call_uuid, MIN(occurred_at) AS entered_at
+			FROM call_events
+			WHERE tenant_id = ? AND queue_id = ? AND type = ? AND occurred_at >= ? AND occurred_at < ?
+			GROUP BY call_uuid
+		), outcome AS (
+			SELECT DISTINCT ON (e.call_uuid) e.call_uuid, e.type, e.occurred_at
+			FROM call_events e
+			JOIN entered ON entered.call_uuid = e.call_uuid AND e.occurred_at >= entered.entered_at
+			WHERE e.tenant_id = ? AND e.queue_id = ? AND e.type IN ?
+			ORDER BY e.call_uuid, e.occurred_at
+		)
+		SELECT
+			COUNT(*) AS offered,
+			COUNT(*) FILTER (WHERE outcome.type = ?) AS answered,
+			COUNT(*) FILTER (WHERE outcome.type = ?) AS abandoned,
+			COUNT(*) FILTER (WHERE outcome.type = ?) AS exited,
+			COUNT(*) FILTER (WHERE outcome.type = ? AND outcome.occurred_at - entered.entered_at <= ? * interval '1 second') AS answered_within_sl,
+			COALESCE(AVG(EXTRACT(EPOCH FROM outcome.occurred_at - entered.entered_at)) FILTER (WHERE outcome.type = ?), 0) AS average_wait
+		FROM entered
+		LEFT JOIN outcome ON outcome.call_uuid = entered.call_uuid`,
+		tenantID, queueID, models.CallEventQueueEnter, from, to,
+		tenantID, queueID, outcomes,
+		models.CallEventQueueAnswer, models.CallEventQueueAbandon, models.CallEventQueueExit,
+		models.CallEventQueueAnswer, queue.ServiceLevelSeconds,
+		models.CallEventQueueAnswer,
+	).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &QueueStats{
+		QueueID:             queue.ID,
+		Offered:             row.Offered,
+		Answered:            row.Answered,
+		Abandoned:           row.Abandoned,
+		Exited:              row.Exited,
+		AverageWait:         roundTo(row.AverageWait, 2),
+		ServiceLevelSeconds: queue.ServiceLevelSeconds,
+		Agents:              map[string]int{},
+	}
+	if left := row.Answered + row.Abandoned + row.Exited; left > 0 {
+		stats.ServiceLevel = roundTo(float64(row.AnsweredWithinSL)/float64(left)*100, 2)
+		stats.AbandonRate = roundTo(float64(row.Abandoned)/float64(left)*100, 2)
+	}
+
+	if err := s.DB.Raw(`SELECT COUNT(DISTINCT e.call_uuid)
+		FROM call_events e
+		WHERE e.tenant_id = ? AND e.queue_id = ? AND e.type = ? AND e.occurred_at > ?
+		AND NOT EXISTS (
+			SELECT 1 FROM call_events o
+			WHERE o.call_uuid = e.call_uuid AND o.queue_id = e.queue_id
+			AND o.type IN ? AND o.occurred_at >= e.occurred_at
+		)`,
+		tenantID, queueID, models.CallEventQueueEnter, time.Now().Add(-queueWaitingMaxAge), outcomes,
+	).Scan(&stats.Waiting).Error; err != nil {
+		return nil, err
+	}
+
+	var states []struct {
+		State string
+		Count int
+	}
+	if err := s.DB.Model(&models.QueueAgent{}).
+		Select("state, COUNT(*) AS count").
+		Where("queue_id = ? AND tenant_id = ?", queueID, tenantID).
+		Group("state").
+		Scan(&states).Error; err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		stats.Agents[state.State] = state.Count
+	}
+
+	return stats, nil
+}
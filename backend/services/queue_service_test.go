@@ -0,0 +1,219 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+	"github.com/your-module/backend/models"
+)
+
+func TestValidateQueue(t *testing.T) {
+	tests := []struct {
+		name  string
+		queue models.Queue
+		want  string
+	}{
+		{"no name", models.Queue{Name: "  "}, "name is required"},
+		{"bad strategy", models.Queue{Name: "Sales", Strategy: "random"}, "invalid queue strategy"},
+		{"negative wrap-up", models.Queue{Name: "Sales", WrapUpSeconds: -1}, "wrap-up seconds cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateQueue(&tt.queue); err == nil || err.Error() != tt.want {
+				t.Errorf("validateQueue() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	queue := models.Queue{Name: " Sales "}
+	if err := validateQueue(&queue); err != nil {
+		t.Fatalf("validateQueue: %v", err)
+	}
+	if queue.Name != "Sales" || queue.Strategy != models.QueueStrategyRingAll || queue.ServiceLevelSeconds != 20 || queue.AgentTimeout != 20 {
+		t.Errorf("defaults = %+v", queue)
+	}
+}
+
+func TestQueueAgentStates(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewQueueService(db)
+
+	queue, err := service.CreateQueue(tenant.ID, &models.Queue{Name: "Support", WrapUpSeconds: 30})
+	if err != nil {
+		t.Fatalf("CreateQueue: %v", err)
+	}
+	user := createTestUser(t, db, tenant.ID, "alice")
+	agent, err := service.AddAgent(tenant.ID, queue.ID, user.ID)
+	if err != nil {
+		t.Fatalf("AddAgent: %v", err)
+	}
+	if agent.State != models.AgentStateAvailable {
+		t.Errorf("new agent state = %q", agent.State)
+	}
+	if _, err := service.AddAgent(tenant.ID, queue.ID, user.ID); err == nil || err.Error() != "user is already an agent of this queue" {
+		t.Errorf("adding an agent twice = %v", err)
+	}
+	outsider := createTestUser(t, db, other.ID, "mallory")
+	if _, err := service.AddAgent(tenant.ID, queue.ID, outsider.ID); err == nil {
+		t.Error("added another tenant's user as an agent")
+	}
+
+	if _, err := service.SetAgentState(tenant.ID, queue.ID, agent.ID, models.AgentStatePaused, ""); err == nil || err.Error() != "pause reason is required" {
+		t.Errorf("pause without a reason = %v", err)
+	}
+	if _, err := service.SetAgentState(tenant.ID, queue.ID, agent.ID, models.AgentStateOnCall, ""); err == nil || err.Error() != "invalid agent state" {
+		t.Errorf("manual on-call = %v", err)
+	}
+
+	agentState := func() models.QueueAgent {
+		t.Helper()
+		var stored models.QueueAgent
+		if err := db.First(&stored, agent.ID).Error; err != nil {
+			t.Fatalf("loading agent: %v", err)
+		}
+		return stored
+	}
+
+	answeredAt := time.Now().Add(-time.Minute)
+	for _, event := range []models.CallEvent{
+		{CallUUID: "call-1", Type: models.CallEventQueueEnter, QueueID: &queue.ID, OccurredAt: answeredAt.Add(-10 * time.Second)},
+		{CallUUID: "call-1", Type: models.CallEventQueueAnswer, QueueID: &queue.ID, AgentID: &agent.ID, OccurredAt: answeredAt},
+	} {
+		if _, err := service.RecordCallEvent(tenant.ID, &event); err != nil {
+			t.Fatalf("RecordCallEvent(%s): %v", event.Type, err)
+		}
+	}
+	if state := agentState().State; state != models.AgentStateOnCall {
+		t.Errorf("agent state after answering = %q", state)
+	}
+	if _, err := service.SetAgentState(tenant.ID, queue.ID, agent.ID, models.AgentStatePaused, "lunch"); err == nil || err.Error() != "agent is on a call" {
+		t.Errorf("pausing an agent on a call = %v", err)
+	}
+	if agents, err := service.SetUserState(tenant.ID, user.ID, models.AgentStatePaused, "lunch"); err != nil || len(agents) != 1 || agents[0].State != models.AgentStateOnCall {
+		t.Errorf("SetUserState on a call = %+v, %v", agents, err)
+	}
+
+	endedAt := answeredAt.Add(30 * time.Second)
+	if _, err := service.RecordCallEvent(tenant.ID, &models.CallEvent{CallUUID: "call-1", Type: models.CallEventAgentHangup, QueueID: &queue.ID, AgentID: &agent.ID, OccurredAt: endedAt}); err != nil {
+		t.Fatalf("RecordCallEvent(agent_hangup): %v", err)
+	}
+	stored := agentState()
+	if stored.State != models.AgentStateWrapUp || stored.LastCallEndedAt == nil || !stored.LastCallEndedAt.Equal(endedAt) {
+		t.Errorf("agent after hangup = %+v", stored)
+	}
+
+	if agents, err := service.SetUserState(tenant.ID, user.ID, models.AgentStatePaused, " lunch "); err != nil || agents[0].State != models.AgentStatePaused || agents[0].PauseReason != "lunch" {
+		t.Errorf("SetUserState = %+v, %v", agents, err)
+	}
+	if _, err := service.SetAgentState(other.ID, queue.ID, agent.ID, models.AgentStateAvailable, ""); err == nil || err.Error() != "queue agent not found" {
+		t.Errorf("another tenant changed the agent: %v", err)
+	}
+}
+
+func TestRecordCallEventRejects(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewQueueService(db)
+
+	queue, err := service.CreateQueue(tenant.ID, &models.Queue{Name: "Support"})
+	if err != nil {
+		t.Fatalf("CreateQueue: %v", err)
+	}
+	foreign, err := service.CreateQueue(other.ID, &models.Queue{Name: "Other"})
+	if err != nil {
+		t.Fatalf("CreateQueue: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		event models.CallEvent
+		want  string
+	}{
+		{"no uuid", models.CallEvent{Type: models.CallEventQueueEnter, QueueID: &queue.ID}, "call uuid is required"},
+		{"bad type", models.CallEvent{CallUUID: "call-1", Type: "transfer", QueueID: &queue.ID}, "invalid call event type"},
+		{"no queue", models.CallEvent{CallUUID: "call-1", Type: models.CallEventQueueEnter}, "queue is required"},
+		{"other tenant's queue", models.CallEvent{CallUUID: "call-1", Type: models.CallEventQueueEnter, QueueID: &foreign.ID}, "queue not found"},
+		{"answer without agent", models.CallEvent{CallUUID: "call-1", Type: models.CallEventQueueAnswer, QueueID: &queue.ID}, "agent is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.RecordCallEvent(tenant.ID, &tt.event); err == nil || err.Error() != tt.want {
+				t.Errorf("RecordCallEvent() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallEventsLinkToLaterCall(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewQueueService(db)
+
+	queue, err := service.CreateQueue(tenant.ID, &models.Queue{Name: "Support"})
+	if err != nil {
+		t.Fatalf("CreateQueue: %v", err)
+	}
+	event, err := service.RecordCallEvent(tenant.ID, &models.CallEvent{CallUUID: "call-1", Type: models.CallEventQueueEnter, QueueID: &queue.ID})
+	if err != nil {
+		t.Fatalf("RecordCallEvent: %v", err)
+	}
+	if event.CallID != nil {
+		t.Fatal("event linked before its call exists")
+	}
+
+	calls := NewCallService(db)
+	call, _, err := calls.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "call-1", Caller: "+14155550100", Callee: "1001"})
+	if err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+
+	// Events arriving once the call is stored are linked straight away
+	if _, err := service.RecordCallEvent(tenant.ID, &models.CallEvent{CallUUID: "call-1", Type: models.CallEventQueueAbandon, QueueID: &queue.ID}); err != nil {
+		t.Fatalf("RecordCallEvent: %v", err)
+	}
+
+	stored, err := calls.GetCallByID(tenant.ID, call.ID)
+	if err != nil {
+		t.Fatalf("GetCallByID: %v", err)
+	}
+	if len(stored.Events) != 2 || stored.Events[0].Type != models.CallEventQueueEnter || stored.Events[1].Type != models.CallEventQueueAbandon {
+		t.Errorf("call events = %+v", stored.Events)
+	}
+}
+
+func TestDeleteQueueInUse(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewQueueService(db)
+	routing := NewRoutingService(db)
+
+	queue, err := service.CreateQueue(tenant.ID, &models.Queue{Name: "Support"})
+	if err != nil {
+		t.Fatalf("CreateQueue: %v", err)
+	}
+	user := createTestUser(t, db, tenant.ID, "alice")
+	if _, err := service.AddAgent(tenant.ID, queue.ID, user.ID); err != nil {
+		t.Fatalf("AddAgent: %v", err)
+	}
+	rule, err := routing.CreateRule(tenant.ID, &models.RoutingRule{Name: "Support", TargetType: "queue", TargetValue: strconv.FormatUint(uint64(queue.ID), 10)})
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	if err := service.DeleteQueue(tenant.ID, queue.ID); err == nil || err.Error() != "queue is used by a routing rule" {
+		t.Errorf("deleting a queue in use = %v", err)
+	}
+	routing.DeleteRule(tenant.ID, rule.ID)
+	if err := service.DeleteQueue(tenant.ID, queue.ID); err != nil {
+		t.Fatalf("DeleteQueue: %v", err)
+	}
+
+	var agents int64
+	db.Model(&models.QueueAgent{}).Where("queue_id = ?", queue.ID).Count(&agents)
+	if agents != 0 {
+		t.Errorf("%d agents left on a deleted queue", agents)
+	}
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+type RatingService struct {
+	DB *gorm.DB
+}
+
+func NewRatingService(db *gorm.DB) *RatingService {
+	return &RatingService{DB: db}
+}
+
+// UploadRateDeck creates a deck, or replaces the rates of the deck with the
+// same name and owner. A nil tenantID uploads a global deck.
+func (s *RatingService) UploadRateDeck(tenantID *uint, name string, rates []models.Rate) (*models.RateDeck, error) {
+	if len(rates) == 0 {
+		return nil, errors.New("rate deck has no rates")
+	}
+
+	seen := map[string]bool{}
+	for i := range rates {
+		if err := validateRate(&rates[i]); err != nil {
+			return nil, err
+		}
+		if seen[rates[i].Prefix] {
+			return nil, errors.New("duplicate prefix " + rates[i].Prefix)
+		}
+		seen[rates[i].Prefix] = true
+	}
+
+	var deck models.RateDeck
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("name = ?", name)
+		if tenantID == nil {
+			query = query.Where("tenant_id IS NULL")
+		} else {
+			query = query.Where("tenant_id = ?", *tenantID)
+		}
+
+		err := query.First(&deck).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			deck = models.RateDeck{
+				TenantID: tenantID,
+				Name:     name,
+				IsActive: true,
+			}
+			if err := tx.Create(&deck).Error; err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if err := tx.Where("rate_deck_id = ?", deck.ID).
+			Delete(&models.Rate{}).Error; err != nil {
+			return err
+		}
+
+		for i := range rates {
+			rates[i].ID = 0
+			rates[i].RateDeckID = deck.ID
+		}
+
+		return tx.CreateInBatches(rates, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deck.Rates = rates
+	return &deck, nil
+}
+
+func validateRate(rate *models.Rate) error {
+	rate.Prefix = strings.TrimPrefix(strings.TrimSpace(rate.Prefix), "+")
+	if rate.Prefix == "" || strings.Trim(rate.Prefix, "0123456789") != "" {
+		return errors.New("rate prefix must contain only digits")
+	}
+	if rate.RatePerMinute < 0 || rate.ConnectionFee < 0 {
+		return errors.New("rate values must be greater than or equal to 0")
+	}
+	if rate.MinDuration < 0 {
+		return errors.New("min duration must be greater than or equal to 0")
+	}
+	if rate.Increment == 0 {
+		rate.Increment = 1
+	}
+	if rate.Increment < 0 {
+		return errors.New("increment must be greater than 0")
+	}
+	return nil
+}
+
+// ParseRateDeckCSV reads rates from a CSV with a header row. Recognised
+// columns are prefix, description, rate_per_minute (or rate),
+// connection_fee, min_duration and increment.
+func ParseRateDeckCSV(r io.Reader) ([]models.Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("rate deck csv header row is required")
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["rate"]; ok {
+		if _, ok := columns["rate_per_minute"]; !ok {
+			columns["rate_per_minute"] = columns["rate"]
+		}
+	}
+	if _, ok := columns["prefix"]; !ok {
+		return nil, errors.New("rate deck csv must have a prefix column")
+	}
+	if _, ok := columns["rate_per_minute"]; !ok {
+		return nil, errors.New("rate deck csv must have a rate_per_minute column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rates []models.Rate
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, errors.New("invalid rate deck csv at line " + strconv.Itoa(line))
+		}
+
+		rate := models.Rate{
+			Prefix:      field(record, "prefix"),
+			Description: field(record, "description"),
+		}
+
+		if rate.RatePerMinute, err = strconv.ParseFloat(field(record, "rate_per_minute"), 64); err != nil {
+			return nil, errors.New("invalid rate_per_minute at line " + strconv.Itoa(line))
+		}
+		if value := field(record, "connection_fee"); value != "" {
+			if rate.ConnectionFee, err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, errors.New("invalid connection_fee at line " + strconv.Itoa(line))
+			}
+		}
+		if value := field(record, "min_duration"); value != "" {
+			if rate.MinDuration, err = strconv.Atoi(value); err != nil {
+				return nil, errors.New("invalid min_duration at line " + strconv.Itoa(line))
+			}
+		}
+		if value := field(record, "increment"); value != "" {
+			if rate.Increment, err = strconv.Atoi(value); err != nil {
+				return nil, errors.New("invalid increment at line " + strconv.Itoa(line))
+			}
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// GetRateDecks lists the tenant's own decks followed by the global ones.
+func (s *RatingService) GetRateDecks(tenantID uint) ([]models.RateDeck, error) {
+	var decks []models.RateDeck
+
+	if err := s.DB.Where("tenant_id = ? OR tenant_id IS NULL", tenantID).
+		Order("tenant_id IS NULL, name").
+		Find(&decks).Error; err != nil {
+		return nil, err
+	}
+
+	return decks, nil
+}
+
+func (s *RatingService) GetRateDeckByID(tenantID, deckID uint) (*models.RateDeck, error) {
+	var deck models.RateDeck
+
+	if err := s.DB.Where("id = ? AND (tenant_id = ? OR tenant_id IS NULL)", deckID, tenantID).
+		Preload("Rates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("prefix")
+		}).
+		First(&deck).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("rate deck not found")
+		}
+		return nil, err
+	}
+
+	return &deck, nil
+}
+
+// DeleteRateDeck removes one of the tenant's own decks. Global decks can only
+// be removed through DeleteGlobalRateDeck.
+func (s *RatingService) DeleteRateDeck(tenantID, deckID uint) error {
+	return s.deleteRateDeck(s.DB.Where("id = ? AND tenant_id = ?", deckID, tenantID))
+}
+
+func (s *RatingService) DeleteGlobalRateDeck(deckID uint) error {
+	return s.deleteRateDeck(s.DB.Where("id = ? AND tenant_id IS NULL", deckID))
+}
+
+func (s *RatingService) deleteRateDeck(query *gorm.DB) error {
+	var deck models.RateDeck
+
+	if err := query.First(&deck).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("rate deck not found")
+		}
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rate_deck_id = ?", deck.ID).
+			Delete(&models.Rate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&deck).Error
+	})
+}
+
+// FindRate returns the rate with the longest prefix matching the
+// destination. Rates from the tenant's own decks take precedence over global
+// decks, and between decks with the same prefix the most recently created
+// one wins. A nil rate with no error means the destination is unrated.
+func (s *RatingService) FindRate(tenantID uint, destination string) (*models.Rate, error) {
+	digits := destinationDigits(destination)
+	if digits == "" {
+		return nil, nil
+	}
+
+	prefixes := make([]string, 0, len(digits))
+	for i := len(digits); i > 0; i-- {
+		prefixes = append(prefixes, digits[:i])
+	}
+
+	var rate models.Rate
+	err := s.DB.Joins("JOIN rate_decks ON rate_decks.id = rates.rate_deck_id AND rate_decks.deleted_at IS NULL").
+		Where("rates.prefix IN ? AND rate_decks.is_active = ?", prefixes, true).
+		Where("rate_decks.tenant_id = ? OR rate_decks.tenant_id IS NULL", tenantID).
+		Order("rate_decks.tenant_id IS NULL, LENGTH(rates.prefix) DESC, rate_decks.id DESC, rates.id").
+		First(&rate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// RateCall sets call.Cost from the matching rate. Unanswered calls and
+// unrated destinations cost nothing.
+func (s *RatingService) RateCall(call *models.Call) error {
+	if call.Billsec <= 0 {
+		call.Cost = 0
+		return nil
+	}
+
+	// Rate decks are keyed by international prefixes, which only the
+	// normalized number reliably starts with.
+	destination := call.CalleeE164
+	if destination == "" {
+		destination = call.Callee
+	}
+
+	rate, err := s.FindRate(call.TenantID, destination)
+	if err != nil {
+		return err
+	}
+
+	call.Cost = CalculateCost(rate, call.Billsec)
+	return nil
+}
+
+// CalculateCost prices billsec seconds with the given rate, rounded to the
+// precision of the cost column.
+func CalculateCost(rate *models.Rate, billsec int) float64 {
+	if rate == nil || billsec <= 0 {
+		return 0
+	}
+
+	billable := billsec
+	if billable < rate.MinDuration {
+		billable = rate.MinDuration
+	}
+
+	increment := rate.Increment
+	if increment <= 0 {
+		increment = 1
+	}
+
+	if extra := billable - rate.MinDuration; extra > 0 {
+		billable = rate.MinDuration + ((extra+increment-1)/increment)*increment
+	}
+
+	cost := rate.ConnectionFee + float64(billable)/60*rate.RatePerMinute
+	return math.Round(cost*10000) / 10000
+}
+
+// RerateCalls recomputes Cost for the tenant's calls that started within
+// [from, to], for example after a rate deck upload. It returns how many
+// calls changed cost.
+func (s *RatingService) RerateCalls(tenantID uint, from, to time.Time) (int, error) {
+	var calls []models.Call
+	changed := 0
+
+	result := s.DB.Where("tenant_id = ? AND start_time BETWEEN ? AND ?", tenantID, from, to).
+		FindInBatches(&calls, 500, func(tx *gorm.DB, batch int) error {
+			for i := range calls {
+				previous := calls[i].Cost
+				if err := s.RateCall(&calls[i]); err != nil {
+					return err
+				}
+				if calls[i].Cost == previous {
+					continue
+				}
+				if err := s.DB.Model(&calls[i]).
+					UpdateColumn("cost", calls[i].Cost).Error; err != nil {
+					return err
+				}
+				changed++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return changed, result.Error
+	}
+
+	return changed, nil
+}
+
+func destinationDigits(number string) string {
+	var b strings.Builder
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"github.com/your-module/backend/models"
+)
+
+func TestFindRate(t *testing.T) {
+	db := newTestDB(t)
+	acme := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	service := NewRatingService(db)
+
+	if _, err := service.UploadRateDeck(nil, "global", []models.Rate{
+		{Prefix: "1", Description: "US", RatePerMinute: 0.01},
+		{Prefix: "44", Description: "UK", RatePerMinute: 0.02},
+		{Prefix: "447", Description: "UK mobile", RatePerMinute: 0.10},
+	}); err != nil {
+		t.Fatalf("uploading global deck: %v", err)
+	}
+	if _, err := service.UploadRateDeck(&acme.ID, "acme", []models.Rate{
+		{Prefix: "+44", Description: "UK contract", RatePerMinute: 0.015},
+	}); err != nil {
+		t.Fatalf("uploading tenant deck: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		tenantID    uint
+		destination string
+		want        string
+	}{
+		{"longest prefix", other.ID, "+447700900123", "UK mobile"},
+		{"shorter prefix", other.ID, "+442079460958", "UK"},
+		{"separators ignored", other.ID, "+1 (415) 555-0100", "US"},
+		{"tenant deck first", acme.ID, "+447700900123", "UK contract"},
+		{"global fallback", acme.ID, "+14155550100", "US"},
+		{"unrated", acme.ID, "+33142685300", ""},
+		{"no digits", acme.ID, "anonymous", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := service.FindRate(tt.tenantID, tt.destination)
+			if err != nil {
+				t.Fatalf("FindRate: %v", err)
+			}
+			got := ""
+			if rate != nil {
+				got = rate.Description
+			}
+			if got != tt.want {
+				t.Errorf("FindRate(%q) = %q, want %q", tt.destination, got, tt.want)
+			}
+		})
+	}
+
+	// Between decks with the same prefix the newest wins
+	if _, err := service.UploadRateDeck(&acme.ID, "acme renewal", []models.Rate{
+		{Prefix: "44", Description: "UK renewed contract", RatePerMinute: 0.012},
+	}); err != nil {
+		t.Fatalf("uploading second tenant deck: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		rate, err := service.FindRate(acme.ID, "+447700900123")
+		if err != nil || rate == nil || rate.Description != "UK renewed contract" {
+			t.Fatalf("FindRate with two tenant decks = %+v, %v, want the newest deck's rate", rate, err)
+		}
+	}
+
+	// An inactive deck no longer prices calls
+	db.Model(&models.RateDeck{}).Where("tenant_id = ?", acme.ID).Update("is_active", false)
+	rate, err := service.FindRate(acme.ID, "+447700900123")
+	if err != nil {
+		t.Fatalf("FindRate: %v", err)
+	}
+	if rate == nil || rate.Description != "UK mobile" {
+		t.Errorf("FindRate with an inactive tenant deck = %+v, want the global UK mobile rate", rate)
+	}
+}
+
+func TestCalculateCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    *models.Rate
+		billsec int
+		want    float64
+	}{
+		{"no rate", nil, 60, 0},
+		{"unanswered", &models.Rate{RatePerMinute: 1}, 0, 0},
+		{"per second", &models.Rate{RatePerMinute: 0.6, Increment: 1}, 61, 0.61},
+		{"minimum", &models.Rate{RatePerMinute: 0.6, MinDuration: 60, Increment: 6}, 10, 0.6},
+		{"increments after minimum", &models.Rate{RatePerMinute: 0.6, MinDuration: 60, Increment: 6}, 61, 0.66},
+		{"connection fee", &models.Rate{RatePerMinute: 0.6, ConnectionFee: 0.05, Increment: 60}, 30, 0.65},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateCost(tt.rate, tt.billsec); got != tt.want {
+				t.Errorf("CalculateCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRateDeckCSV(t *testing.T) {
+	rates, err := ParseRateDeckCSV(strings.NewReader("Prefix,Description,Rate,Connection_Fee,Min_Duration,Increment\n" +
+		"44,UK,0.02,0.01,60,6\n" +
+		"447,UK mobile,0.10,,,\n"))
+	if err != nil {
+		t.Fatalf("ParseRateDeckCSV: %v", err)
+	}
+
+	want := []models.Rate{
+		{Prefix: "44", Description: "UK", RatePerMinute: 0.02, ConnectionFee: 0.01, MinDuration: 60, Increment: 6},
+		{Prefix: "447", Description: "UK mobile", RatePerMinute: 0.10},
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("ParseRateDeckCSV() = %+v, want %+v", rates, want)
+	}
+	for i := range want {
+		if rates[i] != want[i] {
+			t.Errorf("rate %d = %+v, want %+v", i, rates[i], want[i])
+		}
+	}
+
+	for name, body := range map[string]string{
+		"no prefix column": "description,rate\nUK,0.02\n",
+		"no rate column":   "prefix,description\n44,UK\n",
+		"bad rate":         "prefix,rate\n44,cheap\n",
+		"bad increment":    "prefix,rate,increment\n44,0.02,six\n",
+	} {
+		if _, err := ParseRateDeckCSV(strings.NewReader(body)); err == nil {
+			t.Errorf("%s: ParseRateDeckCSV accepted the deck", name)
+		}
+	}
+}
+
+func TestUploadRateDeckReplacesRates(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewRatingService(db)
+
+	first, err := service.UploadRateDeck(&tenant.ID, "contract", []models.Rate{{Prefix: "44", RatePerMinute: 0.02}, {Prefix: "1", RatePerMinute: 0.01}})
+	if err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	second, err := service.UploadRateDeck(&tenant.ID, "contract", []models.Rate{{Prefix: "+33", RatePerMinute: 0.03}})
+	if err != nil {
+		t.Fatalf("second upload: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("upload with the same name created deck %d, want %d replaced", second.ID, first.ID)
+	}
+
+	var prefixes []string
+	db.Model(&models.Rate{}).Where("rate_deck_id = ?", first.ID).Pluck("prefix", &prefixes)
+	if len(prefixes) != 1 || prefixes[0] != "33" {
+		t.Errorf("deck rates = %v, want only the new upload", prefixes)
+	}
+
+	for name, rates := range map[string][]models.Rate{
+		"empty":            nil,
+		"duplicate prefix": {{Prefix: "44", RatePerMinute: 0.02}, {Prefix: "+44", RatePerMinute: 0.03}},
+		"letters":          {{Prefix: "44A", RatePerMinute: 0.02}},
+		"negative rate":    {{Prefix: "44", RatePerMinute: -1}},
+	} {
+		if _, err := service.UploadRateDeck(&tenant.ID, name, rates); err == nil {
+			t.Errorf("%s: UploadRateDeck accepted the deck", name)
+		}
+	}
+}
+
+func TestRatedCalls(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewRatingService(db)
+
+	if _, err := service.UploadRateDeck(&tenant.ID, "contract", []models.Rate{{Prefix: "44", RatePerMinute: 0.60}}); err != nil {
+		t.Fatalf("UploadRateDeck: %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	call, _, err := NewCallService(db).UpsertCallByUUID(tenant.ID, &models.Call{
+		UUID: "call-1", Caller: "1001", Callee: "+442079460958", StartTime: &start, Billsec: 30,
+	})
+	if err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	if call.Cost != 0.30 {
+		t.Errorf("cost on ingestion = %v, want 0.30", call.Cost)
+	}
+
+	if _, err := service.UploadRateDeck(&tenant.ID, "contract", []models.Rate{{Prefix: "44", RatePerMinute: 1.20}}); err != nil {
+		t.Fatalf("UploadRateDeck: %v", err)
+	}
+	changed, err := service.RerateCalls(tenant.ID, start.Add(-time.Minute), time.Now())
+	if err != nil {
+		t.Fatalf("RerateCalls: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("RerateCalls changed %d calls, want 1", changed)
+	}
+
+	var stored models.Call
+	db.First(&stored, call.ID)
+	if stored.Cost != 0.60 {
+		t.Errorf("cost after rerating = %v, want 0.60", stored.Cost)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+)
+
+var recordingExtensions = map[string]bool{
+	".wav":  true,
+	".mp3":  true,
+	".ogg":  true,
+	".opus": true,
+}
+
+type RecordingService struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+}
+
+func NewRecordingService(db *gorm.DB, store storage.Storage) *RecordingService {
+	return &RecordingService{DB: db, Storage: store}
+}
+
+// recordingKey is the object key of a call's recording inside the tenant
+// namespace.
+func recordingKey(call *models.Call, filename string) string {
+	return "recordings/" + call.UUID + strings.ToLower(path.Ext(filename))
+}
+
+// AttachRecording uploads audio for the call and records its storage key.
+// A previous recording stored under a different key is removed.
+func (s *RecordingService) AttachRecording(tenantID, callID uint, filename string, r io.Reader, size int64) (*models.Call, error) {
+	ext := strings.ToLower(path.Ext(filename))
+	if !recordingExtensions[ext] {
+		return nil, errors.New("unsupported recording format")
+	}
+
+	var call models.Call
+	if err := s.DB.Where("id = ? AND tenant_id = ?", callID, tenantID).
+		First(&call).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("call not found")
+		}
+		return nil, err
+	}
+
+	store := storage.ForTenant(s.Storage, tenantID)
+	key := recordingKey(&call, filename)
+	if err := store.Put(key, r, size, storage.ContentTypeFor(key)); err != nil {
+		return nil, err
+	}
+
+	previous := call.RecordingKey
+	if err := s.DB.Model(&call).Update("recording_key", key).Error; err != nil {
+		return nil, err
+	}
+
+	if previous != "" && previous != key {
+		store.Delete(previous)
+	}
+
+	return &call, nil
+}
+
+// GetRecordedCall returns the call if it has a stored recording or one at
+// a remote URL.
+func (s *RecordingService) GetRecordedCall(tenantID, callID uint) (*models.Call, error) {
+	var call models.Call
+	if err := s.DB.Where("id = ? AND tenant_id = ?", callID, tenantID).
+		First(&call).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("call not found")
+		}
+		return nil, err
+	}
+
+	if call.RecordingKey == "" && call.RemoteRecordingURL() == "" {
+		return nil, errors.New("recording not found")
+	}
+
+	return &call, nil
+}
+
+// OpenRecording returns a reader for the call's stored recording. A call
+// whose recording is only at a remote URL comes back with a nil reader, and
+// the client is sent to its RemoteRecordingURL.
+func (s *RecordingService) OpenRecording(tenantID, callID uint) (io.ReadCloser, *storage.ObjectInfo, *models.Call, error) {
+	call, err := s.GetRecordedCall(tenantID, callID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if call.RecordingKey == "" {
+		return nil, nil, call, nil
+	}
+
+	reader, info, err := storage.ForTenant(s.Storage, tenantID).Get(call.RecordingKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, nil, errors.New("recording not found")
+		}
+		return nil, nil, nil, err
+	}
+
+	return reader, info, call, nil
+}
+
+func (s *RecordingService) DeleteRecording(tenantID, callID uint) error {
+	var call models.Call
+	if err := s.DB.Where("id = ? AND tenant_id = ?", callID, tenantID).
+		First(&call).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("call not found")
+		}
+		return err
+	}
+
+	if call.RecordingKey == "" && call.RecordingURL == "" {
+		return errors.New("recording not found")
+	}
+
+	if call.RecordingKey != "" {
+		if err := storage.ForTenant(s.Storage, tenantID).Delete(call.RecordingKey); err != nil {
+			return err
+		}
+	}
+
+	// A recording kept elsewhere is only forgotten
+	return s.DB.Model(&call).Updates(map[string]interface{}{
+		"recording_key": "",
+		"recording_url": "",
+	}).Error
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+)
+
+func TestRecordingLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	service := NewRecordingService(db, store)
+
+	call := models.Call{TenantID: tenant.ID, UUID: "call-1", Caller: "1001", Callee: "1002"}
+	db.Create(&call)
+
+	if _, err := service.AttachRecording(tenant.ID, call.ID, "call.exe", strings.NewReader("x"), 1); err == nil || err.Error() != "unsupported recording format" {
+		t.Errorf("attaching an .exe = %v", err)
+	}
+	if _, err := service.AttachRecording(other.ID, call.ID, "call.wav", strings.NewReader("x"), 1); err == nil || err.Error() != "call not found" {
+		t.Errorf("attaching to another tenant's call = %v", err)
+	}
+
+	if _, err := service.AttachRecording(tenant.ID, call.ID, "call.WAV", strings.NewReader("first"), 5); err != nil {
+		t.Fatalf("AttachRecording: %v", err)
+	}
+	if _, err := service.AttachRecording(tenant.ID, call.ID, "call.mp3", strings.NewReader("second"), 6); err != nil {
+		t.Fatalf("replacing the recording: %v", err)
+	}
+	if _, _, err := storage.ForTenant(store, tenant.ID).Get("recordings/call-1.wav"); err != storage.ErrNotFound {
+		t.Errorf("replaced recording still stored: %v", err)
+	}
+
+	r, info, _, err := service.OpenRecording(tenant.ID, call.ID)
+	if err != nil {
+		t.Fatalf("OpenRecording: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "second" || info.ContentType != "audio/mpeg" {
+		t.Errorf("recording = %q, %s", data, info.ContentType)
+	}
+
+	if _, _, _, err := service.OpenRecording(other.ID, call.ID); err == nil {
+		t.Error("another tenant opened the recording")
+	}
+
+	page, err := NewCallService(db).SearchCalls(tenant.ID, CallFilter{HasRecording: new(bool)})
+	if err != nil || len(page.Calls) != 0 {
+		t.Errorf("calls without recording = %v, %v", page, err)
+	}
+
+	if err := service.DeleteRecording(tenant.ID, call.ID); err != nil {
+		t.Fatalf("DeleteRecording: %v", err)
+	}
+	if _, _, _, err := service.OpenRecording(tenant.ID, call.ID); err == nil || err.Error() != "recording not found" {
+		t.Errorf("opening a deleted recording = %v", err)
+	}
+	if err := service.DeleteRecording(tenant.ID, call.ID); err == nil || err.Error() != "recording not found" {
+		t.Errorf("deleting twice = %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+)
+
+const retentionPurgeBatch = 500
+
+type RetentionService struct {
+	DB        *gorm.DB
+	Storage   storage.Storage
+	GraceDays uint
+}
+
+func NewRetentionService(db *gorm.DB, store storage.Storage, graceDays uint) *RetentionService {
+	return &RetentionService{
+		DB:        db,
+		Storage:   store,
+		GraceDays: graceDays,
+	}
+}
+
+// RetentionPolicy is the retention that applies to a tenant and where it
+// comes from: "tenant" for an override, "plan" for the active plan, or
+// "none" when calls are kept forever.
+type RetentionPolicy struct {
+	TenantID      uint   `json:"tenant_id"`
+	RetentionDays uint   `json:"retention_days"`
+	GraceDays     uint   `json:"grace_days"`
+	Source        string `json:"source"`
+}
+
+func (s *RetentionService) GetRetentionPolicy(tenantID uint) (*RetentionPolicy, error) {
+	var tenant models.Tenant
+	if err := s.DB.First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+
+	return s.policyFor(&tenant)
+}
+
+func (s *RetentionService) policyFor(tenant *models.Tenant) (*RetentionPolicy, error) {
+	policy := &RetentionPolicy{
+		TenantID:  tenant.ID,
+		GraceDays: s.GraceDays,
+		Source:    "none",
+	}
+
+	if tenant.RetentionDays != nil {
+		policy.RetentionDays = *tenant.RetentionDays
+		policy.Source = "tenant"
+		return policy, nil
+	}
+
+	subscription, err := NewSubscriptionService(s.DB).GetTenantSubscription(tenant.ID)
+	if err != nil {
+		if err.Error() == "subscription not found" {
+			return policy, nil
+		}
+		return nil, err
+	}
+
+	if subscription.Plan.RetentionDays > 0 {
+		policy.RetentionDays = subscription.Plan.RetentionDays
+		policy.Source = "plan"
+	}
+
+	return policy, nil
+}
+
+func (s *RetentionService) SetPlanRetention(planID uint, days uint) (*models.Plan, error) {
+	var plan models.Plan
+	if err := s.DB.First(&plan, planID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("plan not found")
+		}
+		return nil, err
+	}
+
+	if err := s.DB.Model(&plan).Update("retention_days", days).Error; err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// SetTenantRetention sets the tenant override. A nil value removes it so the
+// plan's retention applies again.
+func (s *RetentionService) SetTenantRetention(tenantID uint, days *uint) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := s.DB.First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+
+	if err := s.DB.Model(&tenant).Update("retention_days", days).Error; err != nil {
+		return nil, err
+	}
+	tenant.RetentionDays = days
+
+	return &tenant, nil
+}
+
+func (s *RetentionService) GetPurgeLogs(tenantID uint, limit int) ([]models.PurgeLog, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	var logs []models.PurgeLog
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// Run purges on every tick until stop is closed.
+func (s *RetentionService) Run(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeExpired(); err != nil {
+			log.Printf("retention: purge failed: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired applies every tenant's retention policy and writes a purge
+// log entry per tenant with a policy. Tenants without one are left alone,
+// including calls that users deleted by hand.
+func (s *RetentionService) PurgeExpired() ([]models.PurgeLog, error) {
+	var tenants []models.Tenant
+	if err := s.DB.Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+
+	logs := []models.PurgeLog{}
+	for i := range tenants {
+		policy, err := s.policyFor(&tenants[i])
+		if err != nil {
+			log.Printf("retention: tenant %d: %v", tenants[i].ID, err)
+			continue
+		}
+		if policy.RetentionDays == 0 {
+			continue
+		}
+
+		entry := s.purgeTenant(policy)
+		if err := s.DB.Create(&entry).Error; err != nil {
+			return logs, err
+		}
+		logs = append(logs, entry)
+	}
+
+	return logs, nil
+}
+
+// purgeTenant soft-deletes calls older than the retention period, then
+// permanently removes calls that have been soft-deleted for longer than the
+// grace window, together with their recordings, transcripts and call
+// events.
+func (s *RetentionService) purgeTenant(policy *RetentionPolicy) models.PurgeLog {
+	now := time.Now()
+	entry := models.PurgeLog{
+		TenantID:         policy.TenantID,
+		RetentionDays:    policy.RetentionDays,
+		GraceDays:        policy.GraceDays,
+		SoftDeleteCutoff: now.AddDate(0, 0, -int(policy.RetentionDays)),
+		HardDeleteCutoff: now.AddDate(0, 0, -int(policy.GraceDays)),
+		StartedAt:        now,
+	}
+
+	result := s.DB.Where("tenant_id = ? AND COALESCE(start_time, created_at) < ?", policy.TenantID, entry.SoftDeleteCutoff).
+		Delete(&models.Call{})
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+		entry.FinishedAt = time.Now()
+		return entry
+	}
+	entry.CallsSoftDeleted = result.RowsAffected
+
+	if err := s.hardDeleteCalls(policy.TenantID, &entry); err != nil {
+		entry.Error = err.Error()
+	}
+
+	entry.FinishedAt = time.Now()
+	return entry
+}
+
+// hardDeleteCalls removes the tenant's calls past the grace window in
+// batches. The rows go first and the recordings after the batch commits,
+// so a failed transaction never leaves calls pointing at deleted audio. A
+// batch or recording that cannot be deleted is logged and counted as a
+// failure, and the run moves on to the next batch.
+func (s *RetentionService) hardDeleteCalls(tenantID uint, entry *models.PurgeLog) error {
+	store := storage.ForTenant(s.Storage, tenantID)
+	var lastID uint
+
+	for {
+		var calls []models.Call
+		if err := s.DB.Unscoped().
+			Select("id, recording_key").
+			Where("tenant_id = ? AND deleted_at IS NOT NULL AND deleted_at < ? AND id > ?",
+				tenantID, entry.HardDeleteCutoff, lastID).
+			Order("id").
+			Limit(retentionPurgeBatch).
+			Find(&calls).Error; err != nil {
+			return err
+		}
+		if len(calls) == 0 {
+			return nil
+		}
+		lastID = calls[len(calls)-1].ID
+
+		ids := make([]uint, len(calls))
+		for i, call := range calls {
+			ids[i] = call.ID
+		}
+
+		var deleted int64
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("transcript_id IN (?)",
+				tx.Unscoped().Model(&models.Transcript{}).Select("id").Where("call_id IN ?", ids)).
+				Delete(&models.TranscriptSegment{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("call_id IN ?", ids).
+				Delete(&models.Transcript{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("call_id IN ?", ids).
+				Delete(&models.CallEvent{}).Error; err != nil {
+				return err
+			}
+
+			result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.Call{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted = result.RowsAffected
+			return nil
+		})
+		if err != nil {
+			log.Printf("retention: tenant %d: deleting calls %d to %d: %v", tenantID, ids[0], lastID, err)
+			entry.Failures += int64(len(ids))
+			continue
+		}
+		entry.CallsHardDeleted += deleted
+
+		for _, call := range calls {
+			if call.RecordingKey == "" {
+				continue
+			}
+			if err := store.Delete(call.RecordingKey); err != nil {
+				log.Printf("retention: tenant %d: deleting recording %s of purged call %d: %v", tenantID, call.RecordingKey, call.ID, err)
+				entry.Failures++
+				continue
+			}
+			entry.RecordingsDeleted++
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+)
+
+// failingDeleteStorage is a storage backend that cannot delete.
+type failingDeleteStorage struct {
+	storage.Storage
+}
+
+func (s failingDeleteStorage) Delete(key string) error {
+	return errors.New("storage unavailable")
+}
+
+// createRetentionCall stores a call that started the given number of days
+// ago, soft-deleted deletedDaysAgo days ago when that is not negative.
+func createRetentionCall(t *testing.T, db *gorm.DB, store storage.Storage, tenantID uint, uuid string, startedDaysAgo, deletedDaysAgo int, recorded bool) *models.Call {
+	t.Helper()
+
+	start := time.Now().AddDate(0, 0, -startedDaysAgo)
+	call := models.Call{TenantID: tenantID, UUID: uuid, Caller: "1001", Callee: "1002", StartTime: &start}
+	if recorded {
+		call.RecordingKey = "recordings/" + uuid + ".wav"
+		if err := storage.ForTenant(store, tenantID).Put(call.RecordingKey, strings.NewReader("audio"), 5, "audio/wav"); err != nil {
+			t.Fatalf("storing recording: %v", err)
+		}
+	}
+	if err := db.Create(&call).Error; err != nil {
+		t.Fatalf("creating call: %v", err)
+	}
+	if deletedDaysAgo >= 0 {
+		db.Unscoped().Model(&call).Update("deleted_at", time.Now().AddDate(0, 0, -deletedDaysAgo))
+	}
+	return &call
+}
+
+func callExists(db *gorm.DB, id uint) (exists, deleted bool) {
+	var call models.Call
+	if err := db.Unscoped().First(&call, id).Error; err != nil {
+		return false, false
+	}
+	return true, call.DeletedAt.Valid
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{RetentionDays: 90})
+	service := NewRetentionService(db, nil, 7)
+
+	policy, err := service.GetRetentionPolicy(tenant.ID)
+	if err != nil || policy.Source != "plan" || policy.RetentionDays != 90 || policy.GraceDays != 7 {
+		t.Fatalf("policy = %+v, %v, want the plan's", policy, err)
+	}
+
+	days := uint(0)
+	if _, err := service.SetTenantRetention(tenant.ID, &days); err != nil {
+		t.Fatalf("SetTenantRetention: %v", err)
+	}
+	if policy, _ := service.GetRetentionPolicy(tenant.ID); policy.Source != "tenant" || policy.RetentionDays != 0 {
+		t.Errorf("policy = %+v, want the tenant's keep-forever override", policy)
+	}
+
+	if _, err := service.SetTenantRetention(tenant.ID, nil); err != nil {
+		t.Fatalf("clearing the override: %v", err)
+	}
+	if policy, _ := service.GetRetentionPolicy(tenant.ID); policy.Source != "plan" {
+		t.Errorf("policy = %+v, want the plan's again", policy)
+	}
+
+	if _, err := service.GetRetentionPolicy(tenant.ID + 100); err == nil || err.Error() != "tenant not found" {
+		t.Errorf("unknown tenant = %v", err)
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{RetentionDays: 30})
+	keeper := createTestTenant(t, db, "keeper.example.com", models.Plan{})
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	service := NewRetentionService(db, store, 7)
+
+	expired := createRetentionCall(t, db, store, tenant.ID, "expired", 40, -1, true)
+	recent := createRetentionCall(t, db, store, tenant.ID, "recent", 1, -1, false)
+	pastGrace := createRetentionCall(t, db, store, tenant.ID, "past-grace", 50, 10, true)
+	inGrace := createRetentionCall(t, db, store, tenant.ID, "in-grace", 35, 2, false)
+	kept := createRetentionCall(t, db, store, keeper.ID, "kept", 400, -1, false)
+
+	transcript := models.Transcript{TenantID: tenant.ID, CallID: pastGrace.ID, Status: models.TranscriptStatusCompleted}
+	db.Create(&transcript)
+	db.Create(&models.TranscriptSegment{TranscriptID: transcript.ID, Text: "hello"})
+	db.Create(&models.CallEvent{TenantID: tenant.ID, CallUUID: pastGrace.UUID, CallID: &pastGrace.ID, Type: models.CallEventQueueEnter, OccurredAt: *pastGrace.StartTime})
+	db.Create(&models.CallEvent{TenantID: tenant.ID, CallUUID: inGrace.UUID, CallID: &inGrace.ID, Type: models.CallEventQueueEnter, OccurredAt: *inGrace.StartTime})
+
+	logs, err := service.PurgeExpired()
+	if err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+	if len(logs) != 1 || logs[0].TenantID != tenant.ID {
+		t.Fatalf("purge logs = %+v, want one for the tenant with a policy", logs)
+	}
+	entry := logs[0]
+	if entry.CallsSoftDeleted != 1 || entry.CallsHardDeleted != 1 || entry.RecordingsDeleted != 1 || entry.Failures != 0 || entry.Error != "" {
+		t.Errorf("purge log = %+v", entry)
+	}
+
+	tests := []struct {
+		name            string
+		call            *models.Call
+		exists, deleted bool
+	}{
+		{"expired", expired, true, true},
+		{"recent", recent, true, false},
+		{"past grace", pastGrace, false, false},
+		{"in grace", inGrace, true, true},
+		{"no policy", kept, true, false},
+	}
+	for _, tt := range tests {
+		if exists, deleted := callExists(db, tt.call.ID); exists != tt.exists || deleted != tt.deleted {
+			t.Errorf("%s call exists %v deleted %v, want %v and %v", tt.name, exists, deleted, tt.exists, tt.deleted)
+		}
+	}
+
+	tenantStore := storage.ForTenant(store, tenant.ID)
+	if _, _, err := tenantStore.Get(pastGrace.RecordingKey); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("purged call's recording = %v, want it deleted", err)
+	}
+	if _, _, err := tenantStore.Get(expired.RecordingKey); err != nil {
+		t.Errorf("soft-deleted call's recording = %v, want it kept through the grace window", err)
+	}
+
+	var transcripts, segments int64
+	db.Unscoped().Model(&models.Transcript{}).Count(&transcripts)
+	db.Model(&models.TranscriptSegment{}).Count(&segments)
+	if transcripts != 0 || segments != 0 {
+		t.Errorf("%d transcripts and %d segments left for purged calls", transcripts, segments)
+	}
+
+	var events []models.CallEvent
+	db.Find(&events)
+	if len(events) != 1 || events[0].CallUUID != inGrace.UUID {
+		t.Errorf("call events left = %+v, want only the one of the call in grace", events)
+	}
+
+	stored, err := service.GetPurgeLogs(tenant.ID, 0)
+	if err != nil || len(stored) != 1 || stored[0].CallsHardDeleted != 1 {
+		t.Errorf("stored purge logs = %+v, %v", stored, err)
+	}
+}
+
+func TestPurgeExpiredCountsRecordingsThatCannotBeDeleted(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{RetentionDays: 30})
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	service := NewRetentionService(db, failingDeleteStorage{store}, 7)
+
+	recorded := createRetentionCall(t, db, store, tenant.ID, "recorded", 50, 10, true)
+	unrecorded := createRetentionCall(t, db, store, tenant.ID, "unrecorded", 50, 10, false)
+
+	logs, err := service.PurgeExpired()
+	if err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+	if logs[0].CallsHardDeleted != 2 || logs[0].Failures != 1 || logs[0].RecordingsDeleted != 0 || logs[0].Error != "" {
+		t.Errorf("purge log = %+v", logs[0])
+	}
+	for _, call := range []*models.Call{recorded, unrecorded} {
+		if exists, _ := callExists(db, call.ID); exists {
+			t.Errorf("call %s was not purged", call.UUID)
+		}
+	}
+}
+
+func TestPurgeExpiredSkipsFailedBatches(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{RetentionDays: 30})
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	service := NewRetentionService(db, store, 7)
+
+	first := createRetentionCall(t, db, store, tenant.ID, "first", 50, 10, true)
+	calls := make([]models.Call, retentionPurgeBatch)
+	deletedAt := time.Now().AddDate(0, 0, -10)
+	for i := range calls {
+		calls[i] = models.Call{TenantID: tenant.ID, UUID: fmt.Sprintf("call-%d", i), Caller: "1001", Callee: "1002"}
+		calls[i].DeletedAt.Time, calls[i].DeletedAt.Valid = deletedAt, true
+	}
+	if err := db.CreateInBatches(&calls, 100).Error; err != nil {
+		t.Fatalf("creating calls: %v", err)
+	}
+
+	// The first batch fails to delete; the second must still be purged
+	failed := false
+	db.Callback().Delete().Before("gorm:delete").Register("test:fail_first_batch", func(tx *gorm.DB) {
+		if tx.Statement.Table == "calls" && tx.Statement.Unscoped && !failed {
+			failed = true
+			tx.AddError(errors.New("database unavailable"))
+		}
+	})
+
+	logs, err := service.PurgeExpired()
+	if err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+	if logs[0].CallsHardDeleted != 1 || logs[0].Failures != retentionPurgeBatch || logs[0].Error != "" {
+		t.Errorf("purge log = %+v", logs[0])
+	}
+	if exists, _ := callExists(db, first.ID); !exists {
+		t.Error("call of the failed batch was purged")
+	}
+	if logs[0].RecordingsDeleted != 0 {
+		t.Error("recording of a call left in place was deleted")
+	}
+	if _, _, err := storage.ForTenant(store, tenant.ID).Get(first.RecordingKey); err != nil {
+		t.Errorf("recording of a call left in place = %v, want it kept", err)
+	}
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+// RoutingScheduleService manages the schedules and holiday calendars that
+// routing rules refer to.
+type RoutingScheduleService struct {
+	DB *gorm.DB
+}
+
+func NewRoutingScheduleService(db *gorm.DB) *RoutingScheduleService {
+	return &RoutingScheduleService{DB: db}
+}
+
+// parseClock reads "HH:MM" as minutes since midnight. "24:00" is accepted
+// so a window can run to the end of the day.
+func parseClock(value string) (int, bool) {
+	hours, minutes, ok := strings.Cut(value, ":")
+	if !ok || len(hours) != 2 || len(minutes) != 2 {
+		return 0, false
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m > 59 || h < 0 || m < 0 {
+		return 0, false
+	}
+	if h > 24 || (h == 24 && m != 0) {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func validateTimezone(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "UTC", nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", errors.New("invalid timezone")
+	}
+	return name, nil
+}
+
+func validateRoutingSchedule(schedule *models.RoutingSchedule) error {
+	schedule.Name = strings.TrimSpace(schedule.Name)
+	if schedule.Name == "" {
+		return errors.New("name is required")
+	}
+
+	timezone, err := validateTimezone(schedule.Timezone)
+	if err != nil {
+		return err
+	}
+	schedule.Timezone = timezone
+
+	for _, window := range schedule.Windows {
+		if window.Weekday < 0 || window.Weekday > 6 {
+			return errors.New("weekday must be 0 (Sunday) to 6")
+		}
+		start, ok := parseClock(window.StartTime)
+		if !ok {
+			return errors.New("invalid window time")
+		}
+		end, ok := parseClock(window.EndTime)
+		if !ok {
+			return errors.New("invalid window time")
+		}
+		if start >= end {
+			return errors.New("window must end after it starts")
+		}
+	}
+
+	return nil
+}
+
+func validateHolidayCalendar(calendar *models.HolidayCalendar) error {
+	calendar.Name = strings.TrimSpace(calendar.Name)
+	if calendar.Name == "" {
+		return errors.New("name is required")
+	}
+
+	timezone, err := validateTimezone(calendar.Timezone)
+	if err != nil {
+		return err
+	}
+	calendar.Timezone = timezone
+
+	seen := map[string]bool{}
+	for i := range calendar.Holidays {
+		holiday := &calendar.Holidays[i]
+		holiday.Date = strings.TrimSpace(holiday.Date)
+		if _, err := time.Parse("2006-01-02", holiday.Date); err != nil {
+			return errors.New("invalid holiday date")
+		}
+		if seen[holiday.Date] {
+			return errors.New("duplicate holiday date")
+		}
+		seen[holiday.Date] = true
+	}
+
+	return nil
+}
+
+// InSchedule reports whether at falls in one of the schedule's windows.
+func InSchedule(schedule *models.RoutingSchedule, at time.Time) bool {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := at.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, window := range schedule.Windows {
+		if window.Weekday != int(local.Weekday()) {
+			continue
+		}
+		start, _ := parseClock(window.StartTime)
+		end, _ := parseClock(window.EndTime)
+		if minute >= start && minute < end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsHoliday reports whether at falls on one of the calendar's dates.
+func IsHoliday(calendar *models.HolidayCalendar, at time.Time) bool {
+	loc, err := time.LoadLocation(calendar.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	date := at.In(loc).Format("2006-01-02")
+
+	for _, holiday := range calendar.Holidays {
+		if holiday.Date == date {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *RoutingScheduleService) CreateSchedule(tenantID uint, schedule *models.RoutingSchedule) (*models.RoutingSchedule, error) {
+	schedule.ID = 0
+	schedule.TenantID = tenantID
+	if err := validateRoutingSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Create(schedule).Error; err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *RoutingScheduleService) GetSchedules(tenantID uint) ([]models.RoutingSchedule, error) {
+	var schedules []models.RoutingSchedule
+
+	if err := s.DB.Preload("Windows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("weekday, start_time")
+	}).Where("tenant_id = ?", tenantID).
+		Order("name").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (s *RoutingScheduleService) GetScheduleByID(tenantID, scheduleID uint) (*models.RoutingSchedule, error) {
+	var schedule models.RoutingSchedule
+
+	if err := s.DB.Preload("Windows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("weekday, start_time")
+	}).Where("id = ? AND tenant_id = ?", scheduleID, tenantID).
+		First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("schedule not found")
+		}
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// UpdateSchedule renames the schedule and replaces its windows.
+func (s *RoutingScheduleService) UpdateSchedule(tenantID, scheduleID uint, changes *models.RoutingSchedule) (*models.RoutingSchedule, error) {
+	schedule, err := s.GetScheduleByID(tenantID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateRoutingSchedule(changes); err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(schedule).Updates(map[string]interface{}{
+			"name":     changes.Name,
+			"timezone": changes.Timezone,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("schedule_id = ?", schedule.ID).
+			Delete(&models.RoutingScheduleWindow{}).Error; err != nil {
+			return err
+		}
+
+		for i := range changes.Windows {
+			changes.Windows[i].ID = 0
+			changes.Windows[i].ScheduleID = schedule.ID
+		}
+		if len(changes.Windows) > 0 {
+			if err := tx.Create(&changes.Windows).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetScheduleByID(tenantID, scheduleID)
+}
+
+// DeleteSchedule refuses to delete a schedule a routing rule still uses.
+func (s *RoutingScheduleService) DeleteSchedule(tenantID, scheduleID uint) error {
+	schedule, err := s.GetScheduleByID(tenantID, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.RoutingRule{}).
+		Where("schedule_id = ?", schedule.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("schedule is used by a routing rule")
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("schedule_id = ?", schedule.ID).
+			Delete(&models.RoutingScheduleWindow{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(schedule).Error
+	})
+}
+
+func (s *RoutingScheduleService) CreateCalendar(tenantID uint, calendar *models.HolidayCalendar) (*models.HolidayCalendar, error) {
+	calendar.ID = 0
+	calendar.TenantID = tenantID
+	if err := validateHolidayCalendar(calendar); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Create(calendar).Error; err != nil {
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+func (s *RoutingScheduleService) GetCalendars(tenantID uint) ([]models.HolidayCalendar, error) {
+	var calendars []models.HolidayCalendar
+
+	if err := s.DB.Preload("Holidays", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date")
+	}).Where("tenant_id = ?", tenantID).
+		Order("name").
+		Find(&calendars).Error; err != nil {
+		return nil, err
+	}
+
+	return calendars, nil
+}
+
+func (s *RoutingScheduleService) GetCalendarByID(tenantID, calendarID uint) (*models.HolidayCalendar, error) {
+	var calendar models.HolidayCalendar
+
+	if err := s.DB.Preload("Holidays", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date")
+	}).Where("id = ? AND tenant_id = ?", calendarID, tenantID).
+		First(&calendar).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("holiday calendar not found")
+		}
+		return nil, err
+	}
+
+	return &calendar, nil
+}
+
+// UpdateCalendar renames the calendar and replaces its holidays.
+func (s *RoutingScheduleService) UpdateCalendar(tenantID, calendarID uint, changes *models.HolidayCalendar) (*models.HolidayCalendar, error) {
+	calendar, err := s.GetCalendarByID(tenantID, calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateHolidayCalendar(changes); err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(calendar).Updates(map[string]interface{}{
+			"name":     changes.Name,
+			"timezone": changes.Timezone,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("calendar_id = ?", calendar.ID).
+			Delete(&models.Holiday{}).Error; err != nil {
+			return err
+		}
+
+		for i := range changes.Holidays {
+			changes.Holidays[i].ID = 0
+			changes.Holidays[i].CalendarID = calendar.ID
+		}
+		if len(changes.Holidays) > 0 {
+			if err := tx.Create(&changes.Holidays).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCalendarByID(tenantID, calendarID)
+}
+
+// DeleteCalendar refuses to delete a calendar a routing rule still uses.
+func (s *RoutingScheduleService) DeleteCalendar(tenantID, calendarID uint) error {
+	calendar, err := s.GetCalendarByID(tenantID, calendarID)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.RoutingRule{}).
+		Where("holiday_calendar_id = ?", calendar.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("holiday calendar is used by a routing rule")
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("calendar_id = ?", calendar.ID).
+			Delete(&models.Holiday{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(calendar).Error
+	})
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/phonenumber"
+)
+
+const maxCallerPatternLength = 200
+
+// RoutingTargetTypes are the accepted values of RoutingRule.TargetType.
+var RoutingTargetTypes = map[string]bool{
+	"extension":  true,
+	"ring_group": true,
+	"queue":      true,
+	"ivr":        true,
+	"voicemail":  true,
+	"external":   true,
+}
+
+type RoutingService struct {
+	DB *gorm.DB
+}
+
+func NewRoutingService(db *gorm.DB) *RoutingService {
+	return &RoutingService{DB: db}
+}
+
+// RoutingResult is where an inbound call goes. Source is "rule" when a
+// routing rule matched, and "number" when the number's own routing was
+// used because no rule did. Source is "blocked", with Block set and no
+// target, when the caller is on the tenant's blocklist.
+type RoutingResult struct {
+	Source      string                  `json:"source"`
+	Rule        *models.RoutingRule     `json:"rule,omitempty"`
+	Block       *models.CallerListEntry `json:"block,omitempty"`
+	PhoneNumber *models.PhoneNumber     `json:"phone_number"`
+	TargetType  string                  `json:"target_type"`
+	TargetValue string                  `json:"target_value"`
+}
+
+// validateRoutingTarget checks a target and normalizes its value. Values end
+// up in switch dialplan arguments, so they are held to a strict format.
+func validateRoutingTarget(targetType, value string) (string, error) {
+	if !RoutingTargetTypes[targetType] {
+		return "", errors.New("invalid target type")
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("target value is required")
+	}
+
+	switch targetType {
+	case "extension", "voicemail", "queue", "ivr":
+		if !isDigits(value) {
+			return "", errors.New("invalid target value")
+		}
+	case "ring_group":
+		var members []string
+		for _, member := range strings.Split(value, ",") {
+			member = strings.TrimSpace(member)
+			if !isDigits(member) {
+				return "", errors.New("invalid target value")
+			}
+			members = append(members, member)
+		}
+		value = strings.Join(members, ",")
+	case "external":
+		parsed, err := parseInventoryNumber(value)
+		if err != nil {
+			return "", errors.New("invalid target value")
+		}
+		value = parsed.E164
+	}
+
+	return value, nil
+}
+
+// ruleReference is a record a routing rule points at by ID.
+type ruleReference struct {
+	id       *uint
+	model    interface{}
+	notFound string
+}
+
+// checkRuleReferences makes sure the number, schedule, calendar and target
+// IVR flow or queue a rule refers to belong to the tenant.
+func checkRuleReferences(db *gorm.DB, tenantID uint, rule *models.RoutingRule) error {
+	references := []ruleReference{
+		{rule.PhoneNumberID, &models.PhoneNumber{}, "phone number not found"},
+		{rule.ScheduleID, &models.RoutingSchedule{}, "schedule not found"},
+		{rule.HolidayCalendarID, &models.HolidayCalendar{}, "holiday calendar not found"},
+	}
+	if rule.TargetType == "ivr" || rule.TargetType == "queue" {
+		targetID, err := strconv.ParseUint(rule.TargetValue, 10, 32)
+		if err != nil {
+			return errors.New("invalid target value")
+		}
+		id := uint(targetID)
+		if rule.TargetType == "ivr" {
+			references = append(references, ruleReference{&id, &models.IvrFlow{}, "ivr flow not found"})
+		} else {
+			references = append(references, ruleReference{&id, &models.Queue{}, "queue not found"})
+		}
+	}
+
+	for _, ref := range references {
+		if ref.id == nil {
+			continue
+		}
+		var count int64
+		if err := db.Model(ref.model).
+			Where("id = ? AND tenant_id = ?", *ref.id, tenantID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New(ref.notFound)
+		}
+	}
+
+	return nil
+}
+
+func (s *RoutingService) validateRule(tenantID uint, rule *models.RoutingRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+
+	rule.CallerPattern = strings.TrimSpace(rule.CallerPattern)
+	if len(rule.CallerPattern) > maxCallerPatternLength {
+		return errors.New("invalid caller pattern")
+	}
+	if _, err := regexp.Compile(rule.CallerPattern); err != nil {
+		return errors.New("invalid caller pattern")
+	}
+
+	value, err := validateRoutingTarget(rule.TargetType, rule.TargetValue)
+	if err != nil {
+		return err
+	}
+	rule.TargetValue = value
+
+	return checkRuleReferences(s.DB, tenantID, rule)
+}
+
+func (s *RoutingService) CreateRule(tenantID uint, rule *models.RoutingRule) (*models.RoutingRule, error) {
+	rule.ID = 0
+	rule.TenantID = tenantID
+	if err := s.validateRule(tenantID, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Create(rule).Error; err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// GetRules lists the tenant's rules in evaluation order.
+func (s *RoutingService) GetRules(tenantID uint) ([]models.RoutingRule, error) {
+	var rules []models.RoutingRule
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("priority, id").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s *RoutingService) GetRuleByID(tenantID, ruleID uint) (*models.RoutingRule, error) {
+	var rule models.RoutingRule
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", ruleID, tenantID).
+		First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("routing rule not found")
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (s *RoutingService) UpdateRule(tenantID, ruleID uint, changes *models.RoutingRule) (*models.RoutingRule, error) {
+	rule, err := s.GetRuleByID(tenantID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateRule(tenantID, changes); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Model(rule).Updates(map[string]interface{}{
+		"name":                changes.Name,
+		"priority":            changes.Priority,
+		"phone_number_id":     changes.PhoneNumberID,
+		"caller_pattern":      changes.CallerPattern,
+		"schedule_id":         changes.ScheduleID,
+		"holiday_calendar_id": changes.HolidayCalendarID,
+		"target_type":         changes.TargetType,
+		"target_value":        changes.TargetValue,
+		"is_active":           changes.IsActive,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *RoutingService) DeleteRule(tenantID, ruleID uint) error {
+	rule, err := s.GetRuleByID(tenantID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Delete(rule).Error
+}
+
+// Evaluate resolves where a call from caller to one of the tenant's numbers
+// would go at the given time, without involving the switch.
+func (s *RoutingService) Evaluate(tenantID uint, did, caller string, at time.Time) (*RoutingResult, error) {
+	var tenant models.Tenant
+	if err := s.DB.First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+
+	parsed, ok := phonenumber.Normalize(strings.TrimSpace(did), tenant.DefaultCountry)
+	if !ok {
+		return nil, errors.New("invalid phone number")
+	}
+
+	var number models.PhoneNumber
+	if err := s.DB.Where("number = ? AND tenant_id = ?", parsed.E164, tenantID).
+		First(&number).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("phone number not found")
+		}
+		return nil, err
+	}
+
+	result, err := s.Resolve(&tenant, &number, caller, at)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errors.New("no route for this call")
+	}
+
+	return result, nil
+}
+
+// Resolve screens the caller against the tenant's caller lists, then runs
+// the tenant's routing rules for a call to number and falls back to the
+// number's own routing. It returns nil when nothing routes the call.
+func (s *RoutingService) Resolve(tenant *models.Tenant, number *models.PhoneNumber, caller string, at time.Time) (*RoutingResult, error) {
+	blocked, err := NewCallerListService(s.DB).Screen(tenant, caller, at)
+	if err != nil {
+		return nil, err
+	}
+	if blocked != nil {
+		return &RoutingResult{
+			Source:      "blocked",
+			Block:       blocked,
+			PhoneNumber: number,
+		}, nil
+	}
+
+	var rules []models.RoutingRule
+	if err := s.DB.Preload("Schedule.Windows").
+		Preload("HolidayCalendar.Holidays").
+		Where("tenant_id = ? AND is_active = ?", tenant.ID, true).
+		Where("phone_number_id IS NULL OR phone_number_id = ?", number.ID).
+		Order("priority, id").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	callers := []string{strings.TrimSpace(caller)}
+	if normalized, ok := phonenumber.Normalize(caller, tenant.DefaultCountry); ok && normalized.E164 != callers[0] {
+		callers = append(callers, normalized.E164)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !ruleMatches(rule, callers, at) {
+			continue
+		}
+		return &RoutingResult{
+			Source:      "rule",
+			Rule:        rule,
+			PhoneNumber: number,
+			TargetType:  rule.TargetType,
+			TargetValue: rule.TargetValue,
+		}, nil
+	}
+
+	if number.RoutingType == "" {
+		return nil, nil
+	}
+
+	return &RoutingResult{
+		Source:      "number",
+		PhoneNumber: number,
+		TargetType:  number.RoutingType,
+		TargetValue: number.RoutingDestination,
+	}, nil
+}
+
+// ruleMatches checks a rule's conditions. The caller pattern may match
+// either the number as received or its E.164 form.
+func ruleMatches(rule *models.RoutingRule, callers []string, at time.Time) bool {
+	if rule.CallerPattern != "" {
+		pattern, err := regexp.Compile(rule.CallerPattern)
+		if err != nil {
+			return false
+		}
+		matched := false
+		for _, caller := range callers {
+			if pattern.MatchString(caller) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if rule.ScheduleID != nil && (rule.Schedule == nil || !InSchedule(rule.Schedule, at)) {
+		return false
+	}
+
+	if rule.HolidayCalendarID != nil && (rule.HolidayCalendar == nil || !IsHoliday(rule.HolidayCalendar, at)) {
+		return false
+	}
+
+	return true
+}
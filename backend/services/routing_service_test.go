@@ -0,0 +1,273 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+func TestInSchedule(t *testing.T) {
+	schedule := &models.RoutingSchedule{
+		Timezone: "America/New_York",
+		Windows: []models.RoutingScheduleWindow{
+			{Weekday: 1, StartTime: "09:00", EndTime: "17:00"},
+			{Weekday: 5, StartTime: "20:00", EndTime: "24:00"},
+		},
+	}
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"monday opening", time.Date(2023, 11, 13, 9, 0, 0, 0, newYork), true},
+		{"monday before opening", time.Date(2023, 11, 13, 8, 59, 0, 0, newYork), false},
+		{"monday closing is exclusive", time.Date(2023, 11, 13, 17, 0, 0, 0, newYork), false},
+		{"monday in UTC", time.Date(2023, 11, 13, 15, 0, 0, 0, time.UTC), true},
+		{"monday night in UTC is still open in New York", time.Date(2023, 11, 13, 21, 30, 0, 0, time.UTC), true},
+		{"tuesday", time.Date(2023, 11, 14, 10, 0, 0, 0, newYork), false},
+		{"friday until midnight", time.Date(2023, 11, 17, 23, 59, 0, 0, newYork), true},
+		{"saturday after midnight", time.Date(2023, 11, 18, 0, 0, 0, 0, newYork), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InSchedule(schedule, tt.at); got != tt.want {
+				t.Errorf("InSchedule(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHoliday(t *testing.T) {
+	calendar := &models.HolidayCalendar{
+		Timezone: "Europe/London",
+		Holidays: []models.Holiday{{Date: "2023-12-25"}},
+	}
+
+	if !IsHoliday(calendar, time.Date(2023, 12, 25, 0, 30, 0, 0, time.UTC)) {
+		t.Error("christmas morning is not a holiday")
+	}
+	// Already the 25th in London, still the 24th in Los Angeles
+	losAngeles, _ := time.LoadLocation("America/Los_Angeles")
+	if !IsHoliday(calendar, time.Date(2023, 12, 24, 17, 0, 0, 0, losAngeles)) {
+		t.Error("holiday not read in the calendar's timezone")
+	}
+	if IsHoliday(calendar, time.Date(2023, 12, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Error("boxing day is a holiday")
+	}
+}
+
+func TestValidateRouting(t *testing.T) {
+	scheduleTests := []struct {
+		name     string
+		schedule models.RoutingSchedule
+		want     string
+	}{
+		{"no name", models.RoutingSchedule{}, "name is required"},
+		{"bad timezone", models.RoutingSchedule{Name: "Hours", Timezone: "Mars/Olympus"}, "invalid timezone"},
+		{"bad weekday", models.RoutingSchedule{Name: "Hours", Windows: []models.RoutingScheduleWindow{{Weekday: 7, StartTime: "09:00", EndTime: "17:00"}}}, "weekday must be 0 (Sunday) to 6"},
+		{"bad time", models.RoutingSchedule{Name: "Hours", Windows: []models.RoutingScheduleWindow{{Weekday: 1, StartTime: "9:00", EndTime: "17:00"}}}, "invalid window time"},
+		{"past midnight", models.RoutingSchedule{Name: "Hours", Windows: []models.RoutingScheduleWindow{{Weekday: 1, StartTime: "09:00", EndTime: "24:30"}}}, "invalid window time"},
+		{"reversed", models.RoutingSchedule{Name: "Hours", Windows: []models.RoutingScheduleWindow{{Weekday: 1, StartTime: "17:00", EndTime: "09:00"}}}, "window must end after it starts"},
+		{"valid", models.RoutingSchedule{Name: "Hours", Windows: []models.RoutingScheduleWindow{{Weekday: 1, StartTime: "00:00", EndTime: "24:00"}}}, ""},
+	}
+	for _, tt := range scheduleTests {
+		t.Run("schedule "+tt.name, func(t *testing.T) {
+			err := validateRoutingSchedule(&tt.schedule)
+			if (tt.want == "" && err != nil) || (tt.want != "" && (err == nil || err.Error() != tt.want)) {
+				t.Errorf("validateRoutingSchedule() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	targetTests := []struct {
+		targetType, value string
+		want              string
+		wantErr           bool
+	}{
+		{"extension", " 1001 ", "1001", false},
+		{"extension", "1001 XML other.example.com", "", true},
+		{"ring_group", "1001, 1002", "1001,1002", false},
+		{"ring_group", "1001,", "", true},
+		{"external", "+44 20 7946 0958", "+442079460958", false},
+		{"external", "not a number", "", true},
+		{"voicemail", "", "", true},
+		{"conference", "1", "", true},
+	}
+	for _, tt := range targetTests {
+		t.Run("target "+tt.targetType+" "+tt.value, func(t *testing.T) {
+			got, err := validateRoutingTarget(tt.targetType, tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("validateRoutingTarget() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// newRoutingFixture gives the tenant two numbers, the first routed to
+// extension 1001 and the second without routing of its own.
+func newRoutingFixture(t *testing.T) (*gorm.DB, *models.Tenant, []*models.PhoneNumber) {
+	t.Helper()
+
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxPhoneNumbers: 10})
+	numbers := NewPhoneNumberService(db)
+
+	var assigned []*models.PhoneNumber
+	for _, e164 := range []string{"+14155550100", "+14155550101"} {
+		number, err := numbers.CreateNumber(e164, models.PhoneNumber{VoiceEnabled: true})
+		if err != nil {
+			t.Fatalf("CreateNumber: %v", err)
+		}
+		if _, err := numbers.AssignNumber(number.ID, tenant.ID, nil); err != nil {
+			t.Fatalf("AssignNumber: %v", err)
+		}
+		assigned = append(assigned, number)
+	}
+	if _, err := numbers.SetRouting(tenant.ID, assigned[0].ID, "extension", "1001"); err != nil {
+		t.Fatalf("SetRouting: %v", err)
+	}
+
+	return db, tenant, assigned
+}
+
+func TestEvaluateRoutingRules(t *testing.T) {
+	db, tenant, numbers := newRoutingFixture(t)
+	schedules := NewRoutingScheduleService(db)
+	service := NewRoutingService(db)
+
+	hours, err := schedules.CreateSchedule(tenant.ID, &models.RoutingSchedule{
+		Name:    "Business hours",
+		Windows: []models.RoutingScheduleWindow{{Weekday: 1, StartTime: "09:00", EndTime: "17:00"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	holidays, err := schedules.CreateCalendar(tenant.ID, &models.HolidayCalendar{
+		Name:     "Holidays",
+		Holidays: []models.Holiday{{Date: "2023-12-25"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateCalendar: %v", err)
+	}
+
+	for _, rule := range []models.RoutingRule{
+		{Name: "Closed", Priority: 1, HolidayCalendarID: &holidays.ID, TargetType: "voicemail", TargetValue: "1001", IsActive: true},
+		{Name: "VIP", Priority: 2, CallerPattern: `^\+4420`, TargetType: "extension", TargetValue: "1002", IsActive: true},
+		{Name: "Sales", Priority: 3, PhoneNumberID: &numbers[1].ID, ScheduleID: &hours.ID, TargetType: "ring_group", TargetValue: "1003,1004", IsActive: true},
+		{Name: "Disabled", Priority: 0, TargetType: "extension", TargetValue: "1005", IsActive: false},
+	} {
+		if _, err := service.CreateRule(tenant.ID, &rule); err != nil {
+			t.Fatalf("CreateRule(%s): %v", rule.Name, err)
+		}
+	}
+	db.Model(&models.RoutingRule{}).Where("name = ?", "Disabled").Update("is_active", false)
+
+	monday := time.Date(2023, 11, 13, 10, 0, 0, 0, time.UTC)
+	christmas := time.Date(2023, 12, 25, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		did        string
+		caller     string
+		at         time.Time
+		wantSource string
+		wantType   string
+		wantValue  string
+	}{
+		{"holiday wins", "+14155550101", "+442079460958", christmas, "rule", "voicemail", "1001"},
+		{"caller pattern", "+14155550100", "+442079460958", monday, "rule", "extension", "1002"},
+		{"caller pattern on the normalized number", "+14155550100", "011 44 20 7946 0958", monday, "rule", "extension", "1002"},
+		{"number and schedule", "+14155550101", "+14155550199", monday, "rule", "ring_group", "1003,1004"},
+		{"number's own routing", "+14155550100", "+14155550199", monday, "number", "extension", "1001"},
+		{"national format", "(415) 555-0100", "+14155550199", monday, "number", "extension", "1001"},
+	}
+	db.Model(tenant).Update("default_country", "US")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Evaluate(tenant.ID, tt.did, tt.caller, tt.at)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			if result.Source != tt.wantSource || result.TargetType != tt.wantType || result.TargetValue != tt.wantValue {
+				t.Errorf("Evaluate() = %s %s %s, want %s %s %s",
+					result.Source, result.TargetType, result.TargetValue, tt.wantSource, tt.wantType, tt.wantValue)
+			}
+		})
+	}
+
+	outOfHours := monday.Add(8 * time.Hour)
+	if _, err := service.Evaluate(tenant.ID, "+14155550101", "+14155550199", outOfHours); err == nil || err.Error() != "no route for this call" {
+		t.Errorf("call outside the schedule = %v, want no route", err)
+	}
+	if _, err := service.Evaluate(tenant.ID, "+14155550102", "", monday); err == nil || err.Error() != "phone number not found" {
+		t.Errorf("unknown number = %v", err)
+	}
+}
+
+func TestRoutingReferencesAreTenantScoped(t *testing.T) {
+	db, tenant, numbers := newRoutingFixture(t)
+	other := createTestTenant(t, db, "other.example.com", models.Plan{})
+	schedules := NewRoutingScheduleService(db)
+	service := NewRoutingService(db)
+
+	foreign, err := schedules.CreateSchedule(other.ID, &models.RoutingSchedule{Name: "Other hours"})
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	if _, err := service.CreateRule(tenant.ID, &models.RoutingRule{Name: "Borrowed", ScheduleID: &foreign.ID, TargetType: "extension", TargetValue: "1001"}); err == nil || err.Error() != "schedule not found" {
+		t.Errorf("rule with another tenant's schedule = %v", err)
+	}
+	if _, err := service.CreateRule(other.ID, &models.RoutingRule{Name: "Borrowed", PhoneNumberID: &numbers[0].ID, TargetType: "extension", TargetValue: "1001"}); err == nil || err.Error() != "phone number not found" {
+		t.Errorf("rule with another tenant's number = %v", err)
+	}
+
+	own, err := schedules.CreateSchedule(tenant.ID, &models.RoutingSchedule{Name: "Hours"})
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	rule, err := service.CreateRule(tenant.ID, &models.RoutingRule{Name: "Hours", ScheduleID: &own.ID, TargetType: "extension", TargetValue: "1001"})
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if err := schedules.DeleteSchedule(tenant.ID, own.ID); err == nil || err.Error() != "schedule is used by a routing rule" {
+		t.Errorf("deleting a schedule in use = %v", err)
+	}
+
+	if _, err := service.GetRuleByID(other.ID, rule.ID); err == nil || err.Error() != "routing rule not found" {
+		t.Errorf("another tenant read the rule: %v", err)
+	}
+	if err := service.DeleteRule(tenant.ID, rule.ID); err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if err := schedules.DeleteSchedule(tenant.ID, own.ID); err != nil {
+		t.Errorf("DeleteSchedule after its rule went: %v", err)
+	}
+}
+
+func TestXMLCurlInboundRoutingRule(t *testing.T) {
+	db, tenant, numbers := newRoutingFixture(t)
+	if _, err := NewRoutingService(db).CreateRule(tenant.ID, &models.RoutingRule{
+		Name: "Everyone", PhoneNumberID: &numbers[1].ID, TargetType: "ring_group", TargetValue: "1001,1002", IsActive: true,
+	}); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	service := NewXMLCurlService(db, "")
+
+	section := renderedSection(t, service, XMLCurlRequest{Section: "dialplan", Context: "public", DestinationNumber: "+14155550101", CallerNumber: "+14155550199"})
+	if section.Context == nil {
+		t.Fatalf("dialplan = %+v", section)
+	}
+	actions := section.Context.Extensions[0].Condition.Actions
+	last := actions[len(actions)-1]
+	if last != (fsAction{Application: "bridge", Data: "user/1001@acme.example.com,user/1002@acme.example.com"}) {
+		t.Errorf("actions = %+v", actions)
+	}
+	if actions[len(actions)-2].Application != "set" || actions[len(actions)-2].Data == "" {
+		t.Errorf("matched rule not recorded: %+v", actions)
+	}
+}
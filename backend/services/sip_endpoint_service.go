@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/utils"
+)
+
+const defaultSipCodecs = "OPUS,G722,PCMU,PCMA"
+
+var sipCodecs = map[string]bool{
+	"OPUS": true,
+	"G722": true,
+	"PCMU": true,
+	"PCMA": true,
+	"G729": true,
+	"GSM":  true,
+	"ILBC": true,
+	"H264": true,
+	"VP8":  true,
+}
+
+type SipEndpointService struct {
+	DB *gorm.DB
+}
+
+func NewSipEndpointService(db *gorm.DB) *SipEndpointService {
+	return &SipEndpointService{DB: db}
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// validateSipEndpoint checks the endpoint and normalizes its codec list.
+func validateSipEndpoint(endpoint *models.SipEndpoint) error {
+	endpoint.Extension = strings.TrimSpace(endpoint.Extension)
+	if len(endpoint.Extension) < 2 || len(endpoint.Extension) > 10 || !isDigits(endpoint.Extension) {
+		return errors.New("extension must be 2 to 10 digits")
+	}
+
+	callerID := strings.TrimPrefix(endpoint.CallerIDNumber, "+")
+	if endpoint.CallerIDNumber != "" && !isDigits(callerID) {
+		return errors.New("invalid caller ID number")
+	}
+
+	if endpoint.MaxConcurrentCalls < 0 {
+		return errors.New("max concurrent calls cannot be negative")
+	}
+
+	codecs := endpoint.CodecList()
+	if len(codecs) == 0 {
+		endpoint.Codecs = defaultSipCodecs
+		return nil
+	}
+	for i, codec := range codecs {
+		codec = strings.ToUpper(codec)
+		if !sipCodecs[codec] {
+			return errors.New("unsupported codec: " + codec)
+		}
+		codecs[i] = codec
+	}
+	endpoint.Codecs = strings.Join(codecs, ",")
+
+	return nil
+}
+
+// checkEndpointUser makes sure an endpoint is only linked to a user of the
+// same tenant.
+func checkEndpointUser(db *gorm.DB, tenantID uint, userID *uint) error {
+	if userID == nil {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&models.User{}).
+		Where("id = ? AND tenant_id = ?", *userID, tenantID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func extensionTaken(db *gorm.DB, tenantID uint, extension string, excludeID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.SipEndpoint{}).
+		Where("tenant_id = ? AND extension = ? AND id <> ?", tenantID, extension, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateEndpoint provisions a SIP account with a generated password,
+// enforcing the MaxSipEndpoints limit of the tenant's plan. The plain
+// password is returned so it can be handed to the device.
+func (s *SipEndpointService) CreateEndpoint(tenantID uint, endpoint *models.SipEndpoint) (*models.SipEndpoint, string, error) {
+	endpoint.ID = 0
+	endpoint.TenantID = tenantID
+	if err := validateSipEndpoint(endpoint); err != nil {
+		return nil, "", err
+	}
+
+	password, err := utils.GenerateSecret(12)
+	if err != nil {
+		return nil, "", err
+	}
+	endpoint.Password = password
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the tenant so concurrent creates cannot both pass the limit
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&tenant, tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("tenant not found")
+			}
+			return err
+		}
+
+		subscription, err := NewSubscriptionService(tx).GetTenantSubscription(tenantID)
+		if err != nil {
+			if err.Error() == "subscription not found" {
+				return errors.New("no active subscription found")
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.SipEndpoint{}).
+			Where("tenant_id = ?", tenantID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if subscription.Plan.MaxSipEndpoints > 0 && uint(count) >= subscription.Plan.MaxSipEndpoints {
+			return errors.New("quota exceeded: max sip endpoints reached")
+		}
+
+		if err := checkEndpointUser(tx, tenantID, endpoint.UserID); err != nil {
+			return err
+		}
+
+		taken, err := extensionTaken(tx, tenantID, endpoint.Extension, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errors.New("extension already exists")
+		}
+
+		return tx.Create(endpoint).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return endpoint, password, nil
+}
+
+func (s *SipEndpointService) GetEndpoints(tenantID uint) ([]models.SipEndpoint, error) {
+	var endpoints []models.SipEndpoint
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("extension").
+		Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (s *SipEndpointService) GetEndpointByID(tenantID, endpointID uint) (*models.SipEndpoint, error) {
+	var endpoint models.SipEndpoint
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", endpointID, tenantID).
+		First(&endpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("sip endpoint not found")
+		}
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// UpdateEndpoint replaces the endpoint's settings. The password is left
+// alone; use ResetPassword to rotate it.
+func (s *SipEndpointService) UpdateEndpoint(tenantID, endpointID uint, changes *models.SipEndpoint) (*models.SipEndpoint, error) {
+	endpoint, err := s.GetEndpointByID(tenantID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSipEndpoint(changes); err != nil {
+		return nil, err
+	}
+
+	if err := checkEndpointUser(s.DB, tenantID, changes.UserID); err != nil {
+		return nil, err
+	}
+
+	taken, err := extensionTaken(s.DB, tenantID, changes.Extension, endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errors.New("extension already exists")
+	}
+
+	if err := s.DB.Model(endpoint).Updates(map[string]interface{}{
+		"user_id":              changes.UserID,
+		"extension":            changes.Extension,
+		"caller_id_name":       changes.CallerIDName,
+		"caller_id_number":     changes.CallerIDNumber,
+		"codecs":               changes.Codecs,
+		"max_concurrent_calls": changes.MaxConcurrentCalls,
+		"is_active":            changes.IsActive,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (s *SipEndpointService) ResetPassword(tenantID, endpointID uint) (*models.SipEndpoint, string, error) {
+	endpoint, err := s.GetEndpointByID(tenantID, endpointID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	password, err := utils.GenerateSecret(12)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.DB.Model(endpoint).Update("password", password).Error; err != nil {
+		return nil, "", err
+	}
+
+	return endpoint, password, nil
+}
+
+func (s *SipEndpointService) DeleteEndpoint(tenantID, endpointID uint) error {
+	endpoint, err := s.GetEndpointByID(tenantID, endpointID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Delete(endpoint).Error
+}
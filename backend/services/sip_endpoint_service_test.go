@@ -0,0 +1,151 @@
+package services
+
+import (
+	"testing"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+func createTestUser(t *testing.T, db *gorm.DB, tenantID uint, username string) *models.User {
+	t.Helper()
+
+	role := models.Role{TenantID: tenantID, Name: "member"}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("creating role: %v", err)
+	}
+	user := models.User{TenantID: tenantID, Username: username, PasswordHash: "x", RoleID: role.ID}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return &user
+}
+
+func TestValidateSipEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   models.SipEndpoint
+		wantErr    string
+		wantCodecs string
+	}{
+		{"defaults", models.SipEndpoint{Extension: " 1001 "}, "", defaultSipCodecs},
+		{"codecs normalized", models.SipEndpoint{Extension: "1001", Codecs: "pcma, g729 ,"}, "", "PCMA,G729"},
+		{"e164 caller id", models.SipEndpoint{Extension: "1001", CallerIDNumber: "+14155550100"}, "", defaultSipCodecs},
+		{"short extension", models.SipEndpoint{Extension: "1"}, "extension must be 2 to 10 digits", ""},
+		{"named extension", models.SipEndpoint{Extension: "alice"}, "extension must be 2 to 10 digits", ""},
+		{"bad caller id", models.SipEndpoint{Extension: "1001", CallerIDNumber: "anonymous"}, "invalid caller ID number", ""},
+		{"negative limit", models.SipEndpoint{Extension: "1001", MaxConcurrentCalls: -1}, "max concurrent calls cannot be negative", ""},
+		{"unknown codec", models.SipEndpoint{Extension: "1001", Codecs: "PCMU,AMR"}, "unsupported codec: AMR", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSipEndpoint(&tt.endpoint)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("validateSipEndpoint() = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateSipEndpoint: %v", err)
+			}
+			if tt.endpoint.Codecs != tt.wantCodecs || tt.endpoint.Extension != "1001" {
+				t.Errorf("endpoint %q codecs %q, want %q", tt.endpoint.Extension, tt.endpoint.Codecs, tt.wantCodecs)
+			}
+		})
+	}
+}
+
+func TestCreateEndpoint(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxSipEndpoints: 2})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{MaxSipEndpoints: 2})
+	service := NewSipEndpointService(db)
+
+	user := createTestUser(t, db, tenant.ID, "alice")
+	outsider := createTestUser(t, db, other.ID, "bob")
+
+	endpoint, password, err := service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1001", UserID: &user.ID})
+	if err != nil {
+		t.Fatalf("CreateEndpoint: %v", err)
+	}
+	if len(password) < 12 || endpoint.Password != password || endpoint.TenantID != tenant.ID {
+		t.Errorf("endpoint = %+v with password %q", endpoint, password)
+	}
+
+	if _, _, err := service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1001"}); err == nil || err.Error() != "extension already exists" {
+		t.Errorf("duplicate extension = %v", err)
+	}
+	if _, _, err := service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1002", UserID: &outsider.ID}); err == nil || err.Error() != "user not found" {
+		t.Errorf("linking another tenant's user = %v", err)
+	}
+
+	// The same extension is free in another tenant
+	if _, _, err := service.CreateEndpoint(other.ID, &models.SipEndpoint{Extension: "1001"}); err != nil {
+		t.Errorf("same extension in another tenant: %v", err)
+	}
+
+	if _, _, err := service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1002"}); err != nil {
+		t.Fatalf("CreateEndpoint: %v", err)
+	}
+	if _, _, err := service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1003"}); err == nil || err.Error() != "quota exceeded: max sip endpoints reached" {
+		t.Errorf("creating past the plan limit = %v", err)
+	}
+}
+
+func TestCreateEndpointWithoutLimit(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewSipEndpointService(db)
+
+	// A plan limit of 0 leaves the limit off
+	for _, extension := range []string{"1001", "1002", "1003"} {
+		if _, _, err := service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: extension}); err != nil {
+			t.Fatalf("CreateEndpoint %s: %v", extension, err)
+		}
+	}
+}
+
+func TestUpdateEndpointAndResetPassword(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxSipEndpoints: 5})
+	other := createTestTenant(t, db, "other.example.com", models.Plan{MaxSipEndpoints: 5})
+	service := NewSipEndpointService(db)
+
+	endpoint, password, _ := service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1001"})
+	service.CreateEndpoint(tenant.ID, &models.SipEndpoint{Extension: "1002"})
+
+	if _, err := service.UpdateEndpoint(tenant.ID, endpoint.ID, &models.SipEndpoint{Extension: "1002"}); err == nil || err.Error() != "extension already exists" {
+		t.Errorf("renumbering onto a taken extension = %v", err)
+	}
+	if _, err := service.UpdateEndpoint(other.ID, endpoint.ID, &models.SipEndpoint{Extension: "1005"}); err == nil || err.Error() != "sip endpoint not found" {
+		t.Errorf("other tenant updated the endpoint: %v", err)
+	}
+
+	updated, err := service.UpdateEndpoint(tenant.ID, endpoint.ID, &models.SipEndpoint{Extension: "1001", CallerIDName: "Front desk", Codecs: "pcmu", IsActive: false})
+	if err != nil {
+		t.Fatalf("UpdateEndpoint: %v", err)
+	}
+	if updated.CallerIDName != "Front desk" || updated.Codecs != "PCMU" || updated.IsActive || updated.Password != password {
+		t.Errorf("updated endpoint = %+v", updated)
+	}
+
+	_, rotated, err := service.ResetPassword(tenant.ID, endpoint.ID)
+	if err != nil || rotated == password {
+		t.Fatalf("ResetPassword() = %q, %v", rotated, err)
+	}
+	stored, _ := service.GetEndpointByID(tenant.ID, endpoint.ID)
+	if stored.Password != rotated {
+		t.Error("rotated password not stored")
+	}
+
+	if err := service.DeleteEndpoint(other.ID, endpoint.ID); err == nil {
+		t.Error("other tenant deleted the endpoint")
+	}
+	if err := service.DeleteEndpoint(tenant.ID, endpoint.ID); err != nil {
+		t.Fatalf("DeleteEndpoint: %v", err)
+	}
+	if endpoints, _ := service.GetEndpoints(tenant.ID); len(endpoints) != 1 || endpoints[0].Extension != "1002" {
+		t.Errorf("endpoints after delete = %+v", endpoints)
+	}
+}
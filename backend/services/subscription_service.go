@@ -15,13 +15,8 @@ func NewSubscriptionService(db *gorm.DB) *SubscriptionService {
 	return &SubscriptionService{DB: db}
 }
 
-func (s *SubscriptionService) CreatePlan(name string, maxUsers uint, maxCalls uint, price float64) (*models.Plan, error) {
-	plan := models.Plan{
-		Name:     name,
-		MaxUsers: maxUsers,
-		MaxCalls: maxCalls,
-		Price:    price,
-	}
+func (s *SubscriptionService) CreatePlan(plan models.Plan) (*models.Plan, error) {
+	plan.ID = 0
 
 	if err := s.DB.Create(&plan).Error; err != nil {
 		return nil, err
@@ -30,6 +25,29 @@ func (s *SubscriptionService) CreatePlan(name string, maxUsers uint, maxCalls ui
 	return &plan, nil
 }
 
+// UpdatePlan writes the given columns of the plan; columns not in changes
+// keep their values.
+func (s *SubscriptionService) UpdatePlan(planID uint, changes map[string]interface{}) (*models.Plan, error) {
+	var plan models.Plan
+
+	if err := s.DB.First(&plan, planID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("plan not found")
+		}
+		return nil, err
+	}
+
+	if len(changes) == 0 {
+		return &plan, nil
+	}
+
+	if err := s.DB.Model(&plan).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
 func (s *SubscriptionService) GetAllPlans() ([]models.Plan, error) {
 	var plans []models.Plan
 	
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/utils"
+)
+
+type SwitchCredentialService struct {
+	DB *gorm.DB
+}
+
+func NewSwitchCredentialService(db *gorm.DB) *SwitchCredentialService {
+	return &SwitchCredentialService{DB: db}
+}
+
+// CreateCredential issues a new credential for the tenant. The plain secret is
+// only returned here; afterwards only its hash is kept.
+func (s *SwitchCredentialService) CreateCredential(tenantID uint, description string) (*models.SwitchCredential, string, error) {
+	suffix, err := utils.GenerateSecret(8)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := utils.GenerateSecret(24)
+	if err != nil {
+		return nil, "", err
+	}
+
+	credential := models.SwitchCredential{
+		TenantID:    tenantID,
+		Username:    "sw_" + suffix,
+		SecretHash:  utils.HashSecret(secret),
+		Description: description,
+		IsActive:    true,
+	}
+
+	if err := s.DB.Create(&credential).Error; err != nil {
+		return nil, "", err
+	}
+
+	return &credential, secret, nil
+}
+
+func (s *SwitchCredentialService) GetCredentials(tenantID uint) ([]models.SwitchCredential, error) {
+	var credentials []models.SwitchCredential
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func (s *SwitchCredentialService) DeleteCredential(tenantID, credentialID uint) error {
+	var credential models.SwitchCredential
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", credentialID, tenantID).
+		First(&credential).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("switch credential not found")
+		}
+		return err
+	}
+
+	if err := s.DB.Delete(&credential).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Authenticate resolves a username/secret pair to an active credential.
+func (s *SwitchCredentialService) Authenticate(username, secret string) (*models.SwitchCredential, error) {
+	var credential models.SwitchCredential
+
+	if err := s.DB.Where("username = ? AND is_active = ?", username, true).
+		First(&credential).Error; err != nil {
+		return nil, errors.New("invalid switch credentials")
+	}
+
+	if !utils.CheckSecretHash(secret, credential.SecretHash) {
+		return nil, errors.New("invalid switch credentials")
+	}
+
+	now := time.Now()
+	s.DB.Model(&credential).Update("last_used_at", now)
+	credential.LastUsedAt = &now
+
+	return &credential, nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+	"github.com/your-module/backend/models"
+)
+
+func TestSwitchCredentialAuthenticate(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	service := NewSwitchCredentialService(db)
+
+	credential, secret, err := service.CreateCredential(tenant.ID, "edge switch")
+	if err != nil {
+		t.Fatalf("CreateCredential: %v", err)
+	}
+	if credential.SecretHash == secret {
+		t.Fatal("secret stored in plain text")
+	}
+
+	authenticated, err := service.Authenticate(credential.Username, secret)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if authenticated.TenantID != tenant.ID || authenticated.LastUsedAt == nil {
+		t.Errorf("authenticated credential = %+v", authenticated)
+	}
+
+	if _, err := service.Authenticate(credential.Username, secret+"x"); err == nil {
+		t.Error("Authenticate accepted a wrong secret")
+	}
+
+	if err := service.DeleteCredential(tenant.ID, credential.ID); err != nil {
+		t.Fatalf("DeleteCredential: %v", err)
+	}
+	if _, err := service.Authenticate(credential.Username, secret); err == nil {
+		t.Error("Authenticate accepted a deleted credential")
+	}
+}
@@ -2,16 +2,28 @@ package services
 
 import (
 	"errors"
+	"log"
+	"strings"
+	"sync"
 	"gorm.io/gorm"
 	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/phonenumber"
 )
 
 type TenantService struct {
 	DB *gorm.DB
+
+	// normalizing holds the tenants whose calls are being re-normalized,
+	// set to true when another run was asked for in the meantime.
+	mu          sync.Mutex
+	normalizing map[uint]bool
 }
 
 func NewTenantService(db *gorm.DB) *TenantService {
-	return &TenantService{DB: db}
+	return &TenantService{
+		DB:          db,
+		normalizing: map[uint]bool{},
+	}
 }
 
 func (s *TenantService) CreateTenant(name, domain string) (*models.Tenant, error) {
@@ -50,6 +62,70 @@ func (s *TenantService) GetTenantByID(tenantID uint) (*models.Tenant, error) {
 	return &tenant, nil
 }
 
+// SetDefaultCountry changes the country used to resolve national-format
+// numbers. An empty country clears it. The tenant's existing calls are
+// re-normalized in the background, since that can take a while.
+func (s *TenantService) SetDefaultCountry(tenantID uint, country string) (*models.Tenant, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country != "" && !phonenumber.IsKnownCountry(country) {
+		return nil, errors.New("unsupported country")
+	}
+
+	var tenant models.Tenant
+	if err := s.DB.First(&tenant, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+
+	if err := s.DB.Model(&tenant).Update("default_country", country).Error; err != nil {
+		return nil, err
+	}
+
+	s.normalizeCalls(tenantID)
+
+	return &tenant, nil
+}
+
+// normalizeCalls re-normalizes the tenant's calls in the background. Runs
+// for one tenant never overlap: a change made during a run starts another
+// once it finishes, so the calls end up matching the latest country.
+func (s *TenantService) normalizeCalls(tenantID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, running := s.normalizing[tenantID]; running {
+		s.normalizing[tenantID] = true
+		return
+	}
+	s.normalizing[tenantID] = false
+
+	go func() {
+		for {
+			changed, err := NewCallService(s.DB).NormalizeCalls(tenantID)
+			if err != nil {
+				log.Printf("normalize calls: tenant %d: %v", tenantID, err)
+			} else {
+				log.Printf("normalize calls: tenant %d: %d calls changed", tenantID, changed)
+			}
+
+			s.mu.Lock()
+			again := s.normalizing[tenantID]
+			if !again {
+				delete(s.normalizing, tenantID)
+			} else {
+				s.normalizing[tenantID] = false
+			}
+			s.mu.Unlock()
+
+			if !again {
+				return
+			}
+		}
+	}()
+}
+
 func (s *TenantService) DeleteTenant(tenantID uint) error {
 	var tenant models.Tenant
 	
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"github.com/your-module/backend/models"
+)
+
+func TestSetDefaultCountryRenormalizesCalls(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+	callService := NewCallService(db)
+
+	national, _, err := callService.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "national", Caller: "1001", Callee: "07700 900123"})
+	if err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	international, _, err := callService.UpsertCallByUUID(tenant.ID, &models.Call{UUID: "international", Caller: "+14155550100", Callee: "+442079460958"})
+	if err != nil {
+		t.Fatalf("UpsertCallByUUID: %v", err)
+	}
+	if national.CalleeE164 != "" || international.CallerE164 != "+14155550100" || international.CalleeCountry != "GB" {
+		t.Errorf("without a default country: national %q, international %q -> %q (%s)",
+			national.CalleeE164, international.CallerE164, international.CalleeE164, international.CalleeCountry)
+	}
+
+	service := NewTenantService(db)
+	updated, err := service.SetDefaultCountry(tenant.ID, " gb ")
+	if err != nil {
+		t.Fatalf("SetDefaultCountry: %v", err)
+	}
+	if updated.DefaultCountry != "GB" {
+		t.Errorf("country %q, want GB", updated.DefaultCountry)
+	}
+
+	var call models.Call
+	waitFor(t, "the national call to be normalized", func() bool {
+		db.First(&call, national.ID)
+		return call.CalleeE164 != ""
+	})
+	if call.CalleeE164 != "+447700900123" || call.CalleeCountry != "GB" || call.CalleeType != "mobile" {
+		t.Errorf("national callee = %q (%s, %s)", call.CalleeE164, call.CalleeCountry, call.CalleeType)
+	}
+	if call.CallerE164 != "" {
+		t.Errorf("extension normalized to %q", call.CallerE164)
+	}
+
+	if _, err := service.SetDefaultCountry(tenant.ID, "XX"); err == nil || err.Error() != "unsupported country" {
+		t.Errorf("unknown country = %v", err)
+	}
+	if _, err := service.SetDefaultCountry(tenant.ID+100, "US"); err == nil || err.Error() != "tenant not found" {
+		t.Errorf("unknown tenant = %v", err)
+	}
+
+	// Changes in quick succession end with the calls matching the last one
+	for _, country := range []string{"US", "GB", ""} {
+		if _, err := service.SetDefaultCountry(tenant.ID, country); err != nil {
+			t.Fatalf("SetDefaultCountry %q: %v", country, err)
+		}
+	}
+	waitFor(t, "the last normalization", func() bool {
+		service.mu.Lock()
+		defer service.mu.Unlock()
+		_, running := service.normalizing[tenant.ID]
+		return !running
+	})
+	db.First(&call, national.ID)
+	if call.CalleeE164 != "" {
+		t.Errorf("national callee still %q without a default country", call.CalleeE164)
+	}
+	var stored models.Call
+	db.First(&stored, international.ID)
+	if stored.CallerE164 != "+14155550100" {
+		t.Errorf("international caller = %q", stored.CallerE164)
+	}
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+	"github.com/your-module/backend/transcription"
+)
+
+const (
+	transcriptionEnqueueBatch = 500
+	// Jobs stuck in processing longer than this are assumed to belong to a
+	// worker that died and are picked up again.
+	transcriptionStaleAfter = 30 * time.Minute
+)
+
+// recordingFetcher downloads recordings the switch keeps at a URL. It only
+// connects to public addresses, since the URL may have come from an API
+// client.
+var recordingFetcher = &http.Client{
+	Timeout: 10 * time.Minute,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip := net.ParseIP(host)
+				if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+					ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+					return fmt.Errorf("recording host %s is not a public address", host)
+				}
+				return nil
+			},
+		}).DialContext,
+	},
+}
+
+type TranscriptionService struct {
+	DB          *gorm.DB
+	Storage     storage.Storage
+	Provider    transcription.Provider
+	MaxAttempts int
+}
+
+func NewTranscriptionService(db *gorm.DB, store storage.Storage, provider transcription.Provider, maxAttempts int) *TranscriptionService {
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	return &TranscriptionService{
+		DB:          db,
+		Storage:     store,
+		Provider:    provider,
+		MaxAttempts: maxAttempts,
+	}
+}
+
+// Run polls for work until stop is closed: it queues a job for every
+// recorded call without a transcript, then drains the pending jobs.
+func (s *TranscriptionService) Run(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.EnqueuePending(); err != nil {
+			log.Printf("transcription: enqueue failed: %v", err)
+		}
+
+		for {
+			processed, err := s.ProcessNext()
+			if err != nil {
+				log.Printf("transcription: job failed: %v", err)
+			}
+			if !processed {
+				break
+			}
+
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnqueuePending creates pending transcripts for calls that have a stored
+// or remote recording but no transcript yet.
+func (s *TranscriptionService) EnqueuePending() (int64, error) {
+	result := s.DB.Exec(`
+		INSERT INTO transcripts (tenant_id, call_id, status, attempts, created_at, updated_at)
+		SELECT c.tenant_id, c.id, ?, 0, NOW(), NOW()
+		FROM calls c
+		WHERE (`+RecordedCallCondition+`) AND c.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM transcripts t WHERE t.call_id = c.id)
+		ORDER BY c.id
+		LIMIT ?
+		ON CONFLICT (call_id) DO NOTHING`,
+		models.TranscriptStatusPending, transcriptionEnqueueBatch)
+
+	return result.RowsAffected, result.Error
+}
+
+// ProcessNext claims one job and runs it. It returns false when there was
+// nothing to do. Claiming uses SKIP LOCKED so several workers can share the
+// queue.
+func (s *TranscriptionService) ProcessNext() (bool, error) {
+	var job models.Transcript
+
+	// A worker that died on its last attempt leaves the job in processing
+	// with no attempts left, so it would never be claimed again
+	if err := s.DB.Model(&models.Transcript{}).
+		Where("status = ? AND started_at < ? AND attempts >= ?",
+			models.TranscriptStatusProcessing,
+			time.Now().Add(-transcriptionStaleAfter),
+			s.MaxAttempts).
+		Updates(map[string]interface{}{
+			"status": models.TranscriptStatusFailed,
+			"error":  "transcription did not finish",
+		}).Error; err != nil {
+		return false, err
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("attempts < ?", s.MaxAttempts).
+			Where("status = ? OR (status = ? AND started_at < ?)",
+				models.TranscriptStatusPending,
+				models.TranscriptStatusProcessing,
+				time.Now().Add(-transcriptionStaleAfter)).
+			Order("id").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":     models.TranscriptStatusProcessing,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"started_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.transcribe(&job); err != nil {
+		status := models.TranscriptStatusPending
+		if job.Attempts+1 >= s.MaxAttempts {
+			status = models.TranscriptStatusFailed
+		}
+		if updateErr := s.DB.Model(&job).Updates(map[string]interface{}{
+			"status": status,
+			"error":  err.Error(),
+		}).Error; updateErr != nil {
+			return true, fmt.Errorf("%v (recording the failure: %v)", err, updateErr)
+		}
+		return true, err
+	}
+
+	return true, nil
+}
+
+func (s *TranscriptionService) transcribe(job *models.Transcript) error {
+	var call models.Call
+	if err := s.DB.Where("id = ? AND tenant_id = ?", job.CallID, job.TenantID).
+		First(&call).Error; err != nil {
+		return err
+	}
+
+	reader, contentType, err := s.openRecording(&call)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	result, err := s.Provider.Transcribe(reader, contentType)
+	if err != nil {
+		return err
+	}
+
+	segments := make([]models.TranscriptSegment, 0, len(result.Segments))
+	texts := make([]string, 0, len(result.Segments))
+	for i, segment := range result.Segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, models.TranscriptSegment{
+			TranscriptID: job.ID,
+			Position:     i,
+			Start:        segment.Start,
+			End:          segment.End,
+			Speaker:      segment.Speaker,
+			Text:         text,
+		})
+		texts = append(texts, text)
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcript_id = ?", job.ID).
+			Delete(&models.TranscriptSegment{}).Error; err != nil {
+			return err
+		}
+
+		if len(segments) > 0 {
+			if err := tx.CreateInBatches(segments, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(job).Updates(map[string]interface{}{
+			"status":       models.TranscriptStatusCompleted,
+			"provider":     s.Provider.Name(),
+			"language":     result.Language,
+			"text":         strings.Join(texts, "\n"),
+			"error":        "",
+			"completed_at": now,
+		}).Error
+	})
+}
+
+// openRecording reads the call's stored recording, or downloads it when the
+// switch only reported where it keeps it.
+func (s *TranscriptionService) openRecording(call *models.Call) (io.ReadCloser, string, error) {
+	if call.RecordingKey != "" {
+		reader, info, err := storage.ForTenant(s.Storage, call.TenantID).Get(call.RecordingKey)
+		if err != nil {
+			return nil, "", err
+		}
+		return reader, info.ContentType, nil
+	}
+
+	url := call.RemoteRecordingURL()
+	if url == "" {
+		return nil, "", errors.New("call has no recording")
+	}
+
+	resp, err := recordingFetcher.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("fetching recording: %s", resp.Status)
+	}
+	return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
+func (s *TranscriptionService) GetTranscriptByCallID(tenantID, callID uint) (*models.Transcript, error) {
+	var transcript models.Transcript
+
+	if err := s.DB.Where("call_id = ? AND tenant_id = ?", callID, tenantID).
+		Preload("Segments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		First(&transcript).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transcript not found")
+		}
+		return nil, err
+	}
+
+	return &transcript, nil
+}
+
+// TranscriptMatch is a search hit with a highlighted excerpt.
+type TranscriptMatch struct {
+	TranscriptID uint    `json:"transcript_id"`
+	CallID       uint    `json:"call_id"`
+	Snippet      string  `json:"snippet"`
+	Rank         float64 `json:"rank"`
+}
+
+// SearchTranscripts runs a full-text query over the tenant's completed
+// transcripts. The "simple" configuration is used so matching does not
+// depend on the call's language.
+func (s *TranscriptionService) SearchTranscripts(tenantID uint, query string, limit int) ([]TranscriptMatch, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("search query is required")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	matches := []TranscriptMatch{}
+	if err := s.DB.Model(&models.Transcript{}).
+		Select("id AS transcript_id, call_id, "+
+			"ts_headline('simple', text, plainto_tsquery('simple', ?), 'MaxFragments=2') AS snippet, "+
+			"ts_rank(to_tsvector('simple', text), plainto_tsquery('simple', ?)) AS rank", query, query).
+		Where("tenant_id = ? AND status = ?", tenantID, models.TranscriptStatusCompleted).
+		Where("to_tsvector('simple', text) @@ plainto_tsquery('simple', ?)", query).
+		Order("rank DESC").
+		Limit(limit).
+		Scan(&matches).Error; err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+	"github.com/your-module/backend/transcription"
+)
+
+// newTranscriptionFixture returns a service over local storage and a call
+// with a stored recording.
+func newTranscriptionFixture(t *testing.T, provider transcription.Provider, maxAttempts int) (*gorm.DB, *TranscriptionService, *models.Call) {
+	t.Helper()
+
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	if err := storage.ForTenant(store, tenant.ID).Put("recordings/call-1.wav", strings.NewReader("audio"), 5, "audio/wav"); err != nil {
+		t.Fatalf("storing recording: %v", err)
+	}
+
+	call := models.Call{TenantID: tenant.ID, UUID: "call-1", Caller: "1001", Callee: "1002", RecordingKey: "recordings/call-1.wav"}
+	if err := db.Create(&call).Error; err != nil {
+		t.Fatalf("creating call: %v", err)
+	}
+
+	return db, NewTranscriptionService(db, store, provider, maxAttempts), &call
+}
+
+func queueTranscript(t *testing.T, db *gorm.DB, call *models.Call, status string, attempts int, startedAt *time.Time) *models.Transcript {
+	t.Helper()
+
+	job := models.Transcript{TenantID: call.TenantID, CallID: call.ID, Status: status, Attempts: attempts, StartedAt: startedAt}
+	if err := db.Create(&job).Error; err != nil {
+		t.Fatalf("queueing transcript: %v", err)
+	}
+	return &job
+}
+
+func TestProcessNextCompletesJob(t *testing.T) {
+	provider := &transcription.FakeProvider{Segments: []transcription.Segment{
+		{Start: 0, End: 1.5, Speaker: "caller", Text: " hello "},
+		{Start: 1.5, End: 2, Speaker: "callee", Text: "  "},
+		{Start: 2, End: 4, Speaker: "callee", Text: "hi there"},
+	}}
+	db, service, call := newTranscriptionFixture(t, provider, 3)
+	queueTranscript(t, db, call, models.TranscriptStatusPending, 0, nil)
+
+	processed, err := service.ProcessNext()
+	if err != nil || !processed {
+		t.Fatalf("ProcessNext() = %v, %v", processed, err)
+	}
+
+	transcript, err := service.GetTranscriptByCallID(call.TenantID, call.ID)
+	if err != nil {
+		t.Fatalf("GetTranscriptByCallID: %v", err)
+	}
+	if transcript.Status != models.TranscriptStatusCompleted || transcript.Attempts != 1 || transcript.Provider != "fake" || transcript.CompletedAt == nil {
+		t.Errorf("transcript = %s after %d attempts by %q", transcript.Status, transcript.Attempts, transcript.Provider)
+	}
+	if transcript.Text != "hello\nhi there" || transcript.Language != "en" {
+		t.Errorf("text = %q, language %q", transcript.Text, transcript.Language)
+	}
+	if len(transcript.Segments) != 2 || transcript.Segments[0].Text != "hello" || transcript.Segments[1].Position != 2 {
+		t.Errorf("segments = %+v, want blank ones dropped", transcript.Segments)
+	}
+
+	if processed, err := service.ProcessNext(); processed || err != nil {
+		t.Errorf("ProcessNext() on an empty queue = %v, %v", processed, err)
+	}
+
+	if _, err := service.GetTranscriptByCallID(call.TenantID+1, call.ID); err == nil {
+		t.Error("another tenant read the transcript")
+	}
+}
+
+func TestProcessNextRetriesUntilMaxAttempts(t *testing.T) {
+	provider := &transcription.FakeProvider{Err: errors.New("provider unavailable")}
+	db, service, call := newTranscriptionFixture(t, provider, 2)
+	job := queueTranscript(t, db, call, models.TranscriptStatusPending, 0, nil)
+
+	wantStatus := []string{models.TranscriptStatusPending, models.TranscriptStatusFailed}
+	for attempt, want := range wantStatus {
+		processed, err := service.ProcessNext()
+		if !processed || err == nil {
+			t.Fatalf("attempt %d: ProcessNext() = %v, %v, want the provider error", attempt+1, processed, err)
+		}
+
+		db.First(job, job.ID)
+		if job.Status != want || job.Attempts != attempt+1 || job.Error != "provider unavailable" {
+			t.Errorf("attempt %d: job %s after %d attempts, error %q", attempt+1, job.Status, job.Attempts, job.Error)
+		}
+	}
+
+	if processed, err := service.ProcessNext(); processed || err != nil {
+		t.Errorf("failed job was claimed again: %v, %v", processed, err)
+	}
+
+	// A later success clears the error
+	provider.Err = nil
+	db.Model(job).Updates(map[string]interface{}{"status": models.TranscriptStatusPending, "attempts": 0})
+	if _, err := service.ProcessNext(); err != nil {
+		t.Fatalf("ProcessNext: %v", err)
+	}
+	db.First(job, job.ID)
+	if job.Status != models.TranscriptStatusCompleted || job.Error != "" {
+		t.Errorf("job %s with error %q after success", job.Status, job.Error)
+	}
+}
+
+func TestProcessNextRecoversStaleJobs(t *testing.T) {
+	db, service, call := newTranscriptionFixture(t, &transcription.FakeProvider{}, 3)
+
+	recent := time.Now().Add(-time.Minute)
+	job := queueTranscript(t, db, call, models.TranscriptStatusProcessing, 1, &recent)
+
+	if processed, _ := service.ProcessNext(); processed {
+		t.Fatal("job still being worked on was claimed")
+	}
+
+	stale := time.Now().Add(-transcriptionStaleAfter - time.Minute)
+	db.Model(job).Update("started_at", stale)
+
+	processed, err := service.ProcessNext()
+	if err != nil || !processed {
+		t.Fatalf("ProcessNext() = %v, %v, want the stale job reclaimed", processed, err)
+	}
+	db.First(job, job.ID)
+	if job.Status != models.TranscriptStatusCompleted || job.Attempts != 2 {
+		t.Errorf("stale job %s after %d attempts", job.Status, job.Attempts)
+	}
+}
+
+func TestProcessNextFailsExhaustedStaleJobs(t *testing.T) {
+	db, service, call := newTranscriptionFixture(t, &transcription.FakeProvider{}, 3)
+
+	stale := time.Now().Add(-transcriptionStaleAfter - time.Minute)
+	job := queueTranscript(t, db, call, models.TranscriptStatusProcessing, 3, &stale)
+
+	if processed, err := service.ProcessNext(); processed || err != nil {
+		t.Fatalf("ProcessNext() = %v, %v, want nothing claimed", processed, err)
+	}
+	db.First(job, job.ID)
+	if job.Status != models.TranscriptStatusFailed || job.Error != "transcription did not finish" {
+		t.Errorf("stuck job %s with error %q, want failed", job.Status, job.Error)
+	}
+}
+
+func TestProcessNextMissingRecording(t *testing.T) {
+	db, service, call := newTranscriptionFixture(t, &transcription.FakeProvider{}, 1)
+	db.Model(call).Update("recording_key", "")
+	job := queueTranscript(t, db, call, models.TranscriptStatusPending, 0, nil)
+
+	if _, err := service.ProcessNext(); err == nil || err.Error() != "call has no recording" {
+		t.Errorf("ProcessNext() error = %v", err)
+	}
+	db.First(job, job.ID)
+	if job.Status != models.TranscriptStatusFailed {
+		t.Errorf("job %s, want failed after its only attempt", job.Status)
+	}
+}
+
+func TestSearchTranscriptsRequiresQuery(t *testing.T) {
+	_, service, call := newTranscriptionFixture(t, &transcription.FakeProvider{}, 3)
+
+	for _, query := range []string{"", "   "} {
+		if _, err := service.SearchTranscripts(call.TenantID, query, 10); err == nil || err.Error() != "search query is required" {
+			t.Errorf("SearchTranscripts(%q) error = %v", query, err)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/mailer"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+)
+
+// VoicemailService manages voicemail boxes and the messages left in them.
+// Audio is kept in the recording storage; Mailer may be nil, which turns
+// email notifications off.
+type VoicemailService struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+	Mailer  mailer.Mailer
+}
+
+func NewVoicemailService(db *gorm.DB, store storage.Storage, mail mailer.Mailer) *VoicemailService {
+	return &VoicemailService{DB: db, Storage: store, Mailer: mail}
+}
+
+// VoicemailDeposit describes a message the switch recorded. Filename only
+// supplies the audio format.
+type VoicemailDeposit struct {
+	Extension       string
+	CallUUID        string
+	CallerIDNumber  string
+	CallerIDName    string
+	DurationSeconds int
+	Filename        string
+}
+
+// voicemailKey is the object key of a box's greeting or message inside the
+// tenant namespace.
+func voicemailKey(boxID uint, name, filename string) string {
+	return "voicemail/" + strconv.FormatUint(uint64(boxID), 10) + "/" + name + strings.ToLower(path.Ext(filename))
+}
+
+// validateVoicemailBox checks a box. An empty PIN is accepted only when
+// keepPIN is set, meaning the current PIN stays.
+func validateVoicemailBox(box *models.VoicemailBox, keepPIN bool) error {
+	box.Extension = strings.TrimSpace(box.Extension)
+	if len(box.Extension) < 2 || len(box.Extension) > 10 || !isDigits(box.Extension) {
+		return errors.New("extension must be 2 to 10 digits")
+	}
+
+	box.PIN = strings.TrimSpace(box.PIN)
+	if box.PIN != "" || !keepPIN {
+		if len(box.PIN) < 4 || len(box.PIN) > 10 || !isDigits(box.PIN) {
+			return errors.New("pin must be 4 to 10 digits")
+		}
+	}
+
+	box.Email = strings.TrimSpace(box.Email)
+	if box.Email != "" {
+		address, err := mail.ParseAddress(box.Email)
+		if err != nil || address.Address != box.Email {
+			return errors.New("invalid email address")
+		}
+	}
+	if box.EmailOnMessage && box.Email == "" {
+		return errors.New("email is required for message notifications")
+	}
+
+	return nil
+}
+
+func voicemailExtensionTaken(db *gorm.DB, tenantID uint, extension string, excludeID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.VoicemailBox{}).
+		Where("tenant_id = ? AND extension = ? AND id <> ?", tenantID, extension, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *VoicemailService) CreateBox(tenantID uint, box *models.VoicemailBox) (*models.VoicemailBox, error) {
+	box.ID = 0
+	box.TenantID = tenantID
+	box.GreetingKey = ""
+	if err := validateVoicemailBox(box, false); err != nil {
+		return nil, err
+	}
+
+	if err := checkEndpointUser(s.DB, tenantID, box.UserID); err != nil {
+		return nil, err
+	}
+
+	taken, err := voicemailExtensionTaken(s.DB, tenantID, box.Extension, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errors.New("extension already has a voicemail box")
+	}
+
+	if err := s.DB.Create(box).Error; err != nil {
+		return nil, err
+	}
+
+	return box, nil
+}
+
+func (s *VoicemailService) GetBoxes(tenantID uint) ([]models.VoicemailBox, error) {
+	var boxes []models.VoicemailBox
+
+	if err := s.DB.Where("tenant_id = ?", tenantID).
+		Order("extension").
+		Find(&boxes).Error; err != nil {
+		return nil, err
+	}
+
+	return boxes, nil
+}
+
+func (s *VoicemailService) GetBoxByID(tenantID, boxID uint) (*models.VoicemailBox, error) {
+	var box models.VoicemailBox
+
+	if err := s.DB.Where("id = ? AND tenant_id = ?", boxID, tenantID).
+		First(&box).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("voicemail box not found")
+		}
+		return nil, err
+	}
+
+	return &box, nil
+}
+
+// UpdateBox changes the box settings. The PIN is only changed when a new
+// one is given.
+func (s *VoicemailService) UpdateBox(tenantID, boxID uint, changes *models.VoicemailBox) (*models.VoicemailBox, error) {
+	box, err := s.GetBoxByID(tenantID, boxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateVoicemailBox(changes, true); err != nil {
+		return nil, err
+	}
+
+	if err := checkEndpointUser(s.DB, tenantID, changes.UserID); err != nil {
+		return nil, err
+	}
+
+	taken, err := voicemailExtensionTaken(s.DB, tenantID, changes.Extension, box.ID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errors.New("extension already has a voicemail box")
+	}
+
+	updates := map[string]interface{}{
+		"user_id":          changes.UserID,
+		"extension":        changes.Extension,
+		"email_on_message": changes.EmailOnMessage,
+		"email":            changes.Email,
+		"is_active":        changes.IsActive,
+	}
+	if changes.PIN != "" {
+		updates["pin"] = changes.PIN
+	}
+
+	if err := s.DB.Model(box).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return box, nil
+}
+
+// DeleteBox refuses to delete a box that a routing rule, an IVR transfer or
+// a phone number still sends calls to. The box's greeting and messages are
+// deleted with it.
+func (s *VoicemailService) DeleteBox(tenantID, boxID uint) error {
+	box, err := s.GetBoxByID(tenantID, boxID)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.RoutingRule{}).
+		Where("tenant_id = ? AND target_type = ? AND target_value = ?", tenantID, "voicemail", box.Extension).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("voicemail box is used by a routing rule")
+	}
+
+	transferred, err := ivrFlowsTransferTo(s.DB, tenantID, "voicemail", box.Extension)
+	if err != nil {
+		return err
+	}
+	if transferred {
+		return errors.New("voicemail box is used by an ivr flow")
+	}
+
+	if err := s.DB.Model(&models.PhoneNumber{}).
+		Where("tenant_id = ? AND routing_type = ? AND routing_destination = ?", tenantID, "extension", box.Extension).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("voicemail box is used by a phone number")
+	}
+
+	var messages []models.VoicemailMessage
+	if err := s.DB.Where("box_id = ?", box.ID).Find(&messages).Error; err != nil {
+		return err
+	}
+
+	if err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("box_id = ?", box.ID).
+			Delete(&models.VoicemailMessage{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(box).Error
+	}); err != nil {
+		return err
+	}
+
+	// The box is already deleted, so audio that cannot be removed now is
+	// left orphaned and logged
+	store := storage.ForTenant(s.Storage, tenantID)
+	keys := make([]string, 0, len(messages)+1)
+	for _, message := range messages {
+		keys = append(keys, message.StorageKey)
+	}
+	if box.GreetingKey != "" {
+		keys = append(keys, box.GreetingKey)
+	}
+	for _, key := range keys {
+		if err := store.Delete(key); err != nil {
+			log.Printf("voicemail: deleting %s of box %d: %v", key, box.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// SetGreeting uploads a custom greeting for the box, replacing any
+// previous one.
+func (s *VoicemailService) SetGreeting(tenantID, boxID uint, filename string, r io.Reader, size int64) (*models.VoicemailBox, error) {
+	if !recordingExtensions[strings.ToLower(path.Ext(filename))] {
+		return nil, errors.New("unsupported audio format")
+	}
+
+	box, err := s.GetBoxByID(tenantID, boxID)
+	if err != nil {
+		return nil, err
+	}
+
+	store := storage.ForTenant(s.Storage, tenantID)
+	key := voicemailKey(box.ID, "greeting", filename)
+	if err := store.Put(key, r, size, storage.ContentTypeFor(key)); err != nil {
+		return nil, err
+	}
+
+	previous := box.GreetingKey
+	if err := s.DB.Model(box).Update("greeting_key", key).Error; err != nil {
+		return nil, err
+	}
+
+	if previous != "" && previous != key {
+		store.Delete(previous)
+	}
+
+	return box, nil
+}
+
+// OpenGreeting returns a reader for the box's custom greeting.
+func (s *VoicemailService) OpenGreeting(tenantID, boxID uint) (io.ReadCloser, *storage.ObjectInfo, *models.VoicemailBox, error) {
+	box, err := s.GetBoxByID(tenantID, boxID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if box.GreetingKey == "" {
+		return nil, nil, nil, errors.New("greeting not found")
+	}
+
+	reader, info, err := storage.ForTenant(s.Storage, tenantID).Get(box.GreetingKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, nil, errors.New("greeting not found")
+		}
+		return nil, nil, nil, err
+	}
+
+	return reader, info, box, nil
+}
+
+// DeleteGreeting goes back to the switch's default greeting.
+func (s *VoicemailService) DeleteGreeting(tenantID, boxID uint) error {
+	box, err := s.GetBoxByID(tenantID, boxID)
+	if err != nil {
+		return err
+	}
+	if box.GreetingKey == "" {
+		return errors.New("greeting not found")
+	}
+
+	if err := storage.ForTenant(s.Storage, tenantID).Delete(box.GreetingKey); err != nil {
+		return err
+	}
+
+	return s.DB.Model(box).Update("greeting_key", "").Error
+}
+
+// DepositMessage stores a message the switch recorded for the box at the
+// deposit's extension and sends the box's email notification. A failed
+// notification is logged; the message is kept.
+func (s *VoicemailService) DepositMessage(tenantID uint, deposit VoicemailDeposit, r io.Reader, size int64) (*models.VoicemailMessage, error) {
+	if !recordingExtensions[strings.ToLower(path.Ext(deposit.Filename))] {
+		return nil, errors.New("unsupported audio format")
+	}
+	if deposit.DurationSeconds < 0 {
+		return nil, errors.New("duration cannot be negative")
+	}
+
+	var box models.VoicemailBox
+	if err := s.DB.Where("tenant_id = ? AND extension = ? AND is_active = ?", tenantID, strings.TrimSpace(deposit.Extension), true).
+		First(&box).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("voicemail box not found")
+		}
+		return nil, err
+	}
+
+	store := storage.ForTenant(s.Storage, tenantID)
+	key := voicemailKey(box.ID, uuid.New().String(), deposit.Filename)
+	if err := store.Put(key, r, size, storage.ContentTypeFor(key)); err != nil {
+		return nil, err
+	}
+
+	message := models.VoicemailMessage{
+		TenantID:        tenantID,
+		BoxID:           box.ID,
+		CallUUID:        deposit.CallUUID,
+		CallerIDNumber:  deposit.CallerIDNumber,
+		CallerIDName:    deposit.CallerIDName,
+		DurationSeconds: deposit.DurationSeconds,
+		StorageKey:      key,
+		Size:            size,
+	}
+	if err := s.DB.Create(&message).Error; err != nil {
+		store.Delete(key)
+		return nil, err
+	}
+
+	if err := s.notify(&box, &message); err != nil {
+		log.Printf("voicemail: notifying box %d of message %d: %v", box.ID, message.ID, err)
+	}
+
+	return &message, nil
+}
+
+func (s *VoicemailService) notify(box *models.VoicemailBox, message *models.VoicemailMessage) error {
+	if s.Mailer == nil || !box.EmailOnMessage || box.Email == "" {
+		return nil
+	}
+
+	caller := message.CallerIDNumber
+	if caller == "" {
+		caller = "unknown caller"
+	}
+	if message.CallerIDName != "" {
+		caller = message.CallerIDName + " <" + caller + ">"
+	}
+
+	return s.Mailer.Send(mailer.Message{
+		To:      []string{box.Email},
+		Subject: fmt.Sprintf("New voicemail for extension %s from %s", box.Extension, caller),
+		Body: fmt.Sprintf("You have a new voicemail message in box %s.\n\nFrom: %s\nReceived: %s\nDuration: %d seconds\n",
+			box.Extension, caller, message.CreatedAt.UTC().Format(time.RFC1123), message.DurationSeconds),
+	})
+}
+
+// GetMessages lists the box's messages, newest first, optionally only the
+// ones not yet heard.
+func (s *VoicemailService) GetMessages(tenantID, boxID uint, unheardOnly bool) ([]models.VoicemailMessage, error) {
+	if _, err := s.GetBoxByID(tenantID, boxID); err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Where("box_id = ? AND tenant_id = ?", boxID, tenantID)
+	if unheardOnly {
+		query = query.Where("heard_at IS NULL")
+	}
+
+	var messages []models.VoicemailMessage
+	if err := query.Order("created_at DESC, id DESC").
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (s *VoicemailService) getMessage(tenantID, boxID, messageID uint) (*models.VoicemailMessage, error) {
+	var message models.VoicemailMessage
+
+	if err := s.DB.Where("id = ? AND box_id = ? AND tenant_id = ?", messageID, boxID, tenantID).
+		First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("voicemail message not found")
+		}
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// MarkHeard marks a message heard, or unheard again.
+func (s *VoicemailService) MarkHeard(tenantID, boxID, messageID uint, heard bool) (*models.VoicemailMessage, error) {
+	message, err := s.getMessage(tenantID, boxID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var heardAt *time.Time
+	if heard {
+		now := time.Now()
+		if message.HeardAt != nil {
+			now = *message.HeardAt
+		}
+		heardAt = &now
+	}
+
+	if err := s.DB.Model(message).Update("heard_at", heardAt).Error; err != nil {
+		return nil, err
+	}
+	message.HeardAt = heardAt
+
+	return message, nil
+}
+
+// OpenMessage returns a reader for the message audio.
+func (s *VoicemailService) OpenMessage(tenantID, boxID, messageID uint) (io.ReadCloser, *storage.ObjectInfo, *models.VoicemailMessage, error) {
+	message, err := s.getMessage(tenantID, boxID, messageID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	reader, info, err := storage.ForTenant(s.Storage, tenantID).Get(message.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, nil, errors.New("voicemail message not found")
+		}
+		return nil, nil, nil, err
+	}
+
+	return reader, info, message, nil
+}
+
+func (s *VoicemailService) DeleteMessage(tenantID, boxID, messageID uint) error {
+	message, err := s.getMessage(tenantID, boxID, messageID)
+	if err != nil {
+		return err
+	}
+
+	if err := storage.ForTenant(s.Storage, tenantID).Delete(message.StorageKey); err != nil {
+		return err
+	}
+
+	return s.DB.Delete(message).Error
+}
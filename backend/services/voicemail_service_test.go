@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"github.com/your-module/backend/mailer"
+	"github.com/your-module/backend/models"
+	"github.com/your-module/backend/storage"
+)
+
+func newTestVoicemail(t *testing.T, emailOnMessage bool) (*VoicemailService, *mailer.FakeMailer, uint, *models.VoicemailBox) {
+	t.Helper()
+
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{})
+
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("opening storage: %v", err)
+	}
+	fake := &mailer.FakeMailer{}
+	service := NewVoicemailService(db, store, fake)
+
+	box, err := service.CreateBox(tenant.ID, &models.VoicemailBox{
+		Extension:      "1001",
+		PIN:            "4321",
+		EmailOnMessage: emailOnMessage,
+		Email:          "alice@example.com",
+		IsActive:       true,
+	})
+	if err != nil {
+		t.Fatalf("CreateBox: %v", err)
+	}
+
+	return service, fake, tenant.ID, box
+}
+
+func depositTestMessage(t *testing.T, service *VoicemailService, tenantID uint) *models.VoicemailMessage {
+	t.Helper()
+
+	audio := "RIFF fake audio"
+	message, err := service.DepositMessage(tenantID, VoicemailDeposit{
+		Extension:       "1001",
+		CallUUID:        "call-1",
+		CallerIDNumber:  "+14155550100",
+		CallerIDName:    "Bob",
+		DurationSeconds: 12,
+		Filename:        "msg.wav",
+	}, strings.NewReader(audio), int64(len(audio)))
+	if err != nil {
+		t.Fatalf("DepositMessage: %v", err)
+	}
+	return message
+}
+
+func TestDepositMessageNotifies(t *testing.T) {
+	service, fake, tenantID, box := newTestVoicemail(t, true)
+
+	message := depositTestMessage(t, service, tenantID)
+
+	sent := fake.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("%d notifications sent, want 1", len(sent))
+	}
+	if len(sent[0].To) != 1 || sent[0].To[0] != "alice@example.com" {
+		t.Errorf("notification sent to %v", sent[0].To)
+	}
+	if want := "New voicemail for extension 1001 from Bob <+14155550100>"; sent[0].Subject != want {
+		t.Errorf("subject = %q, want %q", sent[0].Subject, want)
+	}
+	if !strings.Contains(sent[0].Body, "Duration: 12 seconds") {
+		t.Errorf("body = %q", sent[0].Body)
+	}
+
+	reader, _, _, err := service.OpenMessage(tenantID, box.ID, message.ID)
+	if err != nil {
+		t.Fatalf("OpenMessage: %v", err)
+	}
+	defer reader.Close()
+	if audio, _ := io.ReadAll(reader); string(audio) != "RIFF fake audio" {
+		t.Errorf("stored audio = %q", audio)
+	}
+}
+
+func TestDepositMessageWithoutNotification(t *testing.T) {
+	service, fake, tenantID, _ := newTestVoicemail(t, false)
+
+	depositTestMessage(t, service, tenantID)
+
+	if sent := fake.Sent(); len(sent) != 0 {
+		t.Errorf("%d notifications sent for a box without them", len(sent))
+	}
+}
+
+func TestDepositMessageKeptWhenMailFails(t *testing.T) {
+	service, fake, tenantID, box := newTestVoicemail(t, true)
+	fake.Err = errors.New("smtp unavailable")
+
+	depositTestMessage(t, service, tenantID)
+
+	messages, err := service.GetMessages(tenantID, box.ID, false)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Errorf("%d messages stored, want 1", len(messages))
+	}
+}
+
+func TestValidateVoicemailBox(t *testing.T) {
+	tests := []struct {
+		name    string
+		box     models.VoicemailBox
+		keepPIN bool
+		want    string
+	}{
+		{"short extension", models.VoicemailBox{Extension: "1", PIN: "1234"}, false, "extension must be 2 to 10 digits"},
+		{"dial string", models.VoicemailBox{Extension: "1001 XML", PIN: "1234"}, false, "extension must be 2 to 10 digits"},
+		{"no pin", models.VoicemailBox{Extension: "1001"}, false, "pin must be 4 to 10 digits"},
+		{"kept pin", models.VoicemailBox{Extension: "1001"}, true, ""},
+		{"short pin", models.VoicemailBox{Extension: "1001", PIN: "123"}, true, "pin must be 4 to 10 digits"},
+		{"bad email", models.VoicemailBox{Extension: "1001", PIN: "1234", Email: "Alice <alice@example.com>"}, false, "invalid email address"},
+		{"notify without email", models.VoicemailBox{Extension: "1001", PIN: "1234", EmailOnMessage: true}, false, "email is required for message notifications"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVoicemailBox(&tt.box, tt.keepPIN)
+			if (tt.want == "" && err != nil) || (tt.want != "" && (err == nil || err.Error() != tt.want)) {
+				t.Errorf("validateVoicemailBox() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoicemailMessages(t *testing.T) {
+	service, _, tenantID, box := newTestVoicemail(t, false)
+
+	if _, err := service.CreateBox(tenantID, &models.VoicemailBox{Extension: "1001", PIN: "1234"}); err == nil || err.Error() != "extension already has a voicemail box" {
+		t.Errorf("second box on an extension = %v", err)
+	}
+
+	// Updating without a PIN keeps the current one
+	if _, err := service.UpdateBox(tenantID, box.ID, &models.VoicemailBox{Extension: "1001", IsActive: true}); err != nil {
+		t.Fatalf("UpdateBox: %v", err)
+	}
+	stored, err := service.GetBoxByID(tenantID, box.ID)
+	if err != nil {
+		t.Fatalf("GetBoxByID: %v", err)
+	}
+	if stored.PIN != "4321" {
+		t.Errorf("PIN = %q after an update without one", stored.PIN)
+	}
+
+	first := depositTestMessage(t, service, tenantID)
+	second := depositTestMessage(t, service, tenantID)
+
+	if _, err := service.MarkHeard(tenantID, box.ID, first.ID, true); err != nil {
+		t.Fatalf("MarkHeard: %v", err)
+	}
+	unheard, err := service.GetMessages(tenantID, box.ID, true)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(unheard) != 1 || unheard[0].ID != second.ID {
+		t.Errorf("unheard messages = %+v", unheard)
+	}
+
+	if _, _, _, err := service.OpenMessage(tenantID+1, box.ID, first.ID); err == nil {
+		t.Error("another tenant opened the message")
+	}
+	reader, _, _, err := service.OpenMessage(tenantID, box.ID, first.ID)
+	if err != nil {
+		t.Fatalf("OpenMessage: %v", err)
+	}
+	audio, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(audio) != "RIFF fake audio" {
+		t.Errorf("message audio = %q", audio)
+	}
+
+	if err := service.DeleteMessage(tenantID, box.ID, first.ID); err != nil {
+		t.Fatalf("DeleteMessage: %v", err)
+	}
+	if _, _, err := storage.ForTenant(service.Storage, tenantID).Get(first.StorageKey); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("audio of a deleted message kept: %v", err)
+	}
+
+	if err := service.DeleteBox(tenantID, box.ID); err != nil {
+		t.Fatalf("DeleteBox: %v", err)
+	}
+	if _, _, err := storage.ForTenant(service.Storage, tenantID).Get(second.StorageKey); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("audio of a deleted box kept: %v", err)
+	}
+	var messages int64
+	service.DB.Model(&models.VoicemailMessage{}).Where("box_id = ?", box.ID).Count(&messages)
+	if messages != 0 {
+		t.Errorf("%d messages left in a deleted box", messages)
+	}
+}
+
+func TestDeleteBoxUsedByRoutingRule(t *testing.T) {
+	service, _, tenantID, box := newTestVoicemail(t, false)
+
+	if _, err := NewRoutingService(service.DB).CreateRule(tenantID, &models.RoutingRule{Name: "After hours", TargetType: "voicemail", TargetValue: box.Extension}); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if err := service.DeleteBox(tenantID, box.ID); err == nil || err.Error() != "voicemail box is used by a routing rule" {
+		t.Errorf("deleting a box in use = %v", err)
+	}
+}
+
+func TestDeleteBoxUsedByIvrFlowOrPhoneNumber(t *testing.T) {
+	service, _, tenantID, box := newTestVoicemail(t, false)
+	flows := NewIvrFlowService(service.DB)
+
+	graph := transferGraph(box.Extension)
+	graph.Nodes[2].TargetType = "voicemail"
+	flow, err := flows.CreateFlow(tenantID, &models.IvrFlow{Name: "Main menu", Graph: graph})
+	if err != nil {
+		t.Fatalf("CreateFlow: %v", err)
+	}
+	if err := service.DeleteBox(tenantID, box.ID); err == nil || err.Error() != "voicemail box is used by an ivr flow" {
+		t.Errorf("deleting a box a draft transfers to = %v", err)
+	}
+
+	// The published version still transfers after the draft stops
+	if _, _, err := flows.PublishFlow(tenantID, flow.ID, nil); err != nil {
+		t.Fatalf("PublishFlow: %v", err)
+	}
+	if _, err := flows.UpdateFlow(tenantID, flow.ID, &models.IvrFlow{Name: "Main menu", Graph: transferGraph(box.Extension)}); err != nil {
+		t.Fatalf("UpdateFlow: %v", err)
+	}
+	if err := service.DeleteBox(tenantID, box.ID); err == nil || err.Error() != "voicemail box is used by an ivr flow" {
+		t.Errorf("deleting a box a published flow transfers to = %v", err)
+	}
+
+	if err := flows.DeleteFlow(tenantID, flow.ID); err != nil {
+		t.Fatalf("DeleteFlow: %v", err)
+	}
+	number := models.PhoneNumber{Number: "+14155550100", TenantID: &tenantID, Status: models.PhoneNumberStatusAssigned, RoutingType: "extension", RoutingDestination: box.Extension}
+	service.DB.Create(&number)
+	if err := service.DeleteBox(tenantID, box.ID); err == nil || err.Error() != "voicemail box is used by a phone number" {
+		t.Errorf("deleting a box a phone number is routed to = %v", err)
+	}
+
+	service.DB.Model(&number).Update("routing_destination", "1002")
+	if err := service.DeleteBox(tenantID, box.ID); err != nil {
+		t.Errorf("DeleteBox once unused: %v", err)
+	}
+}
+
+func TestDeleteBoxWhenStorageFails(t *testing.T) {
+	service, _, tenantID, box := newTestVoicemail(t, false)
+	depositTestMessage(t, service, tenantID)
+	service.Storage = failingDeleteStorage{service.Storage}
+
+	// Audio left behind is orphaned, but the box is deleted
+	if err := service.DeleteBox(tenantID, box.ID); err != nil {
+		t.Fatalf("DeleteBox: %v", err)
+	}
+	if _, err := service.GetBoxByID(tenantID, box.ID); err == nil {
+		t.Error("box kept after its audio could not be deleted")
+	}
+	var messages int64
+	service.DB.Model(&models.VoicemailMessage{}).Where("box_id = ?", box.ID).Count(&messages)
+	if messages != 0 {
+		t.Errorf("%d messages left in a deleted box", messages)
+	}
+}
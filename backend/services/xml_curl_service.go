@@ -0,0 +1,860 @@
+package services
+
+import (
+	"encoding/xml"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/ivr"
+	"github.com/your-module/backend/models"
+)
+
+// XMLCurlRequest holds the mod_xml_curl fetch parameters the provider uses.
+// FreeSWITCH posts many more; the controller picks these out of the form.
+type XMLCurlRequest struct {
+	Section           string
+	Domain            string
+	User              string
+	Context           string
+	DestinationNumber string
+	CallerNumber      string
+	CallerUser        string
+	// CallUUID is the channel's Unique-ID, used for call admission.
+	CallUUID string
+	// ConferencePIN is what the caller entered at a conference room prompt.
+	ConferencePIN string
+	// IvrVariables are the flow variables an IVR has set on the channel,
+	// by flow variable name (channel variables ivr_var_<name>).
+	IvrVariables map[string]string
+}
+
+// XMLCurlService renders FreeSWITCH configuration from tenant data for
+// mod_xml_curl. Lookups that match nothing render a "not found" result so
+// the switch falls back to its static configuration.
+type XMLCurlService struct {
+	DB *gorm.DB
+	// Gateway is the sofia gateway used for routes to external numbers.
+	// External routes are not rendered when it is empty.
+	Gateway string
+}
+
+func NewXMLCurlService(db *gorm.DB, gateway string) *XMLCurlService {
+	return &XMLCurlService{DB: db, Gateway: gateway}
+}
+
+type fsDocument struct {
+	XMLName xml.Name  `xml:"document"`
+	Type    string    `xml:"type,attr"`
+	Section fsSection `xml:"section"`
+}
+
+type fsSection struct {
+	Name    string     `xml:"name,attr"`
+	Domain  *fsDomain  `xml:"domain,omitempty"`
+	Context *fsContext `xml:"context,omitempty"`
+	Result  *fsResult  `xml:"result,omitempty"`
+}
+
+type fsResult struct {
+	Status string `xml:"status,attr"`
+}
+
+type fsDomain struct {
+	Name   string    `xml:"name,attr"`
+	Params []fsParam `xml:"params>param"`
+	Groups []fsGroup `xml:"groups>group"`
+}
+
+type fsGroup struct {
+	Name  string   `xml:"name,attr"`
+	Users []fsUser `xml:"users>user"`
+}
+
+type fsUser struct {
+	ID        string    `xml:"id,attr"`
+	Params    []fsParam `xml:"params>param"`
+	Variables []fsParam `xml:"variables>variable"`
+}
+
+type fsParam struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type fsContext struct {
+	Name       string        `xml:"name,attr"`
+	Extensions []fsExtension `xml:"extension"`
+}
+
+type fsExtension struct {
+	Name      string      `xml:"name,attr"`
+	Condition fsCondition `xml:"condition"`
+}
+
+type fsCondition struct {
+	Field      string     `xml:"field,attr"`
+	Expression string     `xml:"expression,attr"`
+	Actions    []fsAction `xml:"action"`
+}
+
+type fsAction struct {
+	Application string `xml:"application,attr"`
+	Data        string `xml:"data,attr,omitempty"`
+}
+
+func renderXML(section fsSection) ([]byte, error) {
+	body, err := xml.MarshalIndent(fsDocument{Type: "freeswitch/xml", Section: section}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// NotFoundXML is the mod_xml_curl reply for a lookup the provider does not
+// handle.
+func NotFoundXML() []byte {
+	body, _ := renderXML(fsSection{Name: "result", Result: &fsResult{Status: "not found"}})
+	return body
+}
+
+func (s *XMLCurlService) Render(req XMLCurlRequest) ([]byte, error) {
+	switch req.Section {
+	case "directory":
+		return s.Directory(req)
+	case "dialplan":
+		return s.Dialplan(req)
+	}
+	return NotFoundXML(), nil
+}
+
+func (s *XMLCurlService) tenantByDomain(domain string) (*models.Tenant, error) {
+	var tenant models.Tenant
+
+	if err := s.DB.Where("LOWER(domain) = ?", strings.ToLower(domain)).
+		First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+// Directory renders the tenant's active SIP endpoints under its domain,
+// or a single endpoint when the switch asks for one user.
+func (s *XMLCurlService) Directory(req XMLCurlRequest) ([]byte, error) {
+	if req.Domain == "" {
+		return NotFoundXML(), nil
+	}
+
+	tenant, err := s.tenantByDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil || tenant.FraudBlockedAt != nil {
+		return NotFoundXML(), nil
+	}
+
+	query := s.DB.Where("tenant_id = ? AND is_active = ?", tenant.ID, true)
+	if req.User != "" {
+		query = query.Where("extension = ?", req.User)
+	}
+
+	var endpoints []models.SipEndpoint
+	if err := query.Order("extension").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	if req.User != "" && len(endpoints) == 0 {
+		return NotFoundXML(), nil
+	}
+
+	var boxes []models.VoicemailBox
+	if err := s.DB.Where("tenant_id = ? AND is_active = ?", tenant.ID, true).
+		Find(&boxes).Error; err != nil {
+		return nil, err
+	}
+	boxByExtension := map[string]*models.VoicemailBox{}
+	for i := range boxes {
+		boxByExtension[boxes[i].Extension] = &boxes[i]
+	}
+
+	users := make([]fsUser, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		users = append(users, directoryUser(tenant, &endpoint, boxByExtension[endpoint.Extension]))
+	}
+
+	return renderXML(fsSection{
+		Name: "directory",
+		Domain: &fsDomain{
+			Name: tenant.Domain,
+			Params: []fsParam{{
+				Name:  "dial-string",
+				Value: "{^^:sip_invite_domain=${dialed_domain}:presence_id=${dialed_user}@${dialed_domain}}${sofia_contact(*/${dialed_user}@${dialed_domain})}",
+			}},
+			Groups: []fsGroup{{Name: "default", Users: users}},
+		},
+	})
+}
+
+// directoryUser renders an endpoint, with the PIN of the voicemail box at
+// its extension when there is one.
+func directoryUser(tenant *models.Tenant, endpoint *models.SipEndpoint, box *models.VoicemailBox) fsUser {
+	variables := []fsParam{
+		{Name: "user_context", Value: tenant.Domain},
+		{Name: "tenant_id", Value: strconv.FormatUint(uint64(tenant.ID), 10)},
+		{Name: "sip_endpoint_id", Value: strconv.FormatUint(uint64(endpoint.ID), 10)},
+	}
+	if endpoint.CallerIDName != "" {
+		variables = append(variables, fsParam{Name: "effective_caller_id_name", Value: endpoint.CallerIDName})
+	}
+	if endpoint.CallerIDNumber != "" {
+		variables = append(variables, fsParam{Name: "effective_caller_id_number", Value: endpoint.CallerIDNumber})
+	}
+	if codecs := endpoint.CodecList(); len(codecs) > 0 {
+		variables = append(variables, fsParam{Name: "absolute_codec_string", Value: strings.Join(codecs, ",")})
+	}
+
+	params := []fsParam{{Name: "password", Value: endpoint.Password}}
+	if box != nil {
+		params = append(params, fsParam{Name: "vm-password", Value: box.PIN})
+	}
+
+	return fsUser{
+		ID:        endpoint.Extension,
+		Params:    params,
+		Variables: variables,
+	}
+}
+
+// Dialplan routes calls placed from a tenant's endpoints (the context is
+// the tenant domain) to other extensions or conference rooms of that
+// tenant, and calls to an assigned DID according to the tenant's routing
+// rules, falling back to the number's own routing. A room's dial-in number
+// always goes to the room.
+func (s *XMLCurlService) Dialplan(req XMLCurlRequest) ([]byte, error) {
+	if req.DestinationNumber == "" {
+		return NotFoundXML(), nil
+	}
+
+	tenant, err := s.tenantByDomain(req.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	var extension *fsExtension
+	if tenant != nil {
+		extension, err = s.internalRoute(tenant, req)
+	} else {
+		extension, err = s.inboundRoute(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if extension == nil {
+		return NotFoundXML(), nil
+	}
+
+	return renderXML(fsSection{
+		Name: "dialplan",
+		Context: &fsContext{
+			Name:       req.Context,
+			Extensions: []fsExtension{*extension},
+		},
+	})
+}
+
+func (s *XMLCurlService) internalRoute(tenant *models.Tenant, req XMLCurlRequest) (*fsExtension, error) {
+	if tenant.FraudBlockedAt != nil {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(req.DestinationNumber, ivrDestinationPrefix) {
+		return s.ivrStep(tenant, req)
+	}
+
+	var target models.SipEndpoint
+	if err := s.DB.Where("tenant_id = ? AND extension = ? AND is_active = ?", tenant.ID, req.DestinationNumber, true).
+		First(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.conferenceRoute(tenant, req)
+		}
+		return nil, err
+	}
+
+	if hangup, err := s.admit(tenant.ID, req); hangup != nil || err != nil {
+		return hangup, err
+	}
+
+	actions := []fsAction{
+		{Application: "set", Data: "tenant_id=" + strconv.FormatUint(uint64(tenant.ID), 10)},
+	}
+
+	// Per-endpoint channel limit for the calling endpoint
+	if req.CallerUser != "" {
+		var caller models.SipEndpoint
+		err := s.DB.Where("tenant_id = ? AND extension = ?", tenant.ID, req.CallerUser).First(&caller).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil && caller.MaxConcurrentCalls > 0 {
+			actions = append(actions, fsAction{
+				Application: "limit",
+				Data:        "hash " + tenant.Domain + " " + caller.Extension + " " + strconv.Itoa(caller.MaxConcurrentCalls) + " !USER_BUSY",
+			})
+		}
+	}
+
+	actions = append(actions, fsAction{Application: "bridge", Data: "user/" + target.Extension + "@" + tenant.Domain})
+
+	return &fsExtension{
+		Name: "extension_" + target.Extension,
+		Condition: fsCondition{
+			Field:      "destination_number",
+			Expression: "^" + target.Extension + "$",
+			Actions:    actions,
+		},
+	}, nil
+}
+
+func (s *XMLCurlService) inboundRoute(req XMLCurlRequest) (*fsExtension, error) {
+	parsed, err := parseInventoryNumber(req.DestinationNumber)
+	if err != nil {
+		return nil, nil
+	}
+
+	var number models.PhoneNumber
+	if err := s.DB.Where("number = ? AND status = ? AND voice_enabled = ?",
+		parsed.E164, models.PhoneNumberStatusAssigned, true).
+		First(&number).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if number.TenantID == nil {
+		return nil, nil
+	}
+
+	var tenant models.Tenant
+	if err := s.DB.First(&tenant, *number.TenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	route, err := NewRoutingService(s.DB).Resolve(&tenant, &number, req.CallerNumber, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if route != nil && route.Block != nil {
+		return s.blockedCall(&tenant, &number, route.Block, req)
+	}
+
+	// A room's dial-in number goes straight to the room
+	var room models.ConferenceRoom
+	err = s.DB.Where("tenant_id = ? AND phone_number_id = ?", tenant.ID, number.ID).First(&room).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		extension, err := s.conferenceEntry(&tenant, &room, req)
+		if extension != nil {
+			extension.Name = "did_" + strings.TrimPrefix(number.Number, "+")
+			extension.Condition.Actions = append([]fsAction{
+				{Application: "set", Data: "domain_name=" + tenant.Domain},
+			}, extension.Condition.Actions...)
+		}
+		return extension, err
+	}
+
+	if route == nil {
+		return nil, nil
+	}
+
+	target, err := s.targetActions(&tenant, route.TargetType, route.TargetValue)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, nil
+	}
+
+	if hangup, err := s.admit(tenant.ID, req); hangup != nil || err != nil {
+		return hangup, err
+	}
+
+	actions := []fsAction{
+		{Application: "set", Data: "tenant_id=" + strconv.FormatUint(uint64(tenant.ID), 10)},
+		{Application: "set", Data: "domain_name=" + tenant.Domain},
+	}
+	if route.Rule != nil {
+		actions = append(actions, fsAction{Application: "set", Data: "routing_rule_id=" + strconv.FormatUint(uint64(route.Rule.ID), 10)})
+	}
+	actions = append(actions, target...)
+
+	return &fsExtension{
+		Name: "did_" + strings.TrimPrefix(number.Number, "+"),
+		Condition: fsCondition{
+			Field:      "destination_number",
+			Expression: "^" + regexp.QuoteMeta(req.DestinationNumber) + "$",
+			Actions:    actions,
+		},
+	}, nil
+}
+
+// blockedCall records a call from a blocked caller and returns an extension
+// that rejects it.
+func (s *XMLCurlService) blockedCall(tenant *models.Tenant, number *models.PhoneNumber, entry *models.CallerListEntry, req XMLCurlRequest) (*fsExtension, error) {
+	if err := recordBlockedCall(s.DB, tenant, req.CallUUID, req.CallerNumber, number.Number); err != nil {
+		return nil, err
+	}
+
+	return &fsExtension{
+		Name: "blocked_" + strings.TrimPrefix(number.Number, "+"),
+		Condition: fsCondition{
+			Field:      "destination_number",
+			Expression: "^" + regexp.QuoteMeta(req.DestinationNumber) + "$",
+			Actions: []fsAction{
+				{Application: "set", Data: "caller_blocked=" + strconv.FormatUint(uint64(entry.ID), 10)},
+				{Application: "hangup", Data: "CALL_REJECTED"},
+			},
+		},
+	}, nil
+}
+
+// targetActions are the dialplan applications that deliver a call to a
+// routing target. They are nil for targets that cannot be rendered, such as
+// external numbers without a configured gateway, a queue with no available
+// agent or an IVR flow that was never published. Voicemail goes to the box
+// at the target extension.
+func (s *XMLCurlService) targetActions(tenant *models.Tenant, targetType, value string) ([]fsAction, error) {
+	switch targetType {
+	case "extension":
+		return []fsAction{{Application: "transfer", Data: value + " XML " + tenant.Domain}}, nil
+	case "ring_group":
+		// Comma-separated bridge targets ring simultaneously
+		var legs []string
+		for _, member := range strings.Split(value, ",") {
+			legs = append(legs, "user/"+member+"@"+tenant.Domain)
+		}
+		return []fsAction{{Application: "bridge", Data: strings.Join(legs, ",")}}, nil
+	case "queue":
+		return s.queueActions(tenant, value)
+	case "ivr":
+		return s.ivrEntryActions(tenant, value)
+	case "voicemail":
+		return []fsAction{
+			{Application: "answer"},
+			{Application: "voicemail", Data: "default " + tenant.Domain + " " + value},
+		}, nil
+	case "sip_uri":
+		return []fsAction{{Application: "bridge", Data: "sofia/external/" + value}}, nil
+	case "external":
+		if s.Gateway == "" {
+			return nil, nil
+		}
+		return []fsAction{{Application: "bridge", Data: "sofia/gateway/" + s.Gateway + "/" + value}}, nil
+	}
+	return nil, nil
+}
+
+// queueActions rings the queue's available agents in strategy order:
+// together for ring-all, otherwise one after another, each for the queue's
+// agent timeout.
+func (s *XMLCurlService) queueActions(tenant *models.Tenant, value string) ([]fsAction, error) {
+	queueID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, nil
+	}
+
+	queue, members, err := NewQueueService(s.DB).OfferCall(tenant.ID, uint(queueID))
+	if err != nil {
+		if err.Error() == "queue not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	separator := "|"
+	if queue.Strategy == models.QueueStrategyRingAll {
+		separator = ","
+	}
+	legs := make([]string, 0, len(members))
+	for _, member := range members {
+		legs = append(legs, "[queue_agent_id="+strconv.FormatUint(uint64(member.AgentID), 10)+
+			",leg_timeout="+strconv.Itoa(queue.AgentTimeout)+"]user/"+member.Extension+"@"+tenant.Domain)
+	}
+
+	return []fsAction{
+		{Application: "set", Data: "queue_id=" + strconv.FormatUint(uint64(queue.ID), 10)},
+		{Application: "bridge", Data: strings.Join(legs, separator)},
+	}, nil
+}
+
+// conferenceRoute handles a call from the tenant's endpoints to a room
+// extension, and the second pass of a room entry, which the PIN prompt
+// transfers to conference_<room ID> in the tenant context.
+func (s *XMLCurlService) conferenceRoute(tenant *models.Tenant, req XMLCurlRequest) (*fsExtension, error) {
+	query := s.DB.Where("tenant_id = ?", tenant.ID)
+	joining := false
+	if value, ok := strings.CutPrefix(req.DestinationNumber, "conference_"); ok {
+		roomID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, nil
+		}
+		query = query.Where("id = ?", roomID)
+		joining = true
+	} else {
+		query = query.Where("extension = ?", req.DestinationNumber)
+	}
+
+	var room models.ConferenceRoom
+	if err := query.First(&room).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if joining {
+		return s.conferenceJoin(tenant, &room, req)
+	}
+	return s.conferenceEntry(tenant, &room, req)
+}
+
+// conferenceEntry answers a call to a room. Rooms with a PIN collect it and
+// transfer to the join pass, which checks it; rooms without one are joined
+// directly.
+func (s *XMLCurlService) conferenceEntry(tenant *models.Tenant, room *models.ConferenceRoom, req XMLCurlRequest) (*fsExtension, error) {
+	if hangup, err := s.admit(tenant.ID, req); hangup != nil || err != nil {
+		return hangup, err
+	}
+
+	if denied, err := s.conferenceDenied(room, req); denied != nil || err != nil {
+		return denied, err
+	}
+
+	if room.ParticipantPIN == "" && room.ModeratorPIN == "" {
+		return conferenceExtension(req, conferenceJoinActions(tenant, room, false)), nil
+	}
+
+	roomID := strconv.FormatUint(uint64(room.ID), 10)
+	return conferenceExtension(req, []fsAction{
+		{Application: "set", Data: "tenant_id=" + strconv.FormatUint(uint64(tenant.ID), 10)},
+		{Application: "set", Data: "conference_room_id=" + roomID},
+		{Application: "answer"},
+		{Application: "play_and_get_digits", Data: `0 10 3 7000 # conference/conf-pin.wav conference/conf-bad-pin.wav conference_pin \d*`},
+		{Application: "transfer", Data: "conference_" + roomID + " XML " + tenant.Domain},
+	}), nil
+}
+
+// conferenceJoin checks the PIN the caller entered and puts them in the
+// room, as moderator when they entered the moderator PIN.
+func (s *XMLCurlService) conferenceJoin(tenant *models.Tenant, room *models.ConferenceRoom, req XMLCurlRequest) (*fsExtension, error) {
+	if hangup, err := s.admit(tenant.ID, req); hangup != nil || err != nil {
+		return hangup, err
+	}
+
+	if denied, err := s.conferenceDenied(room, req); denied != nil || err != nil {
+		return denied, err
+	}
+
+	moderator := room.ModeratorPIN != "" && req.ConferencePIN == room.ModeratorPIN
+	if !moderator && room.ParticipantPIN != "" && req.ConferencePIN != room.ParticipantPIN {
+		return conferenceExtension(req, []fsAction{
+			{Application: "playback", Data: "conference/conf-bad-pin.wav"},
+			{Application: "hangup", Data: "CALL_REJECTED"},
+		}), nil
+	}
+
+	return conferenceExtension(req, conferenceJoinActions(tenant, room, moderator)), nil
+}
+
+// conferenceDenied hangs up callers the room cannot take now: congestion
+// when it is full, rejection when it is closed.
+func (s *XMLCurlService) conferenceDenied(room *models.ConferenceRoom, req XMLCurlRequest) (*fsExtension, error) {
+	reason, err := NewConferenceService(s.DB).CheckJoin(room, time.Now())
+	if err != nil || reason == "" {
+		return nil, err
+	}
+
+	cause := "CALL_REJECTED"
+	if reason == ConferenceDeniedFull {
+		cause = "NORMAL_CIRCUIT_CONGESTION"
+	}
+
+	return conferenceExtension(req, []fsAction{
+		{Application: "set", Data: "conference_denied=" + reason},
+		{Application: "hangup", Data: cause},
+	}), nil
+}
+
+func conferenceJoinActions(tenant *models.Tenant, room *models.ConferenceRoom, moderator bool) []fsAction {
+	name := ConferenceName(room.ID)
+	actions := []fsAction{
+		{Application: "set", Data: "tenant_id=" + strconv.FormatUint(uint64(tenant.ID), 10)},
+		{Application: "set", Data: "conference_room_id=" + strconv.FormatUint(uint64(room.ID), 10)},
+		{Application: "answer"},
+	}
+	if room.RecordingEnabled {
+		actions = append(actions, fsAction{
+			Application: "set",
+			Data:        "conference_auto_record=${recordings_dir}/conference/" + name + "_${strftime(%Y%m%d-%H%M%S)}.wav",
+		})
+	}
+
+	data := name + "@default"
+	if moderator {
+		data += "+flags{moderator}"
+	}
+	return append(actions, fsAction{Application: "conference", Data: data})
+}
+
+func conferenceExtension(req XMLCurlRequest, actions []fsAction) *fsExtension {
+	return &fsExtension{
+		Name: "conference",
+		Condition: fsCondition{
+			Field:      "destination_number",
+			Expression: "^" + regexp.QuoteMeta(req.DestinationNumber) + "$",
+			Actions:    actions,
+		},
+	}
+}
+
+// admit runs call admission for the channel being routed. It returns nil
+// when the call may proceed, or an extension that hangs it up with a cause
+// matching the reason: congestion for plan limits, rejection otherwise.
+// Fetches without a Unique-ID are not checked.
+func (s *XMLCurlService) admit(tenantID uint, req XMLCurlRequest) (*fsExtension, error) {
+	if req.CallUUID == "" {
+		return nil, nil
+	}
+
+	decision, err := NewAdmissionService(s.DB).Authorize(tenantID, req.CallUUID)
+	if err != nil {
+		return nil, err
+	}
+	if decision.Allow {
+		return nil, nil
+	}
+
+	cause := "CALL_REJECTED"
+	if decision.Reason == AdmissionReasonConcurrent || decision.Reason == AdmissionReasonCPS {
+		cause = "NORMAL_CIRCUIT_CONGESTION"
+	}
+
+	return &fsExtension{
+		Name: "admission_denied",
+		Condition: fsCondition{
+			Field:      "destination_number",
+			Expression: "^" + regexp.QuoteMeta(req.DestinationNumber) + "$",
+			Actions: []fsAction{
+				{Application: "set", Data: "admission_denied=" + decision.Reason},
+				{Application: "hangup", Data: cause},
+			},
+		},
+	}, nil
+}
+
+// ivrDestinationPrefix marks the extensions an IVR flow is run through.
+// Each node is rendered on its own as ivr_<flow ID>_<version>_<node ID> in
+// the tenant context and ends by transferring to the next one, so branches
+// are decided on the channel variables the earlier nodes set. Flow
+// variables are kept in ivr_var_<name> channel variables.
+const (
+	ivrDestinationPrefix = "ivr_"
+	ivrVariablePrefix    = "ivr_var_"
+)
+
+func ivrDestination(flowID uint, version int, nodeID string) string {
+	return ivrDestinationPrefix + strconv.FormatUint(uint64(flowID), 10) + "_" + strconv.Itoa(version) + "_" + nodeID
+}
+
+// ivrEntryActions answers the call and starts the flow's published version.
+// Calls keep running the version they started on.
+func (s *XMLCurlService) ivrEntryActions(tenant *models.Tenant, value string) ([]fsAction, error) {
+	flowID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, nil
+	}
+
+	var flow models.IvrFlow
+	if err := s.DB.Where("id = ? AND tenant_id = ?", flowID, tenant.ID).
+		First(&flow).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if flow.PublishedVersion == 0 {
+		return nil, nil
+	}
+
+	var version models.IvrFlowVersion
+	if err := s.DB.Where("flow_id = ? AND version = ?", flow.ID, flow.PublishedVersion).
+		First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return []fsAction{
+		{Application: "set", Data: "ivr_flow_id=" + strconv.FormatUint(uint64(version.FlowID), 10)},
+		{Application: "set", Data: "ivr_flow_version=" + strconv.Itoa(version.Version)},
+		{Application: "answer"},
+		{Application: "transfer", Data: ivrDestination(version.FlowID, version.Version, version.Graph.Start) + " XML " + tenant.Domain},
+	}, nil
+}
+
+// ivrStep renders one node of a running IVR flow.
+func (s *XMLCurlService) ivrStep(tenant *models.Tenant, req XMLCurlRequest) (*fsExtension, error) {
+	parts := strings.SplitN(strings.TrimPrefix(req.DestinationNumber, ivrDestinationPrefix), "_", 3)
+	if len(parts) != 3 {
+		return nil, nil
+	}
+	flowID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, nil
+	}
+	versionNumber, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, nil
+	}
+
+	var version models.IvrFlowVersion
+	if err := s.DB.Where("tenant_id = ? AND flow_id = ? AND version = ?", tenant.ID, flowID, versionNumber).
+		First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	node := version.Graph.Node(parts[2])
+	if node == nil {
+		return nil, nil
+	}
+
+	next := func(id string) fsAction {
+		return fsAction{Application: "transfer", Data: ivrDestination(version.FlowID, version.Version, id) + " XML " + tenant.Domain}
+	}
+
+	// Versions published before prompts and lookup URLs were checked may
+	// still hold unsafe ones; those steps are skipped rather than handed to
+	// the switch
+	var actions []fsAction
+	switch {
+	case node.Type == ivr.NodePlay && !ivr.ValidPrompt(node.Prompt):
+		actions = []fsAction{next(node.Next)}
+
+	case node.Type == ivr.NodeCollect && !ivr.ValidPrompt(node.Prompt):
+		actions = []fsAction{
+			{Application: "unset", Data: ivrVariablePrefix + node.Variable},
+			next(node.Next),
+		}
+
+	case node.Type == ivr.NodeHTTPLookup && !ivr.ValidLookupURL(node.URL):
+		actions = []fsAction{next(node.OnError)}
+
+	case node.Type == ivr.NodePlay:
+		actions = []fsAction{
+			{Application: "playback", Data: node.Prompt},
+			next(node.Next),
+		}
+
+	case node.Type == ivr.NodeCollect:
+		timeout := node.Timeout
+		if timeout == 0 {
+			timeout = 5
+		}
+		terminator := node.Terminator
+		if terminator == "" {
+			terminator = "none"
+		}
+		actions = []fsAction{
+			{Application: "unset", Data: ivrVariablePrefix + node.Variable},
+			{Application: "play_and_get_digits", Data: strconv.Itoa(node.MinDigits) + " " + strconv.Itoa(node.MaxDigits) +
+				" 1 " + strconv.Itoa(timeout*1000) + " " + terminator + " " + node.Prompt +
+				" silence_stream://250 " + ivrVariablePrefix + node.Variable + ` [0-9*#]*`},
+			next(node.Next),
+		}
+
+	case node.Type == ivr.NodeBranch:
+		target := node.Default
+		value := req.IvrVariables[node.Variable]
+		for _, branch := range node.Branches {
+			if branch.Match == value {
+				target = branch.Next
+				break
+			}
+		}
+		actions = []fsAction{next(target)}
+
+	case node.Type == ivr.NodeHTTPLookup:
+		// mod_curl makes the request from the switch, with the flow
+		// variables set so far as query parameters
+		lookup := node.URL
+		var query []string
+		for _, name := range ivrVariableNames(&version.Graph) {
+			query = append(query, name+"=${url_encode(${"+ivrVariablePrefix+name+"})}")
+		}
+		if len(query) > 0 {
+			separator := "?"
+			if strings.Contains(lookup, "?") {
+				separator = "&"
+			}
+			lookup += separator + strings.Join(query, "&")
+		}
+		onSuccess := ivrDestination(version.FlowID, version.Version, node.Next)
+		onError := ivrDestination(version.FlowID, version.Version, node.OnError)
+		actions = []fsAction{
+			{Application: "curl", Data: lookup},
+			{Application: "set", Data: ivrVariablePrefix + node.Variable + "=${curl_response_data}"},
+			{Application: "transfer", Data: "${cond(${curl_response_code} == 200 ? " + onSuccess + " : " + onError + ")} XML " + tenant.Domain},
+		}
+
+	case node.Type == ivr.NodeTransfer:
+		target, err := s.targetActions(tenant, node.TargetType, node.TargetValue)
+		if err != nil {
+			return nil, err
+		}
+		if target == nil {
+			target = []fsAction{{Application: "hangup", Data: "NO_ROUTE_DESTINATION"}}
+		}
+		actions = target
+
+	default:
+		actions = []fsAction{{Application: "hangup", Data: "NORMAL_CLEARING"}}
+	}
+
+	return &fsExtension{
+		Name: "ivr_" + strconv.FormatUint(uint64(version.FlowID), 10) + "_" + node.ID,
+		Condition: fsCondition{
+			Field:      "destination_number",
+			Expression: "^" + regexp.QuoteMeta(req.DestinationNumber) + "$",
+			Actions:    actions,
+		},
+	}, nil
+}
+
+// ivrVariableNames lists the variables a flow's nodes set, in node order.
+func ivrVariableNames(graph *ivr.Graph) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, node := range graph.Nodes {
+		if (node.Type == ivr.NodeCollect || node.Type == ivr.NodeHTTPLookup) && !seen[node.Variable] {
+			seen[node.Variable] = true
+			names = append(names, node.Variable)
+		}
+	}
+	return names
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+	"github.com/your-module/backend/models"
+)
+
+// renderedSection renders a mod_xml_curl reply and parses it back into its
+// section.
+func renderedSection(t *testing.T, service *XMLCurlService, req XMLCurlRequest) fsSection {
+	t.Helper()
+
+	body, err := service.Render(req)
+	if err != nil {
+		t.Fatalf("rendering: %v", err)
+	}
+	var doc fsDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("parsing %s: %v", body, err)
+	}
+	return doc.Section
+}
+
+func isNotFound(section fsSection) bool {
+	return section.Name == "result" && section.Result != nil && section.Result.Status == "not found"
+}
+
+func variable(params []fsParam, name string) string {
+	for _, param := range params {
+		if param.Name == name {
+			return param.Value
+		}
+	}
+	return ""
+}
+
+func newXMLCurlFixture(t *testing.T) (*gorm.DB, *XMLCurlService, *models.Tenant) {
+	t.Helper()
+
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxSipEndpoints: 10, MaxPhoneNumbers: 10})
+	endpoints := NewSipEndpointService(db)
+
+	for _, endpoint := range []models.SipEndpoint{
+		{Extension: "1001", CallerIDName: "Front desk", CallerIDNumber: "+14155550100", Codecs: "PCMU", MaxConcurrentCalls: 2, IsActive: true},
+		{Extension: "1002", IsActive: true},
+		{Extension: "1003", IsActive: true},
+	} {
+		if _, _, err := endpoints.CreateEndpoint(tenant.ID, &endpoint); err != nil {
+			t.Fatalf("CreateEndpoint: %v", err)
+		}
+	}
+	db.Model(&models.SipEndpoint{}).Where("extension = ?", "1003").Update("is_active", false)
+
+	return db, NewXMLCurlService(db, "carrier"), tenant
+}
+
+func TestXMLCurlDirectory(t *testing.T) {
+	db, service, tenant := newXMLCurlFixture(t)
+
+	section := renderedSection(t, service, XMLCurlRequest{Section: "directory", Domain: "ACME.example.com"})
+	if section.Domain == nil || section.Domain.Name != "acme.example.com" || len(section.Domain.Groups) != 1 {
+		t.Fatalf("directory = %+v", section)
+	}
+	users := section.Domain.Groups[0].Users
+	if len(users) != 2 || users[0].ID != "1001" || users[1].ID != "1002" {
+		t.Fatalf("users = %+v, want the active endpoints", users)
+	}
+
+	var stored models.SipEndpoint
+	db.Where("extension = ?", "1001").First(&stored)
+	if variable(users[0].Params, "password") != stored.Password {
+		t.Error("password param does not match the endpoint")
+	}
+	if variable(users[0].Variables, "user_context") != tenant.Domain ||
+		variable(users[0].Variables, "effective_caller_id_number") != "+14155550100" ||
+		variable(users[0].Variables, "absolute_codec_string") != "PCMU" {
+		t.Errorf("variables = %+v", users[0].Variables)
+	}
+
+	single := renderedSection(t, service, XMLCurlRequest{Section: "directory", Domain: tenant.Domain, User: "1002"})
+	if single.Domain == nil || len(single.Domain.Groups[0].Users) != 1 || single.Domain.Groups[0].Users[0].ID != "1002" {
+		t.Errorf("single user lookup = %+v", single)
+	}
+
+	for name, req := range map[string]XMLCurlRequest{
+		"inactive user":  {Section: "directory", Domain: tenant.Domain, User: "1003"},
+		"unknown user":   {Section: "directory", Domain: tenant.Domain, User: "2000"},
+		"unknown domain": {Section: "directory", Domain: "other.example.com"},
+		"no domain":      {Section: "directory"},
+		"other section":  {Section: "configuration"},
+	} {
+		if section := renderedSection(t, service, req); !isNotFound(section) {
+			t.Errorf("%s = %+v, want not found", name, section)
+		}
+	}
+
+	now := time.Now()
+	db.Model(tenant).Update("fraud_blocked_at", &now)
+	if section := renderedSection(t, service, XMLCurlRequest{Section: "directory", Domain: tenant.Domain}); !isNotFound(section) {
+		t.Errorf("blocked tenant directory = %+v", section)
+	}
+}
+
+func TestXMLCurlInternalDialplan(t *testing.T) {
+	db, service, tenant := newXMLCurlFixture(t)
+
+	section := renderedSection(t, service, XMLCurlRequest{
+		Section: "dialplan", Context: tenant.Domain, DestinationNumber: "1002", CallerUser: "1001",
+	})
+	if section.Context == nil || len(section.Context.Extensions) != 1 {
+		t.Fatalf("dialplan = %+v", section)
+	}
+	condition := section.Context.Extensions[0].Condition
+	want := []fsAction{
+		{Application: "set", Data: fmt.Sprintf("tenant_id=%d", tenant.ID)},
+		{Application: "limit", Data: "hash acme.example.com 1001 2 !USER_BUSY"},
+		{Application: "bridge", Data: "user/1002@acme.example.com"},
+	}
+	if condition.Expression != "^1002$" || len(condition.Actions) != len(want) {
+		t.Fatalf("condition = %+v", condition)
+	}
+	for i := range want {
+		if condition.Actions[i] != want[i] {
+			t.Errorf("action %d = %+v, want %+v", i, condition.Actions[i], want[i])
+		}
+	}
+
+	if section := renderedSection(t, service, XMLCurlRequest{Section: "dialplan", Context: tenant.Domain, DestinationNumber: "1003"}); !isNotFound(section) {
+		t.Errorf("call to an inactive endpoint = %+v", section)
+	}
+
+	now := time.Now()
+	db.Model(tenant).Update("fraud_blocked_at", &now)
+	if section := renderedSection(t, service, XMLCurlRequest{Section: "dialplan", Context: tenant.Domain, DestinationNumber: "1002"}); !isNotFound(section) {
+		t.Errorf("blocked tenant dialplan = %+v", section)
+	}
+}
+
+func TestXMLCurlInboundDialplan(t *testing.T) {
+	db, service, tenant := newXMLCurlFixture(t)
+	numbers := NewPhoneNumberService(db)
+
+	routes := []struct {
+		number, routingType, destination string
+		want                             fsAction
+	}{
+		{"+14155550100", "extension", "1001", fsAction{Application: "transfer", Data: "1001 XML acme.example.com"}},
+		{"+14155550101", "sip_uri", "sip:pbx@sip.example.com:5080", fsAction{Application: "bridge", Data: "sofia/external/pbx@sip.example.com:5080"}},
+		{"+14155550102", "external", "+44 20 7946 0958", fsAction{Application: "bridge", Data: "sofia/gateway/carrier/+442079460958"}},
+	}
+	for _, route := range routes {
+		number, err := numbers.CreateNumber(route.number, models.PhoneNumber{VoiceEnabled: true})
+		if err != nil {
+			t.Fatalf("CreateNumber: %v", err)
+		}
+		if _, err := numbers.AssignNumber(number.ID, tenant.ID, nil); err != nil {
+			t.Fatalf("AssignNumber: %v", err)
+		}
+		if _, err := numbers.SetRouting(tenant.ID, number.ID, route.routingType, route.destination); err != nil {
+			t.Fatalf("SetRouting: %v", err)
+		}
+	}
+
+	for _, route := range routes {
+		section := renderedSection(t, service, XMLCurlRequest{Section: "dialplan", Context: "public", DestinationNumber: route.number[1:]})
+		if section.Context == nil || section.Context.Name != "public" {
+			t.Fatalf("%s: dialplan = %+v", route.number, section)
+		}
+		actions := section.Context.Extensions[0].Condition.Actions
+		if len(actions) != 3 || actions[1].Data != "domain_name=acme.example.com" || actions[2] != route.want {
+			t.Errorf("%s: actions = %+v, want %+v", route.number, actions, route.want)
+		}
+	}
+
+	// Without a gateway external routes are left to the static dialplan
+	service.Gateway = ""
+	if section := renderedSection(t, service, XMLCurlRequest{Section: "dialplan", Context: "public", DestinationNumber: "+14155550102"}); !isNotFound(section) {
+		t.Errorf("external route without gateway = %+v", section)
+	}
+
+	for name, destination := range map[string]string{
+		"unassigned number": "+14155550199",
+		"not a number":      "s",
+	} {
+		if section := renderedSection(t, service, XMLCurlRequest{Section: "dialplan", Context: "public", DestinationNumber: destination}); !isNotFound(section) {
+			t.Errorf("%s = %+v", name, section)
+		}
+	}
+}
+
+func TestSetRoutingValidatesDestination(t *testing.T) {
+	db := newTestDB(t)
+	tenant := createTestTenant(t, db, "acme.example.com", models.Plan{MaxPhoneNumbers: 1})
+	service := NewPhoneNumberService(db)
+
+	number, _ := service.CreateNumber("+14155550100", models.PhoneNumber{})
+	service.AssignNumber(number.ID, tenant.ID, nil)
+
+	tests := []struct {
+		routingType, destination string
+	}{
+		{"extension", "1001 XML other.example.com"},
+		{"sip_uri", "pbx.example.com"},
+		{"sip_uri", "pbx@example.com,user/1001@other.example.com"},
+		{"sip_uri", "pbx@example.com}"},
+		{"external", "1001"},
+	}
+	for _, tt := range tests {
+		if _, err := service.SetRouting(tenant.ID, number.ID, tt.routingType, tt.destination); err == nil || err.Error() != "invalid routing destination" {
+			t.Errorf("SetRouting(%s, %q) = %v", tt.routingType, tt.destination, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps objects as files below a base directory.
+type LocalStorage struct {
+	BasePath string
+}
+
+func NewLocalStorage(basePath string) (*LocalStorage, error) {
+	if basePath == "" {
+		return nil, errors.New("local storage path is required")
+	}
+
+	abs, err := filepath.Abs(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(abs, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{BasePath: abs}, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+
+	full := filepath.Join(s.BasePath, filepath.FromSlash(key))
+	if !strings.HasPrefix(full, s.BasePath+string(os.PathSeparator)) {
+		return "", ErrInvalidKey
+	}
+
+	return full, nil
+}
+
+// Put writes to a temporary file first so readers never see a partial object.
+func (s *LocalStorage) Put(key string, r io.Reader, size int64, contentType string) error {
+	full, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), full)
+}
+
+func (s *LocalStorage) Get(key string) (io.ReadCloser, *ObjectInfo, error) {
+	full, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(full)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, &ObjectInfo{
+		Size:        stat.Size(),
+		ContentType: ContentTypeFor(key),
+	}, nil
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	full, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(full); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
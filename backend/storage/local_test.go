@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	if err := store.Put("tenants/1/call.wav", strings.NewReader("audio"), 5, "audio/wav"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	r, info, err := store.Get("tenants/1/call.wav")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "audio" || info.Size != 5 || info.ContentType != "audio/wav" {
+		t.Errorf("Get() = %q, %+v", data, info)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(store.BasePath, "tenants", "1"))
+	if len(entries) != 1 {
+		t.Errorf("%d files left after Put, want no temporary files", len(entries))
+	}
+
+	if err := store.Delete("tenants/1/call.wav"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := store.Get("tenants/1/call.wav"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("tenants/1/call.wav"); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+}
+
+func TestLocalStorageStaysInBasePath(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "recordings")
+	store, err := NewLocalStorage(base)
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	// A sibling directory sharing the base path as a string prefix
+	if err := os.WriteFile(filepath.Join(dir, "recordings-other"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../recordings-other", "../../etc/passwd", "/etc/passwd", "a/../../recordings-other"} {
+		if _, err := store.path(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("path(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if _, _, err := store.Get(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+
+	full, err := store.path("tenants/1/call.wav")
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	if full != filepath.Join(base, "tenants", "1", "call.wav") {
+		t.Errorf("path() = %q", full)
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// Storage is a flat key/value blob store for call recordings and other
+// media. Keys use "/" as separator regardless of the backend.
+type Storage interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(key string) error
+}
+
+// Config selects and configures a backend. Driver is "local" or "s3".
+type Config struct {
+	Driver    string
+	LocalPath string
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalPath)
+	case "s3":
+		return NewS3Storage(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKey, cfg.SecretKey, cfg.UseSSL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// tenantStorage confines every key to the tenant's namespace.
+type tenantStorage struct {
+	backend Storage
+	prefix  string
+}
+
+// ForTenant returns a Storage whose keys are relative to the tenant's own
+// namespace. Keys are validated so they can never escape it, which keeps one
+// tenant from addressing another tenant's objects.
+func ForTenant(backend Storage, tenantID uint) Storage {
+	return &tenantStorage{
+		backend: backend,
+		prefix:  fmt.Sprintf("tenants/%d/", tenantID),
+	}
+}
+
+func (s *tenantStorage) key(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return s.prefix + key, nil
+}
+
+func (s *tenantStorage) Put(key string, r io.Reader, size int64, contentType string) error {
+	full, err := s.key(key)
+	if err != nil {
+		return err
+	}
+	return s.backend.Put(full, r, size, contentType)
+}
+
+func (s *tenantStorage) Get(key string) (io.ReadCloser, *ObjectInfo, error) {
+	full, err := s.key(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.backend.Get(full)
+}
+
+func (s *tenantStorage) Delete(key string) error {
+	full, err := s.key(key)
+	if err != nil {
+		return err
+	}
+	return s.backend.Delete(full)
+}
+
+// ValidateKey rejects absolute keys, empty segments and any "." or ".."
+// segment.
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// ContentTypeFor guesses a content type from the key's extension.
+func ContentTypeFor(key string) string {
+	switch strings.ToLower(path.Ext(key)) {
+	case ".wav":
+		return "audio/wav"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".ogg", ".opus":
+		return "audio/ogg"
+	}
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// memoryStorage records the keys it is asked for.
+type memoryStorage struct {
+	objects map[string]string
+}
+
+func (m *memoryStorage) Put(key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.objects[key] = string(data)
+	return nil
+}
+
+func (m *memoryStorage) Get(key string) (io.ReadCloser, *ObjectInfo, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(data)), &ObjectInfo{Size: int64(len(data))}, nil
+}
+
+func (m *memoryStorage) Delete(key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := map[string]bool{
+		"recordings/call.wav":    true,
+		"a/b/c.d..wav":           true,
+		"":                       false,
+		"/etc/passwd":            false,
+		"../tenants/2/call.wav":  false,
+		"recordings/../../x":     false,
+		"recordings/./call.wav":  false,
+		"recordings//call.wav":   false,
+		"recordings/":            false,
+		"..":                     false,
+		`recordings\..\call.wav`: false,
+	}
+
+	for key, valid := range tests {
+		err := ValidateKey(key)
+		if valid && err != nil {
+			t.Errorf("ValidateKey(%q) = %v", key, err)
+		}
+		if !valid && !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ValidateKey(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestForTenant(t *testing.T) {
+	backend := &memoryStorage{objects: map[string]string{"tenants/2/call.wav": "other tenant"}}
+	store := ForTenant(backend, 1)
+
+	if err := store.Put("recordings/call.wav", strings.NewReader("audio"), 5, "audio/wav"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if backend.objects["tenants/1/recordings/call.wav"] != "audio" {
+		t.Errorf("backend objects = %v, want the key under the tenant prefix", backend.objects)
+	}
+
+	r, _, err := store.Get("recordings/call.wav")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "audio" {
+		t.Errorf("Get() = %q", data)
+	}
+
+	for _, key := range []string{"../2/call.wav", "/tenants/2/call.wav", "recordings/../../2/call.wav"} {
+		if _, _, err := store.Get(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.Delete(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if backend.objects["tenants/2/call.wav"] != "other tenant" {
+		t.Error("another tenant's object was touched")
+	}
+
+	if err := store.Delete("recordings/call.wav"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := store.Get("recordings/call.wav"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestContentTypeFor(t *testing.T) {
+	tests := map[string]string{
+		"call.wav":     "audio/wav",
+		"call.MP3":     "audio/mpeg",
+		"call.opus":    "audio/ogg",
+		"notes.json":   "application/json",
+		"call":         "application/octet-stream",
+		"call.unknown": "application/octet-stream",
+	}
+	for key, want := range tests {
+		if got := ContentTypeFor(key); got != want {
+			t.Errorf("ContentTypeFor(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{Driver: "ftp"}); err == nil {
+		t.Error("New accepted an unknown driver")
+	}
+	if _, err := New(Config{Driver: "local"}); err == nil {
+		t.Error("New accepted local storage without a path")
+	}
+	if _, err := New(Config{Driver: "s3", Endpoint: "localhost:9000", Bucket: "recordings"}); err == nil {
+		t.Error("New accepted s3 storage without credentials")
+	}
+
+	store, err := New(Config{Driver: "local", LocalPath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, ok := store.(*LocalStorage); !ok {
+		t.Errorf("New() = %T, want *LocalStorage", store)
+	}
+}
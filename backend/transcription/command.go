@@ -0,0 +1,94 @@
+package transcription
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandProvider runs a local program, such as a whisper.cpp wrapper, with
+// the path of the audio file as its last argument. The program must print
+// a Result as JSON on stdout.
+type CommandProvider struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+func NewCommandProvider(commandLine string) *CommandProvider {
+	fields := strings.Fields(commandLine)
+	return &CommandProvider{
+		Command: fields[0],
+		Args:    fields[1:],
+		Timeout: 30 * time.Minute,
+	}
+}
+
+func (p *CommandProvider) Name() string {
+	return "command"
+}
+
+func (p *CommandProvider) Transcribe(audio io.Reader, contentType string) (*Result, error) {
+	file, err := os.CreateTemp("", "transcribe-*"+extensionFor(contentType))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, audio); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(p.Command, append(p.Args, file.Name())...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("transcription command failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+	case <-time.After(p.Timeout):
+		cmd.Process.Kill()
+		<-done
+		return nil, fmt.Errorf("transcription command timed out after %s", p.Timeout)
+	}
+
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("transcription command returned invalid json: %v", err)
+	}
+
+	return &result, nil
+}
+
+func extensionFor(contentType string) string {
+	switch contentType {
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	case "audio/mpeg":
+		return ".mp3"
+	case "audio/ogg":
+		return ".ogg"
+	}
+	return ""
+}
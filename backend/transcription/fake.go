@@ -0,0 +1,38 @@
+package transcription
+
+import (
+	"fmt"
+	"io"
+)
+
+// FakeProvider returns a canned two-speaker transcript without looking at
+// the audio beyond its size. It lets the pipeline run offline and in tests.
+type FakeProvider struct {
+	Segments []Segment
+	Err      error
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Transcribe(audio io.Reader, contentType string) (*Result, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	size, err := io.Copy(io.Discard, audio)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := p.Segments
+	if segments == nil {
+		segments = []Segment{
+			{Start: 0, End: 2.5, Speaker: "caller", Text: "hello, this is a test call"},
+			{Start: 2.5, End: 5, Speaker: "callee", Text: fmt.Sprintf("received %d bytes of %s audio", size, contentType)},
+		}
+	}
+
+	return &Result{Language: "en", Segments: segments}, nil
+}
@@ -0,0 +1,50 @@
+package transcription
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Segment is a stretch of speech with offsets in seconds from the start of
+// the recording.
+type Segment struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker"`
+	Text    string  `json:"text"`
+}
+
+type Result struct {
+	Language string    `json:"language"`
+	Segments []Segment `json:"segments"`
+}
+
+// Provider turns recorded audio into timed, speaker-labelled text.
+type Provider interface {
+	Name() string
+	Transcribe(audio io.Reader, contentType string) (*Result, error)
+}
+
+// Config selects a provider. Driver is "fake" or "command"; an empty driver
+// disables transcription.
+type Config struct {
+	Driver  string
+	Command string
+}
+
+func New(cfg Config) (Provider, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "fake":
+		return &FakeProvider{}, nil
+	case "command":
+		if cfg.Command == "" {
+			return nil, errors.New("transcription command is required")
+		}
+		return NewCommandProvider(cfg.Command), nil
+	default:
+		return nil, fmt.Errorf("unknown transcription provider %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+	"github.com/your-module/backend/config"
+)
+
+func signingSecret() []byte {
+	cfg := config.GetConfig()
+	if cfg.RecordingURLSecret != "" {
+		return []byte(cfg.RecordingURLSecret)
+	}
+	return []byte(cfg.JWTSecret)
+}
+
+func recordingSignature(tenantID, callID uint, expires int64) string {
+	mac := hmac.New(sha256.New, signingSecret())
+	fmt.Fprintf(mac, "recording:%d:%d:%d", tenantID, callID, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignRecording returns the expiry and signature for a recording download
+// link. The signature binds tenant, call and expiry together.
+func SignRecording(tenantID, callID uint) (int64, string) {
+	ttl := config.GetConfig().RecordingURLTTL
+	if ttl <= 0 {
+		ttl = 300
+	}
+
+	expires := time.Now().Add(time.Duration(ttl) * time.Second).Unix()
+	return expires, recordingSignature(tenantID, callID, expires)
+}
+
+// VerifyRecordingSignature checks a download link's signature and expiry.
+func VerifyRecordingSignature(tenantID, callID uint, expires int64, signature string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	expected := recordingSignature(tenantID, callID, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	"github.com/your-module/backend/config"
+)
+
+func useConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+
+	previous := config.AppConfig
+	config.AppConfig = cfg
+	t.Cleanup(func() { config.AppConfig = previous })
+}
+
+func TestRecordingSignature(t *testing.T) {
+	useConfig(t, &config.Config{RecordingURLSecret: "recording-secret", RecordingURLTTL: 60})
+
+	expires, signature := SignRecording(1, 42)
+	tampered := []byte(signature)
+	if tampered[0] == 'A' {
+		tampered[0] = 'B'
+	} else {
+		tampered[0] = 'A'
+	}
+	if ttl := expires - time.Now().Unix(); ttl < 59 || ttl > 60 {
+		t.Errorf("link expires in %ds, want the configured 60s", ttl)
+	}
+
+	tests := []struct {
+		name      string
+		tenantID  uint
+		callID    uint
+		expires   int64
+		signature string
+		want      bool
+	}{
+		{"valid", 1, 42, expires, signature, true},
+		{"other tenant", 2, 42, expires, signature, false},
+		{"other call", 1, 43, expires, signature, false},
+		{"extended expiry", 1, 42, expires + 3600, signature, false},
+		{"tampered signature", 1, 42, expires, string(tampered), false},
+		{"empty signature", 1, 42, expires, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyRecordingSignature(tt.tenantID, tt.callID, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("VerifyRecordingSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordingSignatureExpiry(t *testing.T) {
+	useConfig(t, &config.Config{RecordingURLSecret: "recording-secret"})
+
+	expired := time.Now().Add(-time.Second).Unix()
+	if VerifyRecordingSignature(1, 42, expired, recordingSignature(1, 42, expired)) {
+		t.Error("expired link verified")
+	}
+
+	expires, signature := SignRecording(1, 42)
+	if ttl := expires - time.Now().Unix(); ttl < 299 || ttl > 300 {
+		t.Errorf("link expires in %ds, want the 300s default", ttl)
+	}
+	if !VerifyRecordingSignature(1, 42, expires, signature) {
+		t.Error("fresh link did not verify")
+	}
+}
+
+func TestRecordingSignatureSecret(t *testing.T) {
+	useConfig(t, &config.Config{RecordingURLSecret: "recording-secret", JWTSecret: "jwt-secret"})
+	expires, signature := SignRecording(1, 42)
+
+	config.AppConfig = &config.Config{RecordingURLSecret: "rotated", JWTSecret: "jwt-secret"}
+	if VerifyRecordingSignature(1, 42, expires, signature) {
+		t.Error("link verified after the secret changed")
+	}
+
+	config.AppConfig = &config.Config{JWTSecret: "jwt-secret"}
+	expires, signature = SignRecording(1, 42)
+	if recordingSignature(1, 42, expires) != signature || !VerifyRecordingSignature(1, 42, expires, signature) {
+		t.Error("link signed with the JWT secret did not verify")
+	}
+}